@@ -0,0 +1,96 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"transfer-system/domain/entities"
+)
+
+// Webhook posts a JSON alert to URL when a ledger check finds the ledger
+// inconsistent. The text field makes the payload readable by chat incoming
+// webhooks such as Slack's; the other fields carry the details
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+type driftAlert struct {
+	AccountID  int64  `json:"account_id"`
+	Currency   string `json:"currency"`
+	Recorded   string `json:"recorded_balance"`
+	Expected   string `json:"expected_balance"`
+	Difference string `json:"difference"`
+}
+
+type currencyAlert struct {
+	Currency       string `json:"currency"`
+	InitialBalance string `json:"initial_balance"`
+	Balance        string `json:"balance"`
+	Difference     string `json:"difference"`
+}
+
+type ledgerAlert struct {
+	Text            string           `json:"text"`
+	CheckedAccounts int              `json:"checked_accounts"`
+	Drifts          []*driftAlert    `json:"drifts"`
+	Currencies      []*currencyAlert `json:"unconserved_currencies"`
+}
+
+func (w *Webhook) Report(ctx context.Context, report *entities.LedgerReport) error {
+	if report.Consistent() {
+		return nil
+	}
+
+	alert := &ledgerAlert{CheckedAccounts: report.CheckedAccounts, Drifts: []*driftAlert{}, Currencies: []*currencyAlert{}}
+	for _, drift := range report.Drifts {
+		alert.Drifts = append(alert.Drifts, &driftAlert{
+			AccountID:  drift.AccountID,
+			Currency:   drift.Currency,
+			Recorded:   drift.Recorded.String(),
+			Expected:   drift.Expected.String(),
+			Difference: drift.Difference().String(),
+		})
+	}
+	for _, total := range report.Totals {
+		if total.Conserved() {
+			continue
+		}
+		alert.Currencies = append(alert.Currencies, &currencyAlert{
+			Currency:       total.Currency,
+			InitialBalance: total.InitialBalance.String(),
+			Balance:        total.Balance.String(),
+			Difference:     total.Difference().String(),
+		})
+	}
+	alert.Text = fmt.Sprintf("Ledger check failed: %d of %d accounts drifted, %d currencies not conserved",
+		len(alert.Drifts), report.CheckedAccounts, len(alert.Currencies))
+
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook responded %s", response.Status)
+	}
+	return nil
+}
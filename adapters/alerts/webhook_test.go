@@ -0,0 +1,68 @@
+package alerts_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"transfer-system/adapters/alerts"
+	"transfer-system/domain/entities"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook_Report(t *testing.T) {
+	var received map[string]interface{}
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook := &alerts.Webhook{URL: server.URL, Client: server.Client()}
+
+	t.Run("consistent ledger sends nothing", func(t *testing.T) {
+		report := &entities.LedgerReport{CheckedAccounts: 2, Totals: []*entities.CurrencyTotal{
+			{Currency: "USD", InitialBalance: decimal.NewFromInt(10), Balance: decimal.NewFromInt(10)},
+		}}
+		assert.NoError(t, webhook.Report(context.Background(), report))
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("drift", func(t *testing.T) {
+		report := &entities.LedgerReport{
+			CheckedAccounts: 3,
+			Drifts: []*entities.BalanceDrift{
+				{AccountID: 2, Currency: "USD", Recorded: decimal.NewFromInt(110), Expected: decimal.NewFromInt(100)},
+			},
+			Totals: []*entities.CurrencyTotal{
+				{Currency: "EUR", InitialBalance: decimal.NewFromInt(5), Balance: decimal.NewFromInt(5)},
+				{Currency: "USD", InitialBalance: decimal.NewFromInt(100), Balance: decimal.NewFromInt(110)},
+			},
+		}
+		assert.NoError(t, webhook.Report(context.Background(), report))
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, "Ledger check failed: 1 of 3 accounts drifted, 1 currencies not conserved", received["text"])
+		currencies, _ := received["unconserved_currencies"].([]interface{})
+		require.Len(t, currencies, 1)
+		assert.Equal(t, "10", currencies[0].(map[string]interface{})["difference"])
+	})
+}
+
+func TestWebhook_Report_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	webhook := &alerts.Webhook{URL: server.URL}
+	report := &entities.LedgerReport{Drifts: []*entities.BalanceDrift{{AccountID: 1}}}
+	assert.EqualError(t, webhook.Report(context.Background(), report), "alert webhook responded 502 Bad Gateway")
+}
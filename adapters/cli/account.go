@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"transfer-system/adapters/web/dto"
+	"transfer-system/domain/entities"
+	"transfer-system/pkg/validator"
+
+	"github.com/shopspring/decimal"
+)
+
+func (c *CLI) accountCreate(ctx context.Context, args []string) int {
+	flags, output := c.newFlagSet("account create")
+	id := flags.Int64("id", 0, "account id, generated when omitted")
+	balance := flags.String("balance", "", "initial balance, up to 5 fraction digits")
+	currency := flags.String("currency", "", "ISO 4217 currency, default "+entities.DefaultCurrency)
+	reference := flags.String("reference", "", "external reference")
+	kind := flags.String("kind", "", "customer or settlement, default customer")
+	owner := flags.Int64("owner", 0, "id of the owning customer")
+	positional, ok := parse(flags, output, args)
+	if !ok {
+		return ExitUsage
+	}
+	if len(positional) > 0 || *balance == "" {
+		flags.Usage()
+		return ExitUsage
+	}
+
+	account := &entities.Account{AccountID: *id, Currency: strings.ToUpper(*currency), ExternalReference: *reference, Kind: entities.AccountKind(*kind)}
+	amount, err := parseAmount(*balance)
+	if err != nil {
+		return c.fail(fmt.Errorf("invalid --balance: %w", err))
+	}
+	account.Balance = amount
+	if *id < 0 {
+		return c.fail(fmt.Errorf("invalid --id %d", *id))
+	}
+	if account.Currency != "" && !validator.ValidateCurrency(account.Currency) {
+		return c.fail(fmt.Errorf("invalid --currency %q, expected an ISO 4217 code such as USD", *currency))
+	}
+	if account.ExternalReference != "" && !validator.ValidateReference(account.ExternalReference) {
+		return c.fail(fmt.Errorf("invalid --reference %q", *reference))
+	}
+	if *owner > 0 {
+		account.OwnerID = owner
+	}
+
+	created, err := c.AccountService.Save(ctx, account)
+	if err != nil {
+		return c.fail(err)
+	}
+	return c.printAccount(*output, created)
+}
+
+func (c *CLI) accountShow(ctx context.Context, args []string) int {
+	flags, output := c.newFlagSet("account show")
+	reference := flags.String("reference", "", "find the account by external reference")
+	positional, ok := parse(flags, output, args)
+	if !ok {
+		return ExitUsage
+	}
+
+	var account *entities.Account
+	var err error
+	switch {
+	case *reference != "" && len(positional) == 0:
+		account, err = c.AccountService.FindByExternalReference(ctx, *reference)
+	case *reference == "" && len(positional) == 1:
+		id, parseErr := parseAccountID(positional[0])
+		if parseErr != nil {
+			return c.fail(parseErr)
+		}
+		account, err = c.AccountService.FindById(ctx, id)
+	default:
+		flags.Usage()
+		return ExitUsage
+	}
+	if err != nil {
+		return c.fail(err)
+	}
+	return c.printAccount(*output, account)
+}
+
+func (c *CLI) accountFreeze(ctx context.Context, args []string) int {
+	return c.setAccountStatus(ctx, "account freeze", entities.AccountStatusFrozen, args)
+}
+
+func (c *CLI) accountUnfreeze(ctx context.Context, args []string) int {
+	return c.setAccountStatus(ctx, "account unfreeze", entities.AccountStatusActive, args)
+}
+
+func (c *CLI) setAccountStatus(ctx context.Context, name string, status entities.AccountStatus, args []string) int {
+	flags, output := c.newFlagSet(name)
+	positional, ok := parse(flags, output, args)
+	if !ok {
+		return ExitUsage
+	}
+	if len(positional) != 1 {
+		flags.Usage()
+		return ExitUsage
+	}
+	id, err := parseAccountID(positional[0])
+	if err != nil {
+		return c.fail(err)
+	}
+
+	account, err := c.AccountService.Update(ctx, id, entities.AccountPatch{Status: &status})
+	if err != nil {
+		return c.fail(err)
+	}
+	return c.printAccount(*output, account)
+}
+
+func (c *CLI) printAccount(format string, account *entities.Account) int {
+	response := &dto.AccountResponse{
+		AccountID:         account.AccountID,
+		Balance:           account.Balance.String(),
+		ExternalReference: account.ExternalReference,
+		Currency:          account.Currency,
+		Kind:              string(account.Kind),
+		Status:            string(account.Status),
+		OwnerID:           account.OwnerID,
+		Metadata:          metadataOutput(account.Metadata),
+		CreatedAt:         account.CreatedAt,
+	}
+
+	err := c.render(format, response, func(w io.Writer) {
+		owner := "-"
+		if account.OwnerID != nil {
+			owner = strconv.FormatInt(*account.OwnerID, 10)
+		}
+		row(w, "ID", "BALANCE", "CURRENCY", "STATUS", "REFERENCE", "OWNER", "CREATED")
+		row(w, account.AccountID, account.Balance, account.Currency, account.Status, dash(account.ExternalReference), owner, account.CreatedAt.Format(timeLayout))
+	})
+	if err != nil {
+		return c.fail(err)
+	}
+	return ExitOK
+}
+
+func parseAccountID(value string) (int64, error) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid account id %q", value)
+	}
+	return id, nil
+}
+
+// parseAmount accepts plain decimals with up to five fraction digits, as stored by the database
+func parseAmount(value string) (decimal.Decimal, error) {
+	amount, err := decimal.NewFromString(value)
+	if err != nil || strings.ContainsAny(value, "eE") || amount.Exponent() < -5 {
+		return decimal.Zero, fmt.Errorf("%q is not a decimal with at most 5 fraction digits", value)
+	}
+	if amount.IsNegative() {
+		return decimal.Zero, fmt.Errorf("%q must not be negative", value)
+	}
+	return amount, nil
+}
+
+// dash stands in for empty table cells
+func dash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type apiKeyOutput struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Prefix string `json:"prefix"`
+	Secret string `json:"secret"`
+	// SigningSecret is set for keys issued with --signing
+	SigningSecret string    `json:"signing_secret,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (c *CLI) apiKeyIssue(ctx context.Context, args []string) int {
+	flags, output := c.newFlagSet("apikey issue")
+	signing := flags.Bool("signing", false, "also issue a secret the key's requests must be signed with")
+	positional, ok := parse(flags, output, args)
+	if !ok {
+		return ExitUsage
+	}
+	if len(positional) == 0 {
+		flags.Usage()
+		return ExitUsage
+	}
+
+	key, secret, err := c.APIKeyService.Issue(ctx, strings.Join(positional, " "), *signing)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	response := &apiKeyOutput{ID: key.ID, Name: key.Name, Prefix: key.Prefix, Secret: secret, SigningSecret: key.SigningSecret, CreatedAt: key.CreatedAt}
+	err = c.render(*output, response, func(w io.Writer) {
+		if key.SigningSecret == "" {
+			row(w, "ID", "NAME", "SECRET", "CREATED")
+			row(w, key.ID, key.Name, secret, key.CreatedAt.Format(timeLayout))
+			return
+		}
+		row(w, "ID", "NAME", "SECRET", "SIGNING SECRET", "CREATED")
+		row(w, key.ID, key.Name, secret, key.SigningSecret, key.CreatedAt.Format(timeLayout))
+	})
+	if err != nil {
+		return c.fail(err)
+	}
+	if key.SigningSecret != "" {
+		fmt.Fprintln(c.Stderr, "store the secrets now, they cannot be shown again")
+		return ExitOK
+	}
+	fmt.Fprintln(c.Stderr, "store the secret now, it cannot be shown again")
+	return ExitOK
+}
@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"transfer-system/domain/ports"
+	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Exit codes of a command
+const (
+	ExitOK      = 0
+	ExitFailure = 1
+	ExitUsage   = 2
+)
+
+// CLI runs the operator subcommands of the binary against the same services as the HTTP API
+type CLI struct {
+	AccountService       ports.AccountService
+	TransactionService   ports.TransactionService
+	LedgerService        ports.LedgerService
+	APIKeyService        ports.APIKeyService
+	ImportService        ports.ImportService
+	TransferBatchService ports.TransferBatchService
+	Logger               *logrus.Logger
+	Stdout               io.Writer
+	Stderr               io.Writer
+}
+
+type command struct {
+	usage string
+	run   func(c *CLI, ctx context.Context, args []string) int
+}
+
+// commands maps "group action" to its implementation. It is filled in init
+// because the commands look up their own usage here
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"account create":   {"account create --balance AMOUNT [--id ID] [--currency CODE] [--reference REF] [--kind KIND] [--owner ID]", (*CLI).accountCreate},
+		"account show":     {"account show ID | --reference REF", (*CLI).accountShow},
+		"account freeze":   {"account freeze ID", (*CLI).accountFreeze},
+		"account unfreeze": {"account unfreeze ID", (*CLI).accountUnfreeze},
+		"transfer list":    {"transfer list --account ID [--reference REF] [--type TYPE,...] [--limit N] [--cursor CURSOR]", (*CLI).transferList},
+		"ledger verify":    {"ledger verify", (*CLI).ledgerVerify},
+		"apikey issue":     {"apikey issue [--signing] NAME", (*CLI).apiKeyIssue},
+		"import accounts":  {"import accounts [--dry-run] [--job-id ID] [--column field=Header ...] FILE", (*CLI).importAccounts},
+		"import transfers": {"import transfers [--dry-run] [--job-id ID] [--column field=Header ...] FILE", (*CLI).importTransfers},
+		"import pain001":   {"import pain001 FILE", (*CLI).importPain001},
+	}
+}
+
+// IsCommand reports whether args, without the program name, start with a
+// command group; otherwise the binary serves HTTP
+func IsCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	if args[0] == "help" {
+		return true
+	}
+	for name := range commands {
+		if strings.HasPrefix(name, args[0]+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// Run executes the command named by args and returns the process exit code
+func (c *CLI) Run(args []string) int {
+	if len(args) < 2 || args[0] == "help" {
+		c.usage()
+		if len(args) > 0 && args[0] == "help" {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+
+	name := args[0] + " " + args[1]
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(c.Stderr, "unknown command %q\n", name)
+		c.usage()
+		return ExitUsage
+	}
+
+	// Ctrl-C cancels the command's database work instead of killing it mid-transaction
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx = context.WithValue(ctx, logger.LoggerContextKey, c.Logger.WithField("command", name))
+
+	return cmd.run(c, ctx, args[2:])
+}
+
+func (c *CLI) usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(c.Stderr, "usage: transfer-system [COMMAND]")
+	fmt.Fprintln(c.Stderr, "without a command the HTTP server is started. Commands:")
+	for _, name := range names {
+		fmt.Fprintf(c.Stderr, "  %s\n", commands[name].usage)
+	}
+	fmt.Fprintln(c.Stderr, "every command accepts -o json|table (default table), except import pain001, which writes a pain.002 report")
+}
+
+// newFlagSet returns the flag set of a command, with the shared output flag
+func (c *CLI) newFlagSet(name string) (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.Stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(c.Stderr, "usage: transfer-system %s\n", commands[name].usage)
+		flags.PrintDefaults()
+	}
+	output := flags.String("o", formatTable, "output format, json or table")
+	return flags, output
+}
+
+// parse parses args and returns the positional arguments. Flags may follow
+// them, as in `account show 42 -o json`. Bad flags and output formats are
+// reported with the usage
+func parse(flags *flag.FlagSet, output *string, args []string) ([]string, bool) {
+	var positional []string
+	for {
+		// the flag set reports its own parse errors along with the usage
+		if err := flags.Parse(args); err != nil {
+			return nil, false
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	if *output != formatJSON && *output != formatTable {
+		fmt.Fprintf(flags.Output(), "invalid output format %q, expected json or table\n", *output)
+		flags.Usage()
+		return nil, false
+	}
+	return positional, true
+}
+
+// fail reports err and returns the failure exit code. Service errors carry a
+// client facing message; the details are in the log written to stderr
+func (c *CLI) fail(err error) int {
+	var appErr *appErrors.AppError
+	if errors.As(err, &appErr) {
+		fmt.Fprintf(c.Stderr, "error: %s\n", appErr.Message)
+	} else {
+		fmt.Fprintf(c.Stderr, "error: %s\n", err)
+	}
+	return ExitFailure
+}
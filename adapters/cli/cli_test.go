@@ -0,0 +1,288 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"transfer-system/adapters/cli"
+	"transfer-system/domain/entities"
+	"transfer-system/mocks"
+	appErrors "transfer-system/pkg/errors"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type testCLI struct {
+	*cli.CLI
+	accounts     *mocks.MockAccountService
+	transactions *mocks.MockTransactionService
+	ledger       *mocks.MockLedgerService
+	apiKeys      *mocks.MockAPIKeyService
+	imports      *mocks.MockImportService
+	batches      *mocks.MockTransferBatchService
+	stdout       *bytes.Buffer
+	stderr       *bytes.Buffer
+}
+
+func newTestCLI() *testCLI {
+	t := &testCLI{
+		accounts:     new(mocks.MockAccountService),
+		transactions: new(mocks.MockTransactionService),
+		ledger:       new(mocks.MockLedgerService),
+		apiKeys:      new(mocks.MockAPIKeyService),
+		imports:      new(mocks.MockImportService),
+		batches:      new(mocks.MockTransferBatchService),
+		stdout:       &bytes.Buffer{},
+		stderr:       &bytes.Buffer{},
+	}
+	logger := logrus.New()
+	logger.SetOutput(t.stderr)
+	t.CLI = &cli.CLI{
+		AccountService:       t.accounts,
+		TransactionService:   t.transactions,
+		LedgerService:        t.ledger,
+		APIKeyService:        t.apiKeys,
+		ImportService:        t.imports,
+		TransferBatchService: t.batches,
+		Logger:               logger,
+		Stdout:               t.stdout,
+		Stderr:               t.stderr,
+	}
+	return t
+}
+
+func TestIsCommand(t *testing.T) {
+	assert.True(t, cli.IsCommand([]string{"account", "show", "1"}))
+	assert.True(t, cli.IsCommand([]string{"ledger"}))
+	assert.True(t, cli.IsCommand([]string{"help"}))
+	assert.False(t, cli.IsCommand(nil))
+	assert.False(t, cli.IsCommand([]string{"serve"}))
+}
+
+func TestCLI_Usage(t *testing.T) {
+	for _, args := range [][]string{{"account"}, {"account", "delete", "1"}, {"account", "show"}, {"account", "show", "1", "-o", "yaml"}, {"transfer", "list"}} {
+		c := newTestCLI()
+		assert.Equal(t, cli.ExitUsage, c.Run(args), args)
+		assert.Contains(t, c.stderr.String(), "usage: transfer-system", args)
+		assert.Empty(t, c.stdout.String(), args)
+	}
+}
+
+func TestCLI_AccountShow(t *testing.T) {
+	account := &entities.Account{
+		AccountID: 42, Balance: decimal.RequireFromString("150.5"), Currency: "EUR",
+		Status: entities.AccountStatusActive, CreatedAt: time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("table", func(t *testing.T) {
+		c := newTestCLI()
+		c.accounts.On("FindById", mock.Anything, int64(42)).Return(account, nil)
+
+		assert.Equal(t, cli.ExitOK, c.Run([]string{"account", "show", "42"}))
+		lines := strings.Split(strings.TrimSpace(c.stdout.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.Equal(t, []string{"ID", "BALANCE", "CURRENCY", "STATUS", "REFERENCE", "OWNER", "CREATED"}, strings.Fields(lines[0]))
+		assert.Equal(t, []string{"42", "150.5", "EUR", "active", "-", "-", "2025-03-31T12:00:00Z"}, strings.Fields(lines[1]))
+	})
+
+	t.Run("json by reference", func(t *testing.T) {
+		c := newTestCLI()
+		c.accounts.On("FindByExternalReference", mock.Anything, "DE89370400440532013000").Return(account, nil)
+
+		assert.Equal(t, cli.ExitOK, c.Run([]string{"account", "show", "--reference", "DE89370400440532013000", "-o", "json"}))
+		var output map[string]interface{}
+		require.NoError(t, json.Unmarshal(c.stdout.Bytes(), &output))
+		assert.Equal(t, float64(42), output["account_id"])
+		assert.Equal(t, "150.5", output["balance"])
+		assert.Equal(t, map[string]interface{}{}, output["metadata"])
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		c := newTestCLI()
+		c.accounts.On("FindById", mock.Anything, int64(7)).Return((*entities.Account)(nil), appErrors.NewNotFoundError("Account not found", nil))
+
+		assert.Equal(t, cli.ExitFailure, c.Run([]string{"account", "show", "7"}))
+		assert.Contains(t, c.stderr.String(), "error: Account not found")
+		assert.Empty(t, c.stdout.String())
+	})
+}
+
+func TestCLI_AccountCreate(t *testing.T) {
+	c := newTestCLI()
+	c.accounts.On("Save", mock.Anything, mock.MatchedBy(func(account *entities.Account) bool {
+		return account.AccountID == 0 && account.Balance.Equal(decimal.NewFromInt(100)) && account.Currency == "EUR" &&
+			account.OwnerID != nil && *account.OwnerID == 3
+	})).Return(&entities.Account{AccountID: 43, Balance: decimal.NewFromInt(100), Currency: "EUR", Status: entities.AccountStatusActive}, nil)
+
+	assert.Equal(t, cli.ExitOK, c.Run([]string{"account", "create", "--balance", "100", "--currency", "eur", "--owner", "3"}))
+	assert.Contains(t, c.stdout.String(), "43")
+
+	c = newTestCLI()
+	assert.Equal(t, cli.ExitFailure, c.Run([]string{"account", "create", "--balance", "1.234567"}))
+	assert.Contains(t, c.stderr.String(), "invalid --balance")
+	c.accounts.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestCLI_AccountFreeze(t *testing.T) {
+	c := newTestCLI()
+	c.accounts.On("Update", mock.Anything, int64(42), mock.MatchedBy(func(patch entities.AccountPatch) bool {
+		return patch.Status != nil && *patch.Status == entities.AccountStatusFrozen && patch.Metadata == nil
+	})).Return(&entities.Account{AccountID: 42, Status: entities.AccountStatusFrozen}, nil)
+
+	assert.Equal(t, cli.ExitOK, c.Run([]string{"account", "freeze", "42"}))
+	assert.Contains(t, c.stdout.String(), "frozen")
+	c.accounts.AssertExpectations(t)
+}
+
+func TestCLI_TransferList(t *testing.T) {
+	c := newTestCLI()
+	c.transactions.On("ListByAccount", mock.Anything, entities.TransactionFilter{AccountID: 42, Limit: 2}).Return(&entities.TransactionPage{
+		Transactions: []*entities.Transaction{
+			{Id: 9, Type: entities.TransactionTypeTransfer, SourceAccountID: 42, DestinationAccountID: 7, Amount: decimal.NewFromInt(5), Reference: "INV-1"},
+			{Id: 8, SourceAccountID: 7, DestinationAccountID: 42, Amount: decimal.NewFromInt(10)},
+		},
+		NextCursor: "abc",
+	}, nil)
+
+	assert.Equal(t, cli.ExitOK, c.Run([]string{"transfer", "list", "--account", "42", "--limit", "2"}))
+	lines := strings.Split(strings.TrimSpace(c.stdout.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"9", "transfer", "42", "7", "5", "INV-1", "-"}, strings.Fields(lines[1])[:7])
+	assert.Contains(t, c.stderr.String(), "--cursor abc")
+}
+
+func TestCLI_LedgerVerify(t *testing.T) {
+	t.Run("balanced", func(t *testing.T) {
+		c := newTestCLI()
+		c.ledger.On("Verify", mock.Anything).Return(&entities.LedgerReport{CheckedAccounts: 3, Drifts: []*entities.BalanceDrift{}, Totals: []*entities.CurrencyTotal{
+			{Currency: "USD", Accounts: 3, InitialBalance: decimal.NewFromInt(100), Balance: decimal.NewFromInt(100)},
+		}}, nil)
+
+		assert.Equal(t, cli.ExitOK, c.Run([]string{"ledger", "verify"}))
+		lines := strings.Split(strings.TrimSpace(c.stdout.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.Equal(t, []string{"USD", "3", "100", "100", "0"}, strings.Fields(lines[1]))
+		assert.Contains(t, c.stderr.String(), "checked 3 accounts, 0 with a drifted balance, 0 currencies not conserved")
+	})
+
+	t.Run("money not conserved", func(t *testing.T) {
+		c := newTestCLI()
+		c.ledger.On("Verify", mock.Anything).Return(&entities.LedgerReport{CheckedAccounts: 3, Drifts: []*entities.BalanceDrift{}, Totals: []*entities.CurrencyTotal{
+			{Currency: "USD", Accounts: 3, InitialBalance: decimal.NewFromInt(100), Balance: decimal.NewFromInt(90)},
+		}}, nil)
+
+		assert.Equal(t, cli.ExitFailure, c.Run([]string{"ledger", "verify"}))
+		assert.Contains(t, c.stderr.String(), "1 currencies not conserved")
+	})
+
+	t.Run("drift", func(t *testing.T) {
+		c := newTestCLI()
+		c.ledger.On("Verify", mock.Anything).Return(&entities.LedgerReport{CheckedAccounts: 3, Drifts: []*entities.BalanceDrift{
+			{AccountID: 2, Currency: "USD", Recorded: decimal.NewFromInt(110), Expected: decimal.NewFromInt(100)},
+		}}, nil)
+
+		assert.Equal(t, cli.ExitFailure, c.Run([]string{"ledger", "verify", "-o", "json"}))
+		var output struct {
+			CheckedAccounts int  `json:"checked_accounts"`
+			Consistent      bool `json:"consistent"`
+			Drifts          []struct {
+				AccountID  int64  `json:"account_id"`
+				Difference string `json:"difference"`
+			} `json:"drifts"`
+		}
+		require.NoError(t, json.Unmarshal(c.stdout.Bytes(), &output))
+		assert.Equal(t, 3, output.CheckedAccounts)
+		assert.False(t, output.Consistent)
+		require.Len(t, output.Drifts, 1)
+		assert.Equal(t, int64(2), output.Drifts[0].AccountID)
+		assert.Equal(t, "10", output.Drifts[0].Difference)
+	})
+}
+
+func TestCLI_APIKeyIssue(t *testing.T) {
+	c := newTestCLI()
+	c.apiKeys.On("Issue", mock.Anything, "payroll batch", false).Return(&entities.APIKey{ID: 5, Name: "payroll batch", Prefix: "tsk_abcdefgh"}, "tsk_abcdefgh-secret", nil)
+
+	assert.Equal(t, cli.ExitOK, c.Run([]string{"apikey", "issue", "payroll", "batch", "-o", "json"}))
+	var output map[string]interface{}
+	require.NoError(t, json.Unmarshal(c.stdout.Bytes(), &output))
+	assert.Equal(t, "tsk_abcdefgh-secret", output["secret"])
+	assert.Contains(t, c.stderr.String(), "cannot be shown again")
+	assert.NotContains(t, c.stdout.String(), "signing_secret")
+}
+
+func TestCLI_APIKeyIssue_Signing(t *testing.T) {
+	c := newTestCLI()
+	c.apiKeys.On("Issue", mock.Anything, "partner", true).Return(&entities.APIKey{ID: 6, Name: "partner", Prefix: "tsk_ijklmnop", SigningSecret: "tss_signing"}, "tsk_ijklmnop-secret", nil)
+
+	assert.Equal(t, cli.ExitOK, c.Run([]string{"apikey", "issue", "--signing", "partner"}))
+	assert.Contains(t, c.stdout.String(), "SIGNING SECRET")
+	assert.Contains(t, c.stdout.String(), "tss_signing")
+	assert.Contains(t, c.stderr.String(), "store the secrets now")
+}
+
+func TestCLI_ImportTransfers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payments.csv")
+	require.NoError(t, os.WriteFile(path, []byte("from,to,amount\n1,2,10\n2,1,5\n"), 0o600))
+
+	t.Run("failed job", func(t *testing.T) {
+		c := newTestCLI()
+		job := &entities.ImportJob{ID: "3f0c2a8e-6d55-4a56-9d6b-2b1f3c0d9e11", Kind: entities.ImportKindTransfers, Status: entities.ImportStatusFailed, TotalRows: 2, CommittedRows: 1, FailedLine: 3}
+		c.imports.On("Import", mock.Anything, mock.MatchedBy(func(file *entities.ImportFile) bool {
+			return len(file.Rows) == 2
+		}), "").Return(job, &appErrors.AppError{Message: "line 3: Insufficient balance", StatusCode: http.StatusUnprocessableEntity})
+
+		code := c.Run([]string{"import", "transfers", "--column", "source_account_id=from", "--column", "destination_account_id=to", path})
+		assert.Equal(t, cli.ExitFailure, code)
+		assert.Contains(t, c.stdout.String(), "failed")
+		assert.Contains(t, c.stderr.String(), "error: line 3: Insufficient balance")
+		assert.Contains(t, c.stderr.String(), "--job-id "+job.ID)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		c := newTestCLI()
+		c.imports.On("Validate", mock.Anything, mock.Anything).Return([]entities.ImportRowError{{Line: 3, Message: "source_account_id 2 not found"}}, nil)
+
+		code := c.Run([]string{"import", "transfers", "--dry-run", "--column", "source_account_id=from", "--column", "destination_account_id=to", path})
+		assert.Equal(t, cli.ExitFailure, code)
+		assert.Contains(t, c.stdout.String(), "source_account_id 2 not found")
+		assert.Contains(t, c.stderr.String(), "1 valid rows, 1 invalid rows")
+		c.imports.AssertNotCalled(t, "Import", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestCLI_ImportPain001(t *testing.T) {
+	c := newTestCLI()
+	results := []*entities.TransferInstructionResult{
+		{Instruction: &entities.TransferInstruction{PaymentInformationID: "PAYROLL-03", Reference: "SALARY-0042"}, Transaction: &entities.Transaction{Id: 501}},
+		{Instruction: &entities.TransferInstruction{PaymentInformationID: "PAYROLL-03"}, Transaction: &entities.Transaction{Id: 502}},
+		{Instruction: &entities.TransferInstruction{PaymentInformationID: "SUPPLIERS-03", Reference: "SUP-981"}, Err: appErrors.NewNotFoundError("Account 99 not found", entities.ErrUnknownAccount)},
+	}
+	c.batches.On("Execute", mock.Anything, mock.MatchedBy(func(batch *entities.TransferBatch) bool {
+		return batch.MessageID == "ACME-2025-03-31-001" && len(batch.Instructions) == 3
+	})).Return(results, nil)
+
+	code := c.Run([]string{"import", "pain001", "../iso20022/testdata/pain001_batch.xml"})
+	assert.Equal(t, cli.ExitFailure, code)
+	assert.Contains(t, c.stdout.String(), "<GrpSts>PART</GrpSts>")
+	assert.Contains(t, c.stdout.String(), "<Cd>AC01</Cd>")
+	assert.Contains(t, c.stderr.String(), "2 instructions booked, 1 rejected")
+
+	c = newTestCLI()
+	path := filepath.Join(t.TempDir(), "batch.xml")
+	require.NoError(t, os.WriteFile(path, []byte("<Document><CstmrCdtTrfInitn/></Document>"), 0o600))
+	assert.Equal(t, cli.ExitFailure, c.Run([]string{"import", "pain001", path}))
+	assert.Contains(t, c.stderr.String(), "error: ")
+	assert.Empty(t, c.stdout.String())
+	c.batches.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"transfer-system/adapters/csvimport"
+	"transfer-system/adapters/iso20022"
+	"transfer-system/adapters/web/dto"
+	"transfer-system/domain/entities"
+
+	"github.com/google/uuid"
+)
+
+// columnFlags collects repeated --column field=Header options
+type columnFlags csvimport.Columns
+
+func (c columnFlags) String() string {
+	pairs := make([]string, 0, len(c))
+	for field, header := range c {
+		pairs = append(pairs, field+"="+header)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (c columnFlags) Set(value string) error {
+	field, header, ok := strings.Cut(value, "=")
+	if !ok || field == "" || header == "" {
+		return fmt.Errorf("expected field=Header, got %q", value)
+	}
+	c[field] = header
+	return nil
+}
+
+func (c *CLI) importAccounts(ctx context.Context, args []string) int {
+	return c.importFile(ctx, entities.ImportKindAccounts, args)
+}
+
+func (c *CLI) importTransfers(ctx context.Context, args []string) int {
+	return c.importFile(ctx, entities.ImportKindTransfers, args)
+}
+
+// importFile mirrors POST /imports/{kind}; it exits with ExitFailure when a row is invalid or rejected
+func (c *CLI) importFile(ctx context.Context, kind entities.ImportKind, args []string) int {
+	name := "import " + string(kind)
+	flags, output := c.newFlagSet(name)
+	dryRun := flags.Bool("dry-run", false, "only validate the file, report every invalid row")
+	jobID := flags.String("job-id", "", "resume the import job started with the same file")
+	columns := columnFlags{}
+	flags.Var(columns, "column", "read a field from a differently named header, as field=Header; repeatable. Fields: "+strings.Join(csvimport.Fields(kind), ", "))
+	positional, ok := parse(flags, output, args)
+	if !ok {
+		return ExitUsage
+	}
+	if len(positional) != 1 {
+		flags.Usage()
+		return ExitUsage
+	}
+
+	if *jobID != "" {
+		if _, err := uuid.Parse(*jobID); err != nil {
+			return c.fail(fmt.Errorf("invalid --job-id %q, expected a UUID", *jobID))
+		}
+	}
+
+	in, err := os.Open(positional[0])
+	if err != nil {
+		return c.fail(err)
+	}
+	defer in.Close()
+
+	file, rowErrors, err := csvimport.Parse(in, kind, csvimport.Columns(columns))
+	if err != nil {
+		return c.fail(err)
+	}
+
+	if *dryRun {
+		found, err := c.ImportService.Validate(ctx, file)
+		if err != nil {
+			return c.fail(err)
+		}
+		rowErrors = append(rowErrors, found...)
+		if code := c.printRowErrors(*output, len(file.Rows)-len(found), rowErrors); code != ExitOK {
+			return code
+		}
+		fmt.Fprintln(c.Stderr, "dry run, nothing was imported")
+		if len(rowErrors) > 0 {
+			return ExitFailure
+		}
+		return ExitOK
+	}
+
+	if len(rowErrors) > 0 {
+		if code := c.printRowErrors(*output, len(file.Rows), rowErrors); code != ExitOK {
+			return code
+		}
+		fmt.Fprintln(c.Stderr, "error: the file has invalid rows, nothing was imported")
+		return ExitFailure
+	}
+
+	job, importErr := c.ImportService.Import(ctx, file, *jobID)
+	if job != nil {
+		response := &dto.ImportJobResponse{
+			ID:            job.ID,
+			Kind:          string(job.Kind),
+			Status:        string(job.Status),
+			CommittedRows: job.CommittedRows,
+			TotalRows:     job.TotalRows,
+			FailedLine:    job.FailedLine,
+			Error:         job.Error,
+			CreatedAt:     job.CreatedAt,
+			UpdatedAt:     job.UpdatedAt,
+		}
+		err = c.render(*output, response, func(w io.Writer) {
+			row(w, "JOB", "STATUS", "COMMITTED", "TOTAL", "FAILED LINE")
+			failedLine := "-"
+			if job.FailedLine != 0 {
+				failedLine = fmt.Sprint(job.FailedLine)
+			}
+			row(w, job.ID, job.Status, job.CommittedRows, job.TotalRows, failedLine)
+		})
+		if err != nil {
+			return c.fail(err)
+		}
+	}
+	if importErr != nil {
+		code := c.fail(importErr)
+		if job != nil {
+			fmt.Fprintf(c.Stderr, "fix the cause and run again with --job-id %s to continue\n", job.ID)
+		}
+		return code
+	}
+	return ExitOK
+}
+
+// importPain001 mirrors POST /transactions/batch and writes the pain.002 status
+// report to stdout; it exits with ExitFailure when an instruction was rejected
+func (c *CLI) importPain001(ctx context.Context, args []string) int {
+	flags, output := c.newFlagSet("import pain001")
+	positional, ok := parse(flags, output, args)
+	if !ok {
+		return ExitUsage
+	}
+	if len(positional) != 1 {
+		flags.Usage()
+		return ExitUsage
+	}
+
+	in, err := os.Open(positional[0])
+	if err != nil {
+		return c.fail(err)
+	}
+	defer in.Close()
+
+	message, err := iso20022.ParsePain001(in)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	results, err := c.TransferBatchService.Execute(ctx, message.Batch)
+	if err != nil {
+		return c.fail(err)
+	}
+	if err := iso20022.WritePain002(c.Stdout, message, results, time.Now()); err != nil {
+		return c.fail(err)
+	}
+
+	rejected := 0
+	for _, result := range results {
+		if result.Err != nil {
+			rejected++
+		}
+	}
+	fmt.Fprintf(c.Stderr, "%d instructions booked, %d rejected\n", len(results)-rejected, rejected)
+	if rejected > 0 {
+		return ExitFailure
+	}
+	return ExitOK
+}
+
+func (c *CLI) printRowErrors(format string, validRows int, rowErrors []entities.ImportRowError) int {
+	sort.SliceStable(rowErrors, func(i, j int) bool { return rowErrors[i].Line < rowErrors[j].Line })
+
+	response := &dto.ImportValidationResponse{
+		ValidRows: validRows,
+		Errors:    make([]*dto.ImportRowErrorResponse, 0, len(rowErrors)),
+	}
+	for _, rowError := range rowErrors {
+		response.Errors = append(response.Errors, &dto.ImportRowErrorResponse{Line: rowError.Line, Message: rowError.Message})
+	}
+
+	err := c.render(format, response, func(w io.Writer) {
+		if len(rowErrors) == 0 {
+			return
+		}
+		row(w, "LINE", "ERROR")
+		for _, rowError := range rowErrors {
+			row(w, rowError.Line, rowError.Message)
+		}
+	})
+	if err != nil {
+		return c.fail(err)
+	}
+	fmt.Fprintf(c.Stderr, "%d valid rows, %d invalid rows\n", validRows, len(rowErrors))
+	return ExitOK
+}
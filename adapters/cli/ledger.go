@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+type balanceDriftOutput struct {
+	AccountID  int64  `json:"account_id"`
+	Currency   string `json:"currency"`
+	Recorded   string `json:"recorded_balance"`
+	Expected   string `json:"expected_balance"`
+	Difference string `json:"difference"`
+}
+
+type currencyTotalOutput struct {
+	Currency       string `json:"currency"`
+	Accounts       int    `json:"accounts"`
+	InitialBalance string `json:"initial_balance"`
+	Balance        string `json:"balance"`
+	Difference     string `json:"difference"`
+	Conserved      bool   `json:"conserved"`
+}
+
+type ledgerReportOutput struct {
+	CheckedAccounts int                    `json:"checked_accounts"`
+	Consistent      bool                   `json:"consistent"`
+	Drifts          []*balanceDriftOutput  `json:"drifts"`
+	Totals          []*currencyTotalOutput `json:"totals"`
+}
+
+// ledgerVerify exits with ExitFailure when any balance drifted or a currency
+// total changed, so it can gate scripts
+func (c *CLI) ledgerVerify(ctx context.Context, args []string) int {
+	flags, output := c.newFlagSet("ledger verify")
+	positional, ok := parse(flags, output, args)
+	if !ok {
+		return ExitUsage
+	}
+	if len(positional) > 0 {
+		flags.Usage()
+		return ExitUsage
+	}
+
+	report, err := c.LedgerService.Verify(ctx)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	response := &ledgerReportOutput{
+		CheckedAccounts: report.CheckedAccounts,
+		Consistent:      report.Consistent(),
+		Drifts:          []*balanceDriftOutput{},
+		Totals:          []*currencyTotalOutput{},
+	}
+	for _, drift := range report.Drifts {
+		response.Drifts = append(response.Drifts, &balanceDriftOutput{
+			AccountID:  drift.AccountID,
+			Currency:   drift.Currency,
+			Recorded:   drift.Recorded.String(),
+			Expected:   drift.Expected.String(),
+			Difference: drift.Difference().String(),
+		})
+	}
+
+	unconserved := 0
+	for _, total := range report.Totals {
+		if !total.Conserved() {
+			unconserved++
+		}
+		response.Totals = append(response.Totals, &currencyTotalOutput{
+			Currency:       total.Currency,
+			Accounts:       total.Accounts,
+			InitialBalance: total.InitialBalance.String(),
+			Balance:        total.Balance.String(),
+			Difference:     total.Difference().String(),
+			Conserved:      total.Conserved(),
+		})
+	}
+
+	err = c.render(*output, response, func(w io.Writer) {
+		row(w, "CURRENCY", "ACCOUNTS", "INITIAL", "BALANCE", "DIFFERENCE")
+		for _, total := range report.Totals {
+			row(w, total.Currency, total.Accounts, total.InitialBalance, total.Balance, total.Difference())
+		}
+		if len(report.Drifts) == 0 {
+			return
+		}
+		row(w)
+		row(w, "ACCOUNT", "CURRENCY", "RECORDED", "EXPECTED", "DIFFERENCE")
+		for _, drift := range report.Drifts {
+			row(w, drift.AccountID, drift.Currency, drift.Recorded, drift.Expected, drift.Difference())
+		}
+	})
+	if err != nil {
+		return c.fail(err)
+	}
+
+	fmt.Fprintf(c.Stderr, "checked %d accounts, %d with a drifted balance, %d currencies not conserved\n",
+		report.CheckedAccounts, len(report.Drifts), unconserved)
+	if !report.Consistent() {
+		return ExitFailure
+	}
+	return ExitOK
+}
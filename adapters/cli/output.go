@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"transfer-system/domain/entities"
+)
+
+const (
+	formatJSON  = "json"
+	formatTable = "table"
+)
+
+// timeLayout formats timestamps in table output
+const timeLayout = time.RFC3339
+
+// render writes value as indented JSON, or calls table to write aligned
+// tab separated columns
+func (c *CLI) render(format string, value interface{}, table func(w io.Writer)) error {
+	if format == formatJSON {
+		encoder := json.NewEncoder(c.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	writer := tabwriter.NewWriter(c.Stdout, 0, 4, 2, ' ', 0)
+	table(writer)
+	return writer.Flush()
+}
+
+// row writes one table row
+func row(w io.Writer, columns ...interface{}) {
+	for i, column := range columns {
+		if i > 0 {
+			fmt.Fprint(w, "\t")
+		}
+		fmt.Fprint(w, column)
+	}
+	fmt.Fprintln(w)
+}
+
+// metadataOutput renders missing metadata as an empty object, as the HTTP API does
+func metadataOutput(metadata entities.Metadata) map[string]string {
+	if metadata == nil {
+		return map[string]string{}
+	}
+	return metadata
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"transfer-system/adapters/web/dto"
+	"transfer-system/domain/entities"
+)
+
+func (c *CLI) transferList(ctx context.Context, args []string) int {
+	flags, output := c.newFlagSet("transfer list")
+	account := flags.Int64("account", 0, "account whose transfers are listed, newest first")
+	reference := flags.String("reference", "", "only transfers with this client reference")
+	types := flags.String("type", "", "comma separated types: transfer, deposit, withdrawal, fee, reversal")
+	limit := flags.Int("limit", 0, "page size, default 50")
+	cursor := flags.String("cursor", "", "next_cursor of the previous page")
+	positional, ok := parse(flags, output, args)
+	if !ok {
+		return ExitUsage
+	}
+	if len(positional) > 0 || *account <= 0 || *limit < 0 {
+		flags.Usage()
+		return ExitUsage
+	}
+
+	filter := entities.TransactionFilter{
+		AccountID: *account,
+		Reference: *reference,
+		Cursor:    *cursor,
+		Limit:     *limit,
+	}
+	if *types != "" {
+		for _, name := range strings.Split(*types, ",") {
+			transactionType := entities.TransactionType(strings.TrimSpace(name))
+			if !transactionType.IsValid() {
+				return c.fail(fmt.Errorf("invalid --type %q", name))
+			}
+			filter.Types = append(filter.Types, transactionType)
+		}
+	}
+
+	page, err := c.TransactionService.ListByAccount(ctx, filter)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	response := &dto.TransactionListResponse{
+		Transactions: make([]*dto.TransactionResponse, 0, len(page.Transactions)),
+		NextCursor:   page.NextCursor,
+	}
+	for _, transaction := range page.Transactions {
+		item := &dto.TransactionResponse{
+			Id:                   transaction.Id,
+			Type:                 string(transaction.Type),
+			SourceAccountID:      transaction.SourceAccountID,
+			DestinationAccountID: transaction.DestinationAccountID,
+			Amount:               transaction.Amount.String(),
+			Description:          transaction.Description,
+			Reference:            transaction.Reference,
+			Metadata:             metadataOutput(transaction.Metadata),
+			CreatedAt:            transaction.CreatedAt,
+		}
+		if remittance := transaction.Remittance; remittance != nil {
+			item.Remittance = &dto.RemittanceResponse{
+				CreditorReference: remittance.CreditorReference,
+				InvoiceNumber:     remittance.InvoiceNumber,
+			}
+			if remittance.InvoiceDate != nil {
+				item.Remittance.InvoiceDate = remittance.InvoiceDate.Format("2006-01-02")
+			}
+		}
+		response.Transactions = append(response.Transactions, item)
+	}
+
+	err = c.render(*output, response, func(w io.Writer) {
+		row(w, "ID", "TYPE", "FROM", "TO", "AMOUNT", "REFERENCE", "DESCRIPTION", "CREATED")
+		for _, transaction := range page.Transactions {
+			row(w, transaction.Id, dash(string(transaction.Type)), transaction.SourceAccountID, transaction.DestinationAccountID, transaction.Amount,
+				dash(transaction.Reference), dash(transaction.Description), transaction.CreatedAt.Format(timeLayout))
+		}
+	})
+	if err != nil {
+		return c.fail(err)
+	}
+	// in JSON the cursor is part of the document
+	if *output == formatTable && page.NextCursor != "" {
+		fmt.Fprintf(c.Stderr, "more transfers: --cursor %s\n", page.NextCursor)
+	}
+	return ExitOK
+}
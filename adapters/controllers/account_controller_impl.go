@@ -1,9 +1,11 @@
 package controllers
 
 import (
-	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"transfer-system/adapters/web"
 	"transfer-system/adapters/web/dto"
@@ -20,39 +22,35 @@ import (
 
 type AccountController struct {
 	AccountService ports.AccountService
+	Binder         web.Binder
 }
 
 // Create Acount godoc
 // @Summary      Create Account
-// @Description  Add new account with initial balance
+// @Description  Add new account with initial balance. The account id is generated when account_id is omitted
 // @Tags         Accounts
 // @Accept       json
 // @Produce      json
 // @Param body body dto.AccountRequest true "Account creation payload" example({"account_id":123,"initial_balance":"100.23344"})
-// @Success      201   {object}  dto.WebResponse
-// @Failure      400   {object}  dto.WebResponse
+// @Success      201   {object}  dto.WebResponse{data=dto.AccountResponse}
+// @Failure      400   {object}  dto.WebResponse{data=dto.ValidationErrorResponse}
+// @Failure      409   {object}  dto.WebResponse
+// @Failure      403   {object}  dto.WebResponse
 // @Failure      500   {object}  dto.WebResponse
+// @Failure      503   {object}  dto.WebResponse
+// @Failure      504   {object}  dto.WebResponse
 // @Router       /accounts [post]
 func (c *AccountController) Create(ctx echo.Context) error {
 	logger, _ := ctx.Request().Context().Value(logger.LoggerContextKey).(*logrus.Entry)
-	accountRequest := dto.AccountRequest{}
-
-	if err := web.GetPayload(ctx, &accountRequest); err != nil {
-		return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
-			Message: "Invalid request Payload",
-			Status:  0,
-			Data:    nil,
-		})
+	if err := authorize(ctx, entities.ScopeAccountsWrite); err != nil {
+		return errorResponse(ctx, err)
 	}
+	accountRequest := dto.AccountRequest{}
 
-	if !validator.ValidateDecimalFormat(accountRequest.Balance) {
-		logger.Errorf("Invalid initial balance format: %s", accountRequest.Balance)
-		return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
-			Message: "Invalid initial balance format",
-			Status:  0,
-			Data:    nil,
-		})
+	if err := c.Binder.Bind(ctx, &accountRequest); err != nil {
+		return invalidPayloadResponse(ctx, err)
 	}
+	metadata := entities.Metadata(accountRequest.Metadata)
 
 	initialBalanceDecimal, err := decimal.NewFromString(accountRequest.Balance)
 	if err != nil {
@@ -65,52 +63,56 @@ func (c *AccountController) Create(ctx echo.Context) error {
 	}
 
 	internalServiceRequest := &entities.Account{
-		AccountID: accountRequest.AccountID,
-		Balance:   initialBalanceDecimal,
+		AccountID:         accountRequest.AccountID,
+		Balance:           initialBalanceDecimal,
+		ExternalReference: accountRequest.ExternalReference,
+		Currency:          accountRequest.Currency,
+		Kind:              entities.AccountKind(accountRequest.Kind),
+		OwnerID:           accountRequest.OwnerID,
+		Metadata:          metadata,
 	}
 
-	err = c.AccountService.Save(ctx.Request().Context(), internalServiceRequest)
+	account, err := c.AccountService.Save(ctx.Request().Context(), internalServiceRequest)
 
 	if err != nil {
-		var appErr *appErrors.AppError
-		if errors.As(err, &appErr) {
-			return ctx.JSON(appErr.StatusCode, dto.WebResponse{
-				Message: appErr.Message,
-				Status:  0,
-				Data:    nil,
-			})
-		} else {
-			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse{
-				Message: "An unexpected error occurred",
-				Status:  0,
-				Data:    nil,
-			})
-		}
+		return errorResponse(ctx, err)
 	}
 
 	response := dto.WebResponse{
 		Message: "success create account",
 		Status:  1,
-		Data:    nil,
+		Data:    toAccountResponse(account),
 	}
 
+	ctx.Response().Header().Set("ETag", accountETag(account))
+
 	return ctx.JSON(http.StatusCreated, response)
 }
 
 // FindById godoc
 // @Summary Get Account by ID
-// @Description Get an account by its ID
+// @Description Get an account by its ID. The ETag header carries the account version; send it back
+// @Description in If-None-Match to get 304 while the account is unchanged, or in If-Match to update it.
 // @ID get-account-by-id
 // @Tags         Accounts
 // @Accept json
 // @Produce json
 // @Param accountId path int true "Account ID" // Name is 'accountId'
+// @Param If-None-Match header string false "ETag from an earlier response"
 // @Success 200 {object} dto.WebResponse{data=dto.AccountResponse} "Successfully retrieved account"
+// @Success 304 "Account unchanged since the ETag in If-None-Match"
 // @Failure 400 {object} dto.WebResponse "Invalid accountId format"
 // @Failure 404 {object} dto.WebResponse "Account not found"
+// @Failure 403 {object} dto.WebResponse "Bearer token lacks the scope"
+// @Failure 500 {object} dto.WebResponse "Internal error"
+// @Failure 503 {object} dto.WebResponse "Database unavailable, safe to retry"
+// @Failure 504 {object} dto.WebResponse "Request timed out, safe to retry"
 // @Router /accounts/{accountId} [get] // Path parameter is {accountId}
 func (c *AccountController) FindById(ctx echo.Context) error {
 	logger, _ := ctx.Request().Context().Value("logger").(*logrus.Entry)
+	if err := authorize(ctx, entities.ScopeAccountsRead); err != nil {
+		return errorResponse(ctx, err)
+	}
 	accountIdStr := ctx.Param("accountId")
 
 	accountId, err := strconv.ParseInt(accountIdStr, 10, 64)
@@ -127,24 +129,331 @@ func (c *AccountController) FindById(ctx echo.Context) error {
 
 	if err != nil {
 		logger.Error("Error find by id controller: ", err)
+		return errorResponse(ctx, err)
+	}
+
+	etag := accountETag(account)
+	ctx.Response().Header().Set("ETag", etag)
+	if match := ctx.Request().Header.Get("If-None-Match"); match != "" && etagListed(match, etag) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
 
-		return ctx.JSON(http.StatusNotFound, dto.WebResponse{
-			Message: "Account not found",
+	response := dto.WebResponse{
+		Message: "success get account by id",
+		Status:  1,
+		Data:    toAccountResponse(account),
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// Update godoc
+// @Summary Update account
+// @Description Partially update an account. Metadata keys are merged into the stored metadata; a null value removes the key.
+// @Description Status freezes, unfreezes or closes the account; a closed account cannot be reopened.
+// @Description If-Match must carry the ETag the account was read with, or * to update whatever the version.
+// @ID update-account
+// @Tags         Accounts
+// @Accept json
+// @Produce json
+// @Param accountId path int true "Account ID"
+// @Param If-Match header string true "ETag of the account version being updated, or *"
+// @Param body body dto.AccountPatchRequest true "Account patch payload" example({"metadata":{"cost_center":"CC-42","legacy_code":null},"status":"frozen"})
+// @Success 200 {object} dto.WebResponse{data=dto.AccountResponse} "Successfully updated account"
+// @Failure 400 {object} dto.WebResponse{data=dto.ValidationErrorResponse} "Invalid payload"
+// @Failure 404 {object} dto.WebResponse "Account not found"
+// @Failure 409 {object} dto.WebResponse "Account is closed"
+// @Failure 412 {object} dto.WebResponse "Account changed since the ETag in If-Match"
+// @Failure 422 {object} dto.WebResponse "Merged metadata exceeds the limits"
+// @Failure 428 {object} dto.WebResponse "If-Match header missing"
+// @Failure 403 {object} dto.WebResponse "Bearer token lacks the scope"
+// @Failure 500 {object} dto.WebResponse "Internal error"
+// @Failure 503 {object} dto.WebResponse "Database unavailable, safe to retry"
+// @Failure 504 {object} dto.WebResponse "Request timed out, safe to retry"
+// @Router /accounts/{accountId} [patch]
+func (c *AccountController) Update(ctx echo.Context) error {
+	logger, _ := ctx.Request().Context().Value(logger.LoggerContextKey).(*logrus.Entry)
+	if err := authorize(ctx, entities.ScopeAccountsWrite); err != nil {
+		return errorResponse(ctx, err)
+	}
+	accountIdStr := ctx.Param("accountId")
+
+	accountId, err := strconv.ParseInt(accountIdStr, 10, 64)
+	if err != nil {
+		logger.WithError(err).Errorf("Invalid accountId parameter: %s", accountIdStr)
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
+			Message: "Invalid accountId format. Please provide a valid number.",
 			Status:  0,
 			Data:    nil,
 		})
 	}
 
-	accountResponse := &dto.AccountResponse{
-		AccountID: account.AccountID,
-		Balance:   account.Balance.String(),
+	patchRequest := dto.AccountPatchRequest{}
+	if err := c.Binder.Bind(ctx, &patchRequest); err != nil {
+		return invalidPayloadResponse(ctx, err)
+	}
+
+	// the payload is checked first: a request that would fail anyway answers 400, not 428 or 412
+	ifMatch := ctx.Request().Header.Get("If-Match")
+	if ifMatch == "" {
+		logger.Errorf("Update of AccountID %d without If-Match", accountId)
+		return errorResponse(ctx, appErrors.NewPreconditionRequiredError("If-Match header is required, send the ETag the account was read with", nil))
+	}
+	version, ok := ifMatchVersion(ifMatch)
+	if !ok {
+		logger.Errorf("If-Match %s cannot match AccountID %d", ifMatch, accountId)
+		return errorResponse(ctx, appErrors.NewPreconditionFailedError("Account was modified, fetch it again and retry", nil))
+	}
+
+	patch := entities.AccountPatch{Metadata: patchRequest.Metadata, Version: version}
+	if patchRequest.Status != "" {
+		status := entities.AccountStatus(patchRequest.Status)
+		patch.Status = &status
+	}
+	account, err := c.AccountService.Update(ctx.Request().Context(), accountId, patch)
+	if err != nil {
+		logger.Error("Error update account controller: ", err)
+		return errorResponse(ctx, err)
 	}
 
 	response := dto.WebResponse{
-		Message: "success get account by id",
+		Message: "success update account",
+		Status:  1,
+		Data:    toAccountResponse(account),
+	}
+
+	ctx.Response().Header().Set("ETag", accountETag(account))
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// FindByExternalReference looks up the single account holding the external_reference query parameter
+func (c *AccountController) FindByExternalReference(ctx echo.Context) error {
+	logger, _ := ctx.Request().Context().Value(logger.LoggerContextKey).(*logrus.Entry)
+	reference := ctx.QueryParam("external_reference")
+
+	if !validator.ValidateReference(reference) {
+		logger.Errorf("Invalid external_reference parameter: %s", reference)
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
+			Message: "Invalid external_reference. Please provide a valid reference.",
+			Status:  0,
+			Data:    nil,
+		})
+	}
+
+	account, err := c.AccountService.FindByExternalReference(ctx.Request().Context(), reference)
+
+	if err != nil {
+		logger.Error("Error find by external reference controller: ", err)
+		return errorResponse(ctx, err)
+	}
+
+	response := dto.WebResponse{
+		Message: "success get account by external reference",
+		Status:  1,
+		Data:    toAccountResponse(account),
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// List godoc
+// @Summary List accounts
+// @Description List accounts page by page. Pass next_cursor from the previous page as cursor to continue.
+// @Description When external_reference is given the single matching account is returned instead of a page.
+// @ID list-accounts
+// @Tags         Accounts
+// @Accept json
+// @Produce json
+// @Param external_reference query string false "Return the account with this external reference"
+// @Param status query string false "Account status" Enums(active, frozen, closed)
+// @Param currency query string false "ISO 4217 currency code"
+// @Param min_balance query string false "Minimum balance, inclusive"
+// @Param max_balance query string false "Maximum balance, inclusive"
+// @Param created_from query string false "Created at or after (RFC 3339)"
+// @Param created_to query string false "Created before (RFC 3339)"
+// @Param owner_id query int false "Owning customer id"
+// @Param metadata[key] query string false "Only accounts whose metadata holds key with this value; repeat for several keys"
+// @Param sort query string false "Sort field, prefix with - for descending" Enums(id, -id, balance, -balance, created_at, -created_at)
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Param include_total query bool false "Include the total number of matching accounts"
+// @Success 200 {object} dto.WebResponse{data=dto.AccountListResponse} "Successfully listed accounts"
+// @Failure 400 {object} dto.WebResponse "Invalid filter"
+// @Failure 404 {object} dto.WebResponse "Account not found (external_reference lookup)"
+// @Failure 403 {object} dto.WebResponse "Bearer token lacks the scope"
+// @Failure 500 {object} dto.WebResponse "Internal error"
+// @Failure 503 {object} dto.WebResponse "Database unavailable, safe to retry"
+// @Failure 504 {object} dto.WebResponse "Request timed out, safe to retry"
+// @Router /accounts [get]
+func (c *AccountController) List(ctx echo.Context) error {
+	logger, _ := ctx.Request().Context().Value(logger.LoggerContextKey).(*logrus.Entry)
+	if err := authorize(ctx, entities.ScopeAccountsRead); err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	if ctx.QueryParams().Has("external_reference") {
+		return c.FindByExternalReference(ctx)
+	}
+
+	filter, err := parseAccountFilter(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Invalid account filter")
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
+			Message: err.Error(),
+			Status:  0,
+			Data:    nil,
+		})
+	}
+
+	page, err := c.AccountService.List(ctx.Request().Context(), filter)
+	if err != nil {
+		logger.Error("Error list accounts controller: ", err)
+		return errorResponse(ctx, err)
+	}
+
+	listResponse := &dto.AccountListResponse{
+		Accounts:   make([]*dto.AccountResponse, 0, len(page.Accounts)),
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	}
+	for _, account := range page.Accounts {
+		listResponse.Accounts = append(listResponse.Accounts, toAccountResponse(account))
+	}
+
+	response := dto.WebResponse{
+		Message: "success list accounts",
 		Status:  1,
-		Data:    accountResponse,
+		Data:    listResponse,
 	}
 
 	return ctx.JSON(http.StatusOK, response)
 }
+
+func parseAccountFilter(ctx echo.Context) (entities.AccountFilter, error) {
+	filter := entities.AccountFilter{
+		Status:   entities.AccountStatus(ctx.QueryParam("status")),
+		Currency: ctx.QueryParam("currency"),
+		Cursor:   ctx.QueryParam("cursor"),
+	}
+
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return filter, fmt.Errorf("invalid status %q", filter.Status)
+	}
+	if filter.Currency != "" && !validator.ValidateCurrency(filter.Currency) {
+		return filter, fmt.Errorf("invalid currency %q", filter.Currency)
+	}
+
+	var err error
+	if filter.MinBalance, err = parseOptionalDecimal(ctx, "min_balance"); err != nil {
+		return filter, err
+	}
+	if filter.MaxBalance, err = parseOptionalDecimal(ctx, "max_balance"); err != nil {
+		return filter, err
+	}
+	if filter.CreatedFrom, err = parseOptionalTime(ctx, "created_from"); err != nil {
+		return filter, err
+	}
+	if filter.CreatedTo, err = parseOptionalTime(ctx, "created_to"); err != nil {
+		return filter, err
+	}
+	if value := ctx.QueryParam("owner_id"); value != "" {
+		ownerID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("invalid owner_id %q", value)
+		}
+		filter.OwnerID = &ownerID
+	}
+	for name, values := range ctx.QueryParams() {
+		key, ok := queryMapKey("metadata", name)
+		if !ok {
+			continue
+		}
+		if filter.Metadata == nil {
+			filter.Metadata = entities.Metadata{}
+		}
+		filter.Metadata[key] = values[0]
+	}
+	if err := filter.Metadata.Validate(); err != nil {
+		return filter, err
+	}
+	if value := ctx.QueryParam("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit <= 0 {
+			return filter, fmt.Errorf("invalid limit %q", value)
+		}
+	}
+	if value := ctx.QueryParam("include_total"); value != "" {
+		if filter.IncludeTotal, err = strconv.ParseBool(value); err != nil {
+			return filter, fmt.Errorf("invalid include_total %q", value)
+		}
+	}
+
+	sort := ctx.QueryParam("sort")
+	if strings.HasPrefix(sort, "-") {
+		filter.SortDesc = true
+		sort = sort[1:]
+	}
+	switch entities.AccountSortField(sort) {
+	case "", entities.AccountSortById, entities.AccountSortByBalance, entities.AccountSortByCreatedAt:
+		filter.SortBy = entities.AccountSortField(sort)
+	default:
+		return filter, fmt.Errorf("invalid sort %q", ctx.QueryParam("sort"))
+	}
+
+	return filter, nil
+}
+
+// queryMapKey extracts key from a prefix[key] query parameter name
+func queryMapKey(prefix, name string) (string, bool) {
+	if !strings.HasPrefix(name, prefix+"[") || !strings.HasSuffix(name, "]") {
+		return "", false
+	}
+	return name[len(prefix)+1 : len(name)-1], true
+}
+
+func parseOptionalDecimal(ctx echo.Context, name string) (*decimal.Decimal, error) {
+	value := ctx.QueryParam(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := decimal.NewFromString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return &parsed, nil
+}
+
+func parseOptionalTime(ctx echo.Context, name string) (*time.Time, error) {
+	value := ctx.QueryParam(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q, expected RFC 3339", name, value)
+	}
+	return &parsed, nil
+}
+
+func toAccountResponse(account *entities.Account) *dto.AccountResponse {
+	return &dto.AccountResponse{
+		AccountID:         account.AccountID,
+		Balance:           account.Balance.String(),
+		ExternalReference: account.ExternalReference,
+		Currency:          account.Currency,
+		Kind:              string(account.Kind),
+		Status:            string(account.Status),
+		OwnerID:           account.OwnerID,
+		Metadata:          metadataResponse(account.Metadata),
+		Version:           account.Version,
+		CreatedAt:         account.CreatedAt,
+	}
+}
+
+// metadataResponse renders missing metadata as an empty object rather than null
+func metadataResponse(metadata entities.Metadata) map[string]string {
+	if metadata == nil {
+		return map[string]string{}
+	}
+	return metadata
+}
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/shopspring/decimal"
@@ -19,6 +20,8 @@ import (
 	"transfer-system/domain/entities"
 	"transfer-system/internal/testutils"
 	"transfer-system/mocks"
+	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/validator"
 )
 
 func TestAccountController_Create_Success(t *testing.T) {
@@ -43,7 +46,7 @@ func TestAccountController_Create_Success(t *testing.T) {
 		Balance:   decimal.NewFromFloat(100.23344),
 	}
 
-	mockService.On("Save", mock.Anything, acc).Return(nil)
+	mockService.On("Save", mock.Anything, acc).Return(acc, nil)
 
 	err := controller.Create(c)
 	assert.NoError(t, err)
@@ -53,6 +56,61 @@ func TestAccountController_Create_Success(t *testing.T) {
 	json.Unmarshal(rec.Body.Bytes(), &response)
 	assert.Equal(t, "success create account", response.Message)
 	assert.Equal(t, int(1), response.Status)
+	assert.Equal(t, float64(12345), response.Data.(map[string]interface{})["account_id"])
+}
+
+func TestAccountController_Create_GeneratedId(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockAccountService)
+	controller := &controllers.AccountController{AccountService: mockService}
+
+	bodyBytes := []byte(`{"initial_balance":"100.23344","external_reference":"crm-000123"}`)
+
+	req := httptest.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(bodyBytes))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	testutils.InjectLoggerToContext(c)
+
+	request := &entities.Account{
+		Balance:           decimal.RequireFromString("100.23344"),
+		ExternalReference: "crm-000123",
+	}
+	created := &entities.Account{
+		AccountID:         42,
+		Balance:           request.Balance,
+		ExternalReference: request.ExternalReference,
+	}
+	mockService.On("Save", mock.Anything, request).Return(created, nil)
+
+	err := controller.Create(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var response dto.WebResponse
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response.Data.(map[string]interface{})
+	assert.Equal(t, float64(42), data["account_id"])
+	assert.Equal(t, "crm-000123", data["external_reference"])
+}
+
+func TestAccountController_Create_InvalidExternalReference(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockAccountService)
+	controller := &controllers.AccountController{AccountService: mockService}
+
+	bodyBytes := []byte(`{"initial_balance":"100.23344","external_reference":"has spaces"}`)
+
+	req := httptest.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(bodyBytes))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	testutils.InjectLoggerToContext(c)
+
+	err := controller.Create(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockService.AssertNotCalled(t, "Save")
 }
 
 func TestAccountController_Create_InvalidBalanceFormat(t *testing.T) {
@@ -110,7 +168,7 @@ func TestAccountController_FindById_NotFound(t *testing.T) {
 	controller := &controllers.AccountController{AccountService: mockService}
 
 	accId := int64(99999)
-	mockService.On("FindById", mock.Anything, accId).Return(&entities.Account{}, errors.New("Account not found"))
+	mockService.On("FindById", mock.Anything, accId).Return(&entities.Account{}, appErrors.NewNotFoundError("Account not found", nil))
 
 	req := httptest.NewRequest(http.MethodGet, "/accounts/99999", nil)
 	rec := httptest.NewRecorder()
@@ -123,3 +181,461 @@ func TestAccountController_FindById_NotFound(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusNotFound, rec.Code)
 }
+
+func TestAccountController_FindById_DatabaseUnavailable(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockAccountService)
+	controller := &controllers.AccountController{AccountService: mockService}
+
+	accId := int64(12345)
+	mockService.On("FindById", mock.Anything, accId).Return(&entities.Account{}, appErrors.NewServiceUnavailableError("Service temporarily unavailable, please retry", nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts/12345", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("accountId")
+	c.SetParamValues(strconv.FormatInt(accId, 10))
+	testutils.InjectLoggerToContext(c)
+
+	err := controller.FindById(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
+
+func TestAccountController_FindById_UnexpectedError(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockAccountService)
+	controller := &controllers.AccountController{AccountService: mockService}
+
+	accId := int64(12345)
+	mockService.On("FindById", mock.Anything, accId).Return(&entities.Account{}, errors.New("boom"))
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts/12345", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("accountId")
+	c.SetParamValues(strconv.FormatInt(accId, 10))
+	testutils.InjectLoggerToContext(c)
+
+	err := controller.FindById(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestAccountController_FindByExternalReference_Success(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockAccountService)
+	controller := &controllers.AccountController{AccountService: mockService}
+
+	expectedResp := &entities.Account{
+		AccountID:         42,
+		Balance:           decimal.NewFromFloat(100.23344),
+		ExternalReference: "crm-000123",
+	}
+	mockService.On("FindByExternalReference", mock.Anything, "crm-000123").Return(expectedResp, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts?external_reference=crm-000123", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	testutils.InjectLoggerToContext(c)
+
+	err := controller.List(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response dto.WebResponse
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.Equal(t, float64(42), response.Data.(map[string]interface{})["account_id"])
+}
+
+func TestAccountController_FindByExternalReference_Empty(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockAccountService)
+	controller := &controllers.AccountController{AccountService: mockService}
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts?external_reference=", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	testutils.InjectLoggerToContext(c)
+
+	err := controller.List(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockService.AssertNotCalled(t, "FindByExternalReference")
+}
+
+func TestAccountController_List_Success(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockAccountService)
+	controller := &controllers.AccountController{AccountService: mockService}
+
+	minBalance := decimal.RequireFromString("10.5")
+	createdFrom := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ownerID := int64(7)
+	expectedFilter := entities.AccountFilter{
+		Status:       entities.AccountStatusActive,
+		Currency:     "USD",
+		MinBalance:   &minBalance,
+		CreatedFrom:  &createdFrom,
+		OwnerID:      &ownerID,
+		SortBy:       entities.AccountSortByBalance,
+		SortDesc:     true,
+		Cursor:       "abc",
+		Limit:        20,
+		IncludeTotal: true,
+	}
+	total := int64(1)
+	page := &entities.AccountPage{
+		Accounts:   []*entities.Account{{AccountID: 1, Balance: decimal.NewFromInt(20), Currency: "USD", Status: entities.AccountStatusActive}},
+		NextCursor: "next",
+		Total:      &total,
+	}
+	mockService.On("List", mock.Anything, expectedFilter).Return(page, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts?status=active&currency=USD&min_balance=10.5&created_from=2025-01-01T00:00:00Z&owner_id=7&sort=-balance&cursor=abc&limit=20&include_total=true", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	testutils.InjectLoggerToContext(c)
+
+	err := controller.List(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response dto.WebResponse
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response.Data.(map[string]interface{})
+	assert.Equal(t, "next", data["next_cursor"])
+	assert.Equal(t, float64(1), data["total"])
+	assert.Len(t, data["accounts"], 1)
+}
+
+func TestAccountController_List_InvalidFilter(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockAccountService)
+	controller := &controllers.AccountController{AccountService: mockService}
+
+	for _, query := range []string{"status=gone", "currency=usd", "min_balance=abc", "created_to=yesterday", "sort=name", "limit=-1"} {
+		req := httptest.NewRequest(http.MethodGet, "/accounts?"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		testutils.InjectLoggerToContext(c)
+
+		err := controller.List(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+	mockService.AssertNotCalled(t, "List")
+}
+
+func TestAccountController_Update_Success(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockAccountService)
+	controller := &controllers.AccountController{AccountService: mockService}
+
+	body := []byte(`{"metadata":{"cost_center":"CC-42","legacy_code":null}}`)
+	req := httptest.NewRequest(http.MethodPatch, "/accounts/12345", bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("If-Match", `"3"`)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("accountId")
+	c.SetParamValues("12345")
+	testutils.InjectLoggerToContext(c)
+
+	costCenter := "CC-42"
+	version := int64(3)
+	expectedPatch := entities.AccountPatch{Metadata: map[string]*string{"cost_center": &costCenter, "legacy_code": nil}, Version: &version}
+	updated := &entities.Account{
+		AccountID: 12345,
+		Balance:   decimal.NewFromInt(10),
+		Metadata:  entities.Metadata{"cost_center": "CC-42"},
+		Version:   4,
+	}
+	mockService.On("Update", mock.Anything, int64(12345), expectedPatch).Return(updated, nil)
+
+	err := controller.Update(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+
+	var response dto.WebResponse
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.Equal(t, "success update account", response.Message)
+	assert.Equal(t, map[string]interface{}{"cost_center": "CC-42"}, response.Data.(map[string]interface{})["metadata"])
+}
+
+func TestAccountController_Update_InvalidMetadataKey(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockAccountService)
+	controller := &controllers.AccountController{AccountService: mockService}
+
+	// a key being removed is not checked, so keys that no longer pass can be dropped
+	body := []byte(`{"metadata":{"cost center":"CC-42","old key":null}}`)
+	req := httptest.NewRequest(http.MethodPatch, "/accounts/12345", bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("accountId")
+	c.SetParamValues("12345")
+	testutils.InjectLoggerToContext(c)
+
+	err := controller.Update(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var resp struct {
+		Data dto.ValidationErrorResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, validator.Errors{
+		{Field: "metadata", Message: `metadata key "cost center" must be 1-40 characters of letters, digits, _ or -`},
+	}, resp.Data.Errors)
+	mockService.AssertNotCalled(t, "Update")
+}
+
+func TestAccountController_Update_Preconditions(t *testing.T) {
+	cases := []struct {
+		name    string
+		ifMatch string
+		status  int
+	}{
+		{name: "missing If-Match", ifMatch: "", status: http.StatusPreconditionRequired},
+		{name: "weak tag", ifMatch: `W/"3"`, status: http.StatusPreconditionFailed},
+		{name: "several tags", ifMatch: `"3", "4"`, status: http.StatusPreconditionFailed},
+		{name: "not a version", ifMatch: `"abc"`, status: http.StatusPreconditionFailed},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			mockService := new(mocks.MockAccountService)
+			controller := &controllers.AccountController{AccountService: mockService}
+
+			req := httptest.NewRequest(http.MethodPatch, "/accounts/12345", bytes.NewReader([]byte(`{"metadata":{"cost_center":"CC-42"}}`)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("accountId")
+			c.SetParamValues("12345")
+			testutils.InjectLoggerToContext(c)
+
+			err := controller.Update(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.status, rec.Code)
+			mockService.AssertNotCalled(t, "Update")
+		})
+	}
+}
+
+func TestAccountController_Update_AnyVersion(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockAccountService)
+	controller := &controllers.AccountController{AccountService: mockService}
+
+	req := httptest.NewRequest(http.MethodPatch, "/accounts/12345", bytes.NewReader([]byte(`{"metadata":{"cost_center":"CC-42"}}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("If-Match", "*")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("accountId")
+	c.SetParamValues("12345")
+	testutils.InjectLoggerToContext(c)
+
+	mockService.On("Update", mock.Anything, int64(12345), mock.MatchedBy(func(patch entities.AccountPatch) bool {
+		return patch.Version == nil
+	})).Return(&entities.Account{AccountID: 12345, Version: 2}, nil)
+
+	err := controller.Update(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestAccountController_Update_VersionMismatch(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockAccountService)
+	controller := &controllers.AccountController{AccountService: mockService}
+
+	req := httptest.NewRequest(http.MethodPatch, "/accounts/12345", bytes.NewReader([]byte(`{"metadata":{"cost_center":"CC-42"}}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("If-Match", `"3"`)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("accountId")
+	c.SetParamValues("12345")
+	testutils.InjectLoggerToContext(c)
+
+	mockService.On("Update", mock.Anything, int64(12345), mock.Anything).
+		Return((*entities.Account)(nil), appErrors.NewPreconditionFailedError("Account was modified, fetch it again and retry", entities.ErrVersionMismatch))
+
+	err := controller.Update(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+}
+
+func TestAccountController_Update_Status(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockAccountService)
+	controller := &controllers.AccountController{AccountService: mockService}
+
+	req := httptest.NewRequest(http.MethodPatch, "/accounts/12345", bytes.NewReader([]byte(`{"status":"frozen"}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("If-Match", `"3"`)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("accountId")
+	c.SetParamValues("12345")
+	testutils.InjectLoggerToContext(c)
+
+	frozen := entities.AccountStatusFrozen
+	version := int64(3)
+	mockService.On("Update", mock.Anything, int64(12345), entities.AccountPatch{Status: &frozen, Version: &version}).
+		Return(&entities.Account{AccountID: 12345, Status: entities.AccountStatusFrozen, Version: 4}, nil)
+
+	err := controller.Update(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+}
+
+func TestAccountController_Update_InvalidStatus(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockAccountService)
+	controller := &controllers.AccountController{AccountService: mockService}
+
+	req := httptest.NewRequest(http.MethodPatch, "/accounts/12345", bytes.NewReader([]byte(`{"status":"dormant"}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("If-Match", `"3"`)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("accountId")
+	c.SetParamValues("12345")
+	testutils.InjectLoggerToContext(c)
+
+	err := controller.Update(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockService.AssertNotCalled(t, "Update")
+}
+
+func TestAccountController_Update_StaleStatusChange(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockAccountService)
+	controller := &controllers.AccountController{AccountService: mockService}
+
+	req := httptest.NewRequest(http.MethodPatch, "/accounts/12345", bytes.NewReader([]byte(`{"status":"closed"}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("If-Match", `"2"`)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("accountId")
+	c.SetParamValues("12345")
+	testutils.InjectLoggerToContext(c)
+
+	closed := entities.AccountStatusClosed
+	version := int64(2)
+	mockService.On("Update", mock.Anything, int64(12345), entities.AccountPatch{Status: &closed, Version: &version}).
+		Return((*entities.Account)(nil), appErrors.NewPreconditionFailedError("Account was modified, fetch it again and retry", entities.ErrVersionMismatch))
+
+	err := controller.Update(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAccountController_FindById_ETag(t *testing.T) {
+	account := &entities.Account{AccountID: 12345, Balance: decimal.NewFromInt(10), Version: 7}
+	cases := []struct {
+		name        string
+		ifNoneMatch string
+		status      int
+	}{
+		{name: "no If-None-Match", ifNoneMatch: "", status: http.StatusOK},
+		{name: "current version", ifNoneMatch: `"7"`, status: http.StatusNotModified},
+		{name: "weak current version in a list", ifNoneMatch: `"5", W/"7"`, status: http.StatusNotModified},
+		{name: "older version", ifNoneMatch: `"6"`, status: http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			mockService := new(mocks.MockAccountService)
+			controller := &controllers.AccountController{AccountService: mockService}
+			mockService.On("FindById", mock.Anything, int64(12345)).Return(account, nil)
+
+			req := httptest.NewRequest(http.MethodGet, "/accounts/12345", nil)
+			if tc.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("accountId")
+			c.SetParamValues("12345")
+			testutils.InjectLoggerToContext(c)
+
+			err := controller.FindById(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.status, rec.Code)
+			assert.Equal(t, `"7"`, rec.Header().Get("ETag"))
+			if tc.status == http.StatusNotModified {
+				assert.Empty(t, rec.Body.Bytes())
+			}
+		})
+	}
+}
+
+func TestAccountController_List_MetadataFilter(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockAccountService)
+	controller := &controllers.AccountController{AccountService: mockService}
+
+	expectedFilter := entities.AccountFilter{
+		Metadata: entities.Metadata{"cost_center": "CC-42", "product": "savings"},
+	}
+	mockService.On("List", mock.Anything, expectedFilter).Return(&entities.AccountPage{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts?metadata%5Bcost_center%5D=CC-42&metadata[product]=savings", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	testutils.InjectLoggerToContext(c)
+
+	err := controller.List(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAccountController_Scopes(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockAccountService)
+	controller := &controllers.AccountController{AccountService: mockService}
+	readOnly := &entities.Principal{Subject: "reporting", Scopes: []string{entities.ScopeAccountsRead}}
+
+	account := &entities.Account{AccountID: 1, Balance: decimal.NewFromInt(10), Version: 2}
+	mockService.On("FindById", mock.Anything, int64(1)).Return(account, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts/1", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("accountId")
+	c.SetParamValues("1")
+	testutils.InjectLoggerToContext(c)
+	testutils.InjectPrincipalToContext(c, readOnly)
+
+	assert.NoError(t, controller.FindById(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPatch, "/accounts/1", bytes.NewReader([]byte(`{"metadata":{"team":"ops"}}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("If-Match", `"2"`)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("accountId")
+	c.SetParamValues("1")
+	testutils.InjectLoggerToContext(c)
+	testutils.InjectPrincipalToContext(c, readOnly)
+
+	assert.NoError(t, controller.Update(c))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	mockService.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"transfer-system/adapters/web/dto"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+type BalanceController struct {
+	BalanceService ports.BalanceService
+}
+
+// BalanceAsOf godoc
+// @Summary Point-in-time balance
+// @Description Balance of an account including every transfer created at or before as_of. Defaults to now.
+// @ID get-account-balance
+// @Tags         Accounts
+// @Accept json
+// @Produce json
+// @Param accountId path int true "Account ID"
+// @Param as_of query string false "Point in time (RFC 3339), e.g. 2025-03-31T23:59:59Z"
+// @Success 200 {object} dto.WebResponse{data=dto.BalanceResponse} "Successfully computed balance"
+// @Failure 400 {object} dto.WebResponse "Invalid accountId or as_of"
+// @Failure 403 {object} dto.WebResponse "Token lacks the accounts:read scope"
+// @Failure 404 {object} dto.WebResponse "Account not found"
+// @Failure 422 {object} dto.WebResponse "Account did not exist at as_of"
+// @Failure 500 {object} dto.WebResponse "Internal error"
+// @Failure 503 {object} dto.WebResponse "Database unavailable, safe to retry"
+// @Failure 504 {object} dto.WebResponse "Request timed out, safe to retry"
+// @Router /accounts/{accountId}/balance [get]
+func (c *BalanceController) BalanceAsOf(ctx echo.Context) error {
+	logger, _ := ctx.Request().Context().Value(logger.LoggerContextKey).(*logrus.Entry)
+	if err := authorize(ctx, entities.ScopeAccountsRead); err != nil {
+		return errorResponse(ctx, err)
+	}
+	accountIdStr := ctx.Param("accountId")
+
+	accountId, err := strconv.ParseInt(accountIdStr, 10, 64)
+	if err != nil {
+		logger.WithError(err).Errorf("Invalid accountId parameter: %s", accountIdStr)
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
+			Message: "Invalid accountId format. Please provide a valid number.",
+			Status:  0,
+			Data:    nil,
+		})
+	}
+
+	now := time.Now().UTC()
+	asOf := &now
+	if ctx.QueryParam("as_of") != "" {
+		if asOf, err = parseOptionalTime(ctx, "as_of"); err != nil {
+			logger.WithError(err).Error("Invalid as_of parameter")
+			return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
+				Message: err.Error(),
+				Status:  0,
+				Data:    nil,
+			})
+		}
+	}
+	if asOf.After(now) {
+		logger.Errorf("as_of %s is in the future", asOf)
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
+			Message: "as_of must not be in the future",
+			Status:  0,
+			Data:    nil,
+		})
+	}
+
+	balance, err := c.BalanceService.BalanceAsOf(ctx.Request().Context(), accountId, *asOf)
+	if err != nil {
+		logger.Error("Error balance as of controller: ", err)
+		return errorResponse(ctx, err)
+	}
+
+	response := dto.WebResponse{
+		Message: "success get account balance",
+		Status:  1,
+		Data: &dto.BalanceResponse{
+			AccountID: balance.AccountID,
+			Currency:  balance.Currency,
+			Balance:   balance.Balance.String(),
+			AsOf:      balance.AsOf,
+		},
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// Statement godoc
+// @Summary Account statement
+// @Description Opening balance, every movement in (from, to] with its running balance, and the closing balance.
+// @Description The statement is streamed; a response cut short means the statement failed part way.
+// @ID get-account-statement
+// @Tags         Accounts
+// @Produce json
+// @Produce text/csv
+// @Produce application/xml
+// @Param accountId path int true "Account ID"
+// @Param from query string true "Period start, exclusive (RFC 3339)"
+// @Param to query string true "Period end, inclusive (RFC 3339)"
+// @Param format query string false "Output format" Enums(json, csv, camt053) default(json)
+// @Success 200 {object} dto.WebResponse{data=dto.StatementResponse} "Statement"
+// @Failure 400 {object} dto.WebResponse "Invalid accountId, period or format"
+// @Failure 403 {object} dto.WebResponse "Token lacks the transactions:read scope"
+// @Failure 404 {object} dto.WebResponse "Account not found"
+// @Failure 422 {object} dto.WebResponse "Account did not exist in the period"
+// @Failure 500 {object} dto.WebResponse "Internal error"
+// @Failure 503 {object} dto.WebResponse "Database unavailable, safe to retry"
+// @Failure 504 {object} dto.WebResponse "Request timed out, safe to retry"
+// @Router /accounts/{accountId}/statement [get]
+func (c *BalanceController) Statement(ctx echo.Context) error {
+	logger, _ := ctx.Request().Context().Value(logger.LoggerContextKey).(*logrus.Entry)
+	if err := authorize(ctx, entities.ScopeTransactionsRead); err != nil {
+		return errorResponse(ctx, err)
+	}
+	accountIdStr := ctx.Param("accountId")
+
+	accountId, err := strconv.ParseInt(accountIdStr, 10, 64)
+	if err != nil {
+		logger.WithError(err).Errorf("Invalid accountId parameter: %s", accountIdStr)
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
+			Message: "Invalid accountId format. Please provide a valid number.",
+			Status:  0,
+			Data:    nil,
+		})
+	}
+
+	from, to, err := parseStatementPeriod(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Invalid statement period")
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
+			Message: err.Error(),
+			Status:  0,
+			Data:    nil,
+		})
+	}
+
+	var writer ports.StatementWriter
+	switch format := ctx.QueryParam("format"); format {
+	case "", "json":
+		writer = newJSONStatementWriter(ctx.Response())
+	case "csv":
+		writer = newCSVStatementWriter(ctx.Response())
+	case "camt053":
+		writer = newCamt053StatementWriter(ctx.Response())
+	default:
+		logger.Errorf("Invalid statement format: %s", format)
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
+			Message: fmt.Sprintf("invalid format %q, expected json, csv or camt053", format),
+			Status:  0,
+			Data:    nil,
+		})
+	}
+
+	err = c.BalanceService.Statement(ctx.Request().Context(), accountId, from, to, writer)
+	if err != nil {
+		logger.Error("Error statement controller: ", err)
+		// once streaming has started the status is sent, all that is left is to cut the response short
+		if ctx.Response().Committed {
+			return nil
+		}
+		return errorResponse(ctx, err)
+	}
+
+	return nil
+}
+
+func parseStatementPeriod(ctx echo.Context) (time.Time, time.Time, error) {
+	if ctx.QueryParam("from") == "" || ctx.QueryParam("to") == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("from and to are required")
+	}
+	from, err := parseOptionalTime(ctx, "from")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := parseOptionalTime(ctx, "to")
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !to.After(*from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("to must be after from")
+	}
+	if to.After(time.Now()) {
+		return time.Time{}, time.Time{}, fmt.Errorf("to must not be in the future")
+	}
+	return *from, *to, nil
+}
@@ -0,0 +1,202 @@
+package controllers_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"transfer-system/adapters/controllers"
+	"transfer-system/adapters/web/dto"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/internal/testutils"
+	"transfer-system/mocks"
+	appErrors "transfer-system/pkg/errors"
+)
+
+func TestBalanceController_BalanceAsOf_Success(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockBalanceService)
+	controller := &controllers.BalanceController{BalanceService: mockService}
+
+	asOf := time.Date(2025, 3, 31, 23, 59, 59, 0, time.UTC)
+	mockService.On("BalanceAsOf", mock.Anything, int64(123), mock.MatchedBy(asOf.Equal)).Return(&entities.AccountBalance{
+		AccountID: 123,
+		Currency:  "USD",
+		Balance:   decimal.RequireFromString("60.5"),
+		AsOf:      asOf,
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts/123/balance?as_of=2025-03-31T23:59:59Z", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("accountId")
+	c.SetParamValues("123")
+	testutils.InjectLoggerToContext(c)
+
+	err := controller.BalanceAsOf(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response dto.WebResponse
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response.Data.(map[string]interface{})
+	assert.Equal(t, "60.5", data["balance"])
+	assert.Equal(t, "2025-03-31T23:59:59Z", data["as_of"])
+}
+
+func TestBalanceController_BalanceAsOf_InvalidAsOf(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockBalanceService)
+	controller := &controllers.BalanceController{BalanceService: mockService}
+
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	for _, asOf := range []string{"yesterday", "2025-03-31", future} {
+		req := httptest.NewRequest(http.MethodGet, "/accounts/123/balance?as_of="+asOf, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("accountId")
+		c.SetParamValues("123")
+		testutils.InjectLoggerToContext(c)
+
+		err := controller.BalanceAsOf(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, asOf)
+	}
+	mockService.AssertNotCalled(t, "BalanceAsOf")
+}
+
+func statementRequest(query string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/accounts/123/statement?"+query, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("accountId")
+	c.SetParamValues("123")
+	testutils.InjectLoggerToContext(c)
+	return c, rec
+}
+
+// writeStatement plays the service's part, driving whichever writer the controller picked
+func writeStatement(args mock.Arguments) {
+	writer := args.Get(4).(ports.StatementWriter)
+	from, to := args.Get(2).(time.Time), args.Get(3).(time.Time)
+
+	writer.Begin(entities.StatementHeader{AccountID: 123, Currency: "USD", From: from, To: to, OpeningBalance: decimal.NewFromInt(100)})
+	writer.WriteLine(entities.StatementLine{
+		Transaction:    &entities.Transaction{Id: 7, SourceAccountID: 456, DestinationAccountID: 123, Amount: decimal.NewFromInt(50), Description: "March rent, flat 2"},
+		Amount:         decimal.NewFromInt(50),
+		RunningBalance: decimal.NewFromInt(150),
+	})
+	writer.End(decimal.NewFromInt(150))
+}
+
+func TestBalanceController_Statement_CSV(t *testing.T) {
+	mockService := new(mocks.MockBalanceService)
+	controller := &controllers.BalanceController{BalanceService: mockService}
+
+	mockService.On("Statement", mock.Anything, int64(123), mock.Anything, mock.Anything, mock.Anything).Run(writeStatement).Return(nil)
+
+	c, rec := statementRequest("from=2025-03-01T00:00:00Z&to=2025-04-01T00:00:00Z&format=csv")
+	err := controller.Statement(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "statement-123-20250301-20250401.csv")
+
+	rows, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 4)
+	assert.Equal(t, []string{"2025-03-01T00:00:00Z", "", "", "Opening balance", "", "", "100", "USD"}, rows[1])
+	assert.Equal(t, "March rent, flat 2", rows[2][3])
+	assert.Equal(t, "150", rows[2][6])
+	assert.Equal(t, "Closing balance", rows[3][3])
+}
+
+func TestBalanceController_Statement_JSON(t *testing.T) {
+	mockService := new(mocks.MockBalanceService)
+	controller := &controllers.BalanceController{BalanceService: mockService}
+
+	mockService.On("Statement", mock.Anything, int64(123), mock.Anything, mock.Anything, mock.Anything).Run(writeStatement).Return(nil)
+
+	c, rec := statementRequest("from=2025-03-01T00:00:00Z&to=2025-04-01T00:00:00Z")
+	err := controller.Statement(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response struct {
+		Status int                   `json:"status"`
+		Data   dto.StatementResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Status)
+	assert.Equal(t, "100", response.Data.OpeningBalance)
+	assert.Len(t, response.Data.Lines, 1)
+	assert.Equal(t, int64(456), response.Data.Lines[0].CounterpartyAccountID)
+	assert.Equal(t, "150", response.Data.ClosingBalance)
+}
+
+func TestBalanceController_Statement_Camt053(t *testing.T) {
+	mockService := new(mocks.MockBalanceService)
+	controller := &controllers.BalanceController{BalanceService: mockService}
+
+	mockService.On("Statement", mock.Anything, int64(123), mock.Anything, mock.Anything, mock.Anything).Run(writeStatement).Return(nil)
+
+	c, rec := statementRequest("from=2025-03-01T00:00:00Z&to=2025-04-01T00:00:00Z&format=camt053")
+	err := controller.Statement(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/xml; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "statement-123-20250301-20250401.xml")
+
+	var document struct {
+		Entries []struct {
+			NtryRef   string
+			CdtDbtInd string
+		} `xml:"BkToCstmrStmt>Stmt>Ntry"`
+	}
+	assert.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &document))
+	assert.Len(t, document.Entries, 1)
+	assert.Equal(t, "7", document.Entries[0].NtryRef)
+	assert.Equal(t, "CRDT", document.Entries[0].CdtDbtInd)
+}
+
+func TestBalanceController_Statement_InvalidRequest(t *testing.T) {
+	mockService := new(mocks.MockBalanceService)
+	controller := &controllers.BalanceController{BalanceService: mockService}
+
+	for _, query := range []string{
+		"to=2025-04-01T00:00:00Z",
+		"from=2025-04-01T00:00:00Z&to=2025-03-01T00:00:00Z",
+		"from=2025-03-01T00:00:00Z&to=2025-04-01T00:00:00Z&format=pdf",
+	} {
+		c, rec := statementRequest(query)
+		err := controller.Statement(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+	mockService.AssertNotCalled(t, "Statement")
+}
+
+func TestBalanceController_Statement_AccountNotFound(t *testing.T) {
+	mockService := new(mocks.MockBalanceService)
+	controller := &controllers.BalanceController{BalanceService: mockService}
+
+	mockService.On("Statement", mock.Anything, int64(123), mock.Anything, mock.Anything, mock.Anything).
+		Return(appErrors.NewNotFoundError("Account not found", nil))
+
+	c, rec := statementRequest("from=2025-03-01T00:00:00Z&to=2025-04-01T00:00:00Z")
+	err := controller.Statement(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"transfer-system/adapters/web"
+	"transfer-system/adapters/web/dto"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const dateLayout = "2006-01-02"
+
+type CustomerController struct {
+	CustomerService ports.CustomerService
+	Binder          web.Binder
+}
+
+// Create Customer godoc
+// @Summary      Create Customer
+// @Description  Register a customer that can own accounts
+// @Tags         Customers
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.CustomerRequest  true  "Customer payload"
+// @Success      201   {object}  dto.WebResponse{data=dto.CustomerResponse}
+// @Failure      400   {object}  dto.WebResponse{data=dto.ValidationErrorResponse}
+// @Failure      403   {object}  dto.WebResponse
+// @Failure      409   {object}  dto.WebResponse
+// @Failure      500   {object}  dto.WebResponse
+// @Failure      503   {object}  dto.WebResponse
+// @Router       /customers [post]
+func (c *CustomerController) Create(ctx echo.Context) error {
+	if err := authorize(ctx, entities.ScopeAccountsWrite); err != nil {
+		return errorResponse(ctx, err)
+	}
+	customer, err := c.bindCustomer(ctx)
+	if err != nil {
+		return invalidPayloadResponse(ctx, err)
+	}
+
+	saved, err := c.CustomerService.Save(ctx.Request().Context(), customer)
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusCreated, dto.WebResponse{
+		Message: "success create customer",
+		Status:  1,
+		Data:    toCustomerResponse(saved),
+	})
+}
+
+// FindById godoc
+// @Summary Get Customer by ID
+// @Description Get a customer profile and KYC tier
+// @Tags         Customers
+// @Produce json
+// @Param customerId path int true "Customer ID"
+// @Success 200 {object} dto.WebResponse{data=dto.CustomerResponse}
+// @Failure 400 {object} dto.WebResponse "Invalid customerId format"
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse "Customer not found"
+// @Failure 500 {object} dto.WebResponse
+// @Failure 503 {object} dto.WebResponse
+// @Router /customers/{customerId} [get]
+func (c *CustomerController) FindById(ctx echo.Context) error {
+	if err := authorize(ctx, entities.ScopeAccountsRead); err != nil {
+		return errorResponse(ctx, err)
+	}
+	customerId, ok, err := parseCustomerId(ctx)
+	if !ok {
+		return err
+	}
+
+	customer, err := c.CustomerService.FindById(ctx.Request().Context(), customerId)
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse{
+		Message: "success get customer by id",
+		Status:  1,
+		Data:    toCustomerResponse(customer),
+	})
+}
+
+// Update godoc
+// @Summary Update Customer
+// @Description Replace a customer profile, including the KYC tier
+// @Tags         Customers
+// @Accept json
+// @Produce json
+// @Param customerId path int true "Customer ID"
+// @Param body body dto.CustomerRequest true "Customer payload"
+// @Success 200 {object} dto.WebResponse{data=dto.CustomerResponse}
+// @Failure 400 {object} dto.WebResponse{data=dto.ValidationErrorResponse}
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Failure 409 {object} dto.WebResponse
+// @Failure 500 {object} dto.WebResponse
+// @Failure 503 {object} dto.WebResponse
+// @Router /customers/{customerId} [put]
+func (c *CustomerController) Update(ctx echo.Context) error {
+	if err := authorize(ctx, entities.ScopeAccountsWrite); err != nil {
+		return errorResponse(ctx, err)
+	}
+	customerId, ok, err := parseCustomerId(ctx)
+	if !ok {
+		return err
+	}
+
+	customer, err := c.bindCustomer(ctx)
+	if err != nil {
+		return invalidPayloadResponse(ctx, err)
+	}
+	customer.CustomerID = customerId
+
+	updated, err := c.CustomerService.Update(ctx.Request().Context(), customer)
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse{
+		Message: "success update customer",
+		Status:  1,
+		Data:    toCustomerResponse(updated),
+	})
+}
+
+// Delete godoc
+// @Summary Delete Customer
+// @Description Delete a customer that no longer owns any account
+// @Tags         Customers
+// @Produce json
+// @Param customerId path int true "Customer ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Failure 409 {object} dto.WebResponse "Customer still owns accounts"
+// @Failure 500 {object} dto.WebResponse
+// @Failure 503 {object} dto.WebResponse
+// @Router /customers/{customerId} [delete]
+func (c *CustomerController) Delete(ctx echo.Context) error {
+	if err := authorize(ctx, entities.ScopeAccountsWrite); err != nil {
+		return errorResponse(ctx, err)
+	}
+	customerId, ok, err := parseCustomerId(ctx)
+	if !ok {
+		return err
+	}
+
+	if err := c.CustomerService.Delete(ctx.Request().Context(), customerId); err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse{
+		Message: "success delete customer",
+		Status:  1,
+		Data:    nil,
+	})
+}
+
+// parseCustomerId reads the path id; when ok is false the error response was already written
+func parseCustomerId(ctx echo.Context) (int64, bool, error) {
+	logger, _ := ctx.Request().Context().Value(logger.LoggerContextKey).(*logrus.Entry)
+	customerIdStr := ctx.Param("customerId")
+
+	customerId, err := strconv.ParseInt(customerIdStr, 10, 64)
+	if err != nil {
+		logger.WithError(err).Errorf("Invalid customerId parameter: %s", customerIdStr)
+		return 0, false, ctx.JSON(http.StatusBadRequest, dto.WebResponse{
+			Message: "Invalid customerId format. Please provide a valid number.",
+			Status:  0,
+			Data:    nil,
+		})
+	}
+	return customerId, true, nil
+}
+
+// bindCustomer decodes and validates the payload
+func (c *CustomerController) bindCustomer(ctx echo.Context) (*entities.Customer, error) {
+	customerRequest := dto.CustomerRequest{}
+	if err := c.Binder.Bind(ctx, &customerRequest); err != nil {
+		return nil, err
+	}
+
+	customer := &entities.Customer{
+		FullName: strings.TrimSpace(customerRequest.FullName),
+		Email:    customerRequest.Email,
+		Phone:    customerRequest.Phone,
+		Country:  customerRequest.Country,
+		KYCTier:  entities.KYCTier(customerRequest.KYCTier),
+	}
+	if customerRequest.DateOfBirth != "" {
+		dateOfBirth, err := time.Parse(dateLayout, customerRequest.DateOfBirth)
+		if err != nil {
+			return nil, err
+		}
+		customer.DateOfBirth = &dateOfBirth
+	}
+
+	return customer, nil
+}
+
+func toCustomerResponse(customer *entities.Customer) *dto.CustomerResponse {
+	response := &dto.CustomerResponse{
+		CustomerID: customer.CustomerID,
+		FullName:   customer.FullName,
+		Email:      customer.Email,
+		Phone:      customer.Phone,
+		Country:    customer.Country,
+		KYCTier:    string(customer.KYCTier),
+		CreatedAt:  customer.CreatedAt,
+		UpdatedAt:  customer.UpdatedAt,
+	}
+	if customer.DateOfBirth != nil {
+		response.DateOfBirth = customer.DateOfBirth.Format(dateLayout)
+	}
+	return response
+}
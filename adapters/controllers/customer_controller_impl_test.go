@@ -0,0 +1,145 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"transfer-system/adapters/controllers"
+	"transfer-system/adapters/web/dto"
+	"transfer-system/domain/entities"
+	"transfer-system/internal/testutils"
+	"transfer-system/mocks"
+	appErrors "transfer-system/pkg/errors"
+)
+
+func TestCustomerController_Create_Success(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockCustomerService)
+	controller := &controllers.CustomerController{CustomerService: mockService}
+
+	bodyBytes := []byte(`{"full_name":"Jane Doe","email":"jane@example.com","date_of_birth":"1990-04-21","country":"ID","kyc_tier":"basic"}`)
+
+	req := httptest.NewRequest(http.MethodPost, "/customers", bytes.NewReader(bodyBytes))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	testutils.InjectLoggerToContext(c)
+
+	dateOfBirth := time.Date(1990, 4, 21, 0, 0, 0, 0, time.UTC)
+	expected := &entities.Customer{
+		FullName:    "Jane Doe",
+		Email:       "jane@example.com",
+		DateOfBirth: &dateOfBirth,
+		Country:     "ID",
+		KYCTier:     entities.KYCTierBasic,
+	}
+	saved := *expected
+	saved.CustomerID = 7
+	mockService.On("Save", mock.Anything, expected).Return(&saved, nil)
+
+	err := controller.Create(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var response dto.WebResponse
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	data := response.Data.(map[string]interface{})
+	assert.Equal(t, float64(7), data["customer_id"])
+	assert.Equal(t, "1990-04-21", data["date_of_birth"])
+	assert.Equal(t, "basic", data["kyc_tier"])
+}
+
+func TestCustomerController_Create_InvalidPayload(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockCustomerService)
+	controller := &controllers.CustomerController{CustomerService: mockService}
+
+	for _, body := range []string{
+		`{"full_name":"","email":"jane@example.com"}`,
+		`{"full_name":"Jane Doe","email":"not-an-email"}`,
+		`{"full_name":"Jane Doe","email":"jane@example.com","kyc_tier":"gold"}`,
+		`{"full_name":"Jane Doe","email":"jane@example.com","date_of_birth":"21/04/1990"}`,
+		`{"full_name":"Jane Doe","email":"jane@example.com","country":"IDN"}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/customers", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		testutils.InjectLoggerToContext(c)
+
+		err := controller.Create(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+	}
+	mockService.AssertNotCalled(t, "Save")
+}
+
+func TestCustomerController_FindById_NotFound(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockCustomerService)
+	controller := &controllers.CustomerController{CustomerService: mockService}
+
+	mockService.On("FindById", mock.Anything, int64(7)).Return(nil, appErrors.NewNotFoundError("Customer not found", nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/customers/7", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("customerId")
+	c.SetParamValues("7")
+	testutils.InjectLoggerToContext(c)
+
+	err := controller.FindById(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestCustomerController_Update_Success(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockCustomerService)
+	controller := &controllers.CustomerController{CustomerService: mockService}
+
+	bodyBytes := []byte(`{"full_name":"Jane Doe","email":"jane@example.com","kyc_tier":"full"}`)
+
+	req := httptest.NewRequest(http.MethodPut, "/customers/7", bytes.NewReader(bodyBytes))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("customerId")
+	c.SetParamValues("7")
+	testutils.InjectLoggerToContext(c)
+
+	expected := &entities.Customer{CustomerID: 7, FullName: "Jane Doe", Email: "jane@example.com", KYCTier: entities.KYCTierFull}
+	mockService.On("Update", mock.Anything, expected).Return(expected, nil)
+
+	err := controller.Update(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCustomerController_Delete_Conflict(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockCustomerService)
+	controller := &controllers.CustomerController{CustomerService: mockService}
+
+	mockService.On("Delete", mock.Anything, int64(7)).Return(appErrors.NewConflictError("Customer still owns accounts", nil))
+
+	req := httptest.NewRequest(http.MethodDelete, "/customers/7", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("customerId")
+	c.SetParamValues("7")
+	testutils.InjectLoggerToContext(c)
+
+	err := controller.Delete(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"transfer-system/adapters/web/dto"
+	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/logger"
+	"transfer-system/pkg/validator"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// errorResponse writes err to the client, keeping the status code chosen by the
+// service layer so 4xx and 5xx responses stay meaningful for retrying callers
+func errorResponse(ctx echo.Context, err error) error {
+	var appErr *appErrors.AppError
+	if errors.As(err, &appErr) {
+		return ctx.JSON(appErr.StatusCode, dto.WebResponse{
+			Message: appErr.Message,
+			Status:  0,
+			Data:    nil,
+		})
+	}
+
+	return ctx.JSON(http.StatusInternalServerError, dto.WebResponse{
+		Message: "An unexpected error occurred",
+		Status:  0,
+		Data:    nil,
+	})
+}
+
+// invalidPayloadResponse answers 400 for a payload web.Binder rejected,
+// listing every invalid field
+func invalidPayloadResponse(ctx echo.Context, err error) error {
+	logger, _ := ctx.Request().Context().Value(logger.LoggerContextKey).(*logrus.Entry)
+	logger.WithError(err).Error("Invalid request payload")
+
+	var fieldErrors validator.Errors
+	if errors.As(err, &fieldErrors) {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
+			Message: "Invalid request: " + fieldErrors.Error(),
+			Status:  0,
+			Data:    dto.ValidationErrorResponse{Errors: fieldErrors},
+		})
+	}
+
+	return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
+		Message: "Invalid request Payload",
+		Status:  0,
+		Data:    nil,
+	})
+}
@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+
+	"transfer-system/domain/entities"
+)
+
+// accountETag is the strong entity tag of an account: its version, quoted
+func accountETag(account *entities.Account) string {
+	return strconv.Quote(strconv.FormatInt(account.Version, 10))
+}
+
+// etagListed reports whether the If-None-Match value lists etag or is *.
+// Weak tags match their strong counterpart, as If-None-Match compares weakly
+func etagListed(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion reads the account version an If-Match value names. * matches
+// any version and gives nil. ok is false for anything that cannot match an
+// account tag: a weak tag, a malformed one or a list of several
+func ifMatchVersion(header string) (version *int64, ok bool) {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return nil, true
+	}
+	unquoted, err := strconv.Unquote(header)
+	if err != nil || !strings.HasPrefix(header, `"`) {
+		return nil, false
+	}
+	parsed, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	return &parsed, true
+}
@@ -0,0 +1,293 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"transfer-system/adapters/csvimport"
+	"transfer-system/adapters/web"
+	"transfer-system/adapters/web/dto"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+type ImportController struct {
+	ImportService ports.ImportService
+}
+
+// ImportAccounts godoc
+// @Summary Import accounts from CSV
+// @Description Creates one account per row. Columns: account_id, initial_balance (required), currency, external_reference, owner_id.
+// @Description Upload the file as the request body (text/csv) or as the multipart field "file". Map differently named headers with columns[field]=Header.
+// @Description With dry_run=true every row is checked and reported without writing anything. Otherwise rows are committed in chunks;
+// @Description a failed job keeps the committed chunks and is continued by sending the same file again with job_id.
+// @ID import-accounts
+// @Tags         Imports
+// @Accept text/csv
+// @Accept multipart/form-data
+// @Produce json
+// @Param dry_run query bool false "Only validate the file"
+// @Param job_id query string false "Job to resume"
+// @Param columns[initial_balance] query string false "Header of the initial_balance column, likewise for the other fields"
+// @Success 200 {object} dto.WebResponse{data=dto.ImportJobResponse} "Import completed; a dry run answers with data=dto.ImportValidationResponse"
+// @Failure 400 {object} dto.WebResponse "Unreadable file or parameters"
+// @Failure 403 {object} dto.WebResponse "Token lacks the accounts:write scope"
+// @Failure 404 {object} dto.WebResponse "Import job not found"
+// @Failure 409 {object} dto.WebResponse{data=dto.ImportJobResponse} "Row clashes with an existing account, or job_id was started with another file"
+// @Failure 413 {object} dto.WebResponse "File larger than 20 MiB"
+// @Failure 422 {object} dto.WebResponse{data=dto.ImportValidationResponse} "Invalid rows, nothing was imported"
+// @Failure 500 {object} dto.WebResponse "Internal error"
+// @Failure 503 {object} dto.WebResponse{data=dto.ImportJobResponse} "Database unavailable, resume the job"
+// @Router /imports/accounts [post]
+func (c *ImportController) ImportAccounts(ctx echo.Context) error {
+	return c.importFile(ctx, entities.ImportKindAccounts)
+}
+
+// ImportTransfers godoc
+// @Summary Import transfers from CSV
+// @Description Books one transfer per row, in file order, with the same checks as POST /transactions.
+// @Description Columns: source_account_id, destination_account_id, amount (required), description, reference.
+// @Description Upload, column mapping, dry run and resuming work as for account imports.
+// @ID import-transfers
+// @Tags         Imports
+// @Accept text/csv
+// @Accept multipart/form-data
+// @Produce json
+// @Param dry_run query bool false "Only validate the file"
+// @Param job_id query string false "Job to resume"
+// @Param columns[amount] query string false "Header of the amount column, likewise for the other fields"
+// @Success 200 {object} dto.WebResponse{data=dto.ImportJobResponse} "Import completed; a dry run answers with data=dto.ImportValidationResponse"
+// @Failure 400 {object} dto.WebResponse "Unreadable file or parameters"
+// @Failure 403 {object} dto.WebResponse "Token lacks the transactions:write scope"
+// @Failure 404 {object} dto.WebResponse "Import job not found"
+// @Failure 409 {object} dto.WebResponse "job_id was started with another file"
+// @Failure 413 {object} dto.WebResponse "File larger than 20 MiB"
+// @Failure 422 {object} dto.WebResponse{data=dto.ImportJobResponse} "Invalid rows, or a row was rejected and stopped the job"
+// @Failure 500 {object} dto.WebResponse "Internal error"
+// @Failure 503 {object} dto.WebResponse{data=dto.ImportJobResponse} "Database unavailable, resume the job"
+// @Router /imports/transfers [post]
+func (c *ImportController) ImportTransfers(ctx echo.Context) error {
+	return c.importFile(ctx, entities.ImportKindTransfers)
+}
+
+// FindJob godoc
+// @Summary Get an import job
+// @Description Progress of a CSV import; a failed job names the line that stopped it
+// @ID get-import-job
+// @Tags         Imports
+// @Produce json
+// @Param jobId path string true "Import job ID"
+// @Success 200 {object} dto.WebResponse{data=dto.ImportJobResponse} "Import job"
+// @Failure 400 {object} dto.WebResponse "Invalid jobId"
+// @Failure 403 {object} dto.WebResponse "Token lacks the read scope of the job's kind"
+// @Failure 404 {object} dto.WebResponse "Import job not found"
+// @Failure 500 {object} dto.WebResponse "Internal error"
+// @Router /imports/{jobId} [get]
+func (c *ImportController) FindJob(ctx echo.Context) error {
+	logger, _ := ctx.Request().Context().Value(logger.LoggerContextKey).(*logrus.Entry)
+	jobId := ctx.Param("jobId")
+
+	if _, err := uuid.Parse(jobId); err != nil {
+		logger.WithError(err).Errorf("Invalid jobId parameter: %s", jobId)
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
+			Message: "Invalid jobId format, expected a UUID",
+			Status:  0,
+			Data:    nil,
+		})
+	}
+
+	job, err := c.ImportService.FindJob(ctx.Request().Context(), jobId)
+	if err != nil {
+		logger.Error("Error find import job controller: ", err)
+		return errorResponse(ctx, err)
+	}
+	if err := authorize(ctx, importScope(job.Kind, false)); err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse{
+		Message: "success get import job",
+		Status:  1,
+		Data:    toImportJobResponse(job),
+	})
+}
+
+func (c *ImportController) importFile(ctx echo.Context, kind entities.ImportKind) error {
+	logger, _ := ctx.Request().Context().Value(logger.LoggerContextKey).(*logrus.Entry)
+	if err := authorize(ctx, importScope(kind, true)); err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	dryRun, jobID, columns, err := parseImportOptions(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Invalid import parameters")
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
+			Message: err.Error(),
+			Status:  0,
+			Data:    nil,
+		})
+	}
+
+	file, rowErrors, err := readImportUpload(ctx, kind, columns)
+	if err != nil {
+		logger.WithError(err).Error("Invalid import file")
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+			err = fmt.Errorf("file larger than 20 MiB, split it")
+		}
+		return ctx.JSON(status, dto.WebResponse{
+			Message: err.Error(),
+			Status:  0,
+			Data:    nil,
+		})
+	}
+
+	if dryRun {
+		found, err := c.ImportService.Validate(ctx.Request().Context(), file)
+		if err != nil {
+			logger.Error("Error validate import controller: ", err)
+			return errorResponse(ctx, err)
+		}
+
+		return ctx.JSON(http.StatusOK, dto.WebResponse{
+			Message: "dry run, nothing was imported",
+			Status:  1,
+			Data:    toImportValidationResponse(len(file.Rows)-len(found), append(rowErrors, found...)),
+		})
+	}
+
+	if len(rowErrors) > 0 {
+		logger.Errorf("Import file has %d invalid rows", len(rowErrors))
+		return ctx.JSON(http.StatusUnprocessableEntity, dto.WebResponse{
+			Message: "the file has invalid rows, nothing was imported",
+			Status:  0,
+			Data:    toImportValidationResponse(len(file.Rows), rowErrors),
+		})
+	}
+
+	job, err := c.ImportService.Import(ctx.Request().Context(), file, jobID)
+	if err != nil {
+		logger.Error("Error import controller: ", err)
+		var appErr *appErrors.AppError
+		if job == nil || !errors.As(err, &appErr) {
+			return errorResponse(ctx, err)
+		}
+		// the job tells the client what was committed and how to resume
+		return ctx.JSON(appErr.StatusCode, dto.WebResponse{
+			Message: appErr.Message,
+			Status:  0,
+			Data:    toImportJobResponse(job),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse{
+		Message: "import completed",
+		Status:  1,
+		Data:    toImportJobResponse(job),
+	})
+}
+
+// importScope is the scope a token needs to run, or to read, an import of kind
+func importScope(kind entities.ImportKind, write bool) string {
+	switch {
+	case kind == entities.ImportKindAccounts && write:
+		return entities.ScopeAccountsWrite
+	case kind == entities.ImportKindAccounts:
+		return entities.ScopeAccountsRead
+	case write:
+		return entities.ScopeTransactionsWrite
+	default:
+		return entities.ScopeTransactionsRead
+	}
+}
+
+func parseImportOptions(ctx echo.Context) (bool, string, csvimport.Columns, error) {
+	dryRun := false
+	if value := ctx.QueryParam("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			return false, "", nil, fmt.Errorf("invalid dry_run %q", value)
+		}
+	}
+
+	jobID := ctx.QueryParam("job_id")
+	if jobID != "" {
+		if _, err := uuid.Parse(jobID); err != nil {
+			return false, "", nil, fmt.Errorf("invalid job_id %q, expected a UUID", jobID)
+		}
+	}
+
+	columns := csvimport.Columns{}
+	for name, values := range ctx.QueryParams() {
+		if field, ok := queryMapKey("columns", name); ok {
+			columns[field] = values[0]
+		}
+	}
+
+	return dryRun, jobID, columns, nil
+}
+
+// readImportUpload parses the CSV sent as the body or as the multipart field "file"
+func readImportUpload(ctx echo.Context, kind entities.ImportKind, columns csvimport.Columns) (*entities.ImportFile, []entities.ImportRowError, error) {
+	request := ctx.Request()
+	request.Body = http.MaxBytesReader(ctx.Response(), request.Body, web.MaxImportBytes)
+
+	var upload io.Reader = request.Body
+	if strings.HasPrefix(request.Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		header, err := ctx.FormFile("file")
+		if err != nil {
+			return nil, nil, fmt.Errorf("missing multipart field \"file\": %w", err)
+		}
+		part, err := header.Open()
+		if err != nil {
+			return nil, nil, err
+		}
+		defer part.Close()
+		upload = part
+	}
+
+	return csvimport.Parse(upload, kind, columns)
+}
+
+func toImportValidationResponse(validRows int, rowErrors []entities.ImportRowError) *dto.ImportValidationResponse {
+	sort.SliceStable(rowErrors, func(i, j int) bool { return rowErrors[i].Line < rowErrors[j].Line })
+	if len(rowErrors) > csvimport.MaxRowErrors {
+		rowErrors = rowErrors[:csvimport.MaxRowErrors]
+	}
+
+	response := &dto.ImportValidationResponse{
+		ValidRows: validRows,
+		Errors:    make([]*dto.ImportRowErrorResponse, 0, len(rowErrors)),
+	}
+	for _, rowError := range rowErrors {
+		response.Errors = append(response.Errors, &dto.ImportRowErrorResponse{Line: rowError.Line, Message: rowError.Message})
+	}
+	return response
+}
+
+func toImportJobResponse(job *entities.ImportJob) *dto.ImportJobResponse {
+	return &dto.ImportJobResponse{
+		ID:            job.ID,
+		Kind:          string(job.Kind),
+		Status:        string(job.Status),
+		CommittedRows: job.CommittedRows,
+		TotalRows:     job.TotalRows,
+		FailedLine:    job.FailedLine,
+		Error:         job.Error,
+		CreatedAt:     job.CreatedAt,
+		UpdatedAt:     job.UpdatedAt,
+	}
+}
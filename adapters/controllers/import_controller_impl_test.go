@@ -0,0 +1,156 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"transfer-system/adapters/controllers"
+	"transfer-system/adapters/web/dto"
+	"transfer-system/domain/entities"
+	"transfer-system/internal/testutils"
+	"transfer-system/mocks"
+	appErrors "transfer-system/pkg/errors"
+)
+
+const transfersCSV = "source_account_id,destination_account_id,amount\n" +
+	"1,2,10\n" +
+	"1,2,abc\n" +
+	"2,1,5\n"
+
+func importRequest(target, contentType string, body *bytes.Buffer) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, target, body)
+	req.Header.Set(echo.HeaderContentType, contentType)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	testutils.InjectLoggerToContext(c)
+	return c, rec
+}
+
+func TestImportController_ImportTransfers(t *testing.T) {
+	t.Run("dry run reports every invalid row", func(t *testing.T) {
+		mockService := new(mocks.MockImportService)
+		controller := &controllers.ImportController{ImportService: mockService}
+
+		mockService.On("Validate", mock.Anything, mock.MatchedBy(func(file *entities.ImportFile) bool {
+			return len(file.Rows) == 2
+		})).Return([]entities.ImportRowError{{Line: 4, Message: "insufficient balance, account 2 would hold 0"}}, nil)
+
+		c, rec := importRequest("/imports/transfers?dry_run=true", "text/csv", bytes.NewBufferString(transfersCSV))
+		err := controller.ImportTransfers(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response struct {
+			Data dto.ImportValidationResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, 1, response.Data.ValidRows)
+		require.Len(t, response.Data.Errors, 2)
+		assert.Equal(t, 3, response.Data.Errors[0].Line)
+		assert.Equal(t, 4, response.Data.Errors[1].Line)
+		mockService.AssertNotCalled(t, "Import", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid rows import nothing", func(t *testing.T) {
+		mockService := new(mocks.MockImportService)
+		controller := &controllers.ImportController{ImportService: mockService}
+
+		c, rec := importRequest("/imports/transfers", "text/csv", bytes.NewBufferString(transfersCSV))
+		err := controller.ImportTransfers(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"line":3`)
+		mockService.AssertNotCalled(t, "Import", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("failed job is returned with the error", func(t *testing.T) {
+		mockService := new(mocks.MockImportService)
+		controller := &controllers.ImportController{ImportService: mockService}
+
+		job := &entities.ImportJob{ID: "3f0c2a8e-6d55-4a56-9d6b-2b1f3c0d9e11", Kind: entities.ImportKindTransfers, Status: entities.ImportStatusFailed, TotalRows: 2, CommittedRows: 1, FailedLine: 3, Error: "line 3: Insufficient balance"}
+		mockService.On("Import", mock.Anything, mock.Anything, job.ID).
+			Return(job, appErrors.NewUnprocessableError("line 3: Insufficient balance", entities.ErrInsufficientBalance))
+
+		body := "source,destination,amount\n1,2,10\n2,1,5\n"
+		c, rec := importRequest("/imports/transfers?job_id="+job.ID+"&columns[source_account_id]=source&columns[destination_account_id]=destination", "text/csv", bytes.NewBufferString(body))
+		err := controller.ImportTransfers(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+		var response struct {
+			Message string                `json:"message"`
+			Data    dto.ImportJobResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, "line 3: Insufficient balance", response.Message)
+		assert.Equal(t, "failed", response.Data.Status)
+		assert.Equal(t, 1, response.Data.CommittedRows)
+		assert.Equal(t, 3, response.Data.FailedLine)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		mockService := new(mocks.MockImportService)
+		controller := &controllers.ImportController{ImportService: mockService}
+
+		for _, target := range []string{"/imports/transfers?dry_run=maybe", "/imports/transfers?job_id=42", "/imports/transfers?columns[iban]=IBAN"} {
+			c, rec := importRequest(target, "text/csv", bytes.NewBufferString(transfersCSV))
+			err := controller.ImportTransfers(c)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+		}
+	})
+}
+
+func TestImportController_ImportAccounts(t *testing.T) {
+	mockService := new(mocks.MockImportService)
+	controller := &controllers.ImportController{ImportService: mockService}
+
+	job := &entities.ImportJob{ID: "3f0c2a8e-6d55-4a56-9d6b-2b1f3c0d9e11", Kind: entities.ImportKindAccounts, Status: entities.ImportStatusCompleted, TotalRows: 2, CommittedRows: 2}
+	mockService.On("Import", mock.Anything, mock.MatchedBy(func(file *entities.ImportFile) bool {
+		return file.Kind == entities.ImportKindAccounts && len(file.Rows) == 2
+	}), "").Return(job, nil)
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "accounts.csv")
+	require.NoError(t, err)
+	_, err = part.Write([]byte("account_id,initial_balance\n1,100\n2,50.5\n"))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	c, rec := importRequest("/imports/accounts", writer.FormDataContentType(), body)
+	err = controller.ImportAccounts(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"status":"completed"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestImportController_FindJob(t *testing.T) {
+	mockService := new(mocks.MockImportService)
+	controller := &controllers.ImportController{ImportService: mockService}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/imports/not-a-uuid", strings.NewReader(""))
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("jobId")
+	c.SetParamValues("not-a-uuid")
+	testutils.InjectLoggerToContext(c)
+
+	err := controller.FindJob(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockService.AssertNotCalled(t, "FindJob", mock.Anything, mock.Anything)
+}
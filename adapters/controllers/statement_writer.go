@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"transfer-system/adapters/iso20022"
+	"transfer-system/adapters/web/dto"
+	"transfer-system/domain/entities"
+
+	"github.com/labstack/echo/v4"
+	"github.com/shopspring/decimal"
+)
+
+// statementFlushEvery bounds how many lines are buffered before they are pushed to the client
+const statementFlushEvery = 100
+
+// statementResponse sends the status line and headers once the service knows the
+// statement can be produced, so earlier failures still get a regular error response
+func statementResponse(response *echo.Response, contentType string, header entities.StatementHeader, extension string) {
+	response.Header().Set(echo.HeaderContentType, contentType)
+	response.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=statement-%d-%s-%s.%s",
+		header.AccountID, header.From.UTC().Format("20060102"), header.To.UTC().Format("20060102"), extension))
+	response.WriteHeader(http.StatusOK)
+}
+
+// csvStatementWriter writes one row per movement between an opening and a closing balance row
+type csvStatementWriter struct {
+	response *echo.Response
+	csv      *csv.Writer
+	header   entities.StatementHeader
+	lines    int
+}
+
+func newCSVStatementWriter(response *echo.Response) *csvStatementWriter {
+	return &csvStatementWriter{response: response, csv: csv.NewWriter(response)}
+}
+
+func (w *csvStatementWriter) Begin(header entities.StatementHeader) error {
+	w.header = header
+	statementResponse(w.response, "text/csv; charset=utf-8", header, "csv")
+
+	w.csv.Write([]string{"booked_at", "transaction_id", "counterparty_account_id", "description", "reference", "amount", "balance", "currency"})
+	w.csv.Write([]string{header.From.UTC().Format(time.RFC3339), "", "", "Opening balance", "", "", header.OpeningBalance.String(), header.Currency})
+	return w.flush()
+}
+
+func (w *csvStatementWriter) WriteLine(line entities.StatementLine) error {
+	w.csv.Write([]string{
+		line.Transaction.CreatedAt.UTC().Format(time.RFC3339Nano),
+		strconv.FormatInt(line.Transaction.Id, 10),
+		strconv.FormatInt(line.CounterpartyID(), 10),
+		line.Transaction.Description,
+		line.Transaction.Reference,
+		line.Amount.String(),
+		line.RunningBalance.String(),
+		w.header.Currency,
+	})
+
+	w.lines++
+	if w.lines%statementFlushEvery == 0 {
+		return w.flush()
+	}
+	return w.csv.Error()
+}
+
+func (w *csvStatementWriter) End(closingBalance decimal.Decimal) error {
+	w.csv.Write([]string{w.header.To.UTC().Format(time.RFC3339), "", "", "Closing balance", "", "", closingBalance.String(), w.header.Currency})
+	return w.flush()
+}
+
+func (w *csvStatementWriter) flush() error {
+	w.csv.Flush()
+	w.response.Flush()
+	return w.csv.Error()
+}
+
+// jsonStatementWriter streams a regular WebResponse whose data holds the lines array
+type jsonStatementWriter struct {
+	response *echo.Response
+	lines    int
+}
+
+func newJSONStatementWriter(response *echo.Response) *jsonStatementWriter {
+	return &jsonStatementWriter{response: response}
+}
+
+func (w *jsonStatementWriter) Begin(header entities.StatementHeader) error {
+	statementResponse(w.response, echo.MIMEApplicationJSONCharsetUTF8, header, "json")
+
+	_, err := fmt.Fprintf(w.response, `{"message":"success get statement","status":1,"data":{"account_id":%d,"currency":%s,"from":%s,"to":%s,"opening_balance":%s,"lines":[`,
+		header.AccountID, jsonString(header.Currency), jsonString(header.From.UTC().Format(time.RFC3339Nano)),
+		jsonString(header.To.UTC().Format(time.RFC3339Nano)), jsonString(header.OpeningBalance.String()))
+	return err
+}
+
+func (w *jsonStatementWriter) WriteLine(line entities.StatementLine) error {
+	raw, err := json.Marshal(dto.StatementLineResponse{
+		Transaction:           toTransactionResponse(line.Transaction),
+		Amount:                line.Amount.String(),
+		Balance:               line.RunningBalance.String(),
+		CounterpartyAccountID: line.CounterpartyID(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if w.lines > 0 {
+		if _, err := w.response.Write([]byte(",")); err != nil {
+			return err
+		}
+	}
+	if _, err := w.response.Write(raw); err != nil {
+		return err
+	}
+
+	w.lines++
+	if w.lines%statementFlushEvery == 0 {
+		w.response.Flush()
+	}
+	return nil
+}
+
+func (w *jsonStatementWriter) End(closingBalance decimal.Decimal) error {
+	_, err := fmt.Fprintf(w.response, `],"closing_balance":%s}}`, jsonString(closingBalance.String()))
+	w.response.Flush()
+	return err
+}
+
+func jsonString(value string) string {
+	raw, _ := json.Marshal(value)
+	return string(raw)
+}
+
+// camt053StatementWriter sends the headers before handing the statement to the ISO 20022 encoder
+type camt053StatementWriter struct {
+	*iso20022.Camt053Writer
+	response *echo.Response
+}
+
+func newCamt053StatementWriter(response *echo.Response) *camt053StatementWriter {
+	return &camt053StatementWriter{Camt053Writer: iso20022.NewCamt053Writer(response, time.Now()), response: response}
+}
+
+func (w *camt053StatementWriter) Begin(header entities.StatementHeader) error {
+	statementResponse(w.response, "application/xml; charset=utf-8", header, "xml")
+	return w.Camt053Writer.Begin(header)
+}
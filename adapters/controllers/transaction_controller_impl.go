@@ -1,14 +1,16 @@
 package controllers
 
 import (
-	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"transfer-system/adapters/web"
 	"transfer-system/adapters/web/dto"
 	"transfer-system/domain/entities"
 	"transfer-system/domain/ports"
-	appErrors "transfer-system/pkg/errors"
 	"transfer-system/pkg/logger"
 	"transfer-system/pkg/validator"
 
@@ -19,6 +21,7 @@ import (
 
 type TransactionController struct {
 	TransactionService ports.TransactionService
+	Binder             web.Binder
 }
 
 // Save Transaction godoc
@@ -27,33 +30,276 @@ type TransactionController struct {
 // @Tags         Transactions
 // @Accept       json
 // @Produce      json
-// @Param        body  body      dto.TransactionRequest  true  "Transaction payload"  example({"source_account_id":1,"destination_account_id":2,"amount":"100.00"})
-// @Success      201   {object}  dto.WebResponse
-// @Failure      400   {object}  dto.WebResponse
+// @Param        body  body      dto.TransactionRequest  true  "Transaction payload"  example({"source_account_id":1,"destination_account_id":2,"amount":"100.00","description":"March rent","reference":"PAY-000123","metadata":{"cost_center":"CC-42"}})
+// @Success      201   {object}  dto.WebResponse{data=dto.TransactionResponse}
+// @Failure      400   {object}  dto.WebResponse{data=dto.ValidationErrorResponse}
+// @Failure      404   {object}  dto.WebResponse
+// @Failure      422   {object}  dto.WebResponse
+// @Failure      403   {object}  dto.WebResponse
 // @Failure      500   {object}  dto.WebResponse
+// @Failure      503   {object}  dto.WebResponse
+// @Failure      504   {object}  dto.WebResponse
 // @Router       /transactions [post]
 func (c *TransactionController) Save(ctx echo.Context) error {
 	logger, _ := ctx.Request().Context().Value(logger.LoggerContextKey).(*logrus.Entry)
+	if err := authorize(ctx, entities.ScopeTransactionsWrite); err != nil {
+		return errorResponse(ctx, err)
+	}
 	transactionRequest := dto.TransactionRequest{}
 
-	if err := web.GetPayload(ctx, &transactionRequest); err != nil {
+	if err := c.Binder.Bind(ctx, &transactionRequest); err != nil {
+		return invalidPayloadResponse(ctx, err)
+	}
+
+	amountDecimal, err := decimal.NewFromString(transactionRequest.Amount)
+	if err != nil {
+		logger.WithError(err).Error("Failed to parse amount")
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse{
+			Message: "An internal error occurred while processing balance.",
+			Status:  0,
+			Data:    nil,
+		})
+	}
+
+	internalServiceRequest := &entities.Transaction{
+		SourceAccountID:      transactionRequest.SourceAccountID,
+		DestinationAccountID: transactionRequest.DestinationAccountID,
+		Amount:               amountDecimal,
+		Description:          transactionRequest.Description,
+		Reference:            transactionRequest.Reference,
+		Remittance:           toRemittance(transactionRequest.Remittance),
+		Metadata:             entities.Metadata(transactionRequest.Metadata),
+	}
+
+	transaction, err := c.TransactionService.Save(ctx.Request().Context(), internalServiceRequest)
+
+	if err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	response := dto.WebResponse{
+		Message: "transaction success",
+		Status:  1,
+		Data:    toTransactionResponse(transaction),
+	}
+
+	return ctx.JSON(http.StatusCreated, response)
+}
+
+// FindById godoc
+// @Summary Get Transaction by ID
+// @Description Get a transfer with its description, reference and remittance information
+// @ID get-transaction-by-id
+// @Tags         Transactions
+// @Accept json
+// @Produce json
+// @Param transactionId path int true "Transaction ID"
+// @Success 200 {object} dto.WebResponse{data=dto.TransactionResponse} "Successfully retrieved transaction"
+// @Failure 400 {object} dto.WebResponse "Invalid transactionId format"
+// @Failure 404 {object} dto.WebResponse "Transaction not found"
+// @Failure 403 {object} dto.WebResponse "Bearer token lacks the scope"
+// @Failure 500 {object} dto.WebResponse "Internal error"
+// @Failure 503 {object} dto.WebResponse "Database unavailable, safe to retry"
+// @Failure 504 {object} dto.WebResponse "Request timed out, safe to retry"
+// @Router /transactions/{transactionId} [get]
+func (c *TransactionController) FindById(ctx echo.Context) error {
+	logger, _ := ctx.Request().Context().Value(logger.LoggerContextKey).(*logrus.Entry)
+	if err := authorize(ctx, entities.ScopeTransactionsRead); err != nil {
+		return errorResponse(ctx, err)
+	}
+	transactionIdStr := ctx.Param("transactionId")
+
+	transactionId, err := strconv.ParseInt(transactionIdStr, 10, 64)
+	if err != nil {
+		logger.WithError(err).Errorf("Invalid transactionId parameter: %s", transactionIdStr)
 		return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
-			Message: "Invalid request Payload",
+			Message: "Invalid transactionId format. Please provide a valid number.",
 			Status:  0,
 			Data:    nil,
 		})
 	}
 
-	if !validator.ValidateDecimalFormat(transactionRequest.Amount) {
-		logger.Errorf("Invalid amount format: %s", transactionRequest.Amount)
+	transaction, err := c.TransactionService.FindById(ctx.Request().Context(), transactionId)
+	if err != nil {
+		logger.Error("Error find transaction by id controller: ", err)
+		return errorResponse(ctx, err)
+	}
+
+	response := dto.WebResponse{
+		Message: "success get transaction by id",
+		Status:  1,
+		Data:    toTransactionResponse(transaction),
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// ListByAccount godoc
+// @Summary Account transaction history
+// @Description List transfers sent or received by an account, newest first. Pass next_cursor from the previous page as cursor to continue.
+// @ID list-account-transactions
+// @Tags         Transactions
+// @Accept json
+// @Produce json
+// @Param accountId path int true "Account ID"
+// @Param reference query string false "Only transfers with this client reference"
+// @Param type query string false "Comma separated transaction types: transfer, deposit, withdrawal, fee, reversal"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (default 50, max 200)"
+// @Success 200 {object} dto.WebResponse{data=dto.TransactionListResponse} "Successfully listed transactions"
+// @Failure 400 {object} dto.WebResponse "Invalid filter"
+// @Failure 404 {object} dto.WebResponse "Account not found"
+// @Failure 403 {object} dto.WebResponse "Bearer token lacks the scope"
+// @Failure 500 {object} dto.WebResponse "Internal error"
+// @Failure 503 {object} dto.WebResponse "Database unavailable, safe to retry"
+// @Failure 504 {object} dto.WebResponse "Request timed out, safe to retry"
+// @Router /accounts/{accountId}/transactions [get]
+func (c *TransactionController) ListByAccount(ctx echo.Context) error {
+	logger, _ := ctx.Request().Context().Value(logger.LoggerContextKey).(*logrus.Entry)
+	if err := authorize(ctx, entities.ScopeTransactionsRead); err != nil {
+		return errorResponse(ctx, err)
+	}
+	accountIdStr := ctx.Param("accountId")
+
+	accountId, err := strconv.ParseInt(accountIdStr, 10, 64)
+	if err != nil {
+		logger.WithError(err).Errorf("Invalid accountId parameter: %s", accountIdStr)
 		return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
-			Message: "Invalid amount format",
+			Message: "Invalid accountId format. Please provide a valid number.",
 			Status:  0,
 			Data:    nil,
 		})
 	}
 
-	amountDecimal, err := decimal.NewFromString(transactionRequest.Amount)
+	filter := entities.TransactionFilter{
+		AccountID: accountId,
+		Reference: ctx.QueryParam("reference"),
+		Cursor:    ctx.QueryParam("cursor"),
+	}
+	if filter.Reference != "" && !validator.ValidatePaymentReference(filter.Reference) {
+		logger.Errorf("Invalid reference parameter: %s", filter.Reference)
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
+			Message: fmt.Sprintf("invalid reference %q", filter.Reference),
+			Status:  0,
+			Data:    nil,
+		})
+	}
+	if value := ctx.QueryParam("type"); value != "" {
+		for _, name := range strings.Split(value, ",") {
+			transactionType := entities.TransactionType(strings.TrimSpace(name))
+			if !transactionType.IsValid() {
+				logger.Errorf("Invalid type parameter: %s", value)
+				return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
+					Message: fmt.Sprintf("invalid type %q", name),
+					Status:  0,
+					Data:    nil,
+				})
+			}
+			filter.Types = append(filter.Types, transactionType)
+		}
+	}
+	if value := ctx.QueryParam("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit <= 0 {
+			logger.Errorf("Invalid limit parameter: %s", value)
+			return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
+				Message: fmt.Sprintf("invalid limit %q", value),
+				Status:  0,
+				Data:    nil,
+			})
+		}
+	}
+
+	page, err := c.TransactionService.ListByAccount(ctx.Request().Context(), filter)
+	if err != nil {
+		logger.Error("Error list transactions controller: ", err)
+		return errorResponse(ctx, err)
+	}
+
+	listResponse := &dto.TransactionListResponse{
+		Transactions: make([]*dto.TransactionResponse, 0, len(page.Transactions)),
+		NextCursor:   page.NextCursor,
+	}
+	for _, transaction := range page.Transactions {
+		listResponse.Transactions = append(listResponse.Transactions, toTransactionResponse(transaction))
+	}
+
+	response := dto.WebResponse{
+		Message: "success list transactions",
+		Status:  1,
+		Data:    listResponse,
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// Deposit godoc
+// @Summary Deposit
+// @Description Credit an account with money arriving from outside through a settlement account. Without settlement_account_id the one configured for the account's currency is used.
+// @ID create-deposit
+// @Tags         Transactions
+// @Accept json
+// @Produce json
+// @Param accountId path int true "Account ID"
+// @Param body body dto.SettlementRequest true "Deposit payload" example({"amount":"100.00","description":"Cash deposit","reference":"WIRE-000123"})
+// @Success 201 {object} dto.WebResponse{data=dto.TransactionResponse} "Deposit booked"
+// @Failure 400 {object} dto.WebResponse{data=dto.ValidationErrorResponse} "Invalid payload"
+// @Failure 404 {object} dto.WebResponse "Account or settlement account not found"
+// @Failure 422 {object} dto.WebResponse "Account not active, currency mismatch or no settlement account"
+// @Failure 403 {object} dto.WebResponse "Bearer token lacks the scope"
+// @Failure 500 {object} dto.WebResponse "Internal error"
+// @Failure 503 {object} dto.WebResponse "Database unavailable, safe to retry"
+// @Failure 504 {object} dto.WebResponse "Request timed out, safe to retry"
+// @Router /accounts/{accountId}/deposits [post]
+func (c *TransactionController) Deposit(ctx echo.Context) error {
+	return c.settle(ctx, entities.TransactionTypeDeposit)
+}
+
+// Withdraw godoc
+// @Summary Withdrawal
+// @Description Debit an account with money leaving through a settlement account. Without settlement_account_id the one configured for the account's currency is used. Withdrawals count towards the owner's daily limit.
+// @ID create-withdrawal
+// @Tags         Transactions
+// @Accept json
+// @Produce json
+// @Param accountId path int true "Account ID"
+// @Param body body dto.SettlementRequest true "Withdrawal payload" example({"amount":"100.00","description":"ATM withdrawal"})
+// @Success 201 {object} dto.WebResponse{data=dto.TransactionResponse} "Withdrawal booked"
+// @Failure 400 {object} dto.WebResponse{data=dto.ValidationErrorResponse} "Invalid payload"
+// @Failure 404 {object} dto.WebResponse "Account or settlement account not found"
+// @Failure 422 {object} dto.WebResponse "Insufficient balance, daily limit exceeded, account not active, currency mismatch or no settlement account"
+// @Failure 403 {object} dto.WebResponse "Bearer token lacks the scope"
+// @Failure 500 {object} dto.WebResponse "Internal error"
+// @Failure 503 {object} dto.WebResponse "Database unavailable, safe to retry"
+// @Failure 504 {object} dto.WebResponse "Request timed out, safe to retry"
+// @Router /accounts/{accountId}/withdrawals [post]
+func (c *TransactionController) Withdraw(ctx echo.Context) error {
+	return c.settle(ctx, entities.TransactionTypeWithdrawal)
+}
+
+// settle binds a deposit or withdrawal request for the account in the path
+func (c *TransactionController) settle(ctx echo.Context, transactionType entities.TransactionType) error {
+	logger, _ := ctx.Request().Context().Value(logger.LoggerContextKey).(*logrus.Entry)
+	if err := authorize(ctx, entities.ScopeTransactionsWrite); err != nil {
+		return errorResponse(ctx, err)
+	}
+	accountIdStr := ctx.Param("accountId")
+
+	accountId, err := strconv.ParseInt(accountIdStr, 10, 64)
+	if err != nil {
+		logger.WithError(err).Errorf("Invalid accountId parameter: %s", accountIdStr)
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
+			Message: "Invalid accountId format. Please provide a valid number.",
+			Status:  0,
+			Data:    nil,
+		})
+	}
+
+	settlementRequest := dto.SettlementRequest{}
+	if err := c.Binder.Bind(ctx, &settlementRequest); err != nil {
+		return invalidPayloadResponse(ctx, err)
+	}
+
+	amountDecimal, err := decimal.NewFromString(settlementRequest.Amount)
 	if err != nil {
 		logger.WithError(err).Error("Failed to parse amount")
 		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse{
@@ -64,35 +310,71 @@ func (c *TransactionController) Save(ctx echo.Context) error {
 	}
 
 	internalServiceRequest := &entities.Transaction{
-		SourceAccountID:      transactionRequest.SourceAccountID,
-		DestinationAccountID: transactionRequest.DestinationAccountID,
-		Amount:               amountDecimal,
+		Amount:      amountDecimal,
+		Description: settlementRequest.Description,
+		Reference:   settlementRequest.Reference,
+		Metadata:    entities.Metadata(settlementRequest.Metadata),
 	}
 
-	err = c.TransactionService.Save(ctx.Request().Context(), internalServiceRequest)
-
+	var transaction *entities.Transaction
+	if transactionType == entities.TransactionTypeWithdrawal {
+		internalServiceRequest.SourceAccountID = accountId
+		internalServiceRequest.DestinationAccountID = settlementRequest.SettlementAccountID
+		transaction, err = c.TransactionService.Withdraw(ctx.Request().Context(), internalServiceRequest)
+	} else {
+		internalServiceRequest.SourceAccountID = settlementRequest.SettlementAccountID
+		internalServiceRequest.DestinationAccountID = accountId
+		transaction, err = c.TransactionService.Deposit(ctx.Request().Context(), internalServiceRequest)
+	}
 	if err != nil {
-		var appErr *appErrors.AppError
-		if errors.As(err, &appErr) {
-			return ctx.JSON(appErr.StatusCode, dto.WebResponse{
-				Message: appErr.Message,
-				Status:  0,
-				Data:    nil,
-			})
-		} else {
-			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse{
-				Message: "An unexpected error occurred",
-				Status:  0,
-				Data:    nil,
-			})
-		}
+		return errorResponse(ctx, err)
 	}
 
 	response := dto.WebResponse{
-		Message: "transaction success",
+		Message: fmt.Sprintf("%s success", transactionType),
 		Status:  1,
-		Data:    nil,
+		Data:    toTransactionResponse(transaction),
 	}
 
 	return ctx.JSON(http.StatusCreated, response)
 }
+
+// toRemittance converts the optional structured remittance block, which the binder already validated
+func toRemittance(request *dto.RemittanceRequest) *entities.Remittance {
+	if request == nil || (request.CreditorReference == "" && request.InvoiceNumber == "" && request.InvoiceDate == "") {
+		return nil
+	}
+
+	remittance := &entities.Remittance{
+		CreditorReference: request.CreditorReference,
+		InvoiceNumber:     request.InvoiceNumber,
+	}
+	if invoiceDate, err := time.Parse(dateLayout, request.InvoiceDate); err == nil {
+		remittance.InvoiceDate = &invoiceDate
+	}
+	return remittance
+}
+
+func toTransactionResponse(transaction *entities.Transaction) *dto.TransactionResponse {
+	response := &dto.TransactionResponse{
+		Id:                   transaction.Id,
+		Type:                 string(transaction.Type),
+		SourceAccountID:      transaction.SourceAccountID,
+		DestinationAccountID: transaction.DestinationAccountID,
+		Amount:               transaction.Amount.String(),
+		Description:          transaction.Description,
+		Reference:            transaction.Reference,
+		Metadata:             metadataResponse(transaction.Metadata),
+		CreatedAt:            transaction.CreatedAt,
+	}
+	if remittance := transaction.Remittance; remittance != nil {
+		response.Remittance = &dto.RemittanceResponse{
+			CreditorReference: remittance.CreditorReference,
+			InvoiceNumber:     remittance.InvoiceNumber,
+		}
+		if remittance.InvoiceDate != nil {
+			response.Remittance.InvoiceDate = remittance.InvoiceDate.Format(dateLayout)
+		}
+	}
+	return response
+}
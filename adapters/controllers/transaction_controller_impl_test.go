@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/shopspring/decimal"
@@ -18,6 +19,7 @@ import (
 	"transfer-system/internal/testutils"
 	"transfer-system/mocks"
 	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/validator"
 )
 
 func TestTransactionController_Save_Success(t *testing.T) {
@@ -30,6 +32,7 @@ func TestTransactionController_Save_Success(t *testing.T) {
 		SourceAccountID:      123,
 		DestinationAccountID: 456,
 		Amount:               "100.12345",
+		Metadata:             map[string]string{"cost_center": "CC-42"},
 	}
 	bodyBytes, _ := json.Marshal(reqBody)
 
@@ -43,9 +46,12 @@ func TestTransactionController_Save_Success(t *testing.T) {
 		SourceAccountID:      reqBody.SourceAccountID,
 		DestinationAccountID: reqBody.DestinationAccountID,
 		Amount:               decimal.RequireFromString(reqBody.Amount),
+		Metadata:             entities.Metadata{"cost_center": "CC-42"},
 	}
+	savedEntity := *expectedEntity
+	savedEntity.Id = 99
 
-	mockService.On("Save", mock.Anything, expectedEntity).Return(nil)
+	mockService.On("Save", mock.Anything, expectedEntity).Return(&savedEntity, nil)
 
 	err := controller.Save(c)
 	assert.NoError(t, err)
@@ -56,7 +62,9 @@ func TestTransactionController_Save_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "transaction success", resp.Message)
 	assert.Equal(t, int(1), resp.Status)
-	assert.Nil(t, resp.Data)
+	data := resp.Data.(map[string]interface{})
+	assert.Equal(t, float64(99), data["id"])
+	assert.Equal(t, map[string]interface{}{"cost_center": "CC-42"}, data["metadata"])
 }
 
 func TestTransactionController_Save_InsufficientBalance(t *testing.T) {
@@ -84,12 +92,12 @@ func TestTransactionController_Save_InsufficientBalance(t *testing.T) {
 		Amount:               decimal.RequireFromString(reqBody.Amount),
 	}
 
-	appErr := appErrors.NewBadRequestError("Insufficient balance", nil)
-	mockService.On("Save", mock.Anything, expectedEntity).Return(appErr)
+	appErr := appErrors.NewUnprocessableError("Insufficient balance", nil)
+	mockService.On("Save", mock.Anything, expectedEntity).Return(nil, appErr)
 
 	err := controller.Save(c)
 	assert.NoError(t, err)
-	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
 
 	var resp dto.WebResponse
 	err = json.Unmarshal(rec.Body.Bytes(), &resp)
@@ -107,7 +115,7 @@ func TestTransactionController_Save_InvalidAmountFormat(t *testing.T) {
 	reqBody := dto.TransactionRequest{
 		SourceAccountID:      123,
 		DestinationAccountID: 456,
-		Amount:               "100",
+		Amount:               "100.123456",
 	}
 	bodyBytes, _ := json.Marshal(reqBody)
 
@@ -124,13 +132,59 @@ func TestTransactionController_Save_InvalidAmountFormat(t *testing.T) {
 	var resp dto.WebResponse
 	err = json.Unmarshal(rec.Body.Bytes(), &resp)
 	assert.NoError(t, err)
-	assert.Equal(t, "Invalid amount format", resp.Message)
+	assert.Equal(t, "Invalid request: amount: must be a decimal with at most 15 integer and 5 fraction digits", resp.Message)
 	assert.Equal(t, 0, resp.Status)
-	assert.Nil(t, resp.Data)
 
 	mockService.AssertNotCalled(t, "Save")
 }
 
+func TestTransactionController_Save_AllFieldErrors(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockTransactionService)
+	controller := &controllers.TransactionController{TransactionService: mockService}
+
+	body := []byte(`{"source_account_id":7,"destination_account_id":7,"amount":"0","reference":"has space"}`)
+	req := httptest.NewRequest(http.MethodPost, "/transactions", bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	testutils.InjectLoggerToContext(c)
+
+	assert.NoError(t, controller.Save(c))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var resp struct {
+		Data dto.ValidationErrorResponse `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, validator.Errors{
+		{Field: "destination_account_id", Message: "must differ from source_account_id"},
+		{Field: "amount", Message: "must be greater than zero"},
+		{Field: "reference", Message: "expected up to 35 letters, digits or / - ? : ( ) . , ' +"},
+	}, resp.Data.Errors)
+	mockService.AssertNotCalled(t, "Save")
+}
+
+func TestTransactionController_Save_WholeAmount(t *testing.T) {
+	e := echo.New()
+	mockService := new(mocks.MockTransactionService)
+	controller := &controllers.TransactionController{TransactionService: mockService}
+
+	expectedEntity := &entities.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.RequireFromString("100")}
+	mockService.On("Save", mock.Anything, expectedEntity).Return(expectedEntity, nil)
+
+	body := []byte(`{"source_account_id":1,"destination_account_id":2,"amount":"100"}`)
+	req := httptest.NewRequest(http.MethodPost, "/transactions", bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	testutils.InjectLoggerToContext(c)
+
+	assert.NoError(t, controller.Save(c))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
 func TestTransactionController_Save_AccountNotFound(t *testing.T) {
 	e := echo.New()
 
@@ -156,12 +210,12 @@ func TestTransactionController_Save_AccountNotFound(t *testing.T) {
 		Amount:               decimal.RequireFromString(reqBody.Amount),
 	}
 
-	appErr := appErrors.NewBadRequestError("Account Not Found", nil)
-	mockService.On("Save", mock.Anything, expectedEntity).Return(appErr)
+	appErr := appErrors.NewNotFoundError("Account Not Found", nil)
+	mockService.On("Save", mock.Anything, expectedEntity).Return(nil, appErr)
 
 	err := controller.Save(c)
 	assert.NoError(t, err)
-	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
 
 	var resp dto.WebResponse
 	err = json.Unmarshal(rec.Body.Bytes(), &resp)
@@ -170,3 +224,268 @@ func TestTransactionController_Save_AccountNotFound(t *testing.T) {
 	assert.Equal(t, 0, resp.Status)
 	assert.Nil(t, resp.Data)
 }
+
+func TestTransactionController_Save_WithRemittance(t *testing.T) {
+	e := echo.New()
+
+	mockService := new(mocks.MockTransactionService)
+	controller := &controllers.TransactionController{TransactionService: mockService}
+
+	body := []byte(`{"source_account_id":123,"destination_account_id":456,"amount":"100.12345",
+		"description":"March rent","reference":"PAY-000123",
+		"remittance":{"creditor_reference":"RF18539007547034","invoice_number":"INV-1","invoice_date":"2025-03-01"}}`)
+	req := httptest.NewRequest(http.MethodPost, "/transactions", bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	testutils.InjectLoggerToContext(c)
+
+	invoiceDate := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	expectedEntity := &entities.Transaction{
+		SourceAccountID:      123,
+		DestinationAccountID: 456,
+		Amount:               decimal.RequireFromString("100.12345"),
+		Description:          "March rent",
+		Reference:            "PAY-000123",
+		Remittance: &entities.Remittance{
+			CreditorReference: "RF18539007547034",
+			InvoiceNumber:     "INV-1",
+			InvoiceDate:       &invoiceDate,
+		},
+	}
+	mockService.On("Save", mock.Anything, expectedEntity).Return(expectedEntity, nil)
+
+	err := controller.Save(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var resp dto.WebResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	data := resp.Data.(map[string]interface{})
+	assert.Equal(t, "March rent", data["description"])
+	assert.Equal(t, "PAY-000123", data["reference"])
+	assert.Equal(t, "2025-03-01", data["remittance"].(map[string]interface{})["invoice_date"])
+}
+
+func TestTransactionController_Save_InvalidRemittanceInfo(t *testing.T) {
+	e := echo.New()
+
+	mockService := new(mocks.MockTransactionService)
+	controller := &controllers.TransactionController{TransactionService: mockService}
+
+	for _, body := range []string{
+		`{"source_account_id":1,"destination_account_id":2,"amount":"1.00000","description":"line\nbreak"}`,
+		`{"source_account_id":1,"destination_account_id":2,"amount":"1.00000","reference":"has space"}`,
+		`{"source_account_id":1,"destination_account_id":2,"amount":"1.00000","remittance":{"creditor_reference":"RF19539007547034"}}`,
+		`{"source_account_id":1,"destination_account_id":2,"amount":"1.00000","remittance":{"invoice_date":"01/03/2025"}}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/transactions", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		testutils.InjectLoggerToContext(c)
+
+		err := controller.Save(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+	}
+	mockService.AssertNotCalled(t, "Save")
+}
+
+func TestTransactionController_FindById_NotFound(t *testing.T) {
+	e := echo.New()
+
+	mockService := new(mocks.MockTransactionService)
+	controller := &controllers.TransactionController{TransactionService: mockService}
+
+	mockService.On("FindById", mock.Anything, int64(42)).Return(nil, appErrors.NewNotFoundError("Transaction not found", nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/transactions/42", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("transactionId")
+	c.SetParamValues("42")
+	testutils.InjectLoggerToContext(c)
+
+	err := controller.FindById(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestTransactionController_ListByAccount_Success(t *testing.T) {
+	e := echo.New()
+
+	mockService := new(mocks.MockTransactionService)
+	controller := &controllers.TransactionController{TransactionService: mockService}
+
+	expectedFilter := entities.TransactionFilter{AccountID: 123, Reference: "PAY-1", Cursor: "abc", Limit: 10}
+	page := &entities.TransactionPage{
+		Transactions: []*entities.Transaction{{Id: 7, SourceAccountID: 123, DestinationAccountID: 456, Amount: decimal.NewFromInt(5), Reference: "PAY-1"}},
+		NextCursor:   "next",
+	}
+	mockService.On("ListByAccount", mock.Anything, expectedFilter).Return(page, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts/123/transactions?reference=PAY-1&cursor=abc&limit=10", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("accountId")
+	c.SetParamValues("123")
+	testutils.InjectLoggerToContext(c)
+
+	err := controller.ListByAccount(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.WebResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	data := resp.Data.(map[string]interface{})
+	assert.Equal(t, "next", data["next_cursor"])
+	assert.Len(t, data["transactions"], 1)
+}
+
+func TestTransactionController_ListByAccount_Types(t *testing.T) {
+	e := echo.New()
+
+	mockService := new(mocks.MockTransactionService)
+	controller := &controllers.TransactionController{TransactionService: mockService}
+
+	expectedFilter := entities.TransactionFilter{AccountID: 123, Types: []entities.TransactionType{entities.TransactionTypeDeposit, entities.TransactionTypeWithdrawal}}
+	mockService.On("ListByAccount", mock.Anything, expectedFilter).Return(&entities.TransactionPage{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts/123/transactions?type=deposit,withdrawal", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("accountId")
+	c.SetParamValues("123")
+	testutils.InjectLoggerToContext(c)
+
+	assert.NoError(t, controller.ListByAccount(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/accounts/123/transactions?type=deposit,refund", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("accountId")
+	c.SetParamValues("123")
+	testutils.InjectLoggerToContext(c)
+
+	assert.NoError(t, controller.ListByAccount(c))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockService.AssertNumberOfCalls(t, "ListByAccount", 1)
+}
+
+func TestTransactionController_Deposit(t *testing.T) {
+	e := echo.New()
+
+	mockService := new(mocks.MockTransactionService)
+	controller := &controllers.TransactionController{TransactionService: mockService}
+
+	expectedEntity := &entities.Transaction{
+		SourceAccountID:      9001,
+		DestinationAccountID: 123,
+		Amount:               decimal.RequireFromString("25.50000"),
+		Reference:            "WIRE-1",
+	}
+	deposit := *expectedEntity
+	deposit.Id = 7
+	deposit.Type = entities.TransactionTypeDeposit
+	mockService.On("Deposit", mock.Anything, expectedEntity).Return(&deposit, nil)
+
+	bodyBytes, _ := json.Marshal(dto.SettlementRequest{Amount: "25.50000", SettlementAccountID: 9001, Reference: "WIRE-1"})
+	req := httptest.NewRequest(http.MethodPost, "/accounts/123/deposits", bytes.NewReader(bodyBytes))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("accountId")
+	c.SetParamValues("123")
+	testutils.InjectLoggerToContext(c)
+
+	assert.NoError(t, controller.Deposit(c))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var resp dto.WebResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.Equal(t, "deposit success", resp.Message)
+	data := resp.Data.(map[string]interface{})
+	assert.Equal(t, "deposit", data["type"])
+	assert.Equal(t, float64(123), data["destination_account_id"])
+}
+
+func TestTransactionController_Withdraw_NoSettlementAccount(t *testing.T) {
+	e := echo.New()
+
+	mockService := new(mocks.MockTransactionService)
+	controller := &controllers.TransactionController{TransactionService: mockService}
+
+	expectedEntity := &entities.Transaction{SourceAccountID: 123, Amount: decimal.RequireFromString("10.00000")}
+	mockService.On("Withdraw", mock.Anything, expectedEntity).
+		Return(nil, appErrors.NewUnprocessableError("No settlement account for EUR", entities.ErrNoSettlementAccount))
+
+	req := httptest.NewRequest(http.MethodPost, "/accounts/123/withdrawals", bytes.NewReader([]byte(`{"amount":"10.00000"}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("accountId")
+	c.SetParamValues("123")
+	testutils.InjectLoggerToContext(c)
+
+	assert.NoError(t, controller.Withdraw(c))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestTransactionController_Scopes(t *testing.T) {
+	newContext := func(method, target, body string, scopes ...string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		testutils.InjectLoggerToContext(c)
+		testutils.InjectPrincipalToContext(c, &entities.Principal{Subject: "payroll-service", Scopes: scopes})
+		return c, rec
+	}
+	body := `{"source_account_id":1,"destination_account_id":2,"amount":"10"}`
+
+	t.Run("transfer without transactions:write", func(t *testing.T) {
+		mockService := new(mocks.MockTransactionService)
+		controller := &controllers.TransactionController{TransactionService: mockService}
+		c, rec := newContext(http.MethodPost, "/transactions", body, entities.ScopeTransactionsRead)
+
+		assert.NoError(t, controller.Save(c))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), "transactions:write")
+		mockService.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	t.Run("transfer with transactions:write", func(t *testing.T) {
+		mockService := new(mocks.MockTransactionService)
+		controller := &controllers.TransactionController{TransactionService: mockService}
+		c, rec := newContext(http.MethodPost, "/transactions", body, entities.ScopeTransactionsWrite)
+		mockService.On("Save", mock.Anything, mock.Anything).Return(&entities.Transaction{Id: 7}, nil)
+
+		assert.NoError(t, controller.Save(c))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("deposit without transactions:write", func(t *testing.T) {
+		mockService := new(mocks.MockTransactionService)
+		controller := &controllers.TransactionController{TransactionService: mockService}
+		c, rec := newContext(http.MethodPost, "/accounts/1/deposits", `{"amount":"10"}`, entities.ScopeAccountsWrite)
+		c.SetParamNames("accountId")
+		c.SetParamValues("1")
+
+		assert.NoError(t, controller.Deposit(c))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("history without transactions:read", func(t *testing.T) {
+		mockService := new(mocks.MockTransactionService)
+		controller := &controllers.TransactionController{TransactionService: mockService}
+		c, rec := newContext(http.MethodGet, "/accounts/1/transactions", "", entities.ScopeAccountsRead)
+		c.SetParamNames("accountId")
+		c.SetParamValues("1")
+
+		assert.NoError(t, controller.ListByAccount(c))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"transfer-system/adapters/iso20022"
+	"transfer-system/adapters/web/dto"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// maxTransferBatchBytes bounds the pain.001 document read into memory, ample for the largest batch
+const maxTransferBatchBytes = 10 << 20
+
+type TransferBatchController struct {
+	TransferBatchService ports.TransferBatchService
+}
+
+// Import godoc
+// @Summary Import a pain.001 payment batch
+// @Description Books every credit transfer of an ISO 20022 pain.001 customer credit transfer initiation and answers with a pain.002 status report.
+// @Description Debtor and creditor accounts are named by IBAN or Othr/Id, matched against the account external_reference and then the account id.
+// @Description Each instruction is booked on its own: the report lists ACSC with the transaction id as AcctSvcrRef, or RJCT with a reason code.
+// @ID import-transfer-batch
+// @Tags         Transactions
+// @Accept application/xml
+// @Produce application/xml
+// @Param body body string true "pain.001 document"
+// @Success 200 {string} string "pain.002 status report"
+// @Failure 400 {object} dto.WebResponse "Malformed or inconsistent pain.001 message"
+// @Failure 403 {object} dto.WebResponse "Token lacks the transactions:write scope"
+// @Failure 413 {object} dto.WebResponse "Message larger than 10 MiB"
+// @Failure 500 {object} dto.WebResponse "Internal error"
+// @Router /transactions/batch [post]
+func (c *TransferBatchController) Import(ctx echo.Context) error {
+	logger, _ := ctx.Request().Context().Value(logger.LoggerContextKey).(*logrus.Entry)
+	if err := authorize(ctx, entities.ScopeTransactionsWrite); err != nil {
+		return errorResponse(ctx, err)
+	}
+
+	body := http.MaxBytesReader(ctx.Response(), ctx.Request().Body, maxTransferBatchBytes)
+	message, err := iso20022.ParsePain001(body)
+	if err != nil {
+		logger.WithError(err).Error("Invalid pain.001 message")
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return ctx.JSON(http.StatusRequestEntityTooLarge, dto.WebResponse{
+				Message: "pain.001 message larger than 10 MiB, split the batch",
+				Status:  0,
+				Data:    nil,
+			})
+		}
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse{
+			Message: err.Error(),
+			Status:  0,
+			Data:    nil,
+		})
+	}
+
+	results, err := c.TransferBatchService.Execute(ctx.Request().Context(), message.Batch)
+	if err != nil {
+		logger.Error("Error import transfer batch controller: ", err)
+		return errorResponse(ctx, err)
+	}
+
+	response := ctx.Response()
+	response.Header().Set(echo.HeaderContentType, "application/xml; charset=utf-8")
+	response.WriteHeader(http.StatusOK)
+	return iso20022.WritePain002(response, message, results, time.Now())
+}
@@ -0,0 +1,102 @@
+package controllers_test
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"transfer-system/adapters/controllers"
+	"transfer-system/domain/entities"
+	"transfer-system/internal/testutils"
+	"transfer-system/mocks"
+	appErrors "transfer-system/pkg/errors"
+)
+
+func transferBatchRequest(body string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/transactions/batch", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationXML)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	testutils.InjectLoggerToContext(c)
+	return c, rec
+}
+
+func TestTransferBatchController_Import(t *testing.T) {
+	mockService := new(mocks.MockTransferBatchService)
+	controller := &controllers.TransferBatchController{TransferBatchService: mockService}
+
+	body, err := os.ReadFile("../iso20022/testdata/pain001_batch.xml")
+	require.NoError(t, err)
+
+	results := []*entities.TransferInstructionResult{
+		{Instruction: &entities.TransferInstruction{PaymentInformationID: "PAYROLL-03", Reference: "SALARY-0042"}, Transaction: &entities.Transaction{Id: 501}},
+		{Instruction: &entities.TransferInstruction{PaymentInformationID: "PAYROLL-03"}, Transaction: &entities.Transaction{Id: 502}},
+		{Instruction: &entities.TransferInstruction{PaymentInformationID: "SUPPLIERS-03", Reference: "SUP-981"}, Err: appErrors.NewNotFoundError("Account 99 not found", entities.ErrUnknownAccount)},
+	}
+	mockService.On("Execute", mock.Anything, mock.MatchedBy(func(batch *entities.TransferBatch) bool {
+		return batch.MessageID == "ACME-2025-03-31-001" && len(batch.Instructions) == 3
+	})).Return(results, nil)
+
+	c, rec := transferBatchRequest(string(body))
+	err = controller.Import(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/xml; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+
+	var report struct {
+		GroupStatus string `xml:"CstmrPmtStsRpt>OrgnlGrpInfAndSts>GrpSts"`
+		Payments    []struct {
+			Status       string `xml:"PmtInfSts"`
+			Transactions []struct {
+				Status string `xml:"TxSts"`
+				Reason string `xml:"StsRsnInf>Rsn>Cd"`
+			} `xml:"TxInfAndSts"`
+		} `xml:"CstmrPmtStsRpt>OrgnlPmtInfAndSts"`
+	}
+	require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, "PART", report.GroupStatus)
+	require.Len(t, report.Payments, 2)
+	assert.Equal(t, "ACSC", report.Payments[0].Status)
+	assert.Equal(t, "RJCT", report.Payments[1].Status)
+	assert.Equal(t, "AC01", report.Payments[1].Transactions[0].Reason)
+}
+
+func TestTransferBatchController_Import_InvalidMessage(t *testing.T) {
+	mockService := new(mocks.MockTransferBatchService)
+	controller := &controllers.TransferBatchController{TransferBatchService: mockService}
+
+	for _, body := range []string{
+		"",
+		"<Document><CstmrCdtTrfInitn/></Document>",
+		`<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09"><CstmrCdtTrfInitn><GrpHdr><MsgId>X</MsgId><NbOfTxs>1</NbOfTxs></GrpHdr></CstmrCdtTrfInitn></Document>`,
+	} {
+		c, rec := transferBatchRequest(body)
+		err := controller.Import(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+	}
+	mockService.AssertNotCalled(t, "Execute")
+}
+
+func TestTransferBatchController_Import_Scope(t *testing.T) {
+	mockService := new(mocks.MockTransferBatchService)
+	controller := &controllers.TransferBatchController{TransferBatchService: mockService}
+
+	body, err := os.ReadFile("../iso20022/testdata/pain001_batch.xml")
+	require.NoError(t, err)
+
+	c, rec := transferBatchRequest(string(body))
+	testutils.InjectPrincipalToContext(c, &entities.Principal{Subject: "reporting", Scopes: []string{entities.ScopeAccountsRead}})
+	assert.NoError(t, controller.Import(c))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	mockService.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything)
+}
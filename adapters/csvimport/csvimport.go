@@ -0,0 +1,262 @@
+package csvimport
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"transfer-system/domain/entities"
+	"transfer-system/pkg/validator"
+
+	"github.com/shopspring/decimal"
+)
+
+// MaxRowErrors bounds the row errors reported for one file
+const MaxRowErrors = 100
+
+// amountScale matches the NUMERIC(20, 5) balance and amount columns
+const amountScale = 5
+
+// Columns maps an import field to the header of the column holding it.
+// Fields that are not mapped are read from the column named after the field
+type Columns map[string]string
+
+type field struct {
+	name     string
+	required bool
+}
+
+var accountFields = []field{
+	{name: "account_id"},
+	{name: "initial_balance", required: true},
+	{name: "currency"},
+	{name: "external_reference"},
+	{name: "owner_id"},
+}
+
+var transferFields = []field{
+	{name: "source_account_id", required: true},
+	{name: "destination_account_id", required: true},
+	{name: "amount", required: true},
+	{name: "description"},
+	{name: "reference"},
+}
+
+// Fields lists the import fields of kind, in their documented order
+func Fields(kind entities.ImportKind) []string {
+	fields := accountFields
+	if kind == entities.ImportKindTransfers {
+		fields = transferFields
+	}
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		names = append(names, f.name)
+	}
+	return names
+}
+
+// Parse reads a CSV file with a header row. Rows that fail validation are
+// reported as row errors and left out of the file; an error is returned when
+// the file as a whole cannot be read, such as a missing required column
+func Parse(r io.Reader, kind entities.ImportKind, columns Columns) (*entities.ImportFile, []entities.ImportRowError, error) {
+	fields, build := accountFields, buildAccount
+	switch kind {
+	case entities.ImportKindAccounts:
+	case entities.ImportKindTransfers:
+		fields, build = transferFields, buildTransfer
+	default:
+		return nil, nil, fmt.Errorf("unknown import kind %q", kind)
+	}
+
+	checksum := sha256.New()
+	reader := csv.NewReader(io.TeeReader(r, checksum))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, fmt.Errorf("file is empty, expected a header row")
+		}
+		return nil, nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+	positions, err := columnPositions(header, fields, columns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file := &entities.ImportFile{Kind: kind, Rows: []*entities.ImportRow{}}
+	rowErrors := []entities.ImportRowError{}
+	report := func(line int, message string) {
+		if len(rowErrors) < MaxRowErrors {
+			rowErrors = append(rowErrors, entities.ImportRowError{Line: line, Message: message})
+		}
+	}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			report(parseErr.Line, parseErr.Err.Error())
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		values := map[string]string{}
+		for name, position := range positions {
+			values[name] = strings.TrimSpace(record[position])
+		}
+
+		row, problems := build(values)
+		if len(problems) > 0 {
+			report(line, strings.Join(problems, "; "))
+			continue
+		}
+		row.Line = line
+		file.Rows = append(file.Rows, row)
+	}
+
+	file.Checksum = hex.EncodeToString(checksum.Sum(nil))
+	return file, rowErrors, nil
+}
+
+// columnPositions finds the column of each field, matching headers case-insensitively
+func columnPositions(header []string, fields []field, columns Columns) (map[string]int, error) {
+	known := map[string]bool{}
+	for _, f := range fields {
+		known[f.name] = true
+	}
+	for name := range columns {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown field %q in column mapping", name)
+		}
+	}
+
+	// spreadsheet exports often start with a UTF-8 byte order mark
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	positions := map[string]int{}
+	for _, f := range fields {
+		column := f.name
+		if mapped, ok := columns[f.name]; ok {
+			column = mapped
+		}
+		for i, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name), column) {
+				positions[f.name] = i
+				break
+			}
+		}
+		if _, found := positions[f.name]; !found && f.required {
+			return nil, fmt.Errorf("missing column %q for %s", column, f.name)
+		}
+	}
+
+	return positions, nil
+}
+
+func buildAccount(values map[string]string) (*entities.ImportRow, []string) {
+	var problems []string
+	account := &entities.Account{
+		Currency:          values["currency"],
+		ExternalReference: values["external_reference"],
+	}
+
+	if value := values["account_id"]; value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id <= 0 {
+			problems = append(problems, fmt.Sprintf("invalid account_id %q", value))
+		}
+		account.AccountID = id
+	}
+
+	balance, problem := parseAmount("initial_balance", values["initial_balance"], false)
+	if problem != "" {
+		problems = append(problems, problem)
+	}
+	account.Balance = balance
+
+	if account.Currency != "" && !validator.ValidateCurrency(account.Currency) {
+		problems = append(problems, fmt.Sprintf("invalid currency %q, expected an ISO 4217 code such as USD", account.Currency))
+	}
+	if account.ExternalReference != "" && !validator.ValidateReference(account.ExternalReference) {
+		problems = append(problems, fmt.Sprintf("invalid external_reference %q", account.ExternalReference))
+	}
+
+	if value := values["owner_id"]; value != "" {
+		ownerID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || ownerID <= 0 {
+			problems = append(problems, fmt.Sprintf("invalid owner_id %q", value))
+		}
+		account.OwnerID = &ownerID
+	}
+
+	return &entities.ImportRow{Account: account}, problems
+}
+
+func buildTransfer(values map[string]string) (*entities.ImportRow, []string) {
+	var problems []string
+	transfer := &entities.Transaction{
+		Description: values["description"],
+		Reference:   values["reference"],
+	}
+
+	accounts := []struct {
+		name   string
+		target *int64
+	}{
+		{"source_account_id", &transfer.SourceAccountID},
+		{"destination_account_id", &transfer.DestinationAccountID},
+	}
+	for _, account := range accounts {
+		id, err := strconv.ParseInt(values[account.name], 10, 64)
+		if err != nil || id <= 0 {
+			problems = append(problems, fmt.Sprintf("invalid %s %q", account.name, values[account.name]))
+		}
+		*account.target = id
+	}
+	if transfer.SourceAccountID > 0 && transfer.SourceAccountID == transfer.DestinationAccountID {
+		problems = append(problems, "destination_account_id must differ from source_account_id")
+	}
+
+	amount, problem := parseAmount("amount", values["amount"], true)
+	if problem != "" {
+		problems = append(problems, problem)
+	}
+	transfer.Amount = amount
+
+	if transfer.Description != "" && !validator.ValidateDescription(transfer.Description) {
+		problems = append(problems, "invalid description, expected up to 140 letters, digits, spaces or / - ? : ( ) . , ' +")
+	}
+	if transfer.Reference != "" && !validator.ValidatePaymentReference(transfer.Reference) {
+		problems = append(problems, fmt.Sprintf("invalid reference %q", transfer.Reference))
+	}
+
+	return &entities.ImportRow{Transfer: transfer}, problems
+}
+
+// parseAmount accepts plain decimals with up to five fraction digits, as stored by the database
+func parseAmount(name, value string, positive bool) (decimal.Decimal, string) {
+	amount, err := decimal.NewFromString(value)
+	if err != nil || strings.ContainsAny(value, "eE") || amount.Exponent() < -amountScale {
+		return decimal.Zero, fmt.Sprintf("invalid %s %q, expected a decimal with at most 5 fraction digits", name, value)
+	}
+	if amount.IsNegative() {
+		return decimal.Zero, fmt.Sprintf("invalid %s %q, must not be negative", name, value)
+	}
+	if positive && amount.IsZero() {
+		return decimal.Zero, fmt.Sprintf("invalid %s %q, must be greater than zero", name, value)
+	}
+	return amount, ""
+}
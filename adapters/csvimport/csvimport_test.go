@@ -0,0 +1,141 @@
+package csvimport_test
+
+import (
+	"strings"
+	"testing"
+
+	"transfer-system/adapters/csvimport"
+	"transfer-system/domain/entities"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_Accounts(t *testing.T) {
+	input := "\ufeffAccount_ID,Initial_Balance,Currency,Owner_ID\n" +
+		"1,100.50,EUR,\n" +
+		"2,0,,7\n"
+
+	file, rowErrors, err := csvimport.Parse(strings.NewReader(input), entities.ImportKindAccounts, nil)
+	require.NoError(t, err)
+	assert.Empty(t, rowErrors)
+	assert.Len(t, file.Checksum, 64)
+	require.Len(t, file.Rows, 2)
+
+	assert.Equal(t, 2, file.Rows[0].Line)
+	assert.Equal(t, int64(1), file.Rows[0].Account.AccountID)
+	assert.True(t, decimal.RequireFromString("100.5").Equal(file.Rows[0].Account.Balance))
+	assert.Equal(t, "EUR", file.Rows[0].Account.Currency)
+	assert.Nil(t, file.Rows[0].Account.OwnerID)
+
+	assert.Equal(t, 3, file.Rows[1].Line)
+	require.NotNil(t, file.Rows[1].Account.OwnerID)
+	assert.Equal(t, int64(7), *file.Rows[1].Account.OwnerID)
+}
+
+func TestParse_ColumnMapping(t *testing.T) {
+	input := "From,To,Betrag,Verwendungszweck\n" +
+		"1,2,10.25,Rent March\n"
+
+	file, rowErrors, err := csvimport.Parse(strings.NewReader(input), entities.ImportKindTransfers, csvimport.Columns{
+		"source_account_id":      "From",
+		"destination_account_id": "to",
+		"amount":                 "Betrag",
+		"description":            "Verwendungszweck",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, rowErrors)
+	require.Len(t, file.Rows, 1)
+
+	transfer := file.Rows[0].Transfer
+	assert.Equal(t, int64(1), transfer.SourceAccountID)
+	assert.Equal(t, int64(2), transfer.DestinationAccountID)
+	assert.True(t, decimal.RequireFromString("10.25").Equal(transfer.Amount))
+	assert.Equal(t, "Rent March", transfer.Description)
+}
+
+func TestParse_RowErrors(t *testing.T) {
+	input := "source_account_id,destination_account_id,amount\n" +
+		"1,2,10\n" +
+		"x,2,0\n" +
+		"1,2,1e3\n" +
+		"1,2,-4\n" +
+		"1,2,0.000001\n" +
+		"1,2\n" +
+		"3,4,5\n" +
+		"6,6,5\n"
+
+	file, rowErrors, err := csvimport.Parse(strings.NewReader(input), entities.ImportKindTransfers, nil)
+	require.NoError(t, err)
+	require.Len(t, file.Rows, 2)
+	assert.Equal(t, 2, file.Rows[0].Line)
+	assert.Equal(t, 8, file.Rows[1].Line)
+
+	assert.Equal(t, []entities.ImportRowError{
+		{Line: 3, Message: `invalid source_account_id "x"; invalid amount "0", must be greater than zero`},
+		{Line: 4, Message: `invalid amount "1e3", expected a decimal with at most 5 fraction digits`},
+		{Line: 5, Message: `invalid amount "-4", must not be negative`},
+		{Line: 6, Message: `invalid amount "0.000001", expected a decimal with at most 5 fraction digits`},
+		{Line: 7, Message: "wrong number of fields"},
+		{Line: 9, Message: "destination_account_id must differ from source_account_id"},
+	}, rowErrors)
+}
+
+func TestParse_FileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		kind    entities.ImportKind
+		columns csvimport.Columns
+		message string
+	}{
+		{
+			name:    "empty file",
+			input:   "",
+			kind:    entities.ImportKindAccounts,
+			message: "file is empty, expected a header row",
+		},
+		{
+			name:    "missing required column",
+			input:   "account_id,currency\n1,USD\n",
+			kind:    entities.ImportKindAccounts,
+			message: `missing column "initial_balance" for initial_balance`,
+		},
+		{
+			name:    "missing mapped column",
+			input:   "account_id,initial_balance\n1,10\n",
+			kind:    entities.ImportKindAccounts,
+			columns: csvimport.Columns{"initial_balance": "Opening"},
+			message: `missing column "Opening" for initial_balance`,
+		},
+		{
+			name:    "unknown mapped field",
+			input:   "source_account_id,destination_account_id,amount\n",
+			kind:    entities.ImportKindTransfers,
+			columns: csvimport.Columns{"currency": "Ccy"},
+			message: `unknown field "currency" in column mapping`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, rowErrors, err := csvimport.Parse(strings.NewReader(tt.input), tt.kind, tt.columns)
+			assert.Nil(t, file)
+			assert.Nil(t, rowErrors)
+			assert.EqualError(t, err, tt.message)
+		})
+	}
+}
+
+func TestParse_ChecksumFollowsContent(t *testing.T) {
+	parse := func(input string) string {
+		file, _, err := csvimport.Parse(strings.NewReader(input), entities.ImportKindAccounts, nil)
+		require.NoError(t, err)
+		return file.Checksum
+	}
+
+	first := parse("initial_balance\n10\n")
+	assert.Equal(t, first, parse("initial_balance\n10\n"))
+	assert.NotEqual(t, first, parse("initial_balance\n11\n"))
+}
@@ -0,0 +1,353 @@
+package iso20022
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"transfer-system/domain/entities"
+
+	"github.com/shopspring/decimal"
+)
+
+const Camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"
+
+// camt053FlushEvery bounds how many entries are buffered before they are written out
+const camt053FlushEvery = 100
+
+const (
+	creditIndicator = "CRDT"
+	debitIndicator  = "DBIT"
+	// notProvided is the ISO 20022 placeholder for a missing end-to-end id
+	notProvided = "NOTPROVIDED"
+	isoDate     = "2006-01-02"
+	isoDateTime = "2006-01-02T15:04:05.999999Z07:00"
+)
+
+// Camt053Writer renders a statement as a camt.053.001.08 bank-to-customer
+// statement. Entries are encoded one at a time, so memory use does not grow with
+// the statement. It implements ports.StatementWriter
+type Camt053Writer struct {
+	out     io.Writer
+	encoder *xml.Encoder
+	created time.Time
+	header  entities.StatementHeader
+	entries int
+}
+
+// NewCamt053Writer writes to out; created is the message creation time reported in the group header
+func NewCamt053Writer(out io.Writer, created time.Time) *Camt053Writer {
+	encoder := xml.NewEncoder(out)
+	encoder.Indent("", "  ")
+	return &Camt053Writer{out: out, encoder: encoder, created: created}
+}
+
+func (w *Camt053Writer) Begin(header entities.StatementHeader) error {
+	w.header = header
+	statementId := fmt.Sprintf("%d-%s-%s", header.AccountID, header.From.UTC().Format("20060102"), header.To.UTC().Format("20060102"))
+
+	if _, err := io.WriteString(w.out, xml.Header); err != nil {
+		return err
+	}
+	tokens := []xml.Token{
+		xml.StartElement{Name: xml.Name{Local: "Document"}, Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: Camt053Namespace}}},
+		xml.StartElement{Name: xml.Name{Local: "BkToCstmrStmt"}},
+	}
+	for _, token := range tokens {
+		if err := w.encoder.EncodeToken(token); err != nil {
+			return err
+		}
+	}
+
+	if err := w.encoder.EncodeElement(groupHeader{MsgId: "STMT-" + statementId, CreDtTm: w.created.UTC().Format(isoDateTime)},
+		xml.StartElement{Name: xml.Name{Local: "GrpHdr"}}); err != nil {
+		return err
+	}
+
+	if err := w.encoder.EncodeToken(xml.StartElement{Name: xml.Name{Local: "Stmt"}}); err != nil {
+		return err
+	}
+	// the statement head is encoded field by field so the entries can follow inside the same Stmt element
+	head := []struct {
+		name  string
+		value interface{}
+	}{
+		{"Id", statementId},
+		{"CreDtTm", w.created.UTC().Format(isoDateTime)},
+		{"FrToDt", dateTimePeriod{FrDtTm: header.From.UTC().Format(isoDateTime), ToDtTm: header.To.UTC().Format(isoDateTime)}},
+		{"Acct", cashAccount{Id: otherAccountId(header.AccountID), Ccy: header.Currency}},
+		{"Bal", newBalance("OPBD", header.OpeningBalance, header.Currency, header.From)},
+		{"Bal", newBalance("CLBD", header.ClosingBalance, header.Currency, header.To)},
+	}
+	for _, field := range head {
+		if err := w.encoder.EncodeElement(field.value, xml.StartElement{Name: xml.Name{Local: field.name}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Camt053Writer) WriteLine(line entities.StatementLine) error {
+	transaction := line.Transaction
+	indicator, familyCode := creditIndicator, "RCDT"
+	if line.Amount.IsNegative() {
+		indicator, familyCode = debitIndicator, "ICDT"
+	}
+	amount := newAmount(line.Amount, w.header.Currency)
+	reference := strconv.FormatInt(transaction.Id, 10)
+	bookingDate := dateAndDateTime{DtTm: transaction.CreatedAt.UTC().Format(isoDateTime)}
+
+	endToEndId := transaction.Reference
+	if endToEndId == "" {
+		endToEndId = notProvided
+	}
+
+	// the counterparty paid us on a credit and was paid by us on a debit
+	counterparty := &accountReference{Id: otherAccountId(line.CounterpartyID())}
+	parties := relatedParties{CdtrAcct: counterparty}
+	if indicator == creditIndicator {
+		parties = relatedParties{DbtrAcct: counterparty}
+	}
+
+	err := w.encoder.EncodeElement(entry{
+		NtryRef:     reference,
+		Amt:         amount,
+		CdtDbtInd:   indicator,
+		Sts:         entryStatus{Cd: "BOOK"},
+		BookgDt:     bookingDate,
+		ValDt:       bookingDate,
+		AcctSvcrRef: reference,
+		BkTxCd: bankTransactionCode{Domn: bankTransactionDomain{
+			Cd:   "PMNT",
+			Fmly: bankTransactionFamily{Cd: familyCode, SubFmlyCd: "BOOK"},
+		}},
+		NtryDtls: entryDetails{TxDtls: transactionDetails{
+			Refs:      transactionReferences{AcctSvcrRef: reference, EndToEndId: endToEndId},
+			Amt:       amount,
+			CdtDbtInd: indicator,
+			RltdPties: parties,
+			RmtInf:    newRemittanceInformation(transaction),
+		}},
+	}, xml.StartElement{Name: xml.Name{Local: "Ntry"}})
+	if err != nil {
+		return err
+	}
+
+	w.entries++
+	if w.entries%camt053FlushEvery == 0 {
+		return w.flush()
+	}
+	return nil
+}
+
+// End closes the document; the closing balance was already reported in the statement head
+func (w *Camt053Writer) End(_ decimal.Decimal) error {
+	for _, name := range []string{"Stmt", "BkToCstmrStmt", "Document"} {
+		if err := w.encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}}); err != nil {
+			return err
+		}
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w.out, "\n")
+	return err
+}
+
+func (w *Camt053Writer) flush() error {
+	if err := w.encoder.Flush(); err != nil {
+		return err
+	}
+	if flusher, ok := w.out.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+type groupHeader struct {
+	MsgId   string
+	CreDtTm string
+}
+
+type dateTimePeriod struct {
+	FrDtTm string
+	ToDtTm string
+}
+
+type genericAccountId struct {
+	Id string
+}
+
+type accountId struct {
+	Othr genericAccountId
+}
+
+func otherAccountId(id int64) accountId {
+	return accountId{Othr: genericAccountId{Id: strconv.FormatInt(id, 10)}}
+}
+
+type cashAccount struct {
+	Id  accountId
+	Ccy string
+}
+
+type accountReference struct {
+	Id accountId
+}
+
+type amount struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+// newAmount renders the magnitude; the sign travels in CdtDbtInd
+func newAmount(value decimal.Decimal, currency string) amount {
+	return amount{Ccy: currency, Value: value.Abs().String()}
+}
+
+type balanceTypeCode struct {
+	Cd string
+}
+
+type balanceType struct {
+	CdOrPrtry balanceTypeCode
+}
+
+type dateAndDateTime struct {
+	DtTm string
+}
+
+type balance struct {
+	Tp        balanceType
+	Amt       amount
+	CdtDbtInd string
+	Dt        dateAndDateTime
+}
+
+func newBalance(code string, value decimal.Decimal, currency string, at time.Time) balance {
+	indicator := creditIndicator
+	if value.IsNegative() {
+		indicator = debitIndicator
+	}
+	return balance{
+		Tp:        balanceType{CdOrPrtry: balanceTypeCode{Cd: code}},
+		Amt:       newAmount(value, currency),
+		CdtDbtInd: indicator,
+		Dt:        dateAndDateTime{DtTm: at.UTC().Format(isoDateTime)},
+	}
+}
+
+type entryStatus struct {
+	Cd string
+}
+
+type bankTransactionFamily struct {
+	Cd        string
+	SubFmlyCd string
+}
+
+type bankTransactionDomain struct {
+	Cd   string
+	Fmly bankTransactionFamily
+}
+
+type bankTransactionCode struct {
+	Domn bankTransactionDomain
+}
+
+type entry struct {
+	NtryRef     string
+	Amt         amount
+	CdtDbtInd   string
+	Sts         entryStatus
+	BookgDt     dateAndDateTime
+	ValDt       dateAndDateTime
+	AcctSvcrRef string
+	BkTxCd      bankTransactionCode
+	NtryDtls    entryDetails
+}
+
+type entryDetails struct {
+	TxDtls transactionDetails
+}
+
+type transactionReferences struct {
+	AcctSvcrRef string
+	EndToEndId  string
+}
+
+type relatedParties struct {
+	DbtrAcct *accountReference `xml:",omitempty"`
+	CdtrAcct *accountReference `xml:",omitempty"`
+}
+
+type transactionDetails struct {
+	Refs      transactionReferences
+	Amt       amount
+	CdtDbtInd string
+	RltdPties relatedParties
+	RmtInf    *remittanceInformation `xml:",omitempty"`
+}
+
+type documentTypeCode struct {
+	Cd string
+}
+
+type documentType struct {
+	CdOrPrtry documentTypeCode
+}
+
+type referredDocument struct {
+	Tp     documentType
+	Nb     string `xml:",omitempty"`
+	RltdDt string `xml:",omitempty"`
+}
+
+type creditorReferenceType struct {
+	CdOrPrtry documentTypeCode
+	Issr      string
+}
+
+type creditorReference struct {
+	Tp  creditorReferenceType
+	Ref string
+}
+
+type structuredRemittance struct {
+	RfrdDocInf *referredDocument  `xml:",omitempty"`
+	CdtrRefInf *creditorReference `xml:",omitempty"`
+}
+
+type remittanceInformation struct {
+	Ustrd string                `xml:",omitempty"`
+	Strd  *structuredRemittance `xml:",omitempty"`
+}
+
+func newRemittanceInformation(transaction *entities.Transaction) *remittanceInformation {
+	info := &remittanceInformation{Ustrd: transaction.Description}
+
+	if remittance := transaction.Remittance; remittance != nil {
+		info.Strd = &structuredRemittance{}
+		if remittance.InvoiceNumber != "" || remittance.InvoiceDate != nil {
+			document := &referredDocument{Tp: documentType{CdOrPrtry: documentTypeCode{Cd: "CINV"}}, Nb: remittance.InvoiceNumber}
+			if remittance.InvoiceDate != nil {
+				document.RltdDt = remittance.InvoiceDate.Format(isoDate)
+			}
+			info.Strd.RfrdDocInf = document
+		}
+		if remittance.CreditorReference != "" {
+			info.Strd.CdtrRefInf = &creditorReference{
+				Tp:  creditorReferenceType{CdOrPrtry: documentTypeCode{Cd: "SCOR"}, Issr: "ISO"},
+				Ref: remittance.CreditorReference,
+			}
+		}
+	}
+
+	if info.Ustrd == "" && info.Strd == nil {
+		return nil
+	}
+	return info
+}
@@ -0,0 +1,120 @@
+package iso20022_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"transfer-system/adapters/iso20022"
+	"transfer-system/domain/entities"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func assertGolden(t *testing.T, name string, actual []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.WriteFile(path, actual, 0o644))
+	}
+
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(actual))
+}
+
+func TestCamt053Writer_Statement(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	invoiceDate := time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)
+
+	received := &entities.Transaction{
+		Id:                   11,
+		SourceAccountID:      2,
+		DestinationAccountID: 1,
+		Amount:               decimal.RequireFromString("250.5"),
+		Description:          "Invoice 2025-17",
+		Reference:            "PAY-000123",
+		Remittance: &entities.Remittance{
+			CreditorReference: "RF18539007547034",
+			InvoiceNumber:     "2025-17",
+			InvoiceDate:       &invoiceDate,
+		},
+		CreatedAt: time.Date(2025, 3, 3, 9, 30, 0, 0, time.UTC),
+	}
+	sent := &entities.Transaction{
+		Id:                   12,
+		SourceAccountID:      1,
+		DestinationAccountID: 3,
+		Amount:               decimal.RequireFromString("100"),
+		CreatedAt:            time.Date(2025, 3, 15, 17, 5, 12, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+	writer := iso20022.NewCamt053Writer(&buf, time.Date(2025, 4, 1, 6, 0, 0, 0, time.UTC))
+
+	require.NoError(t, writer.Begin(entities.StatementHeader{
+		AccountID:      1,
+		Currency:       "EUR",
+		From:           from,
+		To:             to,
+		OpeningBalance: decimal.RequireFromString("1000"),
+		ClosingBalance: decimal.RequireFromString("1150.5"),
+	}))
+	require.NoError(t, writer.WriteLine(entities.StatementLine{
+		Transaction:    received,
+		Amount:         received.Amount,
+		RunningBalance: decimal.RequireFromString("1250.5"),
+	}))
+	require.NoError(t, writer.WriteLine(entities.StatementLine{
+		Transaction:    sent,
+		Amount:         sent.Amount.Neg(),
+		RunningBalance: decimal.RequireFromString("1150.5"),
+	}))
+	require.NoError(t, writer.End(decimal.RequireFromString("1150.5")))
+
+	assertGolden(t, "camt053_statement.xml", buf.Bytes())
+}
+
+func TestCamt053Writer_EmptyStatementIsWellFormed(t *testing.T) {
+	var buf bytes.Buffer
+	writer := iso20022.NewCamt053Writer(&buf, time.Date(2025, 4, 1, 6, 0, 0, 0, time.UTC))
+
+	require.NoError(t, writer.Begin(entities.StatementHeader{
+		AccountID:      7,
+		Currency:       "USD",
+		From:           time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:             time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		OpeningBalance: decimal.RequireFromString("-5"),
+		ClosingBalance: decimal.RequireFromString("-5"),
+	}))
+	require.NoError(t, writer.End(decimal.RequireFromString("-5")))
+
+	var document struct {
+		XMLName xml.Name `xml:"Document"`
+		Stmt    struct {
+			Id  string
+			Bal []struct {
+				Amt       string
+				CdtDbtInd string
+			}
+			Ntry []struct{}
+		} `xml:"BkToCstmrStmt>Stmt"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &document))
+
+	assert.Equal(t, iso20022.Camt053Namespace, document.XMLName.Space)
+	assert.Equal(t, "7-20250301-20250401", document.Stmt.Id)
+	assert.Empty(t, document.Stmt.Ntry)
+	require.Len(t, document.Stmt.Bal, 2)
+	assert.Equal(t, "5", document.Stmt.Bal[0].Amt)
+	assert.Equal(t, "DBIT", document.Stmt.Bal[0].CdtDbtInd)
+}
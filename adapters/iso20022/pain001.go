@@ -0,0 +1,209 @@
+package iso20022
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/pkg/validator"
+
+	"github.com/shopspring/decimal"
+)
+
+const pain001NamespacePrefix = "urn:iso:std:iso:20022:tech:xsd:pain.001.001."
+
+// Pain001 is a parsed customer credit transfer initiation
+type Pain001 struct {
+	// MessageName is taken from the namespace, e.g. pain.001.001.09, and echoed in the status report
+	MessageName string
+	Batch       *entities.TransferBatch
+}
+
+// ParsePain001 reads a pain.001 message. Only the elements needed to book the
+// transfers are read; execution dates and agents are ignored since transfers
+// between accounts of this system are booked immediately. A message that does
+// not add up (NbOfTxs, CtrlSum) or carries an invalid value is rejected as a whole
+func ParsePain001(r io.Reader) (*Pain001, error) {
+	var document pain001Document
+	if err := xml.NewDecoder(r).Decode(&document); err != nil {
+		return nil, fmt.Errorf("malformed pain.001 message: %w", err)
+	}
+	if document.XMLName.Local != "Document" || !strings.HasPrefix(document.XMLName.Space, pain001NamespacePrefix) {
+		return nil, fmt.Errorf("not a pain.001 message, expected a Document in the %s* namespace", pain001NamespacePrefix)
+	}
+	initiation := document.Initiation
+	if initiation == nil {
+		return nil, fmt.Errorf("missing CstmrCdtTrfInitn")
+	}
+	if initiation.GrpHdr.MsgId == "" {
+		return nil, fmt.Errorf("missing GrpHdr/MsgId")
+	}
+
+	batch := &entities.TransferBatch{MessageID: initiation.GrpHdr.MsgId}
+	total := decimal.Zero
+	for _, payment := range initiation.PmtInf {
+		if payment.PmtMtd != "TRF" {
+			return nil, fmt.Errorf("payment information %s: unsupported payment method %q, expected TRF", payment.PmtInfId, payment.PmtMtd)
+		}
+		debtorAccount, err := payment.DbtrAcct.identifier()
+		if err != nil {
+			return nil, fmt.Errorf("payment information %s: DbtrAcct %w", payment.PmtInfId, err)
+		}
+
+		for _, transfer := range payment.CdtTrfTxInf {
+			instruction, err := transfer.instruction(payment.PmtInfId, debtorAccount)
+			if err != nil {
+				return nil, fmt.Errorf("payment information %s, instruction %s: %w", payment.PmtInfId, transfer.PmtId.EndToEndId, err)
+			}
+			batch.Instructions = append(batch.Instructions, instruction)
+			total = total.Add(instruction.Amount)
+		}
+	}
+
+	if initiation.GrpHdr.NbOfTxs != fmt.Sprint(len(batch.Instructions)) {
+		return nil, fmt.Errorf("GrpHdr/NbOfTxs is %s but the message holds %d instructions", initiation.GrpHdr.NbOfTxs, len(batch.Instructions))
+	}
+	if controlSum := initiation.GrpHdr.CtrlSum; controlSum != "" {
+		expected, err := decimal.NewFromString(controlSum)
+		if err != nil || !expected.Equal(total) {
+			return nil, fmt.Errorf("GrpHdr/CtrlSum is %s but the instructed amounts add up to %s", controlSum, total)
+		}
+	}
+
+	return &Pain001{MessageName: strings.TrimPrefix(document.XMLName.Space, "urn:iso:std:iso:20022:tech:xsd:"), Batch: batch}, nil
+}
+
+type pain001Document struct {
+	XMLName    xml.Name
+	Initiation *creditTransferInitiation `xml:"CstmrCdtTrfInitn"`
+}
+
+type creditTransferInitiation struct {
+	GrpHdr struct {
+		MsgId   string
+		NbOfTxs string
+		CtrlSum string
+	}
+	PmtInf []paymentInstruction
+}
+
+type paymentInstruction struct {
+	PmtInfId    string
+	PmtMtd      string
+	DbtrAcct    instructedAccount
+	CdtTrfTxInf []creditTransfer
+}
+
+type instructedAccount struct {
+	Id struct {
+		IBAN string
+		Othr struct {
+			Id string
+		}
+	}
+}
+
+// identifier returns the IBAN or proprietary id naming the account
+func (a instructedAccount) identifier() (string, error) {
+	identifier := a.Id.IBAN
+	if identifier == "" {
+		identifier = a.Id.Othr.Id
+	}
+	if identifier == "" {
+		return "", fmt.Errorf("has neither an IBAN nor Othr/Id")
+	}
+	return identifier, nil
+}
+
+type creditTransfer struct {
+	PmtId struct {
+		InstrId    string
+		EndToEndId string
+	}
+	Amt struct {
+		InstdAmt struct {
+			Ccy   string `xml:"Ccy,attr"`
+			Value string `xml:",chardata"`
+		}
+	}
+	CdtrAcct instructedAccount
+	RmtInf   struct {
+		Ustrd []string
+		Strd  []struct {
+			RfrdDocInf []struct {
+				Nb     string
+				RltdDt string
+			}
+			CdtrRefInf struct {
+				Ref string
+			}
+		}
+	}
+}
+
+func (t creditTransfer) instruction(paymentInformationID, debtorAccount string) (*entities.TransferInstruction, error) {
+	creditorAccount, err := t.CdtrAcct.identifier()
+	if err != nil {
+		return nil, fmt.Errorf("CdtrAcct %w", err)
+	}
+
+	amount, err := decimal.NewFromString(strings.TrimSpace(t.Amt.InstdAmt.Value))
+	if err != nil || !amount.IsPositive() {
+		return nil, fmt.Errorf("invalid InstdAmt %q, expected a positive amount", t.Amt.InstdAmt.Value)
+	}
+	if !validator.ValidateCurrency(t.Amt.InstdAmt.Ccy) {
+		return nil, fmt.Errorf("invalid InstdAmt currency %q", t.Amt.InstdAmt.Ccy)
+	}
+
+	instruction := &entities.TransferInstruction{
+		PaymentInformationID: paymentInformationID,
+		InstructionID:        t.PmtId.InstrId,
+		DebtorAccount:        debtorAccount,
+		CreditorAccount:      creditorAccount,
+		Amount:               amount,
+		Currency:             t.Amt.InstdAmt.Ccy,
+		Description:          strings.Join(t.RmtInf.Ustrd, " "),
+	}
+
+	// NOTPROVIDED is the ISO 20022 placeholder for a debtor without its own reference
+	if endToEndId := t.PmtId.EndToEndId; endToEndId != notProvided {
+		if !validator.ValidatePaymentReference(endToEndId) {
+			return nil, fmt.Errorf("invalid EndToEndId %q", endToEndId)
+		}
+		instruction.Reference = endToEndId
+	}
+	if instruction.Description != "" && !validator.ValidateDescription(instruction.Description) {
+		return nil, fmt.Errorf("invalid Ustrd %q, expected up to 140 letters, digits, spaces or / - ? : ( ) . , ' +", instruction.Description)
+	}
+
+	// the transfer holds a single structured remittance, further Strd blocks are not kept
+	if len(t.RmtInf.Strd) > 0 {
+		structured := t.RmtInf.Strd[0]
+		remittance := &entities.Remittance{CreditorReference: structured.CdtrRefInf.Ref}
+		if remittance.CreditorReference != "" && !validator.ValidateCreditorReference(remittance.CreditorReference) {
+			return nil, fmt.Errorf("invalid CdtrRefInf/Ref %q, expected an ISO 11649 reference", remittance.CreditorReference)
+		}
+		if len(structured.RfrdDocInf) > 0 {
+			document := structured.RfrdDocInf[0]
+			if document.Nb != "" && !validator.ValidateInvoiceNumber(document.Nb) {
+				return nil, fmt.Errorf("invalid RfrdDocInf/Nb %q", document.Nb)
+			}
+			remittance.InvoiceNumber = document.Nb
+			if document.RltdDt != "" {
+				invoiceDate, err := time.Parse(isoDate, document.RltdDt)
+				if err != nil {
+					return nil, fmt.Errorf("invalid RfrdDocInf/RltdDt %q, expected YYYY-MM-DD", document.RltdDt)
+				}
+				remittance.InvoiceDate = &invoiceDate
+			}
+		}
+		if remittance.CreditorReference != "" || remittance.InvoiceNumber != "" || remittance.InvoiceDate != nil {
+			instruction.Remittance = remittance
+		}
+	}
+
+	return instruction, nil
+}
@@ -0,0 +1,107 @@
+package iso20022_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"transfer-system/adapters/iso20022"
+	"transfer-system/domain/entities"
+	appErrors "transfer-system/pkg/errors"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseBatchFixture(t *testing.T) *iso20022.Pain001 {
+	t.Helper()
+	file, err := os.Open("testdata/pain001_batch.xml")
+	require.NoError(t, err)
+	defer file.Close()
+
+	message, err := iso20022.ParsePain001(file)
+	require.NoError(t, err)
+	return message
+}
+
+func TestParsePain001(t *testing.T) {
+	message := parseBatchFixture(t)
+
+	assert.Equal(t, "pain.001.001.09", message.MessageName)
+	assert.Equal(t, "ACME-2025-03-31-001", message.Batch.MessageID)
+	require.Len(t, message.Batch.Instructions, 3)
+
+	salary := message.Batch.Instructions[0]
+	assert.Equal(t, "PAYROLL-03", salary.PaymentInformationID)
+	assert.Equal(t, "INSTR-1", salary.InstructionID)
+	assert.Equal(t, "DE89370400440532013000", salary.DebtorAccount)
+	assert.Equal(t, "2", salary.CreditorAccount)
+	assert.True(t, decimal.RequireFromString("1000.50").Equal(salary.Amount))
+	assert.Equal(t, "EUR", salary.Currency)
+	assert.Equal(t, "SALARY-0042", salary.Reference)
+	assert.Equal(t, "Salary March 2025", salary.Description)
+	assert.Nil(t, salary.Remittance)
+
+	invoice := message.Batch.Instructions[1]
+	assert.Equal(t, "FR7630006000011234567890189", invoice.CreditorAccount)
+	assert.Empty(t, invoice.Reference)
+	require.NotNil(t, invoice.Remittance)
+	assert.Equal(t, "RF18539007547034", invoice.Remittance.CreditorReference)
+	assert.Equal(t, "2025-17", invoice.Remittance.InvoiceNumber)
+	assert.Equal(t, "2025-02-28", invoice.Remittance.InvoiceDate.Format("2006-01-02"))
+
+	supplier := message.Batch.Instructions[2]
+	assert.Equal(t, "SUPPLIERS-03", supplier.PaymentInformationID)
+	assert.Equal(t, "1", supplier.DebtorAccount)
+	assert.Equal(t, "99", supplier.CreditorAccount)
+}
+
+func TestParsePain001_Invalid(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/pain001_batch.xml")
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		old, new string
+		expected string
+	}{
+		"not xml":                {"</Document>", "", "malformed"},
+		"other message":          {"pain.001.001.09", "camt.053.001.08", "not a pain.001 message"},
+		"transaction count":      {"<NbOfTxs>3</NbOfTxs>", "<NbOfTxs>2</NbOfTxs>", "NbOfTxs"},
+		"control sum":            {"<CtrlSum>1350.75</CtrlSum>", "<CtrlSum>1350.00</CtrlSum>", "CtrlSum"},
+		"payment method":         {"<PmtMtd>TRF</PmtMtd>", "<PmtMtd>CHK</PmtMtd>", "unsupported payment method"},
+		"negative amount":        {">100</InstdAmt>", ">-100</InstdAmt>", "InstdAmt"},
+		"currency":               {`Ccy="EUR">100<`, `Ccy="euro">100<`, "currency"},
+		"creditor reference":     {"RF18539007547034", "RF00539007547034", "CdtrRefInf"},
+		"missing creditor":       {"<Id>99</Id>", "<Id></Id>", "CdtrAcct"},
+		"description characters": {"Salary March 2025", "Salary March 2025 ✓", "Ustrd"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			document := strings.Replace(string(fixture), test.old, test.new, 1)
+			_, err := iso20022.ParsePain001(strings.NewReader(document))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.expected)
+		})
+	}
+}
+
+func TestWritePain002_Report(t *testing.T) {
+	message := parseBatchFixture(t)
+	instructions := message.Batch.Instructions
+
+	results := []*entities.TransferInstructionResult{
+		{Instruction: instructions[0], Transaction: &entities.Transaction{Id: 501}},
+		{Instruction: instructions[1], Err: appErrors.NewUnprocessableError("Insufficient balance", entities.ErrInsufficientBalance)},
+		{Instruction: instructions[2], Err: appErrors.NewNotFoundError("Account 99 not found", entities.ErrUnknownAccount)},
+	}
+
+	var buf bytes.Buffer
+	err := iso20022.WritePain002(&buf, message, results, time.Date(2025, 3, 31, 8, 0, 5, 0, time.UTC))
+	require.NoError(t, err)
+
+	assertGolden(t, "pain002_report.xml", buf.Bytes())
+}
@@ -0,0 +1,178 @@
+package iso20022
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"transfer-system/domain/entities"
+)
+
+const Pain002Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.002.001.10"
+
+// Transaction and group status codes of the status report
+const (
+	// statusSettled means the transfer was booked on both accounts
+	statusSettled  = "ACSC"
+	statusPartial  = "PART"
+	statusRejected = "RJCT"
+)
+
+// WritePain002 reports the outcome of every instruction of a pain.001 message,
+// grouped by payment information in the order the instructions were received
+func WritePain002(out io.Writer, message *Pain001, results []*entities.TransferInstructionResult, created time.Time) error {
+	report := statusReport{
+		GrpHdr: groupHeader{
+			MsgId:   "STS-" + created.UTC().Format("20060102T150405.000000"),
+			CreDtTm: created.UTC().Format(isoDateTime),
+		},
+		OrgnlGrpInfAndSts: originalGroupStatus{
+			OrgnlMsgId:   message.Batch.MessageID,
+			OrgnlMsgNmId: message.MessageName,
+			OrgnlNbOfTxs: strconv.Itoa(len(results)),
+			GrpSts:       overallStatus(results),
+		},
+	}
+
+	// index by payment information id, the statuses are appended to in place
+	payments := map[string]int{}
+	paymentResults := map[string][]*entities.TransferInstructionResult{}
+	for _, result := range results {
+		paymentId := result.Instruction.PaymentInformationID
+		index, seen := payments[paymentId]
+		if !seen {
+			index = len(report.OrgnlPmtInfAndSts)
+			payments[paymentId] = index
+			report.OrgnlPmtInfAndSts = append(report.OrgnlPmtInfAndSts, originalPaymentStatus{OrgnlPmtInfId: paymentId})
+		}
+		report.OrgnlPmtInfAndSts[index].TxInfAndSts = append(report.OrgnlPmtInfAndSts[index].TxInfAndSts, newTransactionStatus(result))
+		paymentResults[paymentId] = append(paymentResults[paymentId], result)
+	}
+	for i := range report.OrgnlPmtInfAndSts {
+		report.OrgnlPmtInfAndSts[i].PmtInfSts = overallStatus(paymentResults[report.OrgnlPmtInfAndSts[i].OrgnlPmtInfId])
+	}
+
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(out)
+	encoder.Indent("", "  ")
+	err := encoder.Encode(pain002Document{Xmlns: Pain002Namespace, Report: report})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, "\n")
+	return err
+}
+
+// statusReason maps a rejection to an ISO 20022 external status reason code
+func statusReason(err error) string {
+	switch {
+	case errors.Is(err, entities.ErrUnknownAccount):
+		// IncorrectAccountNumber
+		return "AC01"
+	case errors.Is(err, entities.ErrAccountNotActive):
+		// BlockedAccount
+		return "AC06"
+	case errors.Is(err, entities.ErrSystemAccount):
+		// TransactionForbidden
+		return "AG01"
+	case errors.Is(err, entities.ErrInvalidAmount):
+		// ZeroAmount
+		return "AM01"
+	case errors.Is(err, entities.ErrCurrencyMismatch):
+		// NotAllowedCurrency
+		return "AM03"
+	case errors.Is(err, entities.ErrInsufficientBalance):
+		// InsufficientFunds
+		return "AM04"
+	case errors.Is(err, entities.ErrDailyLimitExceeded):
+		// AmountExceedsAgreedLimit
+		return "AM14"
+	}
+	// Narrative, the reason is given in AddtlInf
+	return "NARR"
+}
+
+func overallStatus(results []*entities.TransferInstructionResult) string {
+	settled := 0
+	for _, result := range results {
+		if result.Err == nil {
+			settled++
+		}
+	}
+	switch settled {
+	case len(results):
+		return statusSettled
+	case 0:
+		return statusRejected
+	}
+	return statusPartial
+}
+
+func newTransactionStatus(result *entities.TransferInstructionResult) transactionStatus {
+	status := transactionStatus{
+		OrgnlInstrId:    result.Instruction.InstructionID,
+		OrgnlEndToEndId: result.Instruction.Reference,
+		TxSts:           statusSettled,
+	}
+	if status.OrgnlEndToEndId == "" {
+		status.OrgnlEndToEndId = notProvided
+	}
+
+	if result.Err != nil {
+		status.TxSts = statusRejected
+		status.StsRsnInf = &statusReasonInformation{
+			Rsn:      statusReasonCode{Cd: statusReason(result.Err)},
+			AddtlInf: result.Err.Error(),
+		}
+		return status
+	}
+
+	status.AcctSvcrRef = strconv.FormatInt(result.Transaction.Id, 10)
+	return status
+}
+
+type pain002Document struct {
+	XMLName xml.Name     `xml:"Document"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Report  statusReport `xml:"CstmrPmtStsRpt"`
+}
+
+type statusReport struct {
+	GrpHdr            groupHeader
+	OrgnlGrpInfAndSts originalGroupStatus
+	OrgnlPmtInfAndSts []originalPaymentStatus
+}
+
+type originalGroupStatus struct {
+	OrgnlMsgId   string
+	OrgnlMsgNmId string
+	OrgnlNbOfTxs string
+	GrpSts       string
+}
+
+type originalPaymentStatus struct {
+	OrgnlPmtInfId string
+	PmtInfSts     string
+	TxInfAndSts   []transactionStatus
+}
+
+type statusReasonCode struct {
+	Cd string
+}
+
+type statusReasonInformation struct {
+	Rsn      statusReasonCode
+	AddtlInf string
+}
+
+type transactionStatus struct {
+	OrgnlInstrId    string `xml:",omitempty"`
+	OrgnlEndToEndId string
+	TxSts           string
+	StsRsnInf       *statusReasonInformation `xml:",omitempty"`
+	AcctSvcrRef     string                   `xml:",omitempty"`
+}
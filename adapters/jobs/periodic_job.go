@@ -0,0 +1,67 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PeriodicJob calls Run every Interval until stopped. A run that is still in
+// progress when the next tick fires delays that tick instead of overlapping it
+type PeriodicJob struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+	Logger   *logrus.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (j *PeriodicJob) Start(parent context.Context) {
+	ctx, cancel := context.WithCancel(parent)
+	j.cancel = cancel
+	j.done = make(chan struct{})
+
+	jobLogger := j.Logger.WithField("job", j.Name)
+	ctx = context.WithValue(ctx, logger.LoggerContextKey, jobLogger)
+
+	go func() {
+		defer close(j.done)
+
+		ticker := time.NewTicker(j.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				start := time.Now()
+				if err := j.Run(ctx); err != nil {
+					jobLogger.WithError(err).Error("Job run failed")
+					continue
+				}
+				jobLogger.WithField("duration", time.Since(start).String()).Info("Job run finished")
+			}
+		}
+	}()
+}
+
+// Stop cancels the current run and waits for it to return, matching utils.Operation for graceful shutdown
+func (j *PeriodicJob) Stop(ctx context.Context) error {
+	if j.cancel == nil {
+		return nil
+	}
+	j.cancel()
+
+	select {
+	case <-j.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,256 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultRefreshInterval is how long a loaded JWKS is used before it is loaded again
+const DefaultRefreshInterval = 15 * time.Minute
+
+// DefaultMinReload spaces out the reloads caused by tokens with unknown key
+// ids, so a stream of forged kids cannot hammer the JWKS endpoint
+const DefaultMinReload = 30 * time.Second
+
+// maxJWKSSize bounds the JWKS document read from a file or URL
+const maxJWKSSize = 1 << 20
+
+// ErrUnknownKey is returned for a key id the JWKS does not hold, even after reloading it
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// publicKey is a verification key of the JWKS; alg is empty when the JWK names none
+type publicKey struct {
+	key crypto.PublicKey
+	alg string
+}
+
+// KeySet holds the public keys of a JWKS read from a file or an http(s) URL.
+// The keys are reloaded every Refresh, and earlier when a token names a key id
+// the set does not hold, which picks up keys the issuer rotated in. A failed
+// reload keeps the keys loaded before
+type KeySet struct {
+	Source  string
+	Client  *http.Client
+	Refresh time.Duration
+	// MinReload is the least time between two loads, failed ones included
+	MinReload time.Duration
+	Logger    logrus.FieldLogger
+
+	// mu guards the fields below and is never held while the JWKS is read, so
+	// a slow endpoint does not hold up tokens signed with keys already loaded
+	mu       sync.Mutex
+	keys     map[string]publicKey
+	loadedAt time.Time
+	tried    time.Time
+	// loads lets concurrent reloads share one read of the JWKS
+	loads singleflight.Group
+}
+
+// Load reads the JWKS, failing when it cannot be read or holds no usable key
+func (s *KeySet) Load(ctx context.Context) error {
+	return s.load(ctx)
+}
+
+// Key returns the key named kid; an empty kid names the only key of a set holding one
+func (s *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, string, error) {
+	if key, ok := s.find(kid); ok {
+		// an expired set keeps serving its keys while it is refreshed
+		if s.expired(time.Now()) {
+			go s.reload(context.WithoutCancel(ctx))
+		}
+		return key.key, key.alg, nil
+	}
+
+	s.reload(ctx)
+	if key, ok := s.find(kid); ok {
+		return key.key, key.alg, nil
+	}
+	return nil, "", fmt.Errorf("%w %q", ErrUnknownKey, kid)
+}
+
+func (s *KeySet) find(kid string) (publicKey, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+func (s *KeySet) expired(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return now.Sub(s.loadedAt) >= s.Refresh && now.Sub(s.tried) >= s.MinReload
+}
+
+// reload loads the JWKS unless a load was tried within MinReload. A caller
+// arriving while a load runs waits for that load instead of starting another
+func (s *KeySet) reload(ctx context.Context) {
+	s.loads.Do("jwks", func() (interface{}, error) {
+		s.mu.Lock()
+		due := time.Since(s.tried) >= s.MinReload
+		s.mu.Unlock()
+		if !due {
+			return nil, nil
+		}
+		// shared by the callers waiting on this load, so it must outlive the first one's request
+		if err := s.load(context.WithoutCancel(ctx)); err != nil {
+			s.Logger.WithError(err).WithField("jwks", s.Source).Warn("Failed to reload JWKS, keeping the keys loaded before")
+		}
+		return nil, nil
+	})
+}
+
+// load reads and parses the JWKS, then swaps the keys in
+func (s *KeySet) load(ctx context.Context) error {
+	tried := time.Now()
+	s.mu.Lock()
+	s.tried = tried
+	s.mu.Unlock()
+
+	document, err := s.read(ctx)
+	if err != nil {
+		return err
+	}
+	keys, err := parseJWKS(document)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys, s.loadedAt = keys, tried
+	return nil
+}
+
+func (s *KeySet) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(s.Source, "https://") && !strings.HasPrefix(s.Source, "http://") {
+		file, err := os.Open(strings.TrimPrefix(s.Source, "file://"))
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return io.ReadAll(io.LimitReader(file, maxJWKSSize))
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, s.Source, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", "application/json")
+	response, err := s.Client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS endpoint answered %s", response.Status)
+	}
+	return io.ReadAll(io.LimitReader(response.Body, maxJWKSSize))
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJWKS keeps the RSA and P-256 signing keys of a JWKS, skipping the rest
+func parseJWKS(document []byte) (map[string]publicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(document, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := map[string]publicKey{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		var key crypto.PublicKey
+		var err error
+		switch jwk.Kty {
+		case "RSA":
+			key, err = rsaKey(jwk)
+		case "EC":
+			if jwk.Crv != "P-256" {
+				continue
+			}
+			key, err = ecKey(jwk)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWK %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = publicKey{key: key, alg: jwk.Alg}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS holds no RSA or P-256 signing key")
+	}
+	return keys, nil
+}
+
+func rsaKey(jwk jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, errors.New("invalid exponent")
+	}
+	key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	if key.N.BitLen() < 2048 {
+		return nil, errors.New("RSA keys must have at least 2048 bits")
+	}
+	return key, nil
+}
+
+func ecKey(jwk jwk) (*ecdsa.PublicKey, error) {
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil || len(x) != 32 {
+		return nil, errors.New("invalid x coordinate")
+	}
+	y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+	if err != nil || len(y) != 32 {
+		return nil, errors.New("invalid y coordinate")
+	}
+	// rejects points that are not on the curve
+	if _, err := ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...)); err != nil {
+		return nil, err
+	}
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+}
@@ -0,0 +1,112 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	appErrors "transfer-system/pkg/errors"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// DefaultTenantClaim is the claim read as the principal's tenant
+const DefaultTenantClaim = "tenant"
+
+// compile-time interface check
+var _ ports.TokenVerifier = (*Verifier)(nil)
+
+// Verifier accepts RS256 and ES256 tokens signed with a key of Keys. Tokens
+// must be unexpired and, when Issuer and Audience are set, carry them. Scopes
+// come from the space separated scope claim or the scp list, the tenant from
+// TenantClaim
+type Verifier struct {
+	Keys     *KeySet
+	Issuer   string
+	Audience string
+	// TenantClaim names the claim holding the tenant, DefaultTenantClaim when empty
+	TenantClaim string
+	// Leeway absorbs clock differences with the issuer in exp, nbf and iat
+	Leeway time.Duration
+}
+
+func (v *Verifier) Verify(ctx context.Context, token string) (*entities.Principal, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(v.Leeway),
+	}
+	if v.Issuer != "" {
+		options = append(options, jwt.WithIssuer(v.Issuer))
+	}
+	if v.Audience != "" {
+		options = append(options, jwt.WithAudience(v.Audience))
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, alg, err := v.Keys.Key(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		if alg != "" && alg != token.Method.Alg() {
+			return nil, fmt.Errorf("key %q is for %s, not %s", kid, alg, token.Method.Alg())
+		}
+		// an RSA key must not verify an ES256 token and the other way round
+		switch key.(type) {
+		case *rsa.PublicKey:
+			if token.Method != jwt.SigningMethodRS256 {
+				return nil, fmt.Errorf("key %q is an RSA key", kid)
+			}
+		case *ecdsa.PublicKey:
+			if token.Method != jwt.SigningMethodES256 {
+				return nil, fmt.Errorf("key %q is an EC key", kid)
+			}
+		}
+		return key, nil
+	}, options...)
+	if err != nil {
+		return nil, appErrors.NewUnauthorizedError("Invalid bearer token", err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, appErrors.NewUnauthorizedError("Invalid bearer token", errors.New("token has no subject"))
+	}
+
+	tenantClaim := v.TenantClaim
+	if tenantClaim == "" {
+		tenantClaim = DefaultTenantClaim
+	}
+	tenant, _ := claims[tenantClaim].(string)
+	return &entities.Principal{Subject: subject, Tenant: tenant, Scopes: scopes(claims)}, nil
+}
+
+// scopes reads the OAuth 2.0 scope claim, a space separated string, or the
+// scp claim some issuers send instead, a list or a string
+func scopes(claims jwt.MapClaims) []string {
+	if scope, ok := claims["scope"].(string); ok {
+		return strings.Fields(scope)
+	}
+	switch scp := claims["scp"].(type) {
+	case string:
+		return strings.Fields(scp)
+	case []interface{}:
+		var scopes []string
+		for _, scope := range scp {
+			if scope, ok := scope.(string); ok {
+				scopes = append(scopes, scope)
+			}
+		}
+		return scopes
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package jwtauth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"transfer-system/adapters/jwtauth"
+	appErrors "transfer-system/pkg/errors"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	rsaKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _  = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+)
+
+func encode(value *big.Int, size int) string {
+	return base64.RawURLEncoding.EncodeToString(value.FillBytes(make([]byte, size)))
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA", "kid": kid, "use": "sig", "alg": "RS256",
+		"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func ecJWK(kid string, key *ecdsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "EC", "kid": kid, "crv": "P-256",
+		"x": encode(key.X, 32), "y": encode(key.Y, 32),
+	}
+}
+
+func jwks(t *testing.T, keys ...map[string]string) []byte {
+	document, err := json.Marshal(map[string]interface{}{"keys": keys})
+	require.NoError(t, err)
+	return document
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func claims(overrides jwt.MapClaims) jwt.MapClaims {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":    "https://issuer.example",
+		"aud":    "transfer-system",
+		"sub":    "payroll-service",
+		"tenant": "acme",
+		"scope":  "accounts:read transactions:write",
+		"iat":    now.Unix(),
+		"exp":    now.Add(time.Minute).Unix(),
+	}
+	for name, value := range overrides {
+		if value == nil {
+			delete(claims, name)
+			continue
+		}
+		claims[name] = value
+	}
+	return claims
+}
+
+func fileVerifier(t *testing.T) *jwtauth.Verifier {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwks(t, rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-1", &ecKey.PublicKey)), 0o600))
+	keys := &jwtauth.KeySet{Source: path, Refresh: time.Hour, MinReload: time.Hour, Logger: logrus.New()}
+	require.NoError(t, keys.Load(context.Background()))
+	return &jwtauth.Verifier{Keys: keys, Issuer: "https://issuer.example", Audience: "transfer-system"}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	verifier := fileVerifier(t)
+	ctx := context.Background()
+
+	principal, err := verifier.Verify(ctx, sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(nil)))
+	require.NoError(t, err)
+	assert.Equal(t, "payroll-service", principal.Subject)
+	assert.Equal(t, "acme", principal.Tenant)
+	assert.Equal(t, []string{"accounts:read", "transactions:write"}, principal.Scopes)
+	assert.True(t, principal.HasScope("transactions:write"))
+	assert.False(t, principal.HasScope("accounts:write"))
+
+	principal, err = verifier.Verify(ctx, sign(t, jwt.SigningMethodES256, "ec-1", ecKey, claims(jwt.MapClaims{
+		"scope": nil,
+		"scp":   []string{"transactions:read"},
+	})))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"transactions:read"}, principal.Scopes)
+}
+
+func TestVerifier_Verify_TenantClaim(t *testing.T) {
+	verifier := fileVerifier(t)
+	verifier.TenantClaim = "https://issuer.example/org"
+	ctx := context.Background()
+
+	principal, err := verifier.Verify(ctx, sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{
+		"https://issuer.example/org": "globex",
+	})))
+	require.NoError(t, err)
+	assert.Equal(t, "globex", principal.Tenant)
+
+	// a token without the configured claim has no tenant, the default claim is not read
+	principal, err = verifier.Verify(ctx, sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(nil)))
+	require.NoError(t, err)
+	assert.Empty(t, principal.Tenant)
+}
+
+func TestVerifier_Verify_Rejects(t *testing.T) {
+	verifier := fileVerifier(t)
+	otherRSA, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	hour := time.Now().Add(time.Hour).Unix()
+
+	tokens := map[string]string{
+		"expired":               sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()})),
+		"without exp":           sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"exp": nil})),
+		"not yet valid":         sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"nbf": hour})),
+		"other issuer":          sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"iss": "https://evil.example"})),
+		"other audience":        sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"aud": "billing"})),
+		"without subject":       sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"sub": nil})),
+		"unknown key":           sign(t, jwt.SigningMethodRS256, "rsa-2", otherRSA, claims(nil)),
+		"wrong key":             sign(t, jwt.SigningMethodRS256, "rsa-1", otherRSA, claims(nil)),
+		"key of the other type": sign(t, jwt.SigningMethodES256, "rsa-1", ecKey, claims(nil)),
+		// the public key must not be usable as an HMAC secret
+		"HS256":   sign(t, jwt.SigningMethodHS256, "rsa-1", []byte(encode(rsaKey.N, 256)), claims(nil)),
+		"none":    sign(t, jwt.SigningMethodNone, "rsa-1", jwt.UnsafeAllowNoneSignatureType, claims(nil)),
+		"garbage": "not.a.token",
+	}
+	for name, token := range tokens {
+		t.Run(name, func(t *testing.T) {
+			_, err := verifier.Verify(context.Background(), token)
+			var appErr *appErrors.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, http.StatusUnauthorized, appErr.StatusCode)
+		})
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	var mu sync.Mutex
+	current := jwks(t, rsaJWK("rsa-1", &rsaKey.PublicKey))
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests.Add(1)
+		w.Write(current)
+	}))
+	defer server.Close()
+
+	keys := &jwtauth.KeySet{Source: server.URL, Client: server.Client(), Refresh: time.Hour, Logger: logrus.New()}
+	require.NoError(t, keys.Load(context.Background()))
+	verifier := &jwtauth.Verifier{Keys: keys}
+	ctx := context.Background()
+
+	_, err := verifier.Verify(ctx, sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(nil)))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), requests.Load(), "known keys are served from the cache")
+
+	// the issuer rotates in an EC key; the first token using it reloads the set
+	mu.Lock()
+	current = jwks(t, rsaJWK("rsa-1", &rsaKey.PublicKey), ecJWK("ec-2", &ecKey.PublicKey))
+	mu.Unlock()
+	_, err = verifier.Verify(ctx, sign(t, jwt.SigningMethodES256, "ec-2", ecKey, claims(nil)))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+
+	// a failed reload keeps the keys loaded before
+	mu.Lock()
+	current = []byte("{")
+	mu.Unlock()
+	_, err = verifier.Verify(ctx, sign(t, jwt.SigningMethodRS256, "rsa-9", rsaKey, claims(nil)))
+	assert.Error(t, err)
+	_, err = verifier.Verify(ctx, sign(t, jwt.SigningMethodES256, "ec-2", ecKey, claims(nil)))
+	assert.NoError(t, err)
+}
+
+func TestKeySet_SlowReloadKeepsServingLoadedKeys(t *testing.T) {
+	document := jwks(t, rsaJWK("rsa-1", &rsaKey.PublicKey))
+	var requests atomic.Int32
+	reloading, release := make(chan struct{}), make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// every load after the first hangs until released
+		if requests.Add(1) > 1 {
+			close(reloading)
+			<-release
+		}
+		w.Write(document)
+	}))
+	defer server.Close()
+
+	keys := &jwtauth.KeySet{Source: server.URL, Client: server.Client(), Refresh: time.Hour, Logger: logrus.New()}
+	require.NoError(t, keys.Load(context.Background()))
+	verifier := &jwtauth.Verifier{Keys: keys}
+	ctx := context.Background()
+
+	unknown := make(chan error)
+	go func() {
+		_, err := verifier.Verify(ctx, sign(t, jwt.SigningMethodRS256, "rsa-9", rsaKey, claims(nil)))
+		unknown <- err
+	}()
+	<-reloading
+
+	// the reload for the unknown key id does not hold up known keys
+	verified := make(chan error)
+	go func() {
+		_, err := verifier.Verify(ctx, sign(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(nil)))
+		verified <- err
+	}()
+	select {
+	case err := <-verified:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("a known key waited for the JWKS reload")
+	}
+
+	close(release)
+	assert.Error(t, <-unknown)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestKeySet_Load_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	documents := map[string][]byte{
+		"no signing keys": jwks(t, map[string]string{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"}),
+		"short RSA key":   jwks(t, map[string]string{"kty": "RSA", "kid": "short", "n": "AQAB", "e": "AQAB"}),
+		"point off curve": jwks(t, map[string]string{"kty": "EC", "kid": "bad", "crv": "P-256", "x": encode(big.NewInt(1), 32), "y": encode(big.NewInt(1), 32)}),
+		"not JSON":        []byte("keys"),
+	}
+	for name, document := range documents {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name+".json")
+			require.NoError(t, os.WriteFile(path, document, 0o600))
+			keys := &jwtauth.KeySet{Source: path, Logger: logrus.New()}
+			assert.Error(t, keys.Load(context.Background()))
+		})
+	}
+
+	keys := &jwtauth.KeySet{Source: filepath.Join(dir, "missing.json"), Logger: logrus.New()}
+	assert.Error(t, keys.Load(context.Background()))
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	"transfer-system/domain/entities"
+)
+
+// Ledger is published as the "ledger" expvar, served with the others by GET /debug/vars
+var Ledger = NewLedgerMetrics()
+
+func init() {
+	expvar.Publish("ledger", Ledger.Vars)
+}
+
+// LedgerMetrics keeps the outcome of the latest ledger check as gauges, plus a
+// counter of checks that found the ledger inconsistent
+type LedgerMetrics struct {
+	Vars *expvar.Map
+}
+
+// NewLedgerMetrics returns unpublished metrics, for tests or a second registry
+func NewLedgerMetrics() *LedgerMetrics {
+	return &LedgerMetrics{Vars: new(expvar.Map).Init()}
+}
+
+func (m *LedgerMetrics) Report(ctx context.Context, report *entities.LedgerReport) error {
+	unconserved := new(expvar.Map).Init()
+	for _, total := range report.Totals {
+		if !total.Conserved() {
+			difference := new(expvar.String)
+			difference.Set(total.Difference().String())
+			unconserved.Set(total.Currency, difference)
+		}
+	}
+
+	consistent := new(expvar.Int)
+	if report.Consistent() {
+		consistent.Set(1)
+	} else {
+		m.Vars.Add("inconsistent_checks_total", 1)
+	}
+
+	m.Vars.Add("checks_total", 1)
+	m.Vars.Set("last_check_unix", intVar(time.Now().Unix()))
+	m.Vars.Set("checked_accounts", intVar(int64(report.CheckedAccounts)))
+	m.Vars.Set("drifted_accounts", intVar(int64(len(report.Drifts))))
+	m.Vars.Set("unconserved_currencies", unconserved)
+	m.Vars.Set("consistent", consistent)
+	return nil
+}
+
+func intVar(value int64) *expvar.Int {
+	v := new(expvar.Int)
+	v.Set(value)
+	return v
+}
@@ -0,0 +1,41 @@
+package metrics_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"transfer-system/adapters/metrics"
+	"transfer-system/domain/entities"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedgerMetrics_Report(t *testing.T) {
+	m := metrics.NewLedgerMetrics()
+
+	consistent := &entities.LedgerReport{CheckedAccounts: 2, Totals: []*entities.CurrencyTotal{
+		{Currency: "USD", InitialBalance: decimal.NewFromInt(10), Balance: decimal.NewFromInt(10)},
+	}}
+	require.NoError(t, m.Report(context.Background(), consistent))
+
+	inconsistent := &entities.LedgerReport{
+		CheckedAccounts: 3,
+		Drifts:          []*entities.BalanceDrift{{AccountID: 2, Currency: "USD"}},
+		Totals: []*entities.CurrencyTotal{
+			{Currency: "USD", InitialBalance: decimal.NewFromInt(10), Balance: decimal.RequireFromString("12.5")},
+		},
+	}
+	require.NoError(t, m.Report(context.Background(), inconsistent))
+
+	var vars map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(m.Vars.String()), &vars))
+	assert.Equal(t, float64(2), vars["checks_total"])
+	assert.Equal(t, float64(1), vars["inconsistent_checks_total"])
+	assert.Equal(t, float64(3), vars["checked_accounts"])
+	assert.Equal(t, float64(1), vars["drifted_accounts"])
+	assert.Equal(t, float64(0), vars["consistent"])
+	assert.Equal(t, map[string]interface{}{"USD": "2.5"}, vars["unconserved_currencies"])
+}
@@ -0,0 +1,46 @@
+package nonces
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"transfer-system/domain/ports"
+)
+
+// compile-time interface check
+var _ ports.NonceStore = (*MemoryStore)(nil)
+
+// MemoryStore keeps the nonces in the process, so a request replayed to
+// another instance is not recognised
+type MemoryStore struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{expires: map[string]time.Time{}}
+}
+
+func (s *MemoryStore) Remember(ctx context.Context, nonce string, expires time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if expiry, ok := s.expires[nonce]; ok && time.Now().Before(expiry) {
+		return false, nil
+	}
+	s.expires[nonce] = expires
+	return true, nil
+}
+
+func (s *MemoryStore) Sweep(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for nonce, expiry := range s.expires {
+		if expiry.Before(before) {
+			delete(s.expires, nonce)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package nonces_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"transfer-system/adapters/nonces"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_Remember(t *testing.T) {
+	store := nonces.NewMemoryStore()
+	ctx := context.Background()
+
+	fresh, err := store.Remember(ctx, "5:c29tZSBub25jZSB2YWx1ZQ", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, fresh)
+
+	fresh, err = store.Remember(ctx, "5:c29tZSBub25jZSB2YWx1ZQ", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, fresh, "a replay is rejected")
+
+	// nonces are per key
+	fresh, err = store.Remember(ctx, "6:c29tZSBub25jZSB2YWx1ZQ", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, fresh)
+}
+
+func TestMemoryStore_Expiry(t *testing.T) {
+	store := nonces.NewMemoryStore()
+	ctx := context.Background()
+
+	fresh, err := store.Remember(ctx, "5:ZXhwaXJlZCBub25jZQ", time.Now().Add(-time.Second))
+	require.NoError(t, err)
+	assert.True(t, fresh)
+
+	fresh, err = store.Remember(ctx, "5:ZXhwaXJlZCBub25jZQ", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, fresh, "an expired nonce is new again")
+
+	require.NoError(t, store.Sweep(ctx, time.Now()))
+	fresh, err = store.Remember(ctx, "5:ZXhwaXJlZCBub25jZQ", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, fresh, "an unexpired nonce survives a sweep")
+
+	require.NoError(t, store.Sweep(ctx, time.Now().Add(time.Hour)))
+	fresh, err = store.Remember(ctx, "5:ZXhwaXJlZCBub25jZQ", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, fresh)
+}
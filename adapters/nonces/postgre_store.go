@@ -0,0 +1,63 @@
+package nonces
+
+import (
+	"context"
+	"time"
+
+	"transfer-system/domain/ports"
+)
+
+// compile-time interface check
+var _ ports.NonceStore = (*PostgreStore)(nil)
+
+// PostgreStore keeps the nonces in the request_nonces table, shared by every
+// instance; the primary key makes concurrent uses of a nonce race to one winner
+type PostgreStore struct {
+	DB         ports.Database
+	CtxTimeout time.Duration
+}
+
+func (s *PostgreStore) Remember(ctx context.Context, nonce string, expires time.Time) (fresh bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// an expired row that was not swept yet is taken over
+	query := `
+			INSERT INTO request_nonces (nonce, expires_at)
+			VALUES ($1, $2)
+			ON CONFLICT (nonce) DO UPDATE SET expires_at = EXCLUDED.expires_at
+			WHERE request_nonces.expires_at <= $3`
+	result, err := tx.ExecContext(ctx, query, nonce, expires.UTC(), time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	err = tx.Commit()
+	return inserted == 1, err
+}
+
+func (s *PostgreStore) Sweep(ctx context.Context, before time.Time) error {
+	tx, err := s.DB.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM request_nonces WHERE expires_at < $1", before.UTC()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,37 @@
+package nonces_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"transfer-system/adapters/nonces"
+	"transfer-system/internal/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgreStore_Remember(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+
+	store := &nonces.PostgreStore{DB: db, CtxTimeout: 5 * time.Second}
+	ctx := context.Background()
+	nonce := fmt.Sprintf("5:test-%d", time.Now().UnixNano())
+	defer store.Sweep(ctx, time.Now().Add(time.Hour))
+
+	fresh, err := store.Remember(ctx, nonce, time.Now().Add(-time.Second))
+	require.NoError(t, err)
+	assert.True(t, fresh)
+
+	// the expired row is taken over
+	fresh, err = store.Remember(ctx, nonce, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, fresh)
+
+	fresh, err = store.Remember(ctx, nonce, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, fresh)
+}
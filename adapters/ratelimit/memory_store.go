@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+)
+
+// compile-time interface check
+var _ ports.RateLimitStore = (*MemoryStore)(nil)
+
+// MemoryStore keeps the buckets in the process, so each instance has its own budgets
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*entities.TokenBucket
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*entities.TokenBucket{}}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit entities.RateLimit) (entities.RateLimitDecision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &entities.TokenBucket{}
+		s.buckets[key] = bucket
+	}
+	return bucket.Take(limit, time.Now()), nil
+}
+
+func (s *MemoryStore) Sweep(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, bucket := range s.buckets {
+		if bucket.UpdatedAt.Before(before) {
+			delete(s.buckets, key)
+		}
+	}
+	return nil
+}
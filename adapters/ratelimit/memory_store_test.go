@@ -0,0 +1,87 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"transfer-system/adapters/ratelimit"
+	"transfer-system/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucket_Take(t *testing.T) {
+	limit := entities.RateLimit{Burst: 2, Period: 10 * time.Second}
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	bucket := &entities.TokenBucket{}
+
+	decision := bucket.Take(limit, start)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, 1, decision.Remaining)
+	assert.Equal(t, 5*time.Second, decision.Reset)
+
+	decision = bucket.Take(limit, start)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, 0, decision.Remaining)
+
+	decision = bucket.Take(limit, start.Add(time.Second))
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, 4*time.Second, decision.RetryAfter)
+	assert.Equal(t, 9*time.Second, decision.Reset)
+
+	// a token every 5 seconds
+	decision = bucket.Take(limit, start.Add(5*time.Second))
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, 0, decision.Remaining)
+
+	// refills stop at the burst
+	decision = bucket.Take(limit, start.Add(time.Hour))
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, 1, decision.Remaining)
+}
+
+func TestMemoryStore_Take(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	limit := entities.RateLimit{Burst: 3, Period: time.Hour}
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		decision, err := store.Take(ctx, "read:key:1", limit)
+		require.NoError(t, err)
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, 2-i, decision.Remaining)
+	}
+	decision, err := store.Take(ctx, "read:key:1", limit)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.InDelta(t, 20*time.Minute, decision.RetryAfter, float64(time.Second))
+
+	// budgets are per key
+	decision, err = store.Take(ctx, "read:key:2", limit)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+}
+
+func TestMemoryStore_Sweep(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+	limit := entities.RateLimit{Burst: 1, Period: time.Hour}
+	ctx := context.Background()
+
+	_, err := store.Take(ctx, "transfer:ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	decision, err := store.Take(ctx, "transfer:ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+
+	require.NoError(t, store.Sweep(ctx, time.Now().Add(-time.Minute)))
+	decision, err = store.Take(ctx, "transfer:ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed, "a recent bucket is kept")
+
+	require.NoError(t, store.Sweep(ctx, time.Now().Add(time.Minute)))
+	decision, err = store.Take(ctx, "transfer:ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed, "a swept bucket starts full")
+}
@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+)
+
+// compile-time interface check
+var _ ports.RateLimitStore = (*PostgreStore)(nil)
+
+// PostgreStore keeps the buckets in the rate_limit_buckets table, so instances
+// behind a load balancer share their clients' budgets. A Take is one short
+// transaction holding the bucket's row lock, at read committed so concurrent
+// takes on one bucket queue behind the lock and read the row it leaves
+type PostgreStore struct {
+	DB         ports.Database
+	CtxTimeout time.Duration
+}
+
+func (s *PostgreStore) Take(ctx context.Context, key string, limit entities.RateLimit) (decision entities.RateLimitDecision, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx, ports.TxOptions{Isolation: ports.IsolationReadCommitted})
+	if err != nil {
+		return decision, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	bucket, err := s.lockBucket(ctx, tx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		// a concurrent first request may insert the row first, then we wait for its lock
+		_, err = tx.ExecContext(ctx, "INSERT INTO rate_limit_buckets (key, tokens, updated_at) VALUES ($1, $2, $3) ON CONFLICT (key) DO NOTHING", key, limit.Burst, time.Now().UTC())
+		if err != nil {
+			return decision, err
+		}
+		bucket, err = s.lockBucket(ctx, tx, key)
+	}
+	if err != nil {
+		return decision, err
+	}
+
+	decision = bucket.Take(limit, time.Now().UTC())
+	_, err = tx.ExecContext(ctx, "UPDATE rate_limit_buckets SET tokens = $1, updated_at = $2 WHERE key = $3", bucket.Tokens, bucket.UpdatedAt, key)
+	if err != nil {
+		return decision, err
+	}
+	err = tx.Commit()
+	return decision, err
+}
+
+func (s *PostgreStore) lockBucket(ctx context.Context, tx ports.Transaction, key string) (*entities.TokenBucket, error) {
+	bucket := &entities.TokenBucket{}
+	err := tx.QueryRowContext(ctx, "SELECT tokens, updated_at FROM rate_limit_buckets WHERE key = $1 FOR UPDATE", key).Scan(&bucket.Tokens, &bucket.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return bucket, nil
+}
+
+func (s *PostgreStore) Sweep(ctx context.Context, before time.Time) error {
+	tx, err := s.DB.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM rate_limit_buckets WHERE updated_at < $1", before.UTC()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
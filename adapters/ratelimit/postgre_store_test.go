@@ -0,0 +1,77 @@
+package ratelimit_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"transfer-system/adapters/ratelimit"
+	"transfer-system/domain/entities"
+	"transfer-system/internal/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgreStore_Take(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+
+	store := &ratelimit.PostgreStore{DB: db, CtxTimeout: 5 * time.Second}
+	limit := entities.RateLimit{Burst: 2, Period: time.Hour}
+	ctx := context.Background()
+	key := fmt.Sprintf("read:key:test-%d", time.Now().UnixNano())
+	defer store.Sweep(ctx, time.Now().Add(time.Hour))
+
+	for i := 0; i < 2; i++ {
+		decision, err := store.Take(ctx, key, limit)
+		require.NoError(t, err)
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, 1-i, decision.Remaining)
+	}
+	decision, err := store.Take(ctx, key, limit)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.InDelta(t, 30*time.Minute, decision.RetryAfter, float64(time.Minute))
+
+	require.NoError(t, store.Sweep(ctx, time.Now().Add(time.Minute)))
+	decision, err = store.Take(ctx, key, limit)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+}
+
+func TestPostgreStore_Take_Concurrent(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	defer db.Close()
+
+	store := &ratelimit.PostgreStore{DB: db, CtxTimeout: 5 * time.Second}
+	limit := entities.RateLimit{Burst: 5, Period: time.Hour}
+	ctx := context.Background()
+	key := fmt.Sprintf("transfer:key:test-%d", time.Now().UnixNano())
+	defer store.Sweep(ctx, time.Now().Add(time.Hour))
+
+	// 20 first requests race for a new bucket of 5; none may fail or slip through
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			decision, err := store.Take(ctx, key, limit)
+			if !assert.NoError(t, err) {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if decision.Allowed {
+				allowed++
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 5, allowed)
+}
@@ -0,0 +1,385 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/infrastructure/memstore"
+	"transfer-system/pkg/logger"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// AccountRepositoryMemory keeps accounts in a memstore.Store, enforcing the
+// constraints of the accounts table
+type AccountRepositoryMemory struct {
+	DB ports.Database
+}
+
+func (repository *AccountRepositoryMemory) Save(ctx context.Context, tx ports.Transaction, account *entities.Account) (*entities.Account, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.Currency == "" {
+		account.Currency = entities.DefaultCurrency
+	}
+	if account.Status == "" {
+		account.Status = entities.AccountStatusActive
+	}
+	if account.Kind == "" {
+		account.Kind = entities.AccountKindCustomer
+	}
+
+	if err := repository.checkInsert(ctx, memoryTx, account); err != nil {
+		logger.WithError(err).Error("Failed to insert account")
+		return nil, err
+	}
+
+	id := account.AccountID
+	if id == 0 {
+		// explicit ids share the key space with the sequence, so skip any value already taken
+		for attempt := 0; attempt < maxGeneratedIdAttempts && id == 0; attempt++ {
+			candidate := memoryTx.NextVal(accountsSequence)
+			if err := memoryTx.Lock(ctx, accountsTable, candidate); err != nil {
+				logger.WithError(err).Error("Failed to insert account")
+				return nil, err
+			}
+			if _, taken := memoryTx.Get(accountsTable, candidate); !taken {
+				id = candidate
+			}
+		}
+		if id == 0 {
+			err := fmt.Errorf("no free account id after %d attempts", maxGeneratedIdAttempts)
+			logger.WithError(err).Error("Failed to generate account id")
+			return nil, err
+		}
+	} else {
+		err := lockUnique(ctx, memoryTx, accountsTable, id, func() bool {
+			_, taken := memoryTx.Get(accountsTable, id)
+			return taken
+		})
+		if err != nil {
+			logger.WithError(err).Error("Failed to insert account")
+			return nil, err
+		}
+	}
+
+	account.AccountID = id
+	account.InitialBalance = account.Balance
+	account.Version = 1
+	account.CreatedAt = memoryTx.Now()
+	if err := memoryTx.Put(accountsTable, id, cloneAccount(account)); err != nil {
+		logger.WithError(err).Error("Failed to insert account")
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// checkInsert enforces the foreign key, check and unique constraints a new account is subject to
+func (repository *AccountRepositoryMemory) checkInsert(ctx context.Context, tx *memstore.Tx, account *entities.Account) error {
+	if account.Kind == entities.AccountKindCustomer && account.Balance.IsNegative() {
+		return positiveBalanceViolation()
+	}
+	if account.OwnerID != nil {
+		// inserting a reference keeps the customer from being deleted meanwhile
+		if err := tx.Lock(ctx, customersTable, *account.OwnerID); err != nil {
+			return err
+		}
+		if _, ok := tx.Get(customersTable, *account.OwnerID); !ok {
+			return &memstore.Error{
+				State:   memstore.StateForeignKeyViolation,
+				Message: fmt.Sprintf("owner %d is not present in table %q", *account.OwnerID, customersTable),
+			}
+		}
+	}
+	if account.ExternalReference != "" {
+		err := lockUnique(ctx, tx, "unique_external_reference", account.ExternalReference, func() bool {
+			return findAccount(tx, func(stored *entities.Account) bool {
+				return stored.ExternalReference == account.ExternalReference
+			}) != nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repository *AccountRepositoryMemory) FindById(ctx context.Context, tx ports.Transaction, id int64) (*entities.Account, error) {
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	row, ok := memoryTx.Get(accountsTable, id)
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return cloneAccount(row.(*entities.Account)), nil
+}
+
+func (repository *AccountRepositoryMemory) FindByIdForUpdate(ctx context.Context, tx ports.Transaction, id int64) (*entities.Account, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the row stays locked until tx ends, as with SELECT ... FOR UPDATE
+	if err := memoryTx.Lock(ctx, accountsTable, id); err != nil {
+		logger.WithError(err).Error("Failed to query account by ID")
+		return nil, err
+	}
+	row, ok := memoryTx.Get(accountsTable, id)
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return cloneAccount(row.(*entities.Account)), nil
+}
+
+func (repository *AccountRepositoryMemory) FindByExternalReference(ctx context.Context, tx ports.Transaction, reference string) (*entities.Account, error) {
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	account := findAccount(memoryTx, func(stored *entities.Account) bool {
+		return reference != "" && stored.ExternalReference == reference
+	})
+	if account == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	return cloneAccount(account), nil
+}
+
+// SystemAccount returns the system account of the kind and currency, creating it on first use
+func (repository *AccountRepositoryMemory) SystemAccount(ctx context.Context, tx ports.Transaction, kind entities.AccountKind, currency string) (*entities.Account, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// concurrent first uses wait here for the winner, then find its account
+	if err := memoryTx.Lock(ctx, "accounts_system_kind_currency_idx", string(kind)+"/"+currency); err != nil {
+		logger.WithError(err).Errorf("Failed to query %s account for %s", kind, currency)
+		return nil, err
+	}
+	account := findAccount(memoryTx, func(stored *entities.Account) bool {
+		return stored.Kind == kind && stored.Currency == currency
+	})
+	if account != nil {
+		return cloneAccount(account), nil
+	}
+
+	created, err := repository.Save(ctx, tx, &entities.Account{Currency: currency, Kind: kind})
+	if err != nil {
+		logger.WithError(err).Errorf("Failed to create %s account for %s", kind, currency)
+		return nil, err
+	}
+	return cloneAccount(created), nil
+}
+
+// Update writes the mutable attributes of an account still at account.Version;
+// balance only changes through UpdateBalance
+func (repository *AccountRepositoryMemory) Update(ctx context.Context, tx ports.Transaction, account *entities.Account) (*entities.Account, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := memoryTx.Lock(ctx, accountsTable, account.AccountID); err != nil {
+		logger.WithError(err).Error("Failed to update account")
+		return nil, err
+	}
+	row, ok := memoryTx.Get(accountsTable, account.AccountID)
+	if !ok || row.(*entities.Account).Version != account.Version {
+		return nil, entities.ErrVersionMismatch
+	}
+
+	updated := cloneAccount(row.(*entities.Account))
+	updated.Metadata = cloneMetadata(account.Metadata)
+	updated.Status = account.Status
+	updated.Version++
+	if err := memoryTx.Put(accountsTable, updated.AccountID, updated); err != nil {
+		logger.WithError(err).Error("Failed to update account")
+		return nil, err
+	}
+
+	return cloneAccount(updated), nil
+}
+
+func (repository *AccountRepositoryMemory) List(ctx context.Context, tx ports.Transaction, filter entities.AccountFilter) (*entities.AccountPage, error) {
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	sortBy := filter.SortBy
+	if sortBy == "" {
+		sortBy = entities.AccountSortById
+	}
+	if _, ok := accountSortColumns[sortBy]; !ok {
+		return nil, fmt.Errorf("unsupported sort field %q", sortBy)
+	}
+
+	matching := []*entities.Account{}
+	for _, row := range memoryTx.Rows(accountsTable) {
+		if account := row.(*entities.Account); matchesAccountFilter(account, filter) {
+			matching = append(matching, account)
+		}
+	}
+
+	page := &entities.AccountPage{}
+	if filter.IncludeTotal {
+		total := int64(len(matching))
+		page.Total = &total
+	}
+
+	// before reports whether a sorts ahead of b in ascending order
+	before := func(a, b *entities.Account) bool {
+		switch sortBy {
+		case entities.AccountSortByBalance:
+			if !a.Balance.Equal(b.Balance) {
+				return a.Balance.LessThan(b.Balance)
+			}
+		case entities.AccountSortByCreatedAt:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+		}
+		return a.AccountID < b.AccountID
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		if filter.SortDesc {
+			return before(matching[j], matching[i])
+		}
+		return before(matching[i], matching[j])
+	})
+
+	if filter.Cursor != "" {
+		cursor, err := decodeCursor(filter.Cursor)
+		if err != nil || cursor.Sort != string(sortBy) {
+			return nil, entities.ErrInvalidCursor
+		}
+		last := &entities.Account{AccountID: cursor.Id}
+		switch sortBy {
+		case entities.AccountSortByBalance:
+			if last.Balance, err = decimal.NewFromString(cursor.Value); err != nil {
+				return nil, entities.ErrInvalidCursor
+			}
+		case entities.AccountSortByCreatedAt:
+			if last.CreatedAt, err = cursorTime(cursor.Value); err != nil {
+				return nil, err
+			}
+		}
+
+		remaining := []*entities.Account{}
+		for _, account := range matching {
+			if (!filter.SortDesc && before(last, account)) || (filter.SortDesc && before(account, last)) {
+				remaining = append(remaining, account)
+			}
+		}
+		matching = remaining
+	}
+
+	page.Accounts = []*entities.Account{}
+	for _, account := range matching {
+		if len(page.Accounts) == filter.Limit {
+			last := page.Accounts[len(page.Accounts)-1]
+			page.NextCursor = encodeCursor(pageCursor{
+				Sort:  string(sortBy),
+				Value: accountSortValue(last, sortBy),
+				Id:    last.AccountID,
+			})
+			break
+		}
+		page.Accounts = append(page.Accounts, cloneAccount(account))
+	}
+
+	return page, nil
+}
+
+func (repository *AccountRepositoryMemory) SumByCurrency(ctx context.Context, tx ports.Transaction) ([]*entities.CurrencyTotal, error) {
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	byCurrency := map[string]*entities.CurrencyTotal{}
+	totals := []*entities.CurrencyTotal{}
+	for _, row := range memoryTx.Rows(accountsTable) {
+		account := row.(*entities.Account)
+		total, ok := byCurrency[account.Currency]
+		if !ok {
+			total = &entities.CurrencyTotal{Currency: account.Currency}
+			byCurrency[account.Currency] = total
+			totals = append(totals, total)
+		}
+		total.Accounts++
+		total.InitialBalance = total.InitialBalance.Add(account.InitialBalance)
+		total.Balance = total.Balance.Add(account.Balance)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		return totals[i].Currency < totals[j].Currency
+	})
+
+	return totals, nil
+}
+
+func matchesAccountFilter(account *entities.Account, filter entities.AccountFilter) bool {
+	switch {
+	case filter.Status != "" && account.Status != filter.Status:
+		return false
+	case filter.Currency != "" && account.Currency != filter.Currency:
+		return false
+	case filter.MinBalance != nil && account.Balance.LessThan(*filter.MinBalance):
+		return false
+	case filter.MaxBalance != nil && account.Balance.GreaterThan(*filter.MaxBalance):
+		return false
+	case filter.CreatedFrom != nil && account.CreatedAt.Before(storedTime(*filter.CreatedFrom)):
+		return false
+	case filter.CreatedTo != nil && !account.CreatedAt.Before(storedTime(*filter.CreatedTo)):
+		return false
+	case filter.OwnerID != nil && (account.OwnerID == nil || *account.OwnerID != *filter.OwnerID):
+		return false
+	}
+	return containsMetadata(account.Metadata, filter.Metadata)
+}
+
+// findAccount returns the stored account matching match, nil when there is none
+func findAccount(tx *memstore.Tx, match func(*entities.Account) bool) *entities.Account {
+	for _, row := range tx.Rows(accountsTable) {
+		if account := row.(*entities.Account); match(account) {
+			return account
+		}
+	}
+	return nil
+}
+
+func positiveBalanceViolation() error {
+	return &memstore.Error{
+		State:   memstore.StateCheckViolation,
+		Message: `new row for relation "accounts" violates check constraint "positive_balance"`,
+	}
+}
+
+func cloneAccount(account *entities.Account) *entities.Account {
+	clone := *account
+	clone.OwnerID = cloneInt64(account.OwnerID)
+	clone.Metadata = cloneMetadata(account.Metadata)
+	return &clone
+}
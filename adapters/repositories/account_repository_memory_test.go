@@ -0,0 +1,232 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"transfer-system/adapters/repositories"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/infrastructure/memstore"
+	"transfer-system/pkg/logger"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func memoryContext() context.Context {
+	return context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+}
+
+func beginMemory(t *testing.T, db ports.Database) ports.Transaction {
+	tx, err := db.BeginTx(context.Background())
+	require.NoError(t, err)
+	return tx
+}
+
+func sqlStateOf(err error) string {
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		return stateErr.SQLState()
+	}
+	return ""
+}
+
+func TestAccountRepositoryMemory_Save_GeneratesIdsAroundExplicitOnes(t *testing.T) {
+	db := memstore.New()
+	repo := &repositories.AccountRepositoryMemory{DB: db}
+	ctx := memoryContext()
+	tx := beginMemory(t, db)
+	defer tx.Rollback()
+
+	_, err := repo.Save(ctx, tx, &entities.Account{AccountID: 1, Balance: decimal.NewFromInt(10)})
+	require.NoError(t, err)
+
+	generated, err := repo.Save(ctx, tx, &entities.Account{Metadata: entities.Metadata{"team": "ops"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), generated.AccountID)
+	assert.Equal(t, entities.DefaultCurrency, generated.Currency)
+	assert.Equal(t, entities.AccountKindCustomer, generated.Kind)
+	assert.Equal(t, entities.AccountStatusActive, generated.Status)
+
+	_, err = repo.Save(ctx, tx, &entities.Account{AccountID: 1})
+	assert.Equal(t, memstore.StateUniqueViolation, sqlStateOf(err))
+
+	// the stored row is a copy, changing the saved entity does not change it
+	generated.Metadata["team"] = "sales"
+	found, err := repo.FindById(ctx, tx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "ops", found.Metadata["team"])
+}
+
+func TestAccountRepositoryMemory_Save_Constraints(t *testing.T) {
+	db := memstore.New()
+	repo := &repositories.AccountRepositoryMemory{DB: db}
+	ctx := memoryContext()
+	tx := beginMemory(t, db)
+	defer tx.Rollback()
+
+	_, err := repo.Save(ctx, tx, &entities.Account{ExternalReference: "ERP-1"})
+	require.NoError(t, err)
+	_, err = repo.Save(ctx, tx, &entities.Account{ExternalReference: "ERP-1"})
+	assert.Equal(t, memstore.StateUniqueViolation, sqlStateOf(err))
+
+	ownerID := int64(99)
+	_, err = repo.Save(ctx, tx, &entities.Account{OwnerID: &ownerID})
+	assert.Equal(t, memstore.StateForeignKeyViolation, sqlStateOf(err))
+
+	_, err = repo.Save(ctx, tx, &entities.Account{Balance: decimal.NewFromInt(-1)})
+	assert.Equal(t, memstore.StateCheckViolation, sqlStateOf(err))
+}
+
+func TestAccountRepositoryMemory_Rollback_DiscardsAccount(t *testing.T) {
+	db := memstore.New()
+	repo := &repositories.AccountRepositoryMemory{DB: db}
+	ctx := memoryContext()
+
+	tx := beginMemory(t, db)
+	_, err := repo.Save(ctx, tx, &entities.Account{AccountID: 7, ExternalReference: "ERP-7"})
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback())
+
+	tx = beginMemory(t, db)
+	defer tx.Rollback()
+	_, err = repo.FindById(ctx, tx, 7)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = repo.FindByExternalReference(ctx, tx, "ERP-7")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestAccountRepositoryMemory_SystemAccount_CreatedOnce(t *testing.T) {
+	db := memstore.New()
+	repo := &repositories.AccountRepositoryMemory{DB: db}
+	ctx := memoryContext()
+	tx := beginMemory(t, db)
+	defer tx.Rollback()
+
+	first, err := repo.SystemAccount(ctx, tx, entities.AccountKindEquity, "EUR")
+	require.NoError(t, err)
+	second, err := repo.SystemAccount(ctx, tx, entities.AccountKindEquity, "EUR")
+	require.NoError(t, err)
+	other, err := repo.SystemAccount(ctx, tx, entities.AccountKindEquity, "USD")
+	require.NoError(t, err)
+
+	assert.Equal(t, first.AccountID, second.AccountID)
+	assert.NotEqual(t, first.AccountID, other.AccountID)
+	assert.Equal(t, entities.AccountKindEquity, first.Kind)
+}
+
+func TestAccountRepositoryMemory_List_FiltersSortsAndPages(t *testing.T) {
+	db := memstore.New()
+	repo := &repositories.AccountRepositoryMemory{DB: db}
+	ctx := memoryContext()
+	tx := beginMemory(t, db)
+	defer tx.Rollback()
+
+	for id, balance := range map[int64]int64{1: 30, 2: 10, 3: 20, 4: 10} {
+		_, err := repo.Save(ctx, tx, &entities.Account{
+			AccountID: id,
+			Balance:   decimal.NewFromInt(balance),
+			Metadata:  entities.Metadata{"team": "ops"},
+		})
+		require.NoError(t, err)
+	}
+	_, err := repo.Save(ctx, tx, &entities.Account{AccountID: 5, Balance: decimal.NewFromInt(50), Currency: "EUR"})
+	require.NoError(t, err)
+
+	filter := entities.AccountFilter{
+		Currency:     "USD",
+		Metadata:     entities.Metadata{"team": "ops"},
+		SortBy:       entities.AccountSortByBalance,
+		Limit:        2,
+		IncludeTotal: true,
+	}
+	page, err := repo.List(ctx, tx, filter)
+	require.NoError(t, err)
+	require.NotNil(t, page.Total)
+	assert.Equal(t, int64(4), *page.Total)
+	assert.Equal(t, []int64{2, 4}, accountIds(page.Accounts))
+	require.NotEmpty(t, page.NextCursor)
+
+	filter.Cursor = page.NextCursor
+	page, err = repo.List(ctx, tx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 1}, accountIds(page.Accounts))
+	assert.Empty(t, page.NextCursor)
+
+	filter.SortBy = entities.AccountSortById
+	_, err = repo.List(ctx, tx, filter)
+	assert.ErrorIs(t, err, entities.ErrInvalidCursor)
+
+	page, err = repo.List(ctx, tx, entities.AccountFilter{SortDesc: true, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{5, 4, 3, 2, 1}, accountIds(page.Accounts))
+}
+
+func TestAccountRepositoryMemory_SumByCurrency(t *testing.T) {
+	db := memstore.New()
+	repo := &repositories.AccountRepositoryMemory{DB: db}
+	ctx := memoryContext()
+	tx := beginMemory(t, db)
+	defer tx.Rollback()
+
+	for _, account := range []*entities.Account{
+		{Balance: decimal.NewFromInt(10), Currency: "USD"},
+		{Balance: decimal.NewFromInt(5), Currency: "EUR"},
+		{Balance: decimal.NewFromInt(15), Currency: "USD"},
+	} {
+		_, err := repo.Save(ctx, tx, account)
+		require.NoError(t, err)
+	}
+
+	totals, err := repo.SumByCurrency(ctx, tx)
+	require.NoError(t, err)
+	require.Len(t, totals, 2)
+	assert.Equal(t, "EUR", totals[0].Currency)
+	assert.Equal(t, 1, totals[0].Accounts)
+	assert.Equal(t, "USD", totals[1].Currency)
+	assert.Equal(t, 2, totals[1].Accounts)
+	assert.True(t, totals[1].Balance.Equal(decimal.NewFromInt(25)))
+}
+
+func accountIds(accounts []*entities.Account) []int64 {
+	ids := make([]int64, len(accounts))
+	for i, account := range accounts {
+		ids[i] = account.AccountID
+	}
+	return ids
+}
+
+func TestAccountRepositoryMemory_Update_Version(t *testing.T) {
+	db := memstore.New()
+	repo := &repositories.AccountRepositoryMemory{DB: db}
+	transactions := &repositories.TransactionRepositoryMemory{DB: db}
+	ctx := memoryContext()
+	tx := beginMemory(t, db)
+	defer tx.Rollback()
+
+	account, err := repo.Save(ctx, tx, &entities.Account{AccountID: 1, Balance: decimal.NewFromInt(10)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), account.Version)
+
+	stale := *account
+	account.Metadata = entities.Metadata{"team": "ops"}
+	account, err = repo.Update(ctx, tx, account)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), account.Version)
+
+	stale.Metadata = entities.Metadata{"team": "sales"}
+	_, err = repo.Update(ctx, tx, &stale)
+	assert.ErrorIs(t, err, entities.ErrVersionMismatch)
+
+	// balance changes count as changes to the account
+	require.NoError(t, transactions.UpdateBalance(ctx, tx, 1, decimal.NewFromInt(-1)))
+	found, err := repo.FindById(ctx, tx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), found.Version)
+	assert.Equal(t, "ops", found.Metadata["team"])
+}
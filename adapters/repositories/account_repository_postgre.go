@@ -3,6 +3,8 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 
 	"transfer-system/domain/entities"
 	"transfer-system/domain/ports"
@@ -11,6 +13,10 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// maxGeneratedIdAttempts bounds how many sequence values are tried when they
+// collide with ids that clients picked explicitly
+const maxGeneratedIdAttempts = 5
+
 type AccountRepositoryPostgre struct {
 	DB ports.Database
 }
@@ -18,28 +24,169 @@ type AccountRepositoryPostgre struct {
 func (repository *AccountRepositoryPostgre) Save(ctx context.Context, tx ports.Transaction, account *entities.Account) (*entities.Account, error) {
 	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
 
+	if account.Currency == "" {
+		account.Currency = entities.DefaultCurrency
+	}
+	if account.Status == "" {
+		account.Status = entities.AccountStatusActive
+	}
+	if account.Kind == "" {
+		account.Kind = entities.AccountKindCustomer
+	}
+
+	if account.AccountID == 0 {
+		return repository.saveWithGeneratedId(ctx, tx, account)
+	}
+
 	var id int64
 	query := `
-            INSERT INTO accounts (id, balance)
-            VALUES ($1, $2)
-            RETURNING id`
-	err := tx.QueryRowContext(ctx, query, account.AccountID, account.Balance).Scan(&id)
+            INSERT INTO accounts (id, balance, initial_balance, external_reference, currency, kind, status, owner_id, metadata)
+            VALUES ($1, $2, $2, $3, $4, $5, $6, $7, $8)
+            RETURNING id, version, created_at`
+	err := tx.QueryRowContext(ctx, query, account.AccountID, account.Balance, nullString(account.ExternalReference),
+		account.Currency, account.Kind, account.Status, account.OwnerID, account.Metadata).Scan(&id, &account.Version, &account.CreatedAt)
 	if err != nil {
 		logger.WithError(err).Error("Failed to insert account")
 		return nil, err
 	}
 
 	account.AccountID = id
-	account.Balance = account.Balance
+	account.InitialBalance = account.Balance
 
 	return account, nil
 }
 
+func (repository *AccountRepositoryPostgre) saveWithGeneratedId(ctx context.Context, tx ports.Transaction, account *entities.Account) (*entities.Account, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	// explicit ids share the key space with the sequence, so skip any value already taken
+	query := `
+            INSERT INTO accounts (id, balance, initial_balance, external_reference, currency, kind, status, owner_id, metadata)
+            VALUES (nextval('accounts_id_seq'), $1, $1, $2, $3, $4, $5, $6, $7)
+            ON CONFLICT (id) DO NOTHING
+            RETURNING id, version, created_at`
+	for attempt := 0; attempt < maxGeneratedIdAttempts; attempt++ {
+		var id int64
+		err := tx.QueryRowContext(ctx, query, account.Balance, nullString(account.ExternalReference),
+			account.Currency, account.Kind, account.Status, account.OwnerID, account.Metadata).Scan(&id, &account.Version, &account.CreatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			logger.WithError(err).Error("Failed to insert account")
+			return nil, err
+		}
+
+		account.AccountID = id
+		account.InitialBalance = account.Balance
+		return account, nil
+	}
+
+	err := fmt.Errorf("no free account id after %d attempts", maxGeneratedIdAttempts)
+	logger.WithError(err).Error("Failed to generate account id")
+	return nil, err
+}
+
+// accountCopyColumns are the columns SaveMany copies; created_at takes its default
+var accountCopyColumns = []string{"id", "balance", "initial_balance", "external_reference", "currency", "kind", "status", "owner_id", "metadata"}
+
+// SaveMany copies the accounts into the table with COPY FROM, which needs the pgx driver
+func (repository *AccountRepositoryPostgre) SaveMany(ctx context.Context, tx ports.Transaction, accounts []*entities.Account) error {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	copier, err := copier(tx)
+	if err != nil {
+		return err
+	}
+	if err := repository.reserveIds(ctx, tx, accounts); err != nil {
+		logger.WithError(err).Error("Failed to generate account ids")
+		return err
+	}
+
+	rows := make([][]interface{}, len(accounts))
+	for i, account := range accounts {
+		if account.Currency == "" {
+			account.Currency = entities.DefaultCurrency
+		}
+		if account.Status == "" {
+			account.Status = entities.AccountStatusActive
+		}
+		if account.Kind == "" {
+			account.Kind = entities.AccountKindCustomer
+		}
+		rows[i] = []interface{}{account.AccountID, account.Balance, account.InitialBalance, nullString(account.ExternalReference),
+			string(account.Currency), string(account.Kind), string(account.Status), account.OwnerID, account.Metadata}
+	}
+
+	if _, err := copier.CopyFrom(ctx, "accounts", accountCopyColumns, rows); err != nil {
+		logger.WithError(err).Error("Failed to copy accounts")
+		return err
+	}
+
+	return nil
+}
+
+// reserveIds draws sequence values for the accounts without an id, skipping
+// those taken in the table or by explicit ids of the batch
+func (repository *AccountRepositoryPostgre) reserveIds(ctx context.Context, tx ports.Transaction, accounts []*entities.Account) error {
+	taken := map[int64]bool{}
+	missing := 0
+	for _, account := range accounts {
+		if account.AccountID == 0 {
+			missing++
+		} else {
+			taken[account.AccountID] = true
+		}
+	}
+
+	query := `
+            SELECT s.id
+            FROM (SELECT nextval('accounts_id_seq') AS id FROM generate_series(1, $1)) s
+            WHERE NOT EXISTS (SELECT 1 FROM accounts a WHERE a.id = s.id)`
+	ids := make([]int64, 0, missing)
+	for attempt := 0; len(ids) < missing && attempt < maxGeneratedIdAttempts; attempt++ {
+		rows, err := tx.QueryContext(ctx, query, missing-len(ids))
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			if !taken[id] {
+				ids = append(ids, id)
+			}
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+	}
+	if len(ids) < missing {
+		return fmt.Errorf("no free account id after %d attempts", maxGeneratedIdAttempts)
+	}
+
+	for _, account := range accounts {
+		if account.AccountID == 0 {
+			account.AccountID, ids = ids[0], ids[1:]
+		}
+	}
+	return nil
+}
+
 func (r *AccountRepositoryPostgre) FindById(ctx context.Context, tx ports.Transaction, id int64) (*entities.Account, error) {
+	return r.findById(ctx, tx, "SELECT "+accountColumns+" FROM accounts WHERE id = $1", id)
+}
+
+// FindByIdForUpdate holds the row lock until tx ends; transfers touching the account wait for it
+func (r *AccountRepositoryPostgre) FindByIdForUpdate(ctx context.Context, tx ports.Transaction, id int64) (*entities.Account, error) {
+	return r.findById(ctx, tx, "SELECT "+accountColumns+" FROM accounts WHERE id = $1 FOR UPDATE", id)
+}
+
+func (r *AccountRepositoryPostgre) findById(ctx context.Context, tx ports.Transaction, query string, id int64) (*entities.Account, error) {
 	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
-	account := &entities.Account{}
-	query := "SELECT id, balance FROM accounts WHERE id = $1 FOR UPDATE"
-	err := tx.QueryRowContext(ctx, query, id).Scan(&account.AccountID, &account.Balance)
+	account, err := scanAccount(tx.QueryRowContext(ctx, query, id))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -51,3 +198,256 @@ func (r *AccountRepositoryPostgre) FindById(ctx context.Context, tx ports.Transa
 
 	return account, nil
 }
+
+func (r *AccountRepositoryPostgre) FindByExternalReference(ctx context.Context, tx ports.Transaction, reference string) (*entities.Account, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	query := "SELECT " + accountColumns + " FROM accounts WHERE external_reference = $1"
+	account, err := scanAccount(tx.QueryRowContext(ctx, query, reference))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.WithError(err).Error("Failed to query account by external reference")
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// SystemAccount returns the system account of the kind and currency, creating it on first use
+func (r *AccountRepositoryPostgre) SystemAccount(ctx context.Context, tx ports.Transaction, kind entities.AccountKind, currency string) (*entities.Account, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	// concurrent first uses race on accounts_system_kind_currency_idx: the loser's
+	// insert waits for the winner, does nothing and the next select finds the winner's row.
+	// An insert can also do nothing because its id was picked explicitly, then it is retried
+	query := "SELECT " + accountColumns + " FROM accounts WHERE kind = $1 AND currency = $2"
+	insert := `
+            INSERT INTO accounts (id, currency, kind)
+            VALUES (nextval('accounts_id_seq'), $2, $1)
+            ON CONFLICT DO NOTHING`
+	for attempt := 0; attempt < maxGeneratedIdAttempts; attempt++ {
+		account, err := scanAccount(tx.QueryRowContext(ctx, query, kind, currency))
+		if err == nil {
+			return account, nil
+		}
+		if err != sql.ErrNoRows {
+			logger.WithError(err).Errorf("Failed to query %s account for %s", kind, currency)
+			return nil, err
+		}
+
+		if _, err := tx.ExecContext(ctx, insert, kind, currency); err != nil {
+			logger.WithError(err).Errorf("Failed to create %s account for %s", kind, currency)
+			return nil, err
+		}
+	}
+
+	err := fmt.Errorf("no %s account for %s after %d attempts", kind, currency, maxGeneratedIdAttempts)
+	logger.WithError(err).Error("Failed to create system account")
+	return nil, err
+}
+
+// Update writes the mutable attributes of an account still at account.Version;
+// balance only changes through UpdateBalance
+func (r *AccountRepositoryPostgre) Update(ctx context.Context, tx ports.Transaction, account *entities.Account) (*entities.Account, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	query := "UPDATE accounts SET metadata = $1, status = $2, version = version + 1 WHERE id = $3 AND version = $4 RETURNING " + accountColumns
+	updated, err := scanAccount(tx.QueryRowContext(ctx, query, account.Metadata, account.Status, account.AccountID, account.Version))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrVersionMismatch
+		}
+		logger.WithError(err).Error("Failed to update account")
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// accountSortColumns whitelists the columns a listing may be ordered by
+var accountSortColumns = map[entities.AccountSortField]string{
+	entities.AccountSortById:        "id",
+	entities.AccountSortByBalance:   "balance",
+	entities.AccountSortByCreatedAt: "created_at",
+}
+
+func (r *AccountRepositoryPostgre) List(ctx context.Context, tx ports.Transaction, filter entities.AccountFilter) (*entities.AccountPage, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	sortBy := filter.SortBy
+	if sortBy == "" {
+		sortBy = entities.AccountSortById
+	}
+	column, ok := accountSortColumns[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort field %q", sortBy)
+	}
+
+	builder := newQueryBuilder("SELECT " + accountColumns + " FROM accounts")
+	applyAccountFilter(builder, filter)
+
+	page := &entities.AccountPage{}
+	if filter.IncludeTotal {
+		countBuilder := newQueryBuilder("SELECT COUNT(*) FROM accounts")
+		applyAccountFilter(countBuilder, filter)
+		countQuery, countArgs := countBuilder.Build()
+
+		var total int64
+		if err := tx.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+			logger.WithError(err).Error("Failed to count accounts")
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	comparator, direction := ">", "ASC"
+	if filter.SortDesc {
+		comparator, direction = "<", "DESC"
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := decodeCursor(filter.Cursor)
+		if err != nil || cursor.Sort != string(sortBy) {
+			return nil, entities.ErrInvalidCursor
+		}
+		switch sortBy {
+		case entities.AccountSortById:
+			builder.Where("id "+comparator+" ?", cursor.Id)
+		case entities.AccountSortByBalance:
+			builder.Where("(balance, id) "+comparator+" (?::numeric, ?)", cursor.Value, cursor.Id)
+		case entities.AccountSortByCreatedAt:
+			builder.Where("(created_at, id) "+comparator+" (?::timestamp, ?)", cursor.Value, cursor.Id)
+		}
+	}
+
+	if sortBy != entities.AccountSortById {
+		builder.OrderBy(column + " " + direction)
+	}
+	// fetch one extra row to learn whether another page exists
+	builder.OrderBy("id " + direction).Limit(filter.Limit + 1)
+
+	query, args := builder.Build()
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list accounts")
+		return nil, err
+	}
+	defer rows.Close()
+
+	page.Accounts = []*entities.Account{}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			logger.WithError(err).Error("Failed to scan account")
+			return nil, err
+		}
+		page.Accounts = append(page.Accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("Failed to iterate accounts")
+		return nil, err
+	}
+
+	if len(page.Accounts) > filter.Limit {
+		page.Accounts = page.Accounts[:filter.Limit]
+		last := page.Accounts[len(page.Accounts)-1]
+		page.NextCursor = encodeCursor(pageCursor{
+			Sort:  string(sortBy),
+			Value: accountSortValue(last, sortBy),
+			Id:    last.AccountID,
+		})
+	}
+
+	return page, nil
+}
+
+func (r *AccountRepositoryPostgre) SumByCurrency(ctx context.Context, tx ports.Transaction) ([]*entities.CurrencyTotal, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	// a single statement sees one snapshot, so transfers committing meanwhile cannot split a total
+	query := `
+            SELECT currency, COUNT(*), SUM(initial_balance), SUM(balance)
+            FROM accounts
+            GROUP BY currency
+            ORDER BY currency`
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		logger.WithError(err).Error("Failed to sum account balances")
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := []*entities.CurrencyTotal{}
+	for rows.Next() {
+		total := &entities.CurrencyTotal{}
+		if err := rows.Scan(&total.Currency, &total.Accounts, &total.InitialBalance, &total.Balance); err != nil {
+			logger.WithError(err).Error("Failed to scan balance total")
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("Failed to iterate balance totals")
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+func applyAccountFilter(builder *queryBuilder, filter entities.AccountFilter) {
+	if filter.Status != "" {
+		builder.Where("status = ?", filter.Status)
+	}
+	if filter.Currency != "" {
+		builder.Where("currency = ?", filter.Currency)
+	}
+	if filter.MinBalance != nil {
+		builder.Where("balance >= ?", *filter.MinBalance)
+	}
+	if filter.MaxBalance != nil {
+		builder.Where("balance <= ?", *filter.MaxBalance)
+	}
+	if filter.CreatedFrom != nil {
+		builder.Where("created_at >= ?", filter.CreatedFrom.UTC().Format(timestampLayout))
+	}
+	if filter.CreatedTo != nil {
+		builder.Where("created_at < ?", filter.CreatedTo.UTC().Format(timestampLayout))
+	}
+	if filter.OwnerID != nil {
+		builder.Where("owner_id = ?", *filter.OwnerID)
+	}
+	if len(filter.Metadata) > 0 {
+		builder.Where("metadata @> ?::jsonb", filter.Metadata)
+	}
+}
+
+func accountSortValue(account *entities.Account, sortBy entities.AccountSortField) string {
+	switch sortBy {
+	case entities.AccountSortByBalance:
+		return account.Balance.String()
+	case entities.AccountSortByCreatedAt:
+		return account.CreatedAt.UTC().Format(timestampLayout)
+	}
+	return ""
+}
+
+const accountColumns = "id, balance, initial_balance, external_reference, currency, kind, status, owner_id, metadata, version, created_at"
+
+func scanAccount(row rowScanner) (*entities.Account, error) {
+	account := &entities.Account{}
+	var externalReference sql.NullString
+	var ownerID sql.NullInt64
+	err := row.Scan(&account.AccountID, &account.Balance, &account.InitialBalance, &externalReference, &account.Currency,
+		&account.Kind, &account.Status, &ownerID, &account.Metadata, &account.Version, &account.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	account.ExternalReference = externalReference.String
+	if ownerID.Valid {
+		account.OwnerID = &ownerID.Int64
+	}
+	return account, nil
+}
@@ -6,7 +6,9 @@ import (
 	"testing"
 	"transfer-system/adapters/repositories"
 	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
 	"transfer-system/internal/testutils"
+	"transfer-system/mocks"
 	"transfer-system/pkg/logger"
 
 	"github.com/shopspring/decimal"
@@ -108,3 +110,285 @@ func TestAccountRepositoryPostgre_FindById_NotFound(t *testing.T) {
 	assert.Nil(t, acc)
 	assert.Equal(t, sql.ErrNoRows, err)
 }
+
+func TestAccountRepositoryPostgre_Save_GeneratedId(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	repo := &repositories.AccountRepositoryPostgre{DB: db}
+
+	account := &entities.Account{
+		Balance:           decimal.NewFromFloat(10),
+		ExternalReference: "repo-test-generated",
+	}
+
+	savedAccount, err := repo.Save(ctx, tx, account)
+
+	assert.NoError(t, err)
+	assert.NotZero(t, savedAccount.AccountID)
+
+	fetchedAccount, err := repo.FindByExternalReference(ctx, tx, "repo-test-generated")
+	assert.NoError(t, err)
+	assert.Equal(t, savedAccount.AccountID, fetchedAccount.AccountID)
+}
+
+func TestAccountRepositoryPostgre_Save_DuplicateExternalReference(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	repo := &repositories.AccountRepositoryPostgre{DB: db}
+
+	_, err := repo.Save(ctx, tx, &entities.Account{Balance: decimal.Zero, ExternalReference: "repo-test-duplicate"})
+	require.NoError(t, err)
+
+	_, err = repo.Save(ctx, tx, &entities.Account{Balance: decimal.Zero, ExternalReference: "repo-test-duplicate"})
+	assert.Error(t, err, "Expected error due to duplicate external reference")
+}
+
+func TestAccountRepositoryPostgre_FindByExternalReference_NotFound(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	repo := &repositories.AccountRepositoryPostgre{DB: db}
+
+	acc, err := repo.FindByExternalReference(ctx, tx, "does-not-exist")
+
+	assert.Nil(t, acc)
+	assert.Equal(t, sql.ErrNoRows, err)
+}
+
+func TestAccountRepositoryPostgre_List_Pagination(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	repo := &repositories.AccountRepositoryPostgre{DB: db}
+
+	for i, balance := range []float64{30, 10, 20} {
+		_, err := repo.Save(ctx, tx, &entities.Account{
+			AccountID: int64(7000 + i),
+			Balance:   decimal.NewFromFloat(balance),
+			Currency:  "IDR",
+			Status:    entities.AccountStatusActive,
+		})
+		require.NoError(t, err)
+	}
+
+	filter := entities.AccountFilter{
+		Currency:     "IDR",
+		SortBy:       entities.AccountSortByBalance,
+		Limit:        2,
+		IncludeTotal: true,
+	}
+	firstPage, err := repo.List(ctx, tx, filter)
+	require.NoError(t, err)
+	require.Len(t, firstPage.Accounts, 2)
+	assert.Equal(t, int64(7001), firstPage.Accounts[0].AccountID)
+	assert.Equal(t, int64(7002), firstPage.Accounts[1].AccountID)
+	assert.Equal(t, int64(3), *firstPage.Total)
+	assert.NotEmpty(t, firstPage.NextCursor)
+
+	filter.Cursor = firstPage.NextCursor
+	secondPage, err := repo.List(ctx, tx, filter)
+	require.NoError(t, err)
+	require.Len(t, secondPage.Accounts, 1)
+	assert.Equal(t, int64(7000), secondPage.Accounts[0].AccountID)
+	assert.Empty(t, secondPage.NextCursor)
+}
+
+func TestAccountRepositoryPostgre_List_BalanceRange(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	repo := &repositories.AccountRepositoryPostgre{DB: db}
+
+	for i, balance := range []float64{5, 15, 25} {
+		_, err := repo.Save(ctx, tx, &entities.Account{
+			AccountID: int64(7100 + i),
+			Balance:   decimal.NewFromFloat(balance),
+			Currency:  "SGD",
+			Status:    entities.AccountStatusActive,
+		})
+		require.NoError(t, err)
+	}
+
+	minBalance, maxBalance := decimal.NewFromInt(10), decimal.NewFromInt(20)
+	page, err := repo.List(ctx, tx, entities.AccountFilter{
+		Currency:   "SGD",
+		MinBalance: &minBalance,
+		MaxBalance: &maxBalance,
+		Limit:      10,
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Accounts, 1)
+	assert.Equal(t, int64(7101), page.Accounts[0].AccountID)
+}
+
+func TestAccountRepositoryPostgre_List_InvalidCursor(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	repo := &repositories.AccountRepositoryPostgre{DB: db}
+
+	_, err := repo.List(ctx, tx, entities.AccountFilter{Cursor: "garbage", Limit: 10})
+	assert.ErrorIs(t, err, entities.ErrInvalidCursor)
+}
+
+func TestAccountRepositoryPostgre_List_Metadata(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	repo := &repositories.AccountRepositoryPostgre{DB: db}
+
+	for i, costCenter := range []string{"CC-1", "CC-2"} {
+		_, err := repo.Save(ctx, tx, &entities.Account{
+			AccountID: int64(7200 + i),
+			Balance:   decimal.NewFromInt(10),
+			Metadata:  entities.Metadata{"cost_center": costCenter, "product": "savings"},
+		})
+		require.NoError(t, err)
+	}
+
+	page, err := repo.List(ctx, tx, entities.AccountFilter{
+		Metadata: entities.Metadata{"cost_center": "CC-2", "product": "savings"},
+		Limit:    10,
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Accounts, 1)
+	assert.Equal(t, int64(7201), page.Accounts[0].AccountID)
+	assert.Equal(t, "CC-2", page.Accounts[0].Metadata["cost_center"])
+
+	stale := *page.Accounts[0]
+	updated := page.Accounts[0]
+	updated.Metadata = entities.Metadata{"product": "current"}
+	updated, err = repo.Update(ctx, tx, updated)
+	require.NoError(t, err)
+	assert.Equal(t, entities.Metadata{"product": "current"}, updated.Metadata)
+	assert.Equal(t, stale.Version+1, updated.Version)
+
+	_, err = repo.Update(ctx, tx, &stale)
+	assert.ErrorIs(t, err, entities.ErrVersionMismatch)
+}
+
+func TestAccountRepositoryPostgre_SumByCurrency(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	repo := &repositories.AccountRepositoryPostgre{DB: db}
+
+	totalOf := func(currency string) *entities.CurrencyTotal {
+		totals, err := repo.SumByCurrency(ctx, tx)
+		require.NoError(t, err)
+		for _, total := range totals {
+			if total.Currency == currency {
+				return total
+			}
+		}
+		return &entities.CurrencyTotal{Currency: currency}
+	}
+
+	before := totalOf("ISK")
+	for i, balance := range []int64{100, 50} {
+		_, err := repo.Save(ctx, tx, &entities.Account{AccountID: int64(7300 + i), Balance: decimal.NewFromInt(balance), Currency: "ISK"})
+		require.NoError(t, err)
+	}
+	_, err := tx.ExecContext(ctx, "UPDATE accounts SET balance = balance + 5 WHERE id = 7300")
+	require.NoError(t, err)
+
+	after := totalOf("ISK")
+	assert.Equal(t, before.Accounts+2, after.Accounts)
+	assert.True(t, before.InitialBalance.Add(decimal.NewFromInt(150)).Equal(after.InitialBalance))
+	assert.True(t, before.Balance.Add(decimal.NewFromInt(155)).Equal(after.Balance))
+	assert.True(t, before.Difference().Add(decimal.NewFromInt(5)).Equal(after.Difference()))
+}
+
+func TestAccountRepositoryPostgre_SystemAccount(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	repo := &repositories.AccountRepositoryPostgre{DB: db}
+
+	equity, err := repo.SystemAccount(ctx, tx, entities.AccountKindEquity, "ISK")
+	require.NoError(t, err)
+	assert.Equal(t, entities.AccountKindEquity, equity.Kind)
+	assert.Equal(t, "ISK", equity.Currency)
+
+	// the equity account may go negative, customer accounts may not
+	_, err = tx.ExecContext(ctx, "UPDATE accounts SET balance = balance - 100 WHERE id = $1", equity.AccountID)
+	require.NoError(t, err)
+
+	again, err := repo.SystemAccount(ctx, tx, entities.AccountKindEquity, "ISK")
+	require.NoError(t, err)
+	assert.Equal(t, equity.AccountID, again.AccountID)
+	assert.True(t, decimal.NewFromInt(-100).Equal(again.Balance))
+
+	other, err := repo.SystemAccount(ctx, tx, entities.AccountKindEquity, "NOK")
+	require.NoError(t, err)
+	assert.NotEqual(t, equity.AccountID, other.AccountID)
+}
+
+func TestAccountRepositoryPostgre_SaveMany(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+	if _, ok := tx.(ports.Copier); !ok {
+		t.Skip("COPY needs DB_DRIVER=pgx")
+	}
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	repo := &repositories.AccountRepositoryPostgre{DB: db}
+
+	accounts := []*entities.Account{
+		{AccountID: 77000001, Balance: decimal.RequireFromString("12.34567")},
+		{Balance: decimal.NewFromInt(5), ExternalReference: "repo-test-bulk", Metadata: entities.Metadata{"source": "import"}},
+	}
+	err := repo.SaveMany(ctx, tx, accounts)
+	require.NoError(t, err)
+	assert.NotZero(t, accounts[1].AccountID)
+	assert.NotEqual(t, accounts[0].AccountID, accounts[1].AccountID)
+
+	fetched, err := repo.FindByExternalReference(ctx, tx, "repo-test-bulk")
+	require.NoError(t, err)
+	assert.Equal(t, accounts[1].AccountID, fetched.AccountID)
+	assert.Equal(t, "import", fetched.Metadata["source"])
+
+	fetched, err = repo.FindById(ctx, tx, 77000001)
+	require.NoError(t, err)
+	assert.Equal(t, "12.34567", fetched.Balance.String())
+	assert.True(t, fetched.InitialBalance.IsZero())
+}
+
+func TestAccountRepositoryPostgre_SaveMany_NeedsCopy(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	repo := &repositories.AccountRepositoryPostgre{}
+	err := repo.SaveMany(ctx, new(mocks.MockTransaction), []*entities.Account{{Balance: decimal.NewFromInt(1)}})
+	assert.ErrorIs(t, err, repositories.ErrCopyUnsupported)
+}
@@ -0,0 +1,311 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AccountRepositorySQLite needs no row locks: SQLite transactions hold the
+// database write lock from BEGIN, so a read here is as good as FOR UPDATE
+type AccountRepositorySQLite struct {
+	DB ports.Database
+}
+
+func (repository *AccountRepositorySQLite) Save(ctx context.Context, tx ports.Transaction, account *entities.Account) (*entities.Account, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	if account.Currency == "" {
+		account.Currency = entities.DefaultCurrency
+	}
+	if account.Status == "" {
+		account.Status = entities.AccountStatusActive
+	}
+	if account.Kind == "" {
+		account.Kind = entities.AccountKindCustomer
+	}
+	metadata, err := sqliteJSON(account.Metadata)
+	if err != nil {
+		logger.WithError(err).Error("Failed to encode account metadata")
+		return nil, err
+	}
+
+	// a NULL id is generated above the highest id in use, explicit ones included
+	var accountID interface{}
+	if account.AccountID != 0 {
+		accountID = account.AccountID
+	}
+	createdAt := sqliteNow()
+
+	var id int64
+	query := `
+            INSERT INTO accounts (id, balance, initial_balance, external_reference, currency, kind, status, owner_id, metadata, created_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+            RETURNING id, version`
+	err = tx.QueryRowContext(ctx, query, accountID, account.Balance, account.Balance, nullString(account.ExternalReference),
+		account.Currency, account.Kind, account.Status, account.OwnerID, metadata, sqliteTime(createdAt)).Scan(&id, &account.Version)
+	if err != nil {
+		logger.WithError(err).Error("Failed to insert account")
+		return nil, err
+	}
+
+	account.AccountID = id
+	account.InitialBalance = account.Balance
+	account.CreatedAt = createdAt
+
+	return account, nil
+}
+
+func (r *AccountRepositorySQLite) FindById(ctx context.Context, tx ports.Transaction, id int64) (*entities.Account, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	query := "SELECT " + accountColumns + " FROM accounts WHERE id = ?"
+	account, err := scanAccount(tx.QueryRowContext(ctx, query, id))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.WithError(err).Error("Failed to query account by ID")
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// FindByIdForUpdate is FindById: the transaction already holds the database write lock
+func (r *AccountRepositorySQLite) FindByIdForUpdate(ctx context.Context, tx ports.Transaction, id int64) (*entities.Account, error) {
+	return r.FindById(ctx, tx, id)
+}
+
+func (r *AccountRepositorySQLite) FindByExternalReference(ctx context.Context, tx ports.Transaction, reference string) (*entities.Account, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	query := "SELECT " + accountColumns + " FROM accounts WHERE external_reference = ?"
+	account, err := scanAccount(tx.QueryRowContext(ctx, query, reference))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.WithError(err).Error("Failed to query account by external reference")
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// SystemAccount returns the system account of the kind and currency, creating it on first use
+func (r *AccountRepositorySQLite) SystemAccount(ctx context.Context, tx ports.Transaction, kind entities.AccountKind, currency string) (*entities.Account, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	// writers are serialized, so the account cannot appear between the select and the insert
+	query := "SELECT " + accountColumns + " FROM accounts WHERE kind = ? AND currency = ?"
+	account, err := scanAccount(tx.QueryRowContext(ctx, query, kind, currency))
+	if err == nil {
+		return account, nil
+	}
+	if err != sql.ErrNoRows {
+		logger.WithError(err).Errorf("Failed to query %s account for %s", kind, currency)
+		return nil, err
+	}
+
+	account = &entities.Account{Currency: currency, Kind: kind}
+	if _, err := r.Save(ctx, tx, account); err != nil {
+		logger.WithError(err).Errorf("Failed to create %s account for %s", kind, currency)
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// Update writes the mutable attributes of an account still at account.Version;
+// balance only changes through UpdateBalance
+func (r *AccountRepositorySQLite) Update(ctx context.Context, tx ports.Transaction, account *entities.Account) (*entities.Account, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	metadata, err := sqliteJSON(account.Metadata)
+	if err != nil {
+		logger.WithError(err).Error("Failed to encode account metadata")
+		return nil, err
+	}
+	query := "UPDATE accounts SET metadata = ?, status = ?, version = version + 1 WHERE id = ? AND version = ? RETURNING " + accountColumns
+	updated, err := scanAccount(tx.QueryRowContext(ctx, query, metadata, account.Status, account.AccountID, account.Version))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.ErrVersionMismatch
+		}
+		logger.WithError(err).Error("Failed to update account")
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (r *AccountRepositorySQLite) List(ctx context.Context, tx ports.Transaction, filter entities.AccountFilter) (*entities.AccountPage, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	sortBy := filter.SortBy
+	if sortBy == "" {
+		sortBy = entities.AccountSortById
+	}
+	column, ok := accountSortColumns[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort field %q", sortBy)
+	}
+
+	builder := newSQLiteQueryBuilder("SELECT " + accountColumns + " FROM accounts")
+	applySQLiteAccountFilter(builder, filter)
+
+	page := &entities.AccountPage{}
+	if filter.IncludeTotal {
+		countBuilder := newSQLiteQueryBuilder("SELECT COUNT(*) FROM accounts")
+		applySQLiteAccountFilter(countBuilder, filter)
+		countQuery, countArgs := countBuilder.Build()
+
+		var total int64
+		if err := tx.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+			logger.WithError(err).Error("Failed to count accounts")
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	comparator, direction := ">", "ASC"
+	if filter.SortDesc {
+		comparator, direction = "<", "DESC"
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := decodeCursor(filter.Cursor)
+		if err != nil || cursor.Sort != string(sortBy) {
+			return nil, entities.ErrInvalidCursor
+		}
+		switch sortBy {
+		case entities.AccountSortById:
+			builder.Where("id "+comparator+" ?", cursor.Id)
+		case entities.AccountSortByBalance:
+			// balance carries the decimal collation, so the text cursor compares by value
+			builder.Where("(balance, id) "+comparator+" (?, ?)", cursor.Value, cursor.Id)
+		case entities.AccountSortByCreatedAt:
+			createdAt, err := sqliteCursorTime(cursor.Value)
+			if err != nil {
+				return nil, err
+			}
+			builder.Where("(created_at, id) "+comparator+" (?, ?)", createdAt, cursor.Id)
+		}
+	}
+
+	if sortBy != entities.AccountSortById {
+		builder.OrderBy(column + " " + direction)
+	}
+	// fetch one extra row to learn whether another page exists
+	builder.OrderBy("id " + direction).Limit(filter.Limit + 1)
+
+	query, args := builder.Build()
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list accounts")
+		return nil, err
+	}
+	defer rows.Close()
+
+	page.Accounts = []*entities.Account{}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			logger.WithError(err).Error("Failed to scan account")
+			return nil, err
+		}
+		page.Accounts = append(page.Accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("Failed to iterate accounts")
+		return nil, err
+	}
+
+	if len(page.Accounts) > filter.Limit {
+		page.Accounts = page.Accounts[:filter.Limit]
+		last := page.Accounts[len(page.Accounts)-1]
+		page.NextCursor = encodeCursor(pageCursor{
+			Sort:  string(sortBy),
+			Value: accountSortValue(last, sortBy),
+			Id:    last.AccountID,
+		})
+	}
+
+	return page, nil
+}
+
+func (r *AccountRepositorySQLite) SumByCurrency(ctx context.Context, tx ports.Transaction) ([]*entities.CurrencyTotal, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	// SUM would add the text amounts as floating point
+	query := `
+            SELECT currency, COUNT(*), decimal_sum(initial_balance), decimal_sum(balance)
+            FROM accounts
+            GROUP BY currency
+            ORDER BY currency`
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		logger.WithError(err).Error("Failed to sum account balances")
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := []*entities.CurrencyTotal{}
+	for rows.Next() {
+		total := &entities.CurrencyTotal{}
+		if err := rows.Scan(&total.Currency, &total.Accounts, &total.InitialBalance, &total.Balance); err != nil {
+			logger.WithError(err).Error("Failed to scan balance total")
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("Failed to iterate balance totals")
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+func applySQLiteAccountFilter(builder *queryBuilder, filter entities.AccountFilter) {
+	if filter.Status != "" {
+		builder.Where("status = ?", filter.Status)
+	}
+	if filter.Currency != "" {
+		builder.Where("currency = ?", filter.Currency)
+	}
+	if filter.MinBalance != nil {
+		builder.Where("balance >= ?", *filter.MinBalance)
+	}
+	if filter.MaxBalance != nil {
+		builder.Where("balance <= ?", *filter.MaxBalance)
+	}
+	if filter.CreatedFrom != nil {
+		builder.Where("created_at >= ?", sqliteTime(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		builder.Where("created_at < ?", sqliteTime(*filter.CreatedTo))
+	}
+	if filter.OwnerID != nil {
+		builder.Where("owner_id = ?", *filter.OwnerID)
+	}
+
+	// keys are validated to letters, digits, _ and -, so they are safe inside a quoted JSON path
+	keys := make([]string, 0, len(filter.Metadata))
+	for key := range filter.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		builder.Where("json_extract(metadata, ?) = ?", `$."`+key+`"`, filter.Metadata[key])
+	}
+}
@@ -0,0 +1,205 @@
+package repositories_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"transfer-system/adapters/repositories"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/infrastructure/datastore"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sqliteDatabase opens a migrated SQLite file that is removed after the test
+func sqliteDatabase(t *testing.T) ports.Database {
+	db, err := datastore.NewDatabase("sqlite:"+filepath.Join(t.TempDir(), "transfer.db"), logrus.New())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestAccountRepositorySQLite_Save_GeneratesIdsAboveExplicitOnes(t *testing.T) {
+	db := sqliteDatabase(t)
+	repo := &repositories.AccountRepositorySQLite{DB: db}
+	ctx := memoryContext()
+	tx := beginMemory(t, db)
+	defer tx.Rollback()
+
+	_, err := repo.Save(ctx, tx, &entities.Account{AccountID: 5, Balance: decimal.RequireFromString("10.25")})
+	require.NoError(t, err)
+
+	generated, err := repo.Save(ctx, tx, &entities.Account{Metadata: entities.Metadata{"team": "ops"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), generated.AccountID)
+	assert.Equal(t, entities.DefaultCurrency, generated.Currency)
+
+	_, err = repo.Save(ctx, tx, &entities.Account{AccountID: 5})
+	assert.Equal(t, "23505", sqlStateOf(err))
+
+	found, err := repo.FindById(ctx, tx, 5)
+	require.NoError(t, err)
+	assert.Equal(t, "10.25", found.Balance.String())
+	assert.Equal(t, "10.25", found.InitialBalance.String())
+	assert.WithinDuration(t, time.Now(), found.CreatedAt, time.Minute)
+
+	found, err = repo.FindById(ctx, tx, 6)
+	require.NoError(t, err)
+	assert.Equal(t, "ops", found.Metadata["team"])
+
+	_, err = repo.FindById(ctx, tx, 7)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestAccountRepositorySQLite_Save_Constraints(t *testing.T) {
+	db := sqliteDatabase(t)
+	repo := &repositories.AccountRepositorySQLite{DB: db}
+	ctx := memoryContext()
+	tx := beginMemory(t, db)
+	defer tx.Rollback()
+
+	_, err := repo.Save(ctx, tx, &entities.Account{ExternalReference: "ERP-1"})
+	require.NoError(t, err)
+	_, err = repo.Save(ctx, tx, &entities.Account{ExternalReference: "ERP-1"})
+	assert.Equal(t, "23505", sqlStateOf(err))
+
+	ownerID := int64(99)
+	_, err = repo.Save(ctx, tx, &entities.Account{OwnerID: &ownerID})
+	assert.Equal(t, "23503", sqlStateOf(err))
+
+	_, err = repo.Save(ctx, tx, &entities.Account{Balance: decimal.NewFromInt(-1)})
+	assert.Equal(t, "23514", sqlStateOf(err))
+}
+
+func TestAccountRepositorySQLite_SystemAccount_CreatedOnce(t *testing.T) {
+	db := sqliteDatabase(t)
+	repo := &repositories.AccountRepositorySQLite{DB: db}
+	ctx := memoryContext()
+	tx := beginMemory(t, db)
+	defer tx.Rollback()
+
+	first, err := repo.SystemAccount(ctx, tx, entities.AccountKindEquity, "EUR")
+	require.NoError(t, err)
+	second, err := repo.SystemAccount(ctx, tx, entities.AccountKindEquity, "EUR")
+	require.NoError(t, err)
+	other, err := repo.SystemAccount(ctx, tx, entities.AccountKindEquity, "USD")
+	require.NoError(t, err)
+
+	assert.Equal(t, first.AccountID, second.AccountID)
+	assert.NotEqual(t, first.AccountID, other.AccountID)
+	assert.Equal(t, entities.AccountKindEquity, first.Kind)
+}
+
+func TestAccountRepositorySQLite_List_FiltersSortsAndPages(t *testing.T) {
+	db := sqliteDatabase(t)
+	repo := &repositories.AccountRepositorySQLite{DB: db}
+	ctx := memoryContext()
+	tx := beginMemory(t, db)
+	defer tx.Rollback()
+
+	// 100 sorts before 20 as text, the decimal collation must order by value
+	for id, balance := range map[int64]string{1: "100", 2: "9.5", 3: "20", 4: "9.5"} {
+		_, err := repo.Save(ctx, tx, &entities.Account{
+			AccountID: id,
+			Balance:   decimal.RequireFromString(balance),
+			Metadata:  entities.Metadata{"team": "ops"},
+		})
+		require.NoError(t, err)
+	}
+	_, err := repo.Save(ctx, tx, &entities.Account{AccountID: 5, Balance: decimal.NewFromInt(50), Currency: "EUR"})
+	require.NoError(t, err)
+
+	filter := entities.AccountFilter{
+		Currency:     "USD",
+		Metadata:     entities.Metadata{"team": "ops"},
+		SortBy:       entities.AccountSortByBalance,
+		Limit:        2,
+		IncludeTotal: true,
+	}
+	page, err := repo.List(ctx, tx, filter)
+	require.NoError(t, err)
+	require.NotNil(t, page.Total)
+	assert.Equal(t, int64(4), *page.Total)
+	assert.Equal(t, []int64{2, 4}, accountIds(page.Accounts))
+	require.NotEmpty(t, page.NextCursor)
+
+	filter.Cursor = page.NextCursor
+	page, err = repo.List(ctx, tx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 1}, accountIds(page.Accounts))
+	assert.Empty(t, page.NextCursor)
+
+	minBalance := decimal.NewFromInt(20)
+	page, err = repo.List(ctx, tx, entities.AccountFilter{MinBalance: &minBalance, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 3, 5}, accountIds(page.Accounts))
+
+	page, err = repo.List(ctx, tx, entities.AccountFilter{SortBy: entities.AccountSortByCreatedAt, SortDesc: true, Limit: 3})
+	require.NoError(t, err)
+	require.NotEmpty(t, page.NextCursor)
+	page, err = repo.List(ctx, tx, entities.AccountFilter{SortBy: entities.AccountSortByCreatedAt, SortDesc: true, Limit: 3, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Len(t, page.Accounts, 2)
+}
+
+func TestAccountRepositorySQLite_SumByCurrency(t *testing.T) {
+	db := sqliteDatabase(t)
+	repo := &repositories.AccountRepositorySQLite{DB: db}
+	ctx := memoryContext()
+	tx := beginMemory(t, db)
+	defer tx.Rollback()
+
+	for _, account := range []*entities.Account{
+		{Balance: decimal.RequireFromString("0.1"), Currency: "USD"},
+		{Balance: decimal.NewFromInt(5), Currency: "EUR"},
+		{Balance: decimal.RequireFromString("0.2"), Currency: "USD"},
+	} {
+		_, err := repo.Save(ctx, tx, account)
+		require.NoError(t, err)
+	}
+
+	totals, err := repo.SumByCurrency(ctx, tx)
+	require.NoError(t, err)
+	require.Len(t, totals, 2)
+	assert.Equal(t, "EUR", totals[0].Currency)
+	assert.Equal(t, "USD", totals[1].Currency)
+	assert.Equal(t, 2, totals[1].Accounts)
+	assert.Equal(t, "0.3", totals[1].Balance.String())
+	assert.Equal(t, "0.3", totals[1].InitialBalance.String())
+}
+
+func TestAccountRepositorySQLite_Update_Version(t *testing.T) {
+	db := sqliteDatabase(t)
+	repo := &repositories.AccountRepositorySQLite{DB: db}
+	transactions := &repositories.TransactionRepositorySQLite{DB: db}
+	ctx := memoryContext()
+	tx := beginMemory(t, db)
+	defer tx.Rollback()
+
+	account, err := repo.Save(ctx, tx, &entities.Account{AccountID: 1, Balance: decimal.NewFromInt(10)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), account.Version)
+
+	stale := *account
+	account.Metadata = entities.Metadata{"team": "ops"}
+	account, err = repo.Update(ctx, tx, account)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), account.Version)
+
+	stale.Metadata = entities.Metadata{"team": "sales"}
+	_, err = repo.Update(ctx, tx, &stale)
+	assert.ErrorIs(t, err, entities.ErrVersionMismatch)
+
+	// balance changes count as changes to the account
+	require.NoError(t, transactions.UpdateBalance(ctx, tx, 1, decimal.NewFromInt(-1)))
+	found, err := repo.FindById(ctx, tx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), found.Version)
+	assert.Equal(t, "ops", found.Metadata["team"])
+}
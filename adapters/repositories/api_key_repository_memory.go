@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/infrastructure/memstore"
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+type APIKeyRepositoryMemory struct {
+	DB ports.Database
+}
+
+func (repository *APIKeyRepositoryMemory) Save(ctx context.Context, tx ports.Transaction, key *entities.APIKey) (*entities.APIKey, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = lockUnique(ctx, memoryTx, "unique_api_key_hash", key.Hash, func() bool {
+		return findAPIKey(memoryTx, key.Hash) != nil
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to insert API key")
+		return nil, err
+	}
+
+	key.ID = memoryTx.NextVal(apiKeysSequence)
+	key.CreatedAt = memoryTx.Now()
+	if err := memoryTx.Put(apiKeysTable, key.ID, cloneAPIKey(key)); err != nil {
+		logger.WithError(err).Error("Failed to insert API key")
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (repository *APIKeyRepositoryMemory) FindByHash(ctx context.Context, tx ports.Transaction, hash string) (*entities.APIKey, error) {
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	key := findAPIKey(memoryTx, hash)
+	if key == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	return cloneAPIKey(key), nil
+}
+
+func findAPIKey(tx *memstore.Tx, hash string) *entities.APIKey {
+	for _, row := range tx.Rows(apiKeysTable) {
+		if key := row.(*entities.APIKey); key.Hash == hash {
+			return key
+		}
+	}
+	return nil
+}
+
+func cloneAPIKey(key *entities.APIKey) *entities.APIKey {
+	clone := *key
+	clone.RevokedAt = cloneTime(key.RevokedAt)
+	return &clone
+}
@@ -0,0 +1,47 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"transfer-system/adapters/repositories"
+	"transfer-system/domain/entities"
+	"transfer-system/internal/testutils"
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyRepositoryPostgre_SaveAndFindByHash(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+	repo := &repositories.APIKeyRepositoryPostgre{DB: db}
+
+	hash := "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+	saved, err := repo.Save(ctx, tx, &entities.APIKey{Name: "payroll", Prefix: "tsk_abcdefgh", Hash: hash})
+	require.NoError(t, err)
+	assert.NotZero(t, saved.ID)
+
+	found, err := repo.FindByHash(ctx, tx, hash)
+	require.NoError(t, err)
+	assert.Equal(t, saved.ID, found.ID)
+	assert.Equal(t, "payroll", found.Name)
+	assert.Nil(t, found.RevokedAt)
+	assert.Empty(t, found.SigningSecret)
+
+	signingHash := "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"
+	_, err = repo.Save(ctx, tx, &entities.APIKey{Name: "partner", Prefix: "tsk_ijklmnop", Hash: signingHash, SigningSecret: "tss_c2lnbmluZw"})
+	require.NoError(t, err)
+	found, err = repo.FindByHash(ctx, tx, signingHash)
+	require.NoError(t, err)
+	assert.Equal(t, "tss_c2lnbmluZw", found.SigningSecret)
+
+	_, err = repo.FindByHash(ctx, tx, "0000000000000000000000000000000000000000000000000000000000000000")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+type APIKeyRepositorySQLite struct {
+	DB ports.Database
+}
+
+func (repository *APIKeyRepositorySQLite) Save(ctx context.Context, tx ports.Transaction, key *entities.APIKey) (*entities.APIKey, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	createdAt := sqliteNow()
+	query := `
+			INSERT INTO api_keys (name, prefix, hash, signing_secret, created_at)
+			VALUES (?, ?, ?, ?, ?)
+			RETURNING id`
+	err := tx.QueryRowContext(ctx, query, key.Name, key.Prefix, key.Hash, nullString(key.SigningSecret), sqliteTime(createdAt)).Scan(&key.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to insert API key")
+		return nil, err
+	}
+
+	key.CreatedAt = createdAt
+	return key, nil
+}
+
+func (repository *APIKeyRepositorySQLite) FindByHash(ctx context.Context, tx ports.Transaction, hash string) (*entities.APIKey, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	key := &entities.APIKey{}
+	var signingSecret sql.NullString
+	var revokedAt sql.NullTime
+	query := `
+			SELECT id, name, prefix, hash, signing_secret, created_at, revoked_at
+			FROM api_keys
+			WHERE hash = ?`
+	err := tx.QueryRowContext(ctx, query, hash).Scan(&key.ID, &key.Name, &key.Prefix, &key.Hash, &signingSecret, &key.CreatedAt, &revokedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.WithError(err).Error("Failed to query API key by hash")
+		return nil, err
+	}
+
+	key.SigningSecret = signingSecret.String
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return key, nil
+}
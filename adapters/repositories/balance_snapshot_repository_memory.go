@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/infrastructure/memstore"
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+type BalanceSnapshotRepositoryMemory struct {
+	DB ports.Database
+}
+
+// snapshotKey is the primary key (account_id, as_of) of a snapshot
+type snapshotKey struct {
+	AccountID int64
+	AsOf      string
+}
+
+func (repository *BalanceSnapshotRepositoryMemory) Save(ctx context.Context, tx ports.Transaction, snapshot *entities.BalanceSnapshot) error {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := memoryTx.Get(accountsTable, snapshot.AccountID); !ok {
+		err := &memstore.Error{
+			State:   memstore.StateForeignKeyViolation,
+			Message: fmt.Sprintf("account %d is not present in table %q", snapshot.AccountID, accountsTable),
+		}
+		logger.WithError(err).Error("Failed to insert balance snapshot")
+		return err
+	}
+
+	stored := &entities.BalanceSnapshot{AccountID: snapshot.AccountID, AsOf: storedTime(snapshot.AsOf), Balance: snapshot.Balance}
+	key := snapshotKey{AccountID: stored.AccountID, AsOf: stored.AsOf.Format(timestampLayout)}
+	if err := memoryTx.Lock(ctx, balanceSnapshotsTable, key); err != nil {
+		logger.WithError(err).Error("Failed to insert balance snapshot")
+		return err
+	}
+	// saving the same account and as_of twice is a no-op, as with ON CONFLICT DO NOTHING
+	if _, exists := memoryTx.Get(balanceSnapshotsTable, key); exists {
+		return nil
+	}
+	if err := memoryTx.Put(balanceSnapshotsTable, key, stored); err != nil {
+		logger.WithError(err).Error("Failed to insert balance snapshot")
+		return err
+	}
+
+	return nil
+}
+
+func (repository *BalanceSnapshotRepositoryMemory) FindLatest(ctx context.Context, tx ports.Transaction, accountID int64, asOf time.Time) (*entities.BalanceSnapshot, error) {
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := latestSnapshot(memoryTx, accountID, storedTime(asOf))
+	if latest == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	snapshot := *latest
+	return &snapshot, nil
+}
+
+func (repository *BalanceSnapshotRepositoryMemory) ListStaleAccounts(ctx context.Context, tx ports.Transaction, asOf time.Time, afterID int64, limit int) ([]int64, error) {
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	asOf = storedTime(asOf)
+	candidates := []int64{}
+	for _, row := range memoryTx.Rows(accountsTable) {
+		if id := row.(*entities.Account).AccountID; id > afterID {
+			candidates = append(candidates, id)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i] < candidates[j] })
+
+	ids := []int64{}
+	for _, id := range candidates {
+		if len(ids) == limit {
+			break
+		}
+		// latest is not bounded by asOf, so accounts snapshotted later are not stale
+		var since time.Time
+		if latest := latestSnapshot(memoryTx, id, time.Time{}); latest != nil {
+			since = latest.AsOf
+		}
+		stale := transactionsOf(memoryTx, id, func(transaction *entities.Transaction) bool {
+			return transaction.CreatedAt.After(since) && !transaction.CreatedAt.After(asOf)
+		})
+		if len(stale) > 0 {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+// latestSnapshot returns the newest snapshot of the account taken at or before asOf; a zero asOf is not bounded
+func latestSnapshot(tx *memstore.Tx, accountID int64, asOf time.Time) *entities.BalanceSnapshot {
+	var latest *entities.BalanceSnapshot
+	for _, row := range tx.Rows(balanceSnapshotsTable) {
+		snapshot := row.(*entities.BalanceSnapshot)
+		if snapshot.AccountID != accountID || (!asOf.IsZero() && snapshot.AsOf.After(asOf)) {
+			continue
+		}
+		if latest == nil || snapshot.AsOf.After(latest.AsOf) {
+			latest = snapshot
+		}
+	}
+	return latest
+}
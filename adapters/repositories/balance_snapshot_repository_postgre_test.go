@@ -0,0 +1,64 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"transfer-system/adapters/repositories"
+	"transfer-system/domain/entities"
+	"transfer-system/internal/testutils"
+	"transfer-system/pkg/logger"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceSnapshotRepositoryPostgre_SnapshotLifecycle(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	accountRepo := &repositories.AccountRepositoryPostgre{DB: db}
+	for _, id := range []int64{1501, 1502} {
+		saved, err := accountRepo.Save(ctx, tx, &entities.Account{AccountID: id, Balance: decimal.NewFromInt(100)})
+		require.NoError(t, err)
+		assert.True(t, decimal.NewFromInt(100).Equal(saved.InitialBalance))
+	}
+
+	transactionRepo := &repositories.TransactionRepositoryPostgre{DB: db}
+	_, err := transactionRepo.Save(ctx, tx, &entities.Transaction{SourceAccountID: 1501, DestinationAccountID: 1502, Amount: decimal.NewFromInt(30)})
+	require.NoError(t, err)
+
+	// created_at is the transaction start time, so everything above is before now
+	now := time.Now().UTC().Add(time.Minute)
+	movement, err := transactionRepo.NetMovement(ctx, tx, 1501, nil, now)
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(-30).Equal(movement))
+
+	repo := &repositories.BalanceSnapshotRepositoryPostgre{DB: db}
+
+	stale, err := repo.ListStaleAccounts(ctx, tx, now, 1500, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1501, 1502}, stale)
+
+	require.NoError(t, repo.Save(ctx, tx, &entities.BalanceSnapshot{AccountID: 1501, AsOf: now, Balance: decimal.NewFromInt(70)}))
+	// saving the same point twice is a no-op
+	require.NoError(t, repo.Save(ctx, tx, &entities.BalanceSnapshot{AccountID: 1501, AsOf: now, Balance: decimal.NewFromInt(70)}))
+
+	stale, err = repo.ListStaleAccounts(ctx, tx, now, 1500, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1502}, stale)
+
+	snapshot, err := repo.FindLatest(ctx, tx, 1501, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(70).Equal(snapshot.Balance))
+
+	_, err = repo.FindLatest(ctx, tx, 1501, now.Add(-time.Hour))
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
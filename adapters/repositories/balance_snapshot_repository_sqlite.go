@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+type BalanceSnapshotRepositorySQLite struct {
+	DB ports.Database
+}
+
+func (repository *BalanceSnapshotRepositorySQLite) Save(ctx context.Context, tx ports.Transaction, snapshot *entities.BalanceSnapshot) error {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	query := `
+			INSERT INTO account_balance_snapshots (account_id, as_of, balance, created_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT (account_id, as_of) DO NOTHING`
+	_, err := tx.ExecContext(ctx, query, snapshot.AccountID, sqliteTime(snapshot.AsOf), snapshot.Balance, sqliteTime(sqliteNow()))
+	if err != nil {
+		logger.WithError(err).Error("Failed to insert balance snapshot")
+		return err
+	}
+
+	return nil
+}
+
+func (repository *BalanceSnapshotRepositorySQLite) FindLatest(ctx context.Context, tx ports.Transaction, accountID int64, asOf time.Time) (*entities.BalanceSnapshot, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	snapshot := &entities.BalanceSnapshot{AccountID: accountID}
+	query := `
+			SELECT as_of, balance
+			FROM account_balance_snapshots
+			WHERE account_id = ? AND as_of <= ?
+			ORDER BY as_of DESC
+			LIMIT 1`
+	err := tx.QueryRowContext(ctx, query, accountID, sqliteTime(asOf)).Scan(&snapshot.AsOf, &snapshot.Balance)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.WithError(err).Error("Failed to query balance snapshot")
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+func (repository *BalanceSnapshotRepositorySQLite) ListStaleAccounts(ctx context.Context, tx ports.Transaction, asOf time.Time, afterID int64, limit int) ([]int64, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	// timestamps are text in a fixed layout, '' sorts before all of them like -infinity
+	query := `
+			SELECT a.id
+			FROM accounts a
+			WHERE a.id > ? AND EXISTS (
+				SELECT 1 FROM transactions t
+				WHERE (t.source_id = a.id OR t.destination_id = a.id)
+					AND t.created_at > COALESCE(
+						(SELECT MAX(s.as_of) FROM account_balance_snapshots s WHERE s.account_id = a.id), '')
+					AND t.created_at <= ?
+			)
+			ORDER BY a.id
+			LIMIT ?`
+	rows, err := tx.QueryContext(ctx, query, afterID, sqliteTime(asOf), limit)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list accounts needing a snapshot")
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			logger.WithError(err).Error("Failed to scan account id")
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("Failed to iterate accounts needing a snapshot")
+		return nil, err
+	}
+
+	return ids, nil
+}
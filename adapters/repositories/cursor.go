@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"transfer-system/domain/entities"
+)
+
+// pageCursor is the keyset position of the last row of a page: the value of
+// the sort column plus the id that breaks ties between equal values
+type pageCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	Id    int64  `json:"id"`
+}
+
+func encodeCursor(cursor pageCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(encoded string) (pageCursor, error) {
+	var cursor pageCursor
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, entities.ErrInvalidCursor
+	}
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return cursor, entities.ErrInvalidCursor
+	}
+	return cursor, nil
+}
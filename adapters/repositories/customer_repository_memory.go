@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/infrastructure/memstore"
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+type CustomerRepositoryMemory struct {
+	DB ports.Database
+}
+
+func (repository *CustomerRepositoryMemory) Save(ctx context.Context, tx ports.Transaction, customer *entities.Customer) (*entities.Customer, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := cloneCustomer(customer)
+	if err := repository.lockEmail(ctx, memoryTx, saved); err != nil {
+		logger.WithError(err).Error("Failed to insert customer")
+		return nil, err
+	}
+
+	saved.CustomerID = memoryTx.NextVal(customersSequence)
+	saved.CreatedAt = memoryTx.Now()
+	saved.UpdatedAt = saved.CreatedAt
+	if err := memoryTx.Lock(ctx, customersTable, saved.CustomerID); err != nil {
+		logger.WithError(err).Error("Failed to insert customer")
+		return nil, err
+	}
+	if err := memoryTx.Put(customersTable, saved.CustomerID, saved); err != nil {
+		logger.WithError(err).Error("Failed to insert customer")
+		return nil, err
+	}
+
+	return cloneCustomer(saved), nil
+}
+
+func (repository *CustomerRepositoryMemory) FindById(ctx context.Context, tx ports.Transaction, id int64) (*entities.Customer, error) {
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	row, ok := memoryTx.Get(customersTable, id)
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return cloneCustomer(row.(*entities.Customer)), nil
+}
+
+func (repository *CustomerRepositoryMemory) FindByIdForUpdate(ctx context.Context, tx ports.Transaction, id int64) (*entities.Customer, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := memoryTx.Lock(ctx, customersTable, id); err != nil {
+		logger.WithError(err).Error("Failed to query customer by ID")
+		return nil, err
+	}
+	return repository.FindById(ctx, tx, id)
+}
+
+func (repository *CustomerRepositoryMemory) Update(ctx context.Context, tx ports.Transaction, customer *entities.Customer) (*entities.Customer, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := memoryTx.Lock(ctx, customersTable, customer.CustomerID); err != nil {
+		logger.WithError(err).Error("Failed to update customer")
+		return nil, err
+	}
+	row, ok := memoryTx.Get(customersTable, customer.CustomerID)
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	updated := cloneCustomer(customer)
+	updated.CreatedAt = row.(*entities.Customer).CreatedAt
+	updated.UpdatedAt = memoryTx.Now()
+	if err := repository.lockEmail(ctx, memoryTx, updated); err != nil {
+		logger.WithError(err).Error("Failed to update customer")
+		return nil, err
+	}
+	if err := memoryTx.Put(customersTable, updated.CustomerID, updated); err != nil {
+		logger.WithError(err).Error("Failed to update customer")
+		return nil, err
+	}
+
+	return cloneCustomer(updated), nil
+}
+
+func (repository *CustomerRepositoryMemory) Delete(ctx context.Context, tx ports.Transaction, id int64) error {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if err := memoryTx.Lock(ctx, customersTable, id); err != nil {
+		logger.WithError(err).Error("Failed to delete customer")
+		return err
+	}
+	if _, ok := memoryTx.Get(customersTable, id); !ok {
+		return sql.ErrNoRows
+	}
+
+	owned := findAccount(memoryTx, func(account *entities.Account) bool {
+		return account.OwnerID != nil && *account.OwnerID == id
+	})
+	if owned != nil {
+		err := &memstore.Error{
+			State:   memstore.StateForeignKeyViolation,
+			Message: fmt.Sprintf("customer %d is still referenced from table %q", id, accountsTable),
+		}
+		logger.WithError(err).Error("Failed to delete customer")
+		return err
+	}
+
+	if err := memoryTx.Delete(customersTable, id); err != nil {
+		logger.WithError(err).Error("Failed to delete customer")
+		return err
+	}
+
+	return nil
+}
+
+// lockEmail enforces unique_customer_email, ignoring the customer's own row
+func (repository *CustomerRepositoryMemory) lockEmail(ctx context.Context, tx *memstore.Tx, customer *entities.Customer) error {
+	return lockUnique(ctx, tx, "unique_customer_email", customer.Email, func() bool {
+		for _, row := range tx.Rows(customersTable) {
+			stored := row.(*entities.Customer)
+			if stored.CustomerID != customer.CustomerID && stored.Email == customer.Email {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func cloneCustomer(customer *entities.Customer) *entities.Customer {
+	clone := *customer
+	clone.DateOfBirth = cloneTime(customer.DateOfBirth)
+	return &clone
+}
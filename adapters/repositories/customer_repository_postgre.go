@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+type CustomerRepositoryPostgre struct {
+	DB ports.Database
+}
+
+const customerColumns = "id, full_name, email, phone, date_of_birth, country, kyc_tier, created_at, updated_at"
+
+func (repository *CustomerRepositoryPostgre) Save(ctx context.Context, tx ports.Transaction, customer *entities.Customer) (*entities.Customer, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	query := `
+            INSERT INTO customers (full_name, email, phone, date_of_birth, country, kyc_tier)
+            VALUES ($1, $2, $3, $4, $5, $6)
+            RETURNING ` + customerColumns
+	saved, err := scanCustomer(tx.QueryRowContext(ctx, query, customer.FullName, customer.Email, nullString(customer.Phone),
+		customer.DateOfBirth, nullString(customer.Country), customer.KYCTier))
+	if err != nil {
+		logger.WithError(err).Error("Failed to insert customer")
+		return nil, err
+	}
+
+	return saved, nil
+}
+
+func (repository *CustomerRepositoryPostgre) FindById(ctx context.Context, tx ports.Transaction, id int64) (*entities.Customer, error) {
+	return repository.findById(ctx, tx, id, "")
+}
+
+func (repository *CustomerRepositoryPostgre) FindByIdForUpdate(ctx context.Context, tx ports.Transaction, id int64) (*entities.Customer, error) {
+	return repository.findById(ctx, tx, id, "FOR UPDATE")
+}
+
+func (repository *CustomerRepositoryPostgre) findById(ctx context.Context, tx ports.Transaction, id int64, lock string) (*entities.Customer, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	query := "SELECT " + customerColumns + " FROM customers WHERE id = $1 " + lock
+	customer, err := scanCustomer(tx.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.WithError(err).Error("Failed to query customer by ID")
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+func (repository *CustomerRepositoryPostgre) Update(ctx context.Context, tx ports.Transaction, customer *entities.Customer) (*entities.Customer, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	query := `
+            UPDATE customers
+            SET full_name = $1, email = $2, phone = $3, date_of_birth = $4, country = $5, kyc_tier = $6,
+                updated_at = CURRENT_TIMESTAMP
+            WHERE id = $7
+            RETURNING ` + customerColumns
+	updated, err := scanCustomer(tx.QueryRowContext(ctx, query, customer.FullName, customer.Email, nullString(customer.Phone),
+		customer.DateOfBirth, nullString(customer.Country), customer.KYCTier, customer.CustomerID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.WithError(err).Error("Failed to update customer")
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (repository *CustomerRepositoryPostgre) Delete(ctx context.Context, tx ports.Transaction, id int64) error {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM customers WHERE id = $1", id)
+	if err != nil {
+		logger.WithError(err).Error("Failed to delete customer")
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get rows affected")
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func scanCustomer(row rowScanner) (*entities.Customer, error) {
+	customer := &entities.Customer{}
+	var phone, country sql.NullString
+	var dateOfBirth sql.NullTime
+	err := row.Scan(&customer.CustomerID, &customer.FullName, &customer.Email, &phone, &dateOfBirth,
+		&country, &customer.KYCTier, &customer.CreatedAt, &customer.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	customer.Phone = phone.String
+	customer.Country = country.String
+	if dateOfBirth.Valid {
+		customer.DateOfBirth = &dateOfBirth.Time
+	}
+	return customer, nil
+}
@@ -0,0 +1,89 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"transfer-system/adapters/repositories"
+	"transfer-system/domain/entities"
+	"transfer-system/internal/testutils"
+	"transfer-system/pkg/logger"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomerRepositoryPostgre_Save_Success(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	repo := &repositories.CustomerRepositoryPostgre{DB: db}
+
+	saved, err := repo.Save(ctx, tx, &entities.Customer{
+		FullName: "Jane Doe",
+		Email:    "jane.repo@example.com",
+		KYCTier:  entities.KYCTierBasic,
+	})
+
+	assert.NoError(t, err)
+	assert.NotZero(t, saved.CustomerID)
+	assert.Equal(t, entities.KYCTierBasic, saved.KYCTier)
+	assert.Nil(t, saved.DateOfBirth)
+
+	fetched, err := repo.FindById(ctx, tx, saved.CustomerID)
+	assert.NoError(t, err)
+	assert.Equal(t, "jane.repo@example.com", fetched.Email)
+}
+
+func TestCustomerRepositoryPostgre_Update_NotFound(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	repo := &repositories.CustomerRepositoryPostgre{DB: db}
+
+	_, err := repo.Update(ctx, tx, &entities.Customer{
+		CustomerID: 999999,
+		FullName:   "Nobody",
+		Email:      "nobody@example.com",
+		KYCTier:    entities.KYCTierUnverified,
+	})
+	assert.Equal(t, sql.ErrNoRows, err)
+}
+
+func TestCustomerRepositoryPostgre_Delete_WithAccounts(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	customerRepo := &repositories.CustomerRepositoryPostgre{DB: db}
+	accountRepo := &repositories.AccountRepositoryPostgre{DB: db}
+
+	customer, err := customerRepo.Save(ctx, tx, &entities.Customer{
+		FullName: "Jane Doe",
+		Email:    "jane.owner@example.com",
+		KYCTier:  entities.KYCTierUnverified,
+	})
+	require.NoError(t, err)
+
+	_, err = accountRepo.Save(ctx, tx, &entities.Account{
+		Balance:  decimal.Zero,
+		Currency: "USD",
+		Status:   entities.AccountStatusActive,
+		OwnerID:  &customer.CustomerID,
+	})
+	require.NoError(t, err)
+
+	err = customerRepo.Delete(ctx, tx, customer.CustomerID)
+	assert.Error(t, err, "Expected foreign key violation while the customer owns accounts")
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+type CustomerRepositorySQLite struct {
+	DB ports.Database
+}
+
+func (repository *CustomerRepositorySQLite) Save(ctx context.Context, tx ports.Transaction, customer *entities.Customer) (*entities.Customer, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	now := sqliteNow()
+
+	query := `
+            INSERT INTO customers (full_name, email, phone, date_of_birth, country, kyc_tier, created_at, updated_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+            RETURNING id`
+	err := tx.QueryRowContext(ctx, query, customer.FullName, customer.Email, nullString(customer.Phone),
+		sqliteNullTime(customer.DateOfBirth), nullString(customer.Country), customer.KYCTier, sqliteTime(now), sqliteTime(now)).
+		Scan(&customer.CustomerID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to insert customer")
+		return nil, err
+	}
+
+	customer.CreatedAt = now
+	customer.UpdatedAt = now
+	return customer, nil
+}
+
+// FindById and FindByIdForUpdate are alike: the transaction already holds the database write lock
+func (repository *CustomerRepositorySQLite) FindById(ctx context.Context, tx ports.Transaction, id int64) (*entities.Customer, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	query := "SELECT " + customerColumns + " FROM customers WHERE id = ?"
+	customer, err := scanCustomer(tx.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.WithError(err).Error("Failed to query customer by ID")
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+func (repository *CustomerRepositorySQLite) FindByIdForUpdate(ctx context.Context, tx ports.Transaction, id int64) (*entities.Customer, error) {
+	return repository.FindById(ctx, tx, id)
+}
+
+func (repository *CustomerRepositorySQLite) Update(ctx context.Context, tx ports.Transaction, customer *entities.Customer) (*entities.Customer, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	query := `
+            UPDATE customers
+            SET full_name = ?, email = ?, phone = ?, date_of_birth = ?, country = ?, kyc_tier = ?, updated_at = ?
+            WHERE id = ?`
+	res, err := tx.ExecContext(ctx, query, customer.FullName, customer.Email, nullString(customer.Phone),
+		sqliteNullTime(customer.DateOfBirth), nullString(customer.Country), customer.KYCTier, sqliteTime(sqliteNow()), customer.CustomerID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to update customer")
+		return nil, err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get rows affected")
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return repository.FindById(ctx, tx, customer.CustomerID)
+}
+
+func (repository *CustomerRepositorySQLite) Delete(ctx context.Context, tx ports.Transaction, id int64) error {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM customers WHERE id = ?", id)
+	if err != nil {
+		logger.WithError(err).Error("Failed to delete customer")
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get rows affected")
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
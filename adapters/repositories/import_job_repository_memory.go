@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+type ImportJobRepositoryMemory struct {
+	DB ports.Database
+}
+
+func (repository *ImportJobRepositoryMemory) Save(ctx context.Context, tx ports.Transaction, job *entities.ImportJob) (*entities.ImportJob, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = lockUnique(ctx, memoryTx, importJobsTable, job.ID, func() bool {
+		_, taken := memoryTx.Get(importJobsTable, job.ID)
+		return taken
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to insert import job")
+		return nil, err
+	}
+
+	job.CreatedAt = memoryTx.Now()
+	job.UpdatedAt = job.CreatedAt
+	stored := *job
+	if err := memoryTx.Put(importJobsTable, job.ID, &stored); err != nil {
+		logger.WithError(err).Error("Failed to insert import job")
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// FindById locks the job so concurrent resumes of the same job are serialized
+func (repository *ImportJobRepositoryMemory) FindById(ctx context.Context, tx ports.Transaction, id string) (*entities.ImportJob, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := memoryTx.Lock(ctx, importJobsTable, id); err != nil {
+		logger.WithError(err).Error("Failed to query import job by ID")
+		return nil, err
+	}
+	row, ok := memoryTx.Get(importJobsTable, id)
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	job := *row.(*entities.ImportJob)
+	return &job, nil
+}
+
+func (repository *ImportJobRepositoryMemory) Update(ctx context.Context, tx ports.Transaction, job *entities.ImportJob) error {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if err := memoryTx.Lock(ctx, importJobsTable, job.ID); err != nil {
+		logger.WithError(err).Error("Failed to update import job")
+		return err
+	}
+	row, ok := memoryTx.Get(importJobsTable, job.ID)
+	if !ok {
+		logger.WithError(sql.ErrNoRows).Error("Failed to update import job")
+		return sql.ErrNoRows
+	}
+
+	updated := *row.(*entities.ImportJob)
+	updated.Status = job.Status
+	updated.CommittedRows = job.CommittedRows
+	updated.FailedLine = job.FailedLine
+	updated.Error = job.Error
+	updated.UpdatedAt = memoryTx.Now()
+	if err := memoryTx.Put(importJobsTable, job.ID, &updated); err != nil {
+		logger.WithError(err).Error("Failed to update import job")
+		return err
+	}
+
+	job.UpdatedAt = updated.UpdatedAt
+	return nil
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+type ImportJobRepositoryPostgre struct {
+	DB ports.Database
+}
+
+func (repository *ImportJobRepositoryPostgre) Save(ctx context.Context, tx ports.Transaction, job *entities.ImportJob) (*entities.ImportJob, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	query := `
+			INSERT INTO import_jobs (id, kind, status, checksum, total_rows, committed_rows)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING created_at, updated_at`
+	err := tx.QueryRowContext(ctx, query, job.ID, job.Kind, job.Status, job.Checksum, job.TotalRows, job.CommittedRows).
+		Scan(&job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to insert import job")
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (repository *ImportJobRepositoryPostgre) FindById(ctx context.Context, tx ports.Transaction, id string) (*entities.ImportJob, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	job := &entities.ImportJob{}
+	var failedLine sql.NullInt64
+	var failure sql.NullString
+	query := `
+			SELECT id, kind, status, checksum, total_rows, committed_rows, failed_line, error, created_at, updated_at
+			FROM import_jobs
+			WHERE id = $1
+			FOR UPDATE`
+	err := tx.QueryRowContext(ctx, query, id).Scan(&job.ID, &job.Kind, &job.Status, &job.Checksum, &job.TotalRows,
+		&job.CommittedRows, &failedLine, &failure, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.WithError(err).Error("Failed to query import job by ID")
+		return nil, err
+	}
+
+	job.FailedLine = int(failedLine.Int64)
+	job.Error = failure.String
+	return job, nil
+}
+
+func (repository *ImportJobRepositoryPostgre) Update(ctx context.Context, tx ports.Transaction, job *entities.ImportJob) error {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	failedLine := sql.NullInt64{Int64: int64(job.FailedLine), Valid: job.FailedLine != 0}
+	query := `
+			UPDATE import_jobs
+			SET status = $1, committed_rows = $2, failed_line = $3, error = $4, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $5
+			RETURNING updated_at`
+	err := tx.QueryRowContext(ctx, query, job.Status, job.CommittedRows, failedLine, nullString(job.Error), job.ID).Scan(&job.UpdatedAt)
+	if err != nil {
+		logger.WithError(err).Error("Failed to update import job")
+		return err
+	}
+
+	return nil
+}
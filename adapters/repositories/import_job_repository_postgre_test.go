@@ -0,0 +1,63 @@
+package repositories_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"transfer-system/adapters/repositories"
+	"transfer-system/domain/entities"
+	"transfer-system/internal/testutils"
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportJobRepositoryPostgre_JobLifecycle(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+	repo := &repositories.ImportJobRepositoryPostgre{DB: db}
+
+	saved, err := repo.Save(ctx, tx, &entities.ImportJob{
+		ID:        "3f0c2a8e-6d55-4a56-9d6b-2b1f3c0d9e11",
+		Kind:      entities.ImportKindTransfers,
+		Status:    entities.ImportStatusRunning,
+		Checksum:  "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		TotalRows: 1200,
+	})
+	require.NoError(t, err)
+	assert.False(t, saved.CreatedAt.IsZero())
+
+	saved.Status = entities.ImportStatusFailed
+	saved.CommittedRows = 1000
+	saved.FailedLine = 1042
+	saved.Error = "line 1042: Insufficient balance"
+	require.NoError(t, repo.Update(ctx, tx, saved))
+
+	found, err := repo.FindById(ctx, tx, saved.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.ImportKindTransfers, found.Kind)
+	assert.Equal(t, entities.ImportStatusFailed, found.Status)
+	assert.Equal(t, 1000, found.CommittedRows)
+	assert.Equal(t, 1042, found.FailedLine)
+	assert.Equal(t, "line 1042: Insufficient balance", found.Error)
+
+	// resuming clears the failure
+	found.Status = entities.ImportStatusRunning
+	found.FailedLine = 0
+	found.Error = ""
+	require.NoError(t, repo.Update(ctx, tx, found))
+
+	found, err = repo.FindById(ctx, tx, saved.ID)
+	require.NoError(t, err)
+	assert.Zero(t, found.FailedLine)
+	assert.Empty(t, found.Error)
+
+	_, err = repo.FindById(ctx, tx, "00000000-0000-0000-0000-000000000000")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+type ImportJobRepositorySQLite struct {
+	DB ports.Database
+}
+
+func (repository *ImportJobRepositorySQLite) Save(ctx context.Context, tx ports.Transaction, job *entities.ImportJob) (*entities.ImportJob, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	now := sqliteNow()
+	query := `
+			INSERT INTO import_jobs (id, kind, status, checksum, total_rows, committed_rows, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := tx.ExecContext(ctx, query, job.ID, job.Kind, job.Status, job.Checksum, job.TotalRows, job.CommittedRows,
+		sqliteTime(now), sqliteTime(now))
+	if err != nil {
+		logger.WithError(err).Error("Failed to insert import job")
+		return nil, err
+	}
+
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	return job, nil
+}
+
+// FindById needs no FOR UPDATE: the transaction already holds the database write lock
+func (repository *ImportJobRepositorySQLite) FindById(ctx context.Context, tx ports.Transaction, id string) (*entities.ImportJob, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	job := &entities.ImportJob{}
+	var failedLine sql.NullInt64
+	var failure sql.NullString
+	query := `
+			SELECT id, kind, status, checksum, total_rows, committed_rows, failed_line, error, created_at, updated_at
+			FROM import_jobs
+			WHERE id = ?`
+	err := tx.QueryRowContext(ctx, query, id).Scan(&job.ID, &job.Kind, &job.Status, &job.Checksum, &job.TotalRows,
+		&job.CommittedRows, &failedLine, &failure, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.WithError(err).Error("Failed to query import job by ID")
+		return nil, err
+	}
+
+	job.FailedLine = int(failedLine.Int64)
+	job.Error = failure.String
+	return job, nil
+}
+
+func (repository *ImportJobRepositorySQLite) Update(ctx context.Context, tx ports.Transaction, job *entities.ImportJob) error {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	failedLine := sql.NullInt64{Int64: int64(job.FailedLine), Valid: job.FailedLine != 0}
+	updatedAt := sqliteNow()
+	query := `
+			UPDATE import_jobs
+			SET status = ?, committed_rows = ?, failed_line = ?, error = ?, updated_at = ?
+			WHERE id = ?`
+	res, err := tx.ExecContext(ctx, query, job.Status, job.CommittedRows, failedLine, nullString(job.Error), sqliteTime(updatedAt), job.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to update import job")
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get rows affected")
+		return err
+	}
+	if rowsAffected == 0 {
+		logger.WithError(sql.ErrNoRows).Error("Failed to update import job")
+		return sql.ErrNoRows
+	}
+
+	job.UpdatedAt = updatedAt
+	return nil
+}
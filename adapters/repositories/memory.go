@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/infrastructure/memstore"
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// tables and sequences of the memory repositories, named after their Postgres counterparts
+const (
+	accountsTable         = "accounts"
+	transactionsTable     = "transactions"
+	customersTable        = "customers"
+	balanceSnapshotsTable = "account_balance_snapshots"
+	importJobsTable       = "import_jobs"
+	apiKeysTable          = "api_keys"
+
+	accountsSequence     = "accounts_id_seq"
+	transactionsSequence = "transactions_id_seq"
+	customersSequence    = "customers_id_seq"
+	apiKeysSequence      = "api_keys_id_seq"
+)
+
+// memoryTransaction returns the memstore transaction behind tx
+func memoryTransaction(ctx context.Context, tx ports.Transaction) (*memstore.Tx, error) {
+	memoryTx, err := memstore.Unwrap(tx)
+	if err != nil {
+		logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+		logger.WithError(err).Error("Memory repository used outside a memory transaction")
+		return nil, err
+	}
+	return memoryTx, nil
+}
+
+// lockUnique serializes writers of the same unique value, then reports whether
+// a row already holds it. Like a unique index, the lock is kept until tx ends
+func lockUnique(ctx context.Context, tx *memstore.Tx, constraint string, value any, taken func() bool) error {
+	if err := tx.Lock(ctx, constraint, value); err != nil {
+		return err
+	}
+	if taken() {
+		return &memstore.Error{
+			State:   memstore.StateUniqueViolation,
+			Message: fmt.Sprintf("duplicate key value violates unique constraint %q", constraint),
+		}
+	}
+	return nil
+}
+
+// storedTime truncates t to the microseconds a TIMESTAMP column keeps
+func storedTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Microsecond)
+}
+
+// cursorTime parses the created_at value of a page cursor
+func cursorTime(value string) (time.Time, error) {
+	t, err := time.Parse(timestampLayout, value)
+	if err != nil {
+		return time.Time{}, entities.ErrInvalidCursor
+	}
+	return t, nil
+}
+
+// cloneMetadata copies metadata so callers cannot change stored rows; nil becomes empty like an empty JSONB object
+func cloneMetadata(metadata entities.Metadata) entities.Metadata {
+	clone := entities.Metadata{}
+	for key, value := range metadata {
+		clone[key] = value
+	}
+	return clone
+}
+
+// containsMetadata matches metadata @> filter
+func containsMetadata(metadata, filter entities.Metadata) bool {
+	for key, value := range filter {
+		if stored, ok := metadata[key]; !ok || stored != value {
+			return false
+		}
+	}
+	return true
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	clone := *t
+	return &clone
+}
+
+func cloneInt64(value *int64) *int64 {
+	if value == nil {
+		return nil
+	}
+	clone := *value
+	return &clone
+}
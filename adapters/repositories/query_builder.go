@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+)
+
+// queryBuilder assembles a SELECT with a dynamic WHERE clause. Conditions are
+// written with ? placeholders and every value travels as a bind argument, so
+// user input is never concatenated into the SQL text
+type queryBuilder struct {
+	base        string
+	conditions  []string
+	args        []interface{}
+	orderBy     []string
+	limit       int
+	suffix      string
+	placeholder func(n int) string
+}
+
+// newQueryBuilder starts from base, whose ? placeholders (e.g. in the select list) consume args
+func newQueryBuilder(base string, args ...interface{}) *queryBuilder {
+	if strings.Count(base, "?") != len(args) {
+		panic(fmt.Sprintf("query builder: %q expects %d args, got %d", base, strings.Count(base, "?"), len(args)))
+	}
+	return &queryBuilder{
+		base:        base,
+		args:        args,
+		placeholder: postgresPlaceholder,
+	}
+}
+
+func postgresPlaceholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// Where adds a condition joined with AND; each ? in condition consumes one arg
+func (b *queryBuilder) Where(condition string, args ...interface{}) *queryBuilder {
+	if strings.Count(condition, "?") != len(args) {
+		panic(fmt.Sprintf("query builder: %q expects %d args, got %d", condition, strings.Count(condition, "?"), len(args)))
+	}
+	b.conditions = append(b.conditions, condition)
+	b.args = append(b.args, args...)
+	return b
+}
+
+// OrderBy appends a sort expression; callers must only pass whitelisted columns
+func (b *queryBuilder) OrderBy(expressions ...string) *queryBuilder {
+	b.orderBy = append(b.orderBy, expressions...)
+	return b
+}
+
+func (b *queryBuilder) Limit(limit int) *queryBuilder {
+	b.limit = limit
+	return b
+}
+
+// Suffix is appended verbatim after LIMIT, e.g. a locking clause
+func (b *queryBuilder) Suffix(suffix string) *queryBuilder {
+	b.suffix = suffix
+	return b
+}
+
+// Build returns the final SQL with numbered placeholders and its args
+func (b *queryBuilder) Build() (string, []interface{}) {
+	var sb strings.Builder
+	sb.WriteString(b.base)
+
+	if len(b.conditions) > 0 {
+		sb.WriteString(" WHERE ")
+		sb.WriteString(strings.Join(b.conditions, " AND "))
+	}
+	if len(b.orderBy) > 0 {
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(strings.Join(b.orderBy, ", "))
+	}
+
+	args := append([]interface{}{}, b.args...)
+	if b.limit > 0 {
+		args = append(args, b.limit)
+		sb.WriteString(" LIMIT ?")
+	}
+	if b.suffix != "" {
+		sb.WriteString(" ")
+		sb.WriteString(b.suffix)
+	}
+
+	return b.numberPlaceholders(sb.String()), args
+}
+
+func (b *queryBuilder) numberPlaceholders(query string) string {
+	var sb strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			sb.WriteString(b.placeholder(n))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
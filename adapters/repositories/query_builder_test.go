@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder_Build(t *testing.T) {
+	query, args := newQueryBuilder("SELECT id FROM accounts").
+		Where("status = ?", "active").
+		Where("(balance, id) > (?::numeric, ?)", "10.5", int64(3)).
+		OrderBy("balance ASC", "id ASC").
+		Limit(11).
+		Build()
+
+	assert.Equal(t, "SELECT id FROM accounts WHERE status = $1 AND (balance, id) > ($2::numeric, $3) ORDER BY balance ASC, id ASC LIMIT $4", query)
+	assert.Equal(t, []interface{}{"active", "10.5", int64(3), 11}, args)
+}
+
+func TestQueryBuilder_BuildWithoutConditions(t *testing.T) {
+	query, args := newQueryBuilder("SELECT COUNT(*) FROM accounts").Build()
+
+	assert.Equal(t, "SELECT COUNT(*) FROM accounts", query)
+	assert.Empty(t, args)
+}
+
+func TestQueryBuilder_BuildWithBaseArgs(t *testing.T) {
+	query, args := newQueryBuilder("SELECT SUM(CASE WHEN destination_id = ? THEN amount ELSE -amount END) FROM transactions", int64(7)).
+		Where("source_id = ?", int64(7)).
+		Build()
+
+	assert.Equal(t, "SELECT SUM(CASE WHEN destination_id = $1 THEN amount ELSE -amount END) FROM transactions WHERE source_id = $2", query)
+	assert.Equal(t, []interface{}{int64(7), int64(7)}, args)
+}
+
+func TestQueryBuilder_WhereArgumentMismatch(t *testing.T) {
+	assert.Panics(t, func() {
+		newQueryBuilder("SELECT id FROM accounts").Where("status = ?")
+	})
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	encoded := encodeCursor(pageCursor{Sort: "balance", Value: "10.5", Id: 3})
+
+	decoded, err := decodeCursor(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, pageCursor{Sort: "balance", Value: "10.5", Id: 3}, decoded)
+
+	_, err = decodeCursor("not a cursor")
+	assert.Error(t, err)
+}
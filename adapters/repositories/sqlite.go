@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"time"
+
+	"transfer-system/domain/entities"
+)
+
+// sqliteTimestampLayout keeps a fixed number of fractional digits, so the
+// TEXT timestamps of the SQLite schema sort chronologically
+const sqliteTimestampLayout = "2006-01-02 15:04:05.000000"
+
+// newSQLiteQueryBuilder is newQueryBuilder with SQLite's ? placeholders
+func newSQLiteQueryBuilder(base string, args ...interface{}) *queryBuilder {
+	builder := newQueryBuilder(base, args...)
+	builder.placeholder = sqlitePlaceholder
+	return builder
+}
+
+func sqlitePlaceholder(int) string {
+	return "?"
+}
+
+// sqliteTime formats t for a TIMESTAMP or DATE column of the SQLite schema
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimestampLayout)
+}
+
+// sqliteNullTime stores a nil time as NULL
+func sqliteNullTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return sqliteTime(*t)
+}
+
+// sqliteNow is the creation time of a row; SQLite's CURRENT_TIMESTAMP has no fractional seconds
+func sqliteNow() time.Time {
+	return storedTime(time.Now())
+}
+
+// sqliteCursorTime converts the created_at value of a page cursor to the stored layout
+func sqliteCursorTime(value string) (string, error) {
+	t, err := cursorTime(value)
+	if err != nil {
+		return "", err
+	}
+	return sqliteTime(t), nil
+}
+
+// sqliteJSON binds metadata as TEXT; JSON functions reject the BLOB Metadata.Value returns
+func sqliteJSON(metadata entities.Metadata) (string, error) {
+	value, err := cloneMetadata(metadata).Value()
+	if err != nil {
+		return "", err
+	}
+	return string(value.([]byte)), nil
+}
@@ -0,0 +1,282 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/infrastructure/memstore"
+	"transfer-system/pkg/logger"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// TransactionRepositoryMemory keeps transfers in a memstore.Store next to the
+// accounts of AccountRepositoryMemory
+type TransactionRepositoryMemory struct {
+	DB ports.Database
+}
+
+func (repository *TransactionRepositoryMemory) Save(ctx context.Context, tx ports.Transaction, transaction *entities.Transaction) (*entities.Transaction, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if transaction.Type == "" {
+		transaction.Type = entities.TransactionTypeTransfer
+	}
+
+	if !transaction.Amount.IsPositive() {
+		err := &memstore.Error{
+			State:   memstore.StateCheckViolation,
+			Message: `new row for relation "transactions" violates check constraint "min_amount"`,
+		}
+		logger.WithError(err).Error("Failed to insert transaction")
+		return nil, err
+	}
+	for _, accountID := range []int64{transaction.SourceAccountID, transaction.DestinationAccountID} {
+		if _, ok := memoryTx.Get(accountsTable, accountID); !ok {
+			err := &memstore.Error{
+				State:   memstore.StateForeignKeyViolation,
+				Message: fmt.Sprintf("account %d is not present in table %q", accountID, accountsTable),
+			}
+			logger.WithError(err).Error("Failed to insert transaction")
+			return nil, err
+		}
+	}
+
+	transaction.Id = memoryTx.NextVal(transactionsSequence)
+	transaction.CreatedAt = memoryTx.Now()
+	stored := cloneTransaction(transaction)
+	// remittance columns are all NULL when no field is set, and read back as no remittance
+	if remittance := stored.Remittance; remittance != nil &&
+		remittance.CreditorReference == "" && remittance.InvoiceNumber == "" && remittance.InvoiceDate == nil {
+		stored.Remittance = nil
+	}
+	if err := memoryTx.Put(transactionsTable, transaction.Id, stored); err != nil {
+		logger.WithError(err).Error("Failed to insert transaction")
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (repository *TransactionRepositoryMemory) FindById(ctx context.Context, tx ports.Transaction, id int64) (*entities.Transaction, error) {
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	row, ok := memoryTx.Get(transactionsTable, id)
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return cloneTransaction(row.(*entities.Transaction)), nil
+}
+
+// ListByAccount pages through transfers sent or received by the account, newest first
+func (repository *TransactionRepositoryMemory) ListByAccount(ctx context.Context, tx ports.Transaction, filter entities.TransactionFilter) (*entities.TransactionPage, error) {
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var last *entities.Transaction
+	if filter.Cursor != "" {
+		cursor, err := decodeCursor(filter.Cursor)
+		if err != nil || cursor.Sort != transactionHistorySort {
+			return nil, entities.ErrInvalidCursor
+		}
+		createdAt, err := cursorTime(cursor.Value)
+		if err != nil {
+			return nil, err
+		}
+		last = &entities.Transaction{Id: cursor.Id, CreatedAt: createdAt}
+	}
+
+	history := transactionsOf(memoryTx, filter.AccountID, func(transaction *entities.Transaction) bool {
+		if filter.Reference != "" && transaction.Reference != filter.Reference {
+			return false
+		}
+		if len(filter.Types) > 0 && !containsType(filter.Types, transaction.Type) {
+			return false
+		}
+		return last == nil || createdBefore(transaction, last)
+	})
+
+	page := &entities.TransactionPage{Transactions: []*entities.Transaction{}}
+	for i := len(history) - 1; i >= 0; i-- {
+		if len(page.Transactions) == filter.Limit {
+			last := page.Transactions[len(page.Transactions)-1]
+			page.NextCursor = encodeCursor(pageCursor{
+				Sort:  transactionHistorySort,
+				Value: last.CreatedAt.UTC().Format(timestampLayout),
+				Id:    last.Id,
+			})
+			break
+		}
+		page.Transactions = append(page.Transactions, cloneTransaction(history[i]))
+	}
+
+	return page, nil
+}
+
+func (repository *TransactionRepositoryMemory) UpdateBalance(ctx context.Context, tx ports.Transaction, accountID int64, amount decimal.Decimal) error {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	// like an UPDATE, the row stays locked until tx ends
+	if err := memoryTx.Lock(ctx, accountsTable, accountID); err != nil {
+		logger.WithError(err).Error("Failed to update account balance")
+		return err
+	}
+	row, ok := memoryTx.Get(accountsTable, accountID)
+	if !ok {
+		return fmt.Errorf("no account found with id %d", accountID)
+	}
+
+	account := cloneAccount(row.(*entities.Account))
+	account.Balance = account.Balance.Add(amount)
+	account.Version++
+	if account.Kind == entities.AccountKindCustomer && account.Balance.IsNegative() {
+		err := positiveBalanceViolation()
+		logger.WithError(err).Error("Failed to update account balance")
+		return err
+	}
+	if err := memoryTx.Put(accountsTable, accountID, account); err != nil {
+		logger.WithError(err).Error("Failed to update account balance")
+		return err
+	}
+
+	return nil
+}
+
+func (repository *TransactionRepositoryMemory) StreamByAccount(ctx context.Context, tx ports.Transaction, accountID int64, after, until time.Time, fn func(*entities.Transaction) error) error {
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	after, until = storedTime(after), storedTime(until)
+	transactions := transactionsOf(memoryTx, accountID, func(transaction *entities.Transaction) bool {
+		return transaction.CreatedAt.After(after) && !transaction.CreatedAt.After(until)
+	})
+	for _, transaction := range transactions {
+		if err := fn(cloneTransaction(transaction)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repository *TransactionRepositoryMemory) NetMovement(ctx context.Context, tx ports.Transaction, accountID int64, after *time.Time, until time.Time) (decimal.Decimal, error) {
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	until = storedTime(until)
+	transactions := transactionsOf(memoryTx, accountID, func(transaction *entities.Transaction) bool {
+		if after != nil && !transaction.CreatedAt.After(storedTime(*after)) {
+			return false
+		}
+		return !transaction.CreatedAt.After(until)
+	})
+
+	// a transfer from the account to itself leaves the balance unchanged
+	total := decimal.Zero
+	for _, transaction := range transactions {
+		switch {
+		case transaction.SourceAccountID == transaction.DestinationAccountID:
+		case transaction.DestinationAccountID == accountID:
+			total = total.Add(transaction.Amount)
+		default:
+			total = total.Sub(transaction.Amount)
+		}
+	}
+
+	return total, nil
+}
+
+func (repository *TransactionRepositoryMemory) SumOutgoingByOwner(ctx context.Context, tx ports.Transaction, ownerID int64, since time.Time) (decimal.Decimal, error) {
+	memoryTx, err := memoryTransaction(ctx, tx)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	since = storedTime(since)
+	total := decimal.Zero
+	for _, row := range memoryTx.Rows(transactionsTable) {
+		transaction := row.(*entities.Transaction)
+		if transaction.CreatedAt.Before(since) {
+			continue
+		}
+		source, ok := memoryTx.Get(accountsTable, transaction.SourceAccountID)
+		if !ok {
+			continue
+		}
+		if owner := source.(*entities.Account).OwnerID; owner != nil && *owner == ownerID {
+			total = total.Add(transaction.Amount)
+		}
+	}
+
+	return total, nil
+}
+
+// transactionsOf returns the transfers sent or received by the account that match, oldest first
+func transactionsOf(tx *memstore.Tx, accountID int64, match func(*entities.Transaction) bool) []*entities.Transaction {
+	transactions := []*entities.Transaction{}
+	for _, row := range tx.Rows(transactionsTable) {
+		transaction := row.(*entities.Transaction)
+		if transaction.SourceAccountID != accountID && transaction.DestinationAccountID != accountID {
+			continue
+		}
+		if match(transaction) {
+			transactions = append(transactions, transaction)
+		}
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		return createdBefore(transactions[i], transactions[j])
+	})
+	return transactions
+}
+
+// createdBefore orders transfers by (created_at, id)
+func createdBefore(a, b *entities.Transaction) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.Id < b.Id
+}
+
+func containsType(types []entities.TransactionType, transactionType entities.TransactionType) bool {
+	for _, candidate := range types {
+		if candidate == transactionType {
+			return true
+		}
+	}
+	return false
+}
+
+func cloneTransaction(transaction *entities.Transaction) *entities.Transaction {
+	clone := *transaction
+	clone.Metadata = cloneMetadata(transaction.Metadata)
+	if transaction.Remittance != nil {
+		remittance := *transaction.Remittance
+		remittance.InvoiceDate = cloneTime(transaction.Remittance.InvoiceDate)
+		clone.Remittance = &remittance
+	}
+	return &clone
+}
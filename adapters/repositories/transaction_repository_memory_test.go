@@ -0,0 +1,229 @@
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"transfer-system/adapters/repositories"
+	"transfer-system/domain/entities"
+	"transfer-system/infrastructure/memstore"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seedMemoryAccounts(t *testing.T, db *memstore.Store, accounts ...*entities.Account) {
+	repo := &repositories.AccountRepositoryMemory{DB: db}
+	tx := beginMemory(t, db)
+	for _, account := range accounts {
+		_, err := repo.Save(memoryContext(), tx, account)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tx.Commit())
+}
+
+func TestTransactionRepositoryMemory_Save_Constraints(t *testing.T) {
+	db := memstore.New()
+	seedMemoryAccounts(t, db, &entities.Account{AccountID: 1}, &entities.Account{AccountID: 2})
+	repo := &repositories.TransactionRepositoryMemory{DB: db}
+	ctx := memoryContext()
+	tx := beginMemory(t, db)
+	defer tx.Rollback()
+
+	_, err := repo.Save(ctx, tx, &entities.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.Zero})
+	assert.Equal(t, memstore.StateCheckViolation, sqlStateOf(err))
+
+	_, err = repo.Save(ctx, tx, &entities.Transaction{SourceAccountID: 1, DestinationAccountID: 3, Amount: decimal.NewFromInt(1)})
+	assert.Equal(t, memstore.StateForeignKeyViolation, sqlStateOf(err))
+
+	saved, err := repo.Save(ctx, tx, &entities.Transaction{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.NewFromInt(1),
+		Remittance:           &entities.Remittance{},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entities.TransactionTypeTransfer, saved.Type)
+
+	found, err := repo.FindById(ctx, tx, saved.Id)
+	require.NoError(t, err)
+	assert.Nil(t, found.Remittance)
+	assert.NotNil(t, found.Metadata)
+}
+
+func TestTransactionRepositoryMemory_UpdateBalance_PositiveBalance(t *testing.T) {
+	db := memstore.New()
+	seedMemoryAccounts(t, db,
+		&entities.Account{AccountID: 1, Balance: decimal.NewFromInt(10)},
+		&entities.Account{AccountID: 2, Kind: entities.AccountKindEquity})
+	repo := &repositories.TransactionRepositoryMemory{DB: db}
+	accounts := &repositories.AccountRepositoryMemory{DB: db}
+	ctx := memoryContext()
+	tx := beginMemory(t, db)
+	defer tx.Rollback()
+
+	err := repo.UpdateBalance(ctx, tx, 1, decimal.NewFromInt(-11))
+	assert.Equal(t, memstore.StateCheckViolation, sqlStateOf(err))
+
+	// system accounts may go negative
+	require.NoError(t, repo.UpdateBalance(ctx, tx, 2, decimal.NewFromInt(-11)))
+	require.NoError(t, repo.UpdateBalance(ctx, tx, 1, decimal.NewFromInt(-10)))
+
+	account, err := accounts.FindById(ctx, tx, 1)
+	require.NoError(t, err)
+	assert.True(t, account.Balance.IsZero())
+
+	assert.Error(t, repo.UpdateBalance(ctx, tx, 3, decimal.NewFromInt(1)))
+}
+
+func TestTransactionRepositoryMemory_UpdateBalance_LocksRowUntilCommit(t *testing.T) {
+	db := memstore.New()
+	seedMemoryAccounts(t, db, &entities.Account{AccountID: 1, Balance: decimal.NewFromInt(10)})
+	repo := &repositories.TransactionRepositoryMemory{DB: db}
+	accounts := &repositories.AccountRepositoryMemory{DB: db}
+	ctx := memoryContext()
+
+	first := beginMemory(t, db)
+	require.NoError(t, repo.UpdateBalance(ctx, first, 1, decimal.NewFromInt(-4)))
+
+	second := beginMemory(t, db)
+	defer second.Rollback()
+	found := make(chan *entities.Account)
+	go func() {
+		account, _ := accounts.FindByIdForUpdate(ctx, second, 1)
+		found <- account
+	}()
+
+	select {
+	case <-found:
+		t.Fatal("locked account read before the update committed")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	require.NoError(t, first.Commit())
+	account := <-found
+	require.NotNil(t, account)
+	assert.True(t, account.Balance.Equal(decimal.NewFromInt(6)))
+}
+
+func TestTransactionRepositoryMemory_UpdateBalance_FindByIdDoesNotWait(t *testing.T) {
+	db := memstore.New()
+	seedMemoryAccounts(t, db, &entities.Account{AccountID: 1, Balance: decimal.NewFromInt(10)})
+	repo := &repositories.TransactionRepositoryMemory{DB: db}
+	accounts := &repositories.AccountRepositoryMemory{DB: db}
+	ctx := memoryContext()
+
+	first := beginMemory(t, db)
+	defer first.Rollback()
+	require.NoError(t, repo.UpdateBalance(ctx, first, 1, decimal.NewFromInt(-4)))
+
+	// a plain read sees the committed balance while the update is in flight
+	second := beginMemory(t, db)
+	defer second.Rollback()
+	account, err := accounts.FindById(ctx, second, 1)
+	require.NoError(t, err)
+	assert.True(t, account.Balance.Equal(decimal.NewFromInt(10)))
+}
+
+func TestTransactionRepositoryMemory_ListByAccount_NewestFirst(t *testing.T) {
+	db := memstore.New()
+	seedMemoryAccounts(t, db, &entities.Account{AccountID: 1}, &entities.Account{AccountID: 2}, &entities.Account{AccountID: 3})
+	repo := &repositories.TransactionRepositoryMemory{DB: db}
+	ctx := memoryContext()
+
+	ids := []int64{}
+	for _, transaction := range []*entities.Transaction{
+		{SourceAccountID: 1, DestinationAccountID: 2, Reference: "INV-1"},
+		{SourceAccountID: 2, DestinationAccountID: 1, Type: entities.TransactionTypeFee},
+		{SourceAccountID: 2, DestinationAccountID: 3},
+		{SourceAccountID: 1, DestinationAccountID: 3, Reference: "INV-1"},
+	} {
+		// one transaction per transfer, so created_at differs like in production
+		tx := beginMemory(t, db)
+		transaction.Amount = decimal.NewFromInt(1)
+		saved, err := repo.Save(ctx, tx, transaction)
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+		ids = append(ids, saved.Id)
+	}
+
+	tx := beginMemory(t, db)
+	defer tx.Rollback()
+
+	page, err := repo.ListByAccount(ctx, tx, entities.TransactionFilter{AccountID: 1, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{ids[3], ids[1]}, transactionIds(page.Transactions))
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = repo.ListByAccount(ctx, tx, entities.TransactionFilter{AccountID: 1, Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{ids[0]}, transactionIds(page.Transactions))
+	assert.Empty(t, page.NextCursor)
+
+	page, err = repo.ListByAccount(ctx, tx, entities.TransactionFilter{AccountID: 1, Reference: "INV-1", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{ids[3], ids[0]}, transactionIds(page.Transactions))
+
+	page, err = repo.ListByAccount(ctx, tx, entities.TransactionFilter{
+		AccountID: 1, Types: []entities.TransactionType{entities.TransactionTypeFee}, Limit: 10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{ids[1]}, transactionIds(page.Transactions))
+}
+
+func TestTransactionRepositoryMemory_Sums(t *testing.T) {
+	db := memstore.New()
+	ownerID := int64(1)
+	customers := &repositories.CustomerRepositoryMemory{DB: db}
+	tx := beginMemory(t, db)
+	customer, err := customers.Save(memoryContext(), tx, &entities.Customer{FullName: "Jane", Email: "jane@example.com"})
+	require.NoError(t, err)
+	require.Equal(t, ownerID, customer.CustomerID)
+	require.NoError(t, tx.Commit())
+
+	seedMemoryAccounts(t, db, &entities.Account{AccountID: 1, OwnerID: &ownerID}, &entities.Account{AccountID: 2})
+	repo := &repositories.TransactionRepositoryMemory{DB: db}
+	ctx := memoryContext()
+	tx = beginMemory(t, db)
+	defer tx.Rollback()
+
+	for _, transaction := range []*entities.Transaction{
+		{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(5)},
+		{SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.NewFromInt(2)},
+	} {
+		_, err := repo.Save(ctx, tx, transaction)
+		require.NoError(t, err)
+	}
+	now := time.Now()
+
+	net, err := repo.NetMovement(ctx, tx, 1, nil, now)
+	require.NoError(t, err)
+	assert.True(t, net.Equal(decimal.NewFromInt(-3)), net.String())
+
+	outgoing, err := repo.SumOutgoingByOwner(ctx, tx, ownerID, now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.True(t, outgoing.Equal(decimal.NewFromInt(5)), outgoing.String())
+
+	streamed := 0
+	err = repo.StreamByAccount(ctx, tx, 2, now.Add(-time.Hour), now, func(*entities.Transaction) error {
+		streamed++
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, streamed)
+}
+
+func TestTransactionRepositoryMemory_RequiresMemoryTransaction(t *testing.T) {
+	repo := &repositories.TransactionRepositoryMemory{}
+	_, err := repo.FindById(memoryContext(), nil, 1)
+	assert.Error(t, err)
+}
+
+func transactionIds(transactions []*entities.Transaction) []int64 {
+	ids := make([]int64, len(transactions))
+	for i, transaction := range transactions {
+		ids[i] = transaction.Id
+	}
+	return ids
+}
@@ -2,7 +2,10 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"transfer-system/domain/entities"
 	"transfer-system/domain/ports"
@@ -19,28 +22,155 @@ type TransactionRepositoryPostgre struct {
 func (repository *TransactionRepositoryPostgre) Save(ctx context.Context, tx ports.Transaction, transaction *entities.Transaction) (*entities.Transaction, error) {
 	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
 
-	var transactionId int64
+	remittance := transaction.Remittance
+	if remittance == nil {
+		remittance = &entities.Remittance{}
+	}
+	if transaction.Type == "" {
+		transaction.Type = entities.TransactionTypeTransfer
+	}
+
 	query := `
-            INSERT INTO transactions (source_id, destination_id, amount)
-            VALUES ($1, $2, $3)
-			RETURNING id`
-	err := tx.QueryRowContext(ctx, query, transaction.SourceAccountID, transaction.DestinationAccountID, transaction.Amount).Scan(&transactionId)
+            INSERT INTO transactions (type, source_id, destination_id, amount, description, reference,
+                remittance_creditor_reference, remittance_invoice_number, remittance_invoice_date, metadata)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING id, created_at`
+	err := tx.QueryRowContext(ctx, query, transaction.Type, transaction.SourceAccountID, transaction.DestinationAccountID, transaction.Amount,
+		nullString(transaction.Description), nullString(transaction.Reference), nullString(remittance.CreditorReference),
+		nullString(remittance.InvoiceNumber), remittance.InvoiceDate, transaction.Metadata).Scan(&transaction.Id, &transaction.CreatedAt)
 	if err != nil {
 		logger.WithError(err).Error("Failed to insert transaction")
 		return nil, err
 	}
 
-	transaction.Id = transactionId
+	return transaction, nil
+}
+
+// transactionCopyColumns are the columns SaveMany copies; created_at takes its default
+var transactionCopyColumns = []string{"type", "source_id", "destination_id", "amount", "description", "reference",
+	"remittance_creditor_reference", "remittance_invoice_number", "remittance_invoice_date", "metadata"}
+
+// SaveMany copies the transactions into the table with COPY FROM, which needs the pgx driver
+func (repository *TransactionRepositoryPostgre) SaveMany(ctx context.Context, tx ports.Transaction, transactions []*entities.Transaction) error {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	copier, err := copier(tx)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]interface{}, len(transactions))
+	for i, transaction := range transactions {
+		remittance := transaction.Remittance
+		if remittance == nil {
+			remittance = &entities.Remittance{}
+		}
+		if transaction.Type == "" {
+			transaction.Type = entities.TransactionTypeTransfer
+		}
+		rows[i] = []interface{}{string(transaction.Type), transaction.SourceAccountID, transaction.DestinationAccountID, transaction.Amount,
+			nullString(transaction.Description), nullString(transaction.Reference), nullString(remittance.CreditorReference),
+			nullString(remittance.InvoiceNumber), remittance.InvoiceDate, transaction.Metadata}
+	}
+
+	if _, err := copier.CopyFrom(ctx, "transactions", transactionCopyColumns, rows); err != nil {
+		logger.WithError(err).Error("Failed to copy transactions")
+		return err
+	}
+
+	return nil
+}
+
+func (repository *TransactionRepositoryPostgre) FindById(ctx context.Context, tx ports.Transaction, id int64) (*entities.Transaction, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	query := "SELECT " + transactionColumns + " FROM transactions WHERE id = $1"
+	transaction, err := scanTransaction(tx.QueryRowContext(ctx, query, id))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.WithError(err).Error("Failed to query transaction by ID")
+		return nil, err
+	}
 
 	return transaction, nil
 }
 
+// transactionHistorySort tags history cursors so they are not accepted by other listings
+const transactionHistorySort = "history"
+
+// ListByAccount pages through transfers sent or received by the account, newest first
+func (repository *TransactionRepositoryPostgre) ListByAccount(ctx context.Context, tx ports.Transaction, filter entities.TransactionFilter) (*entities.TransactionPage, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	builder := newQueryBuilder("SELECT "+transactionColumns+" FROM transactions").
+		Where("(source_id = ? OR destination_id = ?)", filter.AccountID, filter.AccountID)
+	if filter.Reference != "" {
+		builder.Where("reference = ?", filter.Reference)
+	}
+	if len(filter.Types) > 0 {
+		placeholders := make([]string, len(filter.Types))
+		types := make([]interface{}, len(filter.Types))
+		for i, transactionType := range filter.Types {
+			placeholders[i] = "?"
+			types[i] = transactionType
+		}
+		builder.Where("type IN ("+strings.Join(placeholders, ", ")+")", types...)
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := decodeCursor(filter.Cursor)
+		if err != nil || cursor.Sort != transactionHistorySort {
+			return nil, entities.ErrInvalidCursor
+		}
+		builder.Where("(created_at, id) < (?::timestamp, ?)", cursor.Value, cursor.Id)
+	}
+
+	// fetch one extra row to learn whether another page exists
+	builder.OrderBy("created_at DESC", "id DESC").Limit(filter.Limit + 1)
+
+	query, args := builder.Build()
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list transactions")
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &entities.TransactionPage{Transactions: []*entities.Transaction{}}
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			logger.WithError(err).Error("Failed to scan transaction")
+			return nil, err
+		}
+		page.Transactions = append(page.Transactions, transaction)
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("Failed to iterate transactions")
+		return nil, err
+	}
+
+	if len(page.Transactions) > filter.Limit {
+		page.Transactions = page.Transactions[:filter.Limit]
+		last := page.Transactions[len(page.Transactions)-1]
+		page.NextCursor = encodeCursor(pageCursor{
+			Sort:  transactionHistorySort,
+			Value: last.CreatedAt.UTC().Format(timestampLayout),
+			Id:    last.Id,
+		})
+	}
+
+	return page, nil
+}
+
 func (repository *TransactionRepositoryPostgre) UpdateBalance(ctx context.Context, tx ports.Transaction, accountID int64, amount decimal.Decimal) error {
 	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
 
 	query := `
 			UPDATE accounts
-			SET balance = balance + $1
+			SET balance = balance + $1, version = version + 1
 			WHERE id = $2`
 	res, err := tx.ExecContext(ctx, query, amount, accountID)
 	if err != nil {
@@ -59,3 +189,108 @@ func (repository *TransactionRepositoryPostgre) UpdateBalance(ctx context.Contex
 
 	return nil
 }
+
+func (repository *TransactionRepositoryPostgre) StreamByAccount(ctx context.Context, tx ports.Transaction, accountID int64, after, until time.Time, fn func(*entities.Transaction) error) error {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	query, args := newQueryBuilder("SELECT "+transactionColumns+" FROM transactions").
+		Where("(source_id = ? OR destination_id = ?)", accountID, accountID).
+		Where("created_at > ?", after.UTC().Format(timestampLayout)).
+		Where("created_at <= ?", until.UTC().Format(timestampLayout)).
+		OrderBy("created_at ASC", "id ASC").
+		Build()
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.WithError(err).Error("Failed to stream transactions")
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			logger.WithError(err).Error("Failed to scan transaction")
+			return err
+		}
+		if err := fn(transaction); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("Failed to iterate transactions")
+		return err
+	}
+
+	return nil
+}
+
+func (repository *TransactionRepositoryPostgre) NetMovement(ctx context.Context, tx ports.Transaction, accountID int64, after *time.Time, until time.Time) (decimal.Decimal, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	// a transfer from the account to itself leaves the balance unchanged
+	builder := newQueryBuilder(`
+			SELECT COALESCE(SUM(CASE
+				WHEN source_id = destination_id THEN 0
+				WHEN destination_id = ? THEN amount
+				ELSE -amount END), 0)
+			FROM transactions`, accountID).
+		Where("(source_id = ? OR destination_id = ?)", accountID, accountID).
+		Where("created_at <= ?", until.UTC().Format(timestampLayout))
+	if after != nil {
+		builder.Where("created_at > ?", after.UTC().Format(timestampLayout))
+	}
+
+	var total decimal.Decimal
+	query, args := builder.Build()
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		logger.WithError(err).Error("Failed to sum account transactions")
+		return decimal.Zero, err
+	}
+
+	return total, nil
+}
+
+func (repository *TransactionRepositoryPostgre) SumOutgoingByOwner(ctx context.Context, tx ports.Transaction, ownerID int64, since time.Time) (decimal.Decimal, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	var total decimal.Decimal
+	query := `
+			SELECT COALESCE(SUM(t.amount), 0)
+			FROM transactions t
+			JOIN accounts a ON a.id = t.source_id
+			WHERE a.owner_id = $1 AND t.created_at >= $2`
+	err := tx.QueryRowContext(ctx, query, ownerID, since.UTC().Format(timestampLayout)).Scan(&total)
+	if err != nil {
+		logger.WithError(err).Error("Failed to sum outgoing transactions")
+		return decimal.Zero, err
+	}
+
+	return total, nil
+}
+
+const transactionColumns = `id, type, source_id, destination_id, amount, description, reference,
+	remittance_creditor_reference, remittance_invoice_number, remittance_invoice_date, metadata, created_at`
+
+func scanTransaction(row rowScanner) (*entities.Transaction, error) {
+	transaction := &entities.Transaction{}
+	var description, reference, creditorReference, invoiceNumber sql.NullString
+	var invoiceDate sql.NullTime
+	err := row.Scan(&transaction.Id, &transaction.Type, &transaction.SourceAccountID, &transaction.DestinationAccountID, &transaction.Amount,
+		&description, &reference, &creditorReference, &invoiceNumber, &invoiceDate, &transaction.Metadata, &transaction.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction.Description = description.String
+	transaction.Reference = reference.String
+	if creditorReference.Valid || invoiceNumber.Valid || invoiceDate.Valid {
+		transaction.Remittance = &entities.Remittance{
+			CreditorReference: creditorReference.String,
+			InvoiceNumber:     invoiceNumber.String,
+		}
+		if invoiceDate.Valid {
+			transaction.Remittance.InvoiceDate = &invoiceDate.Time
+		}
+	}
+	return transaction, nil
+}
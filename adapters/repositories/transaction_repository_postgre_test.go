@@ -3,9 +3,11 @@ package repositories_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"transfer-system/adapters/repositories"
 	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
 	"transfer-system/internal/testutils"
 	"transfer-system/pkg/logger"
 
@@ -110,3 +112,225 @@ func TestTransactionRepositoryPostgre_UpdateBalance_Invalid(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no account found", "Expected error related to missing account ID")
 }
+
+func TestTransactionRepositoryPostgre_SumOutgoingByOwner(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	customerRepo := &repositories.CustomerRepositoryPostgre{DB: db}
+	accountRepo := &repositories.AccountRepositoryPostgre{DB: db}
+	repo := &repositories.TransactionRepositoryPostgre{DB: db}
+
+	customer, err := customerRepo.Save(ctx, tx, &entities.Customer{
+		FullName: "Jane Doe",
+		Email:    "jane.sum@example.com",
+		KYCTier:  entities.KYCTierUnverified,
+	})
+	require.NoError(t, err)
+
+	owned := &entities.Account{AccountID: 2001, Balance: decimal.NewFromInt(1000), Currency: "USD", Status: entities.AccountStatusActive, OwnerID: &customer.CustomerID}
+	other := &entities.Account{AccountID: 2002, Balance: decimal.NewFromInt(1000), Currency: "USD", Status: entities.AccountStatusActive}
+	_, err = accountRepo.Save(ctx, tx, owned)
+	require.NoError(t, err)
+	_, err = accountRepo.Save(ctx, tx, other)
+	require.NoError(t, err)
+
+	for _, transfer := range []*entities.Transaction{
+		{SourceAccountID: owned.AccountID, DestinationAccountID: other.AccountID, Amount: decimal.NewFromInt(100)},
+		{SourceAccountID: owned.AccountID, DestinationAccountID: other.AccountID, Amount: decimal.NewFromInt(50)},
+		{SourceAccountID: other.AccountID, DestinationAccountID: owned.AccountID, Amount: decimal.NewFromInt(70)},
+	} {
+		_, err = repo.Save(ctx, tx, transfer)
+		require.NoError(t, err)
+	}
+
+	total, err := repo.SumOutgoingByOwner(ctx, tx, customer.CustomerID, time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(150).Equal(total))
+}
+
+func TestTransactionRepositoryPostgre_FindById_Remittance(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	accountRepo := &repositories.AccountRepositoryPostgre{DB: db}
+	for _, id := range []int64{1301, 1302} {
+		_, err := accountRepo.Save(ctx, tx, &entities.Account{AccountID: id, Balance: decimal.NewFromInt(1000)})
+		require.NoError(t, err)
+	}
+
+	repo := &repositories.TransactionRepositoryPostgre{DB: db}
+
+	invoiceDate := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	saved, err := repo.Save(ctx, tx, &entities.Transaction{
+		SourceAccountID:      1301,
+		DestinationAccountID: 1302,
+		Amount:               decimal.NewFromInt(100),
+		Description:          "March rent",
+		Reference:            "PAY-000123",
+		Remittance: &entities.Remittance{
+			CreditorReference: "RF18539007547034",
+			InvoiceNumber:     "INV-1",
+			InvoiceDate:       &invoiceDate,
+		},
+	})
+	require.NoError(t, err)
+
+	found, err := repo.FindById(ctx, tx, saved.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "March rent", found.Description)
+	assert.Equal(t, "PAY-000123", found.Reference)
+	require.NotNil(t, found.Remittance)
+	assert.Equal(t, "RF18539007547034", found.Remittance.CreditorReference)
+	assert.Equal(t, "2025-03-01", found.Remittance.InvoiceDate.Format("2006-01-02"))
+}
+
+func TestTransactionRepositoryPostgre_ListByAccount_Pagination(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	accountRepo := &repositories.AccountRepositoryPostgre{DB: db}
+	for _, id := range []int64{1401, 1402, 1403} {
+		_, err := accountRepo.Save(ctx, tx, &entities.Account{AccountID: id, Balance: decimal.NewFromInt(1000)})
+		require.NoError(t, err)
+	}
+
+	repo := &repositories.TransactionRepositoryPostgre{DB: db}
+
+	var ids []int64
+	for _, pair := range [][2]int64{{1401, 1402}, {1402, 1401}, {1403, 1402}} {
+		saved, err := repo.Save(ctx, tx, &entities.Transaction{
+			SourceAccountID:      pair[0],
+			DestinationAccountID: pair[1],
+			Amount:               decimal.NewFromInt(1),
+		})
+		require.NoError(t, err)
+		ids = append(ids, saved.Id)
+	}
+
+	filter := entities.TransactionFilter{AccountID: 1401, Limit: 1}
+	firstPage, err := repo.ListByAccount(ctx, tx, filter)
+	require.NoError(t, err)
+	require.Len(t, firstPage.Transactions, 1)
+	assert.Equal(t, ids[1], firstPage.Transactions[0].Id)
+	assert.NotEmpty(t, firstPage.NextCursor)
+
+	filter.Cursor = firstPage.NextCursor
+	secondPage, err := repo.ListByAccount(ctx, tx, filter)
+	require.NoError(t, err)
+	require.Len(t, secondPage.Transactions, 1)
+	assert.Equal(t, ids[0], secondPage.Transactions[0].Id)
+	assert.Empty(t, secondPage.NextCursor)
+}
+
+func TestTransactionRepositoryPostgre_ListByAccount_Types(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	accountRepo := &repositories.AccountRepositoryPostgre{DB: db}
+	_, err := accountRepo.Save(ctx, tx, &entities.Account{AccountID: 1501, Balance: decimal.NewFromInt(1000)})
+	require.NoError(t, err)
+	_, err = accountRepo.Save(ctx, tx, &entities.Account{AccountID: 1502, Balance: decimal.Zero, Kind: entities.AccountKindSettlement})
+	require.NoError(t, err)
+
+	repo := &repositories.TransactionRepositoryPostgre{DB: db}
+	for _, transaction := range []*entities.Transaction{
+		{Type: entities.TransactionTypeDeposit, SourceAccountID: 1502, DestinationAccountID: 1501, Amount: decimal.NewFromInt(5)},
+		{Type: entities.TransactionTypeWithdrawal, SourceAccountID: 1501, DestinationAccountID: 1502, Amount: decimal.NewFromInt(2)},
+		{SourceAccountID: 1501, DestinationAccountID: 1502, Amount: decimal.NewFromInt(1)},
+	} {
+		_, err := repo.Save(ctx, tx, transaction)
+		require.NoError(t, err)
+	}
+
+	page, err := repo.ListByAccount(ctx, tx, entities.TransactionFilter{AccountID: 1501, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Transactions, 3)
+	assert.Equal(t, entities.TransactionTypeTransfer, page.Transactions[0].Type)
+
+	page, err = repo.ListByAccount(ctx, tx, entities.TransactionFilter{
+		AccountID: 1501,
+		Types:     []entities.TransactionType{entities.TransactionTypeDeposit, entities.TransactionTypeWithdrawal},
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Transactions, 2)
+	assert.Equal(t, entities.TransactionTypeWithdrawal, page.Transactions[0].Type)
+	assert.Equal(t, entities.TransactionTypeDeposit, page.Transactions[1].Type)
+}
+
+func TestTransactionRepositoryPostgre_StreamByAccount(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	accountRepo := &repositories.AccountRepositoryPostgre{DB: db}
+	for _, id := range []int64{1601, 1602} {
+		_, err := accountRepo.Save(ctx, tx, &entities.Account{AccountID: id, Balance: decimal.NewFromInt(1000)})
+		require.NoError(t, err)
+	}
+
+	repo := &repositories.TransactionRepositoryPostgre{DB: db}
+	var ids []int64
+	for _, pair := range [][2]int64{{1601, 1602}, {1602, 1601}} {
+		saved, err := repo.Save(ctx, tx, &entities.Transaction{SourceAccountID: pair[0], DestinationAccountID: pair[1], Amount: decimal.NewFromInt(1)})
+		require.NoError(t, err)
+		ids = append(ids, saved.Id)
+	}
+
+	var streamed []int64
+	now := time.Now().UTC()
+	err := repo.StreamByAccount(ctx, tx, 1601, now.Add(-time.Hour), now.Add(time.Hour), func(transaction *entities.Transaction) error {
+		streamed = append(streamed, transaction.Id)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ids, streamed)
+}
+
+func TestTransactionRepositoryPostgre_SaveMany(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+	if _, ok := tx.(ports.Copier); !ok {
+		t.Skip("COPY needs DB_DRIVER=pgx")
+	}
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.New())
+
+	accountRepo := &repositories.AccountRepositoryPostgre{DB: db}
+	for _, id := range []int64{78000001, 78000002} {
+		_, err := accountRepo.Save(ctx, tx, &entities.Account{AccountID: id, Balance: decimal.NewFromInt(100)})
+		require.NoError(t, err)
+	}
+
+	repo := &repositories.TransactionRepositoryPostgre{DB: db}
+	transactions := []*entities.Transaction{
+		{SourceAccountID: 78000001, DestinationAccountID: 78000002, Amount: decimal.RequireFromString("0.1"), Reference: "bulk-1"},
+		{SourceAccountID: 78000002, DestinationAccountID: 78000001, Amount: decimal.RequireFromString("0.2"), Reference: "bulk-2"},
+	}
+	require.NoError(t, repo.SaveMany(ctx, tx, transactions))
+
+	page, err := repo.ListByAccount(ctx, tx, entities.TransactionFilter{AccountID: 78000001, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, page.Transactions, 2)
+
+	// balances are left to the caller
+	account, err := accountRepo.FindById(ctx, tx, 78000001)
+	require.NoError(t, err)
+	assert.Equal(t, "100", account.Balance.String())
+}
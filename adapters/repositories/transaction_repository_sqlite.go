@@ -0,0 +1,243 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/pkg/logger"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+type TransactionRepositorySQLite struct {
+	DB ports.Database
+}
+
+func (repository *TransactionRepositorySQLite) Save(ctx context.Context, tx ports.Transaction, transaction *entities.Transaction) (*entities.Transaction, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	remittance := transaction.Remittance
+	if remittance == nil {
+		remittance = &entities.Remittance{}
+	}
+	if transaction.Type == "" {
+		transaction.Type = entities.TransactionTypeTransfer
+	}
+	metadata, err := sqliteJSON(transaction.Metadata)
+	if err != nil {
+		logger.WithError(err).Error("Failed to encode transaction metadata")
+		return nil, err
+	}
+	createdAt := sqliteNow()
+
+	query := `
+            INSERT INTO transactions (type, source_id, destination_id, amount, description, reference,
+                remittance_creditor_reference, remittance_invoice_number, remittance_invoice_date, metadata, created_at)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			RETURNING id`
+	err = tx.QueryRowContext(ctx, query, transaction.Type, transaction.SourceAccountID, transaction.DestinationAccountID, transaction.Amount,
+		nullString(transaction.Description), nullString(transaction.Reference), nullString(remittance.CreditorReference),
+		nullString(remittance.InvoiceNumber), sqliteNullTime(remittance.InvoiceDate), metadata, sqliteTime(createdAt)).Scan(&transaction.Id)
+	if err != nil {
+		logger.WithError(err).Error("Failed to insert transaction")
+		return nil, err
+	}
+
+	transaction.CreatedAt = createdAt
+	return transaction, nil
+}
+
+func (repository *TransactionRepositorySQLite) FindById(ctx context.Context, tx ports.Transaction, id int64) (*entities.Transaction, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+	query := "SELECT " + transactionColumns + " FROM transactions WHERE id = ?"
+	transaction, err := scanTransaction(tx.QueryRowContext(ctx, query, id))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		logger.WithError(err).Error("Failed to query transaction by ID")
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// ListByAccount pages through transfers sent or received by the account, newest first
+func (repository *TransactionRepositorySQLite) ListByAccount(ctx context.Context, tx ports.Transaction, filter entities.TransactionFilter) (*entities.TransactionPage, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	builder := newSQLiteQueryBuilder("SELECT "+transactionColumns+" FROM transactions").
+		Where("(source_id = ? OR destination_id = ?)", filter.AccountID, filter.AccountID)
+	if filter.Reference != "" {
+		builder.Where("reference = ?", filter.Reference)
+	}
+	if len(filter.Types) > 0 {
+		placeholders := make([]string, len(filter.Types))
+		types := make([]interface{}, len(filter.Types))
+		for i, transactionType := range filter.Types {
+			placeholders[i] = "?"
+			types[i] = transactionType
+		}
+		builder.Where("type IN ("+strings.Join(placeholders, ", ")+")", types...)
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := decodeCursor(filter.Cursor)
+		if err != nil || cursor.Sort != transactionHistorySort {
+			return nil, entities.ErrInvalidCursor
+		}
+		createdAt, err := sqliteCursorTime(cursor.Value)
+		if err != nil {
+			return nil, err
+		}
+		builder.Where("(created_at, id) < (?, ?)", createdAt, cursor.Id)
+	}
+
+	// fetch one extra row to learn whether another page exists
+	builder.OrderBy("created_at DESC", "id DESC").Limit(filter.Limit + 1)
+
+	query, args := builder.Build()
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list transactions")
+		return nil, err
+	}
+	defer rows.Close()
+
+	page := &entities.TransactionPage{Transactions: []*entities.Transaction{}}
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			logger.WithError(err).Error("Failed to scan transaction")
+			return nil, err
+		}
+		page.Transactions = append(page.Transactions, transaction)
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("Failed to iterate transactions")
+		return nil, err
+	}
+
+	if len(page.Transactions) > filter.Limit {
+		page.Transactions = page.Transactions[:filter.Limit]
+		last := page.Transactions[len(page.Transactions)-1]
+		page.NextCursor = encodeCursor(pageCursor{
+			Sort:  transactionHistorySort,
+			Value: last.CreatedAt.UTC().Format(timestampLayout),
+			Id:    last.Id,
+		})
+	}
+
+	return page, nil
+}
+
+// UpdateBalance adds amount with decimal_add; balance + amount would convert the text to floating point
+func (repository *TransactionRepositorySQLite) UpdateBalance(ctx context.Context, tx ports.Transaction, accountID int64, amount decimal.Decimal) error {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	query := `
+			UPDATE accounts
+			SET balance = decimal_add(balance, ?), version = version + 1
+			WHERE id = ?`
+	res, err := tx.ExecContext(ctx, query, amount, accountID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to update account balance")
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		logger.WithError(err).Error("Failed to get rows affected")
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("no account found with id %d", accountID)
+	}
+
+	return nil
+}
+
+func (repository *TransactionRepositorySQLite) StreamByAccount(ctx context.Context, tx ports.Transaction, accountID int64, after, until time.Time, fn func(*entities.Transaction) error) error {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	query, args := newSQLiteQueryBuilder("SELECT "+transactionColumns+" FROM transactions").
+		Where("(source_id = ? OR destination_id = ?)", accountID, accountID).
+		Where("created_at > ?", sqliteTime(after)).
+		Where("created_at <= ?", sqliteTime(until)).
+		OrderBy("created_at ASC", "id ASC").
+		Build()
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.WithError(err).Error("Failed to stream transactions")
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			logger.WithError(err).Error("Failed to scan transaction")
+			return err
+		}
+		if err := fn(transaction); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		logger.WithError(err).Error("Failed to iterate transactions")
+		return err
+	}
+
+	return nil
+}
+
+func (repository *TransactionRepositorySQLite) NetMovement(ctx context.Context, tx ports.Transaction, accountID int64, after *time.Time, until time.Time) (decimal.Decimal, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	// a transfer from the account to itself leaves the balance unchanged
+	builder := newSQLiteQueryBuilder(`
+			SELECT decimal_sum(CASE
+				WHEN source_id = destination_id THEN '0'
+				WHEN destination_id = ? THEN amount
+				ELSE '-' || amount END)
+			FROM transactions`, accountID).
+		Where("(source_id = ? OR destination_id = ?)", accountID, accountID).
+		Where("created_at <= ?", sqliteTime(until))
+	if after != nil {
+		builder.Where("created_at > ?", sqliteTime(*after))
+	}
+
+	var total decimal.Decimal
+	query, args := builder.Build()
+	if err := tx.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		logger.WithError(err).Error("Failed to sum account transactions")
+		return decimal.Zero, err
+	}
+
+	return total, nil
+}
+
+func (repository *TransactionRepositorySQLite) SumOutgoingByOwner(ctx context.Context, tx ports.Transaction, ownerID int64, since time.Time) (decimal.Decimal, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	var total decimal.Decimal
+	query := `
+			SELECT decimal_sum(t.amount)
+			FROM transactions t
+			JOIN accounts a ON a.id = t.source_id
+			WHERE a.owner_id = ? AND t.created_at >= ?`
+	err := tx.QueryRowContext(ctx, query, ownerID, sqliteTime(since)).Scan(&total)
+	if err != nil {
+		logger.WithError(err).Error("Failed to sum outgoing transactions")
+		return decimal.Zero, err
+	}
+
+	return total, nil
+}
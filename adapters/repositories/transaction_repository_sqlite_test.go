@@ -0,0 +1,186 @@
+package repositories_test
+
+import (
+	"testing"
+	"time"
+
+	"transfer-system/adapters/repositories"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seedSQLiteAccounts(t *testing.T, db ports.Database, accounts ...*entities.Account) {
+	repo := &repositories.AccountRepositorySQLite{DB: db}
+	tx := beginMemory(t, db)
+	for _, account := range accounts {
+		_, err := repo.Save(memoryContext(), tx, account)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tx.Commit())
+}
+
+func TestTransactionRepositorySQLite_Save_RoundTrip(t *testing.T) {
+	db := sqliteDatabase(t)
+	seedSQLiteAccounts(t, db, &entities.Account{AccountID: 1}, &entities.Account{AccountID: 2})
+	repo := &repositories.TransactionRepositorySQLite{DB: db}
+	ctx := memoryContext()
+	tx := beginMemory(t, db)
+	defer tx.Rollback()
+
+	_, err := repo.Save(ctx, tx, &entities.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.Zero})
+	assert.Equal(t, "23514", sqlStateOf(err))
+
+	_, err = repo.Save(ctx, tx, &entities.Transaction{SourceAccountID: 1, DestinationAccountID: 3, Amount: decimal.NewFromInt(1)})
+	assert.Equal(t, "23503", sqlStateOf(err))
+
+	invoiceDate := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	saved, err := repo.Save(ctx, tx, &entities.Transaction{
+		SourceAccountID:      1,
+		DestinationAccountID: 2,
+		Amount:               decimal.RequireFromString("12.345"),
+		Reference:            "INV-1",
+		Remittance:           &entities.Remittance{InvoiceNumber: "2024-17", InvoiceDate: &invoiceDate},
+		Metadata:             entities.Metadata{"channel": "web"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entities.TransactionTypeTransfer, saved.Type)
+
+	found, err := repo.FindById(ctx, tx, saved.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "12.345", found.Amount.String())
+	assert.Equal(t, "INV-1", found.Reference)
+	require.NotNil(t, found.Remittance)
+	assert.Equal(t, "2024-17", found.Remittance.InvoiceNumber)
+	require.NotNil(t, found.Remittance.InvoiceDate)
+	assert.True(t, invoiceDate.Equal(*found.Remittance.InvoiceDate))
+	assert.Equal(t, "web", found.Metadata["channel"])
+	assert.True(t, saved.CreatedAt.Equal(found.CreatedAt))
+}
+
+func TestTransactionRepositorySQLite_UpdateBalance_IsExact(t *testing.T) {
+	db := sqliteDatabase(t)
+	seedSQLiteAccounts(t, db,
+		&entities.Account{AccountID: 1, Balance: decimal.RequireFromString("0.3")},
+		&entities.Account{AccountID: 2, Kind: entities.AccountKindEquity})
+	repo := &repositories.TransactionRepositorySQLite{DB: db}
+	accounts := &repositories.AccountRepositorySQLite{DB: db}
+	ctx := memoryContext()
+	tx := beginMemory(t, db)
+	defer tx.Rollback()
+
+	err := repo.UpdateBalance(ctx, tx, 1, decimal.RequireFromString("-0.31"))
+	assert.Equal(t, "23514", sqlStateOf(err))
+
+	// system accounts may go negative
+	require.NoError(t, repo.UpdateBalance(ctx, tx, 2, decimal.NewFromInt(-11)))
+	require.NoError(t, repo.UpdateBalance(ctx, tx, 1, decimal.RequireFromString("-0.1")))
+	require.NoError(t, repo.UpdateBalance(ctx, tx, 1, decimal.RequireFromString("-0.2")))
+
+	account, err := accounts.FindById(ctx, tx, 1)
+	require.NoError(t, err)
+	assert.True(t, account.Balance.IsZero(), account.Balance.String())
+
+	assert.Error(t, repo.UpdateBalance(ctx, tx, 3, decimal.NewFromInt(1)))
+}
+
+func TestTransactionRepositorySQLite_ListByAccount_NewestFirst(t *testing.T) {
+	db := sqliteDatabase(t)
+	seedSQLiteAccounts(t, db, &entities.Account{AccountID: 1}, &entities.Account{AccountID: 2}, &entities.Account{AccountID: 3})
+	repo := &repositories.TransactionRepositorySQLite{DB: db}
+	ctx := memoryContext()
+
+	ids := []int64{}
+	for _, transaction := range []*entities.Transaction{
+		{SourceAccountID: 1, DestinationAccountID: 2, Reference: "INV-1"},
+		{SourceAccountID: 2, DestinationAccountID: 1, Type: entities.TransactionTypeFee},
+		{SourceAccountID: 2, DestinationAccountID: 3},
+		{SourceAccountID: 1, DestinationAccountID: 3, Reference: "INV-1"},
+	} {
+		tx := beginMemory(t, db)
+		transaction.Amount = decimal.NewFromInt(1)
+		saved, err := repo.Save(ctx, tx, transaction)
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+		ids = append(ids, saved.Id)
+	}
+
+	tx := beginMemory(t, db)
+	defer tx.Rollback()
+
+	page, err := repo.ListByAccount(ctx, tx, entities.TransactionFilter{AccountID: 1, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{ids[3], ids[1]}, transactionIds(page.Transactions))
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = repo.ListByAccount(ctx, tx, entities.TransactionFilter{AccountID: 1, Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{ids[0]}, transactionIds(page.Transactions))
+	assert.Empty(t, page.NextCursor)
+
+	page, err = repo.ListByAccount(ctx, tx, entities.TransactionFilter{
+		AccountID: 1, Types: []entities.TransactionType{entities.TransactionTypeFee}, Limit: 10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{ids[1]}, transactionIds(page.Transactions))
+}
+
+func TestTransactionRepositorySQLite_Sums(t *testing.T) {
+	db := sqliteDatabase(t)
+	customers := &repositories.CustomerRepositorySQLite{DB: db}
+	tx := beginMemory(t, db)
+	customer, err := customers.Save(memoryContext(), tx, &entities.Customer{
+		FullName: "Jane", Email: "jane@example.com", KYCTier: entities.KYCTierBasic,
+	})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	ownerID := customer.CustomerID
+
+	seedSQLiteAccounts(t, db, &entities.Account{AccountID: 1, OwnerID: &ownerID}, &entities.Account{AccountID: 2})
+	repo := &repositories.TransactionRepositorySQLite{DB: db}
+	snapshots := &repositories.BalanceSnapshotRepositorySQLite{DB: db}
+	ctx := memoryContext()
+	tx = beginMemory(t, db)
+	defer tx.Rollback()
+
+	for _, transaction := range []*entities.Transaction{
+		{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.RequireFromString("5.1")},
+		{SourceAccountID: 2, DestinationAccountID: 1, Amount: decimal.RequireFromString("2.2")},
+	} {
+		_, err := repo.Save(ctx, tx, transaction)
+		require.NoError(t, err)
+	}
+	now := time.Now()
+
+	net, err := repo.NetMovement(ctx, tx, 1, nil, now)
+	require.NoError(t, err)
+	assert.Equal(t, "-2.9", net.String())
+
+	outgoing, err := repo.SumOutgoingByOwner(ctx, tx, ownerID, now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "5.1", outgoing.String())
+
+	streamed := 0
+	err = repo.StreamByAccount(ctx, tx, 2, now.Add(-time.Hour), now, func(*entities.Transaction) error {
+		streamed++
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, streamed)
+
+	stale, err := snapshots.ListStaleAccounts(ctx, tx, now, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, stale)
+
+	require.NoError(t, snapshots.Save(ctx, tx, &entities.BalanceSnapshot{AccountID: 1, AsOf: now, Balance: net}))
+	stale, err = snapshots.ListStaleAccounts(ctx, tx, now, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2}, stale)
+
+	snapshot, err := snapshots.FindLatest(ctx, tx, 1, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "-2.9", snapshot.Balance.String())
+}
@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+
+	"transfer-system/domain/ports"
+)
+
+// timestampLayout matches the TIMESTAMP (without time zone) columns, which hold UTC
+const timestampLayout = "2006-01-02 15:04:05.999999"
+
+// ErrCopyUnsupported is returned by bulk inserts on a transaction that cannot COPY
+var ErrCopyUnsupported = errors.New("bulk inserts need the pgx database driver")
+
+// rowScanner is satisfied by both ports.Row and ports.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// nullString stores empty optional text columns as NULL so unique constraints ignore them
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func copier(tx ports.Transaction) (ports.Copier, error) {
+	copier, ok := tx.(ports.Copier)
+	if !ok {
+		return nil, ErrCopyUnsupported
+	}
+	return copier, nil
+}
@@ -1,22 +1,62 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strings"
+
+	"transfer-system/adapters/web/dto"
+	"transfer-system/domain/ports"
+	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/logger"
+
+	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
 )
 
-func APIKeyMiddleware(next http.Handler, logger *logrus.Logger) http.Handler {
-	mwLogger := logger.WithFields(logrus.Fields{
-		"layer": "middleware",
-	})
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		apiKey := r.Header.Get("x-api-key")
-		if apiKey != "secret-api-key" {
-			mwLogger.Error("Invalid API KEY")
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
+// APIKeyContextKey holds the *entities.APIKey of an authenticated request
+const APIKeyContextKey string = "api_key"
+
+// APIKeyMiddleware rejects requests without a valid x-api-key header, unless
+// BearerTokenMiddleware authenticated them already. The Swagger UI under /docs
+// stays public
+func APIKeyMiddleware(service ports.APIKeyService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			request := ctx.Request()
+			if strings.HasPrefix(request.URL.Path, "/docs/") || hasPrincipal(request) {
+				return next(ctx)
+			}
+
+			logger, _ := request.Context().Value(logger.LoggerContextKey).(*logrus.Entry)
+			mwLogger := logger.WithField("layer", "middleware")
+
+			key, err := service.Authenticate(request.Context(), request.Header.Get("x-api-key"))
+			if err != nil {
+				mwLogger.WithError(err).Error("Invalid API KEY")
+				return middlewareError(ctx, err)
+			}
+
+			newCtx := context.WithValue(request.Context(), APIKeyContextKey, key)
+			ctx.SetRequest(request.WithContext(newCtx))
+			return next(ctx)
 		}
-		next.ServeHTTP(w, r)
-	})
+	}
 }
 
+// middlewareError answers a request a middleware rejects, with the status of
+// an *appErrors.AppError and 500 for anything else
+func middlewareError(ctx echo.Context, err error) error {
+	status := http.StatusInternalServerError
+	message := "An unexpected error occurred"
+	var appErr *appErrors.AppError
+	if errors.As(err, &appErr) {
+		status, message = appErr.StatusCode, appErr.Message
+	}
+	return ctx.JSON(status, dto.WebResponse{
+		Message: message,
+		Status:  0,
+		Data:    nil,
+	})
+}
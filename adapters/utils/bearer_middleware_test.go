@@ -0,0 +1,96 @@
+package utils_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"transfer-system/adapters/utils"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/mocks"
+	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type stubVerifier map[string]*entities.Principal
+
+func (v stubVerifier) Verify(ctx context.Context, token string) (*entities.Principal, error) {
+	if principal, ok := v[token]; ok {
+		return principal, nil
+	}
+	return nil, appErrors.NewUnauthorizedError("Invalid bearer token", nil)
+}
+
+// serveBearer runs a request with the authorization header through the
+// middlewares and returns the response and the principal the handler saw
+func serveBearer(authorization string, middlewares ...echo.MiddlewareFunc) (*httptest.ResponseRecorder, *entities.Principal) {
+	request := httptest.NewRequest(http.MethodGet, "/accounts/1", nil)
+	if authorization != "" {
+		request.Header.Set(echo.HeaderAuthorization, authorization)
+	}
+	request = request.WithContext(context.WithValue(request.Context(), logger.LoggerContextKey, logrus.NewEntry(logrus.New())))
+	recorder := httptest.NewRecorder()
+
+	var seen *entities.Principal
+	handler := func(ctx echo.Context) error {
+		seen, _ = ctx.Request().Context().Value(ports.PrincipalContextKey).(*entities.Principal)
+		return ctx.NoContent(http.StatusOK)
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	handler(echo.New().NewContext(request, recorder))
+	return recorder, seen
+}
+
+func TestBearerTokenMiddleware(t *testing.T) {
+	principal := &entities.Principal{Subject: "payroll-service", Scopes: []string{entities.ScopeAccountsRead}}
+	verifier := stubVerifier{"valid-token": principal}
+	required := utils.BearerTokenMiddleware(verifier, false)
+
+	response, seen := serveBearer("Bearer valid-token", required)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Same(t, principal, seen)
+
+	response, _ = serveBearer("bearer valid-token", required)
+	assert.Equal(t, http.StatusOK, response.Code, "the scheme is case insensitive")
+
+	response, _ = serveBearer("Bearer forged-token", required)
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+	assert.Equal(t, `Bearer error="invalid_token"`, response.Header().Get(echo.HeaderWWWAuthenticate))
+
+	response, _ = serveBearer("", required)
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+	assert.Equal(t, "Bearer", response.Header().Get(echo.HeaderWWWAuthenticate))
+
+	response, _ = serveBearer("Basic dXNlcjpwYXNz", required)
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+}
+
+func TestBearerTokenMiddleware_WithAPIKeys(t *testing.T) {
+	principal := &entities.Principal{Subject: "payroll-service"}
+	apiKeys := new(mocks.MockAPIKeyService)
+	apiKeys.On("Authenticate", mock.Anything, "").Return(nil, appErrors.NewUnauthorizedError("Invalid API key", nil))
+	middlewares := []echo.MiddlewareFunc{
+		utils.BearerTokenMiddleware(stubVerifier{"valid-token": principal}, true),
+		utils.APIKeyMiddleware(apiKeys),
+	}
+
+	// a bearer token is enough, the API key is not looked for
+	response, seen := serveBearer("Bearer valid-token", middlewares...)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Same(t, principal, seen)
+	apiKeys.AssertNotCalled(t, "Authenticate", mock.Anything, mock.Anything)
+
+	// without one the API key middleware decides
+	response, _ = serveBearer("", middlewares...)
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+	apiKeys.AssertCalled(t, "Authenticate", mock.Anything, "")
+}
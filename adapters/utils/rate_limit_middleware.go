@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"transfer-system/adapters/web/dto"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// RateLimitConfig holds the budgets of the rate limit middlewares. A zero
+// Burst leaves that kind of request unlimited
+type RateLimitConfig struct {
+	// Reads applies to GET and HEAD requests
+	Reads entities.RateLimit
+	// Transfers applies to every other request; transfers, deposits,
+	// withdrawals and imports are what it is there to hold back
+	Transfers entities.RateLimit
+	// IP applies per client IP to every request, before it is authenticated,
+	// so requests with bad credentials are held back too
+	IP entities.RateLimit
+}
+
+// IPRateLimitMiddleware spends the IP budget of the request's client IP, as
+// given by the Echo instance's IPExtractor. It goes before the authentication
+// middlewares, which look credentials up in the database
+func IPRateLimitMiddleware(store ports.RateLimitStore, config RateLimitConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if strings.HasPrefix(ctx.Request().URL.Path, "/docs/") {
+				return next(ctx)
+			}
+			return takeToken(ctx, next, store, "ip:"+ctx.RealIP(), config.IP)
+		}
+	}
+}
+
+// RateLimitMiddleware takes a token from the caller's bucket for each request
+// and answers 429 with Retry-After once it is empty. Authenticated callers are
+// limited per token subject or API key, anonymous ones per client IP, so it
+// goes after the authentication middlewares. Responses carry RateLimit-Limit,
+// RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy. A store failure
+// lets the request through: the database it shares with the API is having
+// trouble already
+func RateLimitMiddleware(store ports.RateLimitStore, config RateLimitConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			request := ctx.Request()
+			if strings.HasPrefix(request.URL.Path, "/docs/") {
+				return next(ctx)
+			}
+
+			budget, limit := "transfer", config.Transfers
+			if request.Method == http.MethodGet || request.Method == http.MethodHead {
+				budget, limit = "read", config.Reads
+			}
+			return takeToken(ctx, next, store, budget+":"+rateLimitClient(ctx), limit)
+		}
+	}
+}
+
+// takeToken takes a token from the bucket named key and calls next, or answers 429
+func takeToken(ctx echo.Context, next echo.HandlerFunc, store ports.RateLimitStore, key string, limit entities.RateLimit) error {
+	if limit.Burst <= 0 {
+		return next(ctx)
+	}
+
+	request := ctx.Request()
+	decision, err := store.Take(request.Context(), key, limit)
+	if err != nil {
+		logger, _ := request.Context().Value(logger.LoggerContextKey).(*logrus.Entry)
+		logger.WithField("layer", "middleware").WithError(err).Warn("Rate limit store failed, letting the request through")
+		return next(ctx)
+	}
+
+	header := ctx.Response().Header()
+	header.Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+	header.Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+	header.Set("RateLimit-Reset", ceilSeconds(decision.Reset))
+	header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s", limit.Burst, ceilSeconds(limit.Period)))
+	if !decision.Allowed {
+		header.Set("Retry-After", ceilSeconds(decision.RetryAfter))
+		return ctx.JSON(http.StatusTooManyRequests, dto.WebResponse{
+			Message: "Too many requests, retry later",
+			Status:  0,
+			Data:    nil,
+		})
+	}
+	return next(ctx)
+}
+
+// rateLimitClient names whose budget a request spends
+func rateLimitClient(ctx echo.Context) string {
+	if principal, ok := ctx.Request().Context().Value(ports.PrincipalContextKey).(*entities.Principal); ok {
+		return "sub:" + principal.Tenant + "/" + principal.Subject
+	}
+	if key, ok := ctx.Request().Context().Value(APIKeyContextKey).(*entities.APIKey); ok {
+		return "key:" + strconv.FormatInt(key.ID, 10)
+	}
+	return "ip:" + ctx.RealIP()
+}
+
+// ceilSeconds formats d in whole seconds, rounded up so clients never retry early
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -0,0 +1,73 @@
+package utils_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"transfer-system/adapters/ratelimit"
+	"transfer-system/adapters/utils"
+	"transfer-system/domain/entities"
+	"transfer-system/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// rateLimitedServer answers 401 behind the IP budget, like an API key
+// middleware rejecting every key
+func rateLimitedServer(limit entities.RateLimit) *echo.Echo {
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			request := ctx.Request()
+			ctx.SetRequest(request.WithContext(context.WithValue(request.Context(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))))
+			return next(ctx)
+		}
+	})
+	e.Use(utils.IPRateLimitMiddleware(ratelimit.NewMemoryStore(), utils.RateLimitConfig{IP: limit}))
+	e.GET("/accounts/1", func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusUnauthorized)
+	})
+	return e
+}
+
+func TestIPRateLimitMiddleware(t *testing.T) {
+	e := rateLimitedServer(entities.RateLimit{Burst: 2, Period: time.Minute})
+
+	codes := []int{}
+	for _, forwarded := range []string{"203.0.113.1", "203.0.113.2", "203.0.113.3"} {
+		request := httptest.NewRequest(http.MethodGet, "/accounts/1", nil)
+		request.RemoteAddr = "198.51.100.7:40000"
+		// spoofed headers must not give the client a fresh bucket
+		request.Header.Set(echo.HeaderXForwardedFor, forwarded)
+		request.Header.Set(echo.HeaderXRealIP, forwarded)
+		recorder := httptest.NewRecorder()
+		e.ServeHTTP(recorder, request)
+		codes = append(codes, recorder.Code)
+	}
+	assert.Equal(t, []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests}, codes)
+
+	// another client has its own budget
+	request := httptest.NewRequest(http.MethodGet, "/accounts/1", nil)
+	request.RemoteAddr = "198.51.100.8:40000"
+	recorder := httptest.NewRecorder()
+	e.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, "1", recorder.Header().Get("RateLimit-Remaining"))
+}
+
+func TestIPRateLimitMiddleware_Unlimited(t *testing.T) {
+	e := rateLimitedServer(entities.RateLimit{})
+
+	for range 5 {
+		recorder := httptest.NewRecorder()
+		e.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/accounts/1", nil))
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Empty(t, recorder.Header().Get("RateLimit-Limit"))
+	}
+}
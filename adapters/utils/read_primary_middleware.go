@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"context"
+	"strconv"
+
+	"transfer-system/domain/ports"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReadPrimaryHeader asks for the request's reads to go to the primary, e.g.
+// to see a transfer just made before replicas have replayed it
+const ReadPrimaryHeader = "X-Read-Primary"
+
+// ReadPrimaryMiddleware sets ports.ReadPrimaryContextKey on requests whose
+// X-Read-Primary header is true
+func ReadPrimaryMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		request := ctx.Request()
+		if primary, _ := strconv.ParseBool(request.Header.Get(ReadPrimaryHeader)); primary {
+			newCtx := context.WithValue(request.Context(), ports.ReadPrimaryContextKey, true)
+			ctx.SetRequest(request.WithContext(newCtx))
+		}
+		return next(ctx)
+	}
+}
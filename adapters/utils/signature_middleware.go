@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"transfer-system/adapters/web"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// SignatureHeader carries v1= and the hex HMAC-SHA256 of RequestSignature's string
+	SignatureHeader = "X-Signature"
+	// SignatureTimestampHeader is the Unix time, in seconds, the request was signed at
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+	// SignatureNonceHeader is a value the client never uses twice with its key
+	SignatureNonceHeader = "X-Signature-Nonce"
+	// IdempotencyKeyHeader is part of the signed string, empty when not sent
+	IdempotencyKeyHeader = "Idempotency-Key"
+)
+
+// DefaultSignatureMaxSkew is how far a signed request's timestamp may be from the server's clock
+const DefaultSignatureMaxSkew = 5 * time.Minute
+
+const signatureVersion = "v1"
+
+var nonceFormat = regexp.MustCompile(`^[A-Za-z0-9_-]{16,128}$`)
+
+// SignatureConfig tunes SignatureMiddleware
+type SignatureConfig struct {
+	MaxSkew time.Duration
+	// Required rejects requests made with keys that have no signing secret;
+	// otherwise only keys that have one must sign
+	Required bool
+}
+
+// RequestSignature is the X-Signature value of a request: HMAC-SHA256 keyed
+// with the signing secret over these lines, joined by \n:
+//
+//	v1
+//	method, upper case
+//	path and query exactly as sent, e.g. /transactions?dry_run=true
+//	timestamp
+//	nonce
+//	hex SHA-256 of the body, of nothing when there is none
+//	Idempotency-Key header, empty when not sent
+func RequestSignature(secret, method, uri, timestamp, nonce string, body []byte, idempotencyKey string) string {
+	digest := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join([]string{
+		signatureVersion,
+		strings.ToUpper(method),
+		uri,
+		timestamp,
+		nonce,
+		hex.EncodeToString(digest[:]),
+		idempotencyKey,
+	}, "\n")))
+	return signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureMiddleware checks the HMAC signature of requests made with an API
+// key that has a signing secret, so it goes after APIKeyMiddleware; requests
+// authenticated with a bearer token are not signed. The
+// timestamp must be within MaxSkew of the server's clock and each nonce is
+// accepted once per key while its timestamp is. A failing nonce store rejects
+// the request, as a replay could not be told apart
+func SignatureMiddleware(store ports.NonceStore, config SignatureConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			request := ctx.Request()
+			if strings.HasPrefix(request.URL.Path, "/docs/") || hasPrincipal(request) {
+				return next(ctx)
+			}
+
+			logger, _ := request.Context().Value(logger.LoggerContextKey).(*logrus.Entry)
+			mwLogger := logger.WithField("layer", "middleware")
+
+			key, _ := request.Context().Value(APIKeyContextKey).(*entities.APIKey)
+			if key == nil || key.SigningSecret == "" {
+				if config.Required {
+					mwLogger.Error("Unsigned request with a key that cannot sign")
+					return middlewareError(ctx, appErrors.NewUnauthorizedError("Request signing is required, use an API key issued with a signing secret", nil))
+				}
+				return next(ctx)
+			}
+
+			timestamp := request.Header.Get(SignatureTimestampHeader)
+			nonce := request.Header.Get(SignatureNonceHeader)
+			signature := request.Header.Get(SignatureHeader)
+			if signature == "" || timestamp == "" || nonce == "" {
+				mwLogger.Errorf("Unsigned request with API key %s", key.Prefix)
+				return middlewareError(ctx, appErrors.NewUnauthorizedError("Missing request signature", nil))
+			}
+			if !nonceFormat.MatchString(nonce) {
+				return middlewareError(ctx, appErrors.NewUnauthorizedError("Nonce must be 16 to 128 letters, digits, - or _", nil))
+			}
+
+			seconds, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				return middlewareError(ctx, appErrors.NewUnauthorizedError("Invalid signature timestamp", err))
+			}
+			signedAt := time.Unix(seconds, 0)
+			if skew := time.Since(signedAt).Abs(); skew > config.MaxSkew {
+				mwLogger.WithField("skew", skew.String()).Error("Signature timestamp out of range")
+				return middlewareError(ctx, appErrors.NewUnauthorizedError("Signature timestamp is too far from the server time", nil))
+			}
+
+			// read before any handler, so bounded here rather than only by the handlers' own caps
+			body, err := io.ReadAll(http.MaxBytesReader(ctx.Response(), request.Body, web.MaxImportBytes))
+			if err != nil {
+				mwLogger.WithError(err).Error("Failed to read request body")
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					return middlewareError(ctx, appErrors.NewPayloadTooLargeError("Request body larger than 20 MiB", err))
+				}
+				return middlewareError(ctx, appErrors.NewBadRequestError("Failed to read request body", err))
+			}
+			request.Body = io.NopCloser(bytes.NewReader(body))
+
+			expected := RequestSignature(key.SigningSecret, request.Method, request.RequestURI, timestamp, nonce, body, request.Header.Get(IdempotencyKeyHeader))
+			if !hmac.Equal([]byte(signature), []byte(expected)) {
+				mwLogger.Errorf("Invalid request signature with API key %s", key.Prefix)
+				return middlewareError(ctx, appErrors.NewUnauthorizedError("Invalid request signature", nil))
+			}
+
+			// checked after the signature, so unsigned requests cannot use up a client's nonces
+			fresh, err := store.Remember(request.Context(), strconv.FormatInt(key.ID, 10)+":"+nonce, signedAt.Add(config.MaxSkew))
+			if err != nil {
+				mwLogger.WithError(err).Error("Nonce store failed")
+				return middlewareError(ctx, appErrors.NewServiceUnavailableError("Currently we're facing an issue", err))
+			}
+			if !fresh {
+				mwLogger.Errorf("Replayed nonce with API key %s", key.Prefix)
+				return middlewareError(ctx, appErrors.NewUnauthorizedError("Nonce was already used", nil))
+			}
+
+			return next(ctx)
+		}
+	}
+}
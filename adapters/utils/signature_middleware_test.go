@@ -0,0 +1,177 @@
+package utils_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"transfer-system/adapters/nonces"
+	"transfer-system/adapters/utils"
+	"transfer-system/domain/entities"
+	"transfer-system/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const signingSecret = "tss_c2lnbmluZyBzZWNyZXQ"
+
+var signingKey = &entities.APIKey{ID: 5, Prefix: "tsk_abcdefgh", SigningSecret: signingSecret}
+
+type failingNonceStore struct{}
+
+func (failingNonceStore) Remember(ctx context.Context, nonce string, expires time.Time) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+func (failingNonceStore) Sweep(ctx context.Context, before time.Time) error {
+	return nil
+}
+
+// signedRequest signs a request made with key at signedAt; edit changes it after signing
+func signedRequest(key *entities.APIKey, signedAt time.Time, nonce string, edit func(*http.Request)) *http.Request {
+	body := `{"source_account_id":1,"destination_account_id":2,"amount":"10"}`
+	request := httptest.NewRequest(http.MethodPost, "/transactions?dry_run=true", strings.NewReader(body))
+	request.Header.Set(utils.IdempotencyKeyHeader, "payroll-2025-03")
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	request.Header.Set(utils.SignatureTimestampHeader, timestamp)
+	request.Header.Set(utils.SignatureNonceHeader, nonce)
+	request.Header.Set(utils.SignatureHeader, utils.RequestSignature(signingSecret, http.MethodPost, "/transactions?dry_run=true", timestamp, nonce, []byte(body), "payroll-2025-03"))
+	if edit != nil {
+		edit(request)
+	}
+
+	ctx := context.WithValue(request.Context(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	if key != nil {
+		ctx = context.WithValue(ctx, utils.APIKeyContextKey, key)
+	}
+	return request.WithContext(ctx)
+}
+
+// serveSigned runs the request through SignatureMiddleware and returns the
+// response and the body the handler read, if it was reached
+func serveSigned(t *testing.T, middleware echo.MiddlewareFunc, request *http.Request) (*httptest.ResponseRecorder, string) {
+	e := echo.New()
+	recorder := httptest.NewRecorder()
+	var handled string
+	handler := middleware(func(ctx echo.Context) error {
+		body, err := io.ReadAll(ctx.Request().Body)
+		require.NoError(t, err)
+		handled = string(body)
+		return ctx.NoContent(http.StatusCreated)
+	})
+	require.NoError(t, handler(e.NewContext(request, recorder)))
+	return recorder, handled
+}
+
+func TestSignatureMiddleware(t *testing.T) {
+	middleware := utils.SignatureMiddleware(nonces.NewMemoryStore(), utils.SignatureConfig{MaxSkew: utils.DefaultSignatureMaxSkew})
+	now := time.Now()
+
+	t.Run("valid", func(t *testing.T) {
+		response, body := serveSigned(t, middleware, signedRequest(signingKey, now, "bm9uY2UtdmFsaWQtMDAx", nil))
+		assert.Equal(t, http.StatusCreated, response.Code)
+		assert.Contains(t, body, `"amount":"10"`, "the handler reads the body that was verified")
+	})
+
+	t.Run("replayed nonce", func(t *testing.T) {
+		response, _ := serveSigned(t, middleware, signedRequest(signingKey, now, "bm9uY2UtcmVwbGF5LTAx", nil))
+		assert.Equal(t, http.StatusCreated, response.Code)
+
+		response, body := serveSigned(t, middleware, signedRequest(signingKey, now, "bm9uY2UtcmVwbGF5LTAx", nil))
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+		assert.Contains(t, response.Body.String(), "Nonce was already used")
+		assert.Empty(t, body)
+	})
+
+	t.Run("same nonce with another key", func(t *testing.T) {
+		other := &entities.APIKey{ID: 6, Prefix: "tsk_ijklmnop", SigningSecret: signingSecret}
+		response, _ := serveSigned(t, middleware, signedRequest(other, now, "bm9uY2UtdmFsaWQtMDAx", nil))
+		assert.Equal(t, http.StatusCreated, response.Code)
+	})
+
+	rejected := map[string]struct {
+		signedAt time.Time
+		nonce    string
+		edit     func(*http.Request)
+		message  string
+	}{
+		"tampered body": {now, "bm9uY2UtYm9keS0wMDAx", func(r *http.Request) {
+			r.Body = io.NopCloser(strings.NewReader(`{"source_account_id":1,"destination_account_id":3,"amount":"10"}`))
+		}, "Invalid request signature"},
+		"tampered path": {now, "bm9uY2UtcGF0aC0wMDAx", func(r *http.Request) {
+			r.RequestURI = "/transactions"
+		}, "Invalid request signature"},
+		"tampered idempotency key": {now, "bm9uY2UtaWRlbS0wMDAx", func(r *http.Request) {
+			r.Header.Set(utils.IdempotencyKeyHeader, "payroll-2025-04")
+		}, "Invalid request signature"},
+		"wrong secret": {now, "bm9uY2Utc2VjcmV0LTAx", func(r *http.Request) {
+			r.Header.Set(utils.SignatureHeader, utils.RequestSignature("tss_other", http.MethodPost, "/transactions?dry_run=true", strconv.FormatInt(now.Unix(), 10), "bm9uY2Utc2VjcmV0LTAx", nil, ""))
+		}, "Invalid request signature"},
+		"old timestamp":    {now.Add(-6 * time.Minute), "bm9uY2Utb2xkLTAwMDAx", nil, "too far from the server time"},
+		"future timestamp": {now.Add(6 * time.Minute), "bm9uY2UtZnV0dXJlLTAx", nil, "too far from the server time"},
+		"short nonce":      {now, "short", nil, "Nonce must be"},
+		"unsigned": {now, "bm9uY2UtdW5zaWduZWQx", func(r *http.Request) {
+			r.Header.Del(utils.SignatureHeader)
+		}, "Missing request signature"},
+	}
+	for name, test := range rejected {
+		t.Run(name, func(t *testing.T) {
+			response, body := serveSigned(t, middleware, signedRequest(signingKey, test.signedAt, test.nonce, test.edit))
+			assert.Equal(t, http.StatusUnauthorized, response.Code)
+			assert.Contains(t, response.Body.String(), test.message)
+			assert.Empty(t, body)
+		})
+	}
+	t.Run("endless body", func(t *testing.T) {
+		response, body := serveSigned(t, middleware, signedRequest(signingKey, now, "bm9uY2UtZW5kbGVzcy0x", func(r *http.Request) {
+			r.Body = io.NopCloser(endlessReader{})
+		}))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, response.Code)
+		assert.Empty(t, body)
+	})
+}
+
+// endlessReader is a body that never ends
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'a'
+	}
+	return len(p), nil
+}
+
+func TestSignatureMiddleware_KeysWithoutSigningSecret(t *testing.T) {
+	unsigned := func(r *http.Request) {
+		r.Header.Del(utils.SignatureHeader)
+		r.Header.Del(utils.SignatureTimestampHeader)
+		r.Header.Del(utils.SignatureNonceHeader)
+	}
+	plainKey := &entities.APIKey{ID: 7, Prefix: "tsk_qrstuvwx"}
+
+	optional := utils.SignatureMiddleware(nonces.NewMemoryStore(), utils.SignatureConfig{MaxSkew: time.Minute})
+	response, _ := serveSigned(t, optional, signedRequest(plainKey, time.Now(), "bm9uY2UtcGxhaW4tMDAx", unsigned))
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	required := utils.SignatureMiddleware(nonces.NewMemoryStore(), utils.SignatureConfig{MaxSkew: time.Minute, Required: true})
+	response, _ = serveSigned(t, required, signedRequest(plainKey, time.Now(), "bm9uY2UtcGxhaW4tMDAx", unsigned))
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+	assert.Contains(t, response.Body.String(), "Request signing is required")
+}
+
+func TestSignatureMiddleware_NonceStoreFailure(t *testing.T) {
+	middleware := utils.SignatureMiddleware(failingNonceStore{}, utils.SignatureConfig{MaxSkew: time.Minute})
+
+	response, body := serveSigned(t, middleware, signedRequest(signingKey, time.Now(), "bm9uY2Utc3RvcmUtMDAx", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	assert.Empty(t, body)
+}
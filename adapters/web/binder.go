@@ -0,0 +1,39 @@
+package web
+
+import (
+	"errors"
+
+	"transfer-system/pkg/validator"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrInvalidPayload is returned by Bind when the body cannot be decoded
+var ErrInvalidPayload = errors.New("invalid request payload")
+
+// Validatable payloads declare their own rules; amount bounds their decimal amounts
+type Validatable interface {
+	Validate(amount validator.DecimalRule) error
+}
+
+// Binder decodes request payloads and validates them, so every handler
+// rejects invalid input the same way
+type Binder struct {
+	// Amount bounds balances and amounts, validator.Money when zero
+	Amount validator.DecimalRule
+}
+
+// Bind decodes the payload into request and validates it. It returns
+// ErrInvalidPayload when the body cannot be decoded, or validator.Errors
+// listing every invalid field
+func (b Binder) Bind(ctx echo.Context, request Validatable) error {
+	if err := GetPayload(ctx, request); err != nil {
+		return errors.Join(ErrInvalidPayload, err)
+	}
+
+	amount := b.Amount
+	if amount == (validator.DecimalRule{}) {
+		amount = validator.Money
+	}
+	return request.Validate(amount)
+}
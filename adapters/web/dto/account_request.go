@@ -1,15 +1,68 @@
 package dto
 
-import "github.com/shopspring/decimal"
+import (
+	"transfer-system/pkg/validator"
+
+	"github.com/shopspring/decimal"
+)
 
 // @Description Account creation payload
 type AccountRequest struct {
-	// Account ID
+	// Account ID, generated by the server when omitted
 	// @example 123
-	AccountID int64 `json:"account_id"`
+	AccountID int64 `json:"account_id,omitempty"`
 	// Initial balance (string to allow decimal format)
 	// @example 100.23344
 	Balance string `json:"initial_balance"`
+	// Client reference, unique across accounts
+	// @example crm-000123
+	ExternalReference string `json:"external_reference,omitempty"`
+	// ISO 4217 currency code, defaults to USD
+	// @example USD
+	Currency string `json:"currency,omitempty"`
+	// customer or settlement, defaults to customer
+	// @example customer
+	Kind string `json:"kind,omitempty"`
+	// Owning customer
+	// @example 7
+	OwnerID *int64 `json:"owner_id,omitempty"`
+	// Free-form key/value pairs, at most 50 keys
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Validate checks every field, amount bounds the initial balance
+func (r *AccountRequest) Validate(amount validator.DecimalRule) error {
+	fields := []validator.Errors{
+		validator.Field("account_id", r.AccountID, validator.Min(0)),
+		validator.Field("initial_balance", r.Balance, validator.Required, validator.Decimal(amount)),
+		validator.Field("external_reference", r.ExternalReference, validator.Optional(validator.Matches(validator.ValidateReference,
+			"expected up to 64 letters, digits or . _ : / -"))),
+		validator.Field("currency", r.Currency, validator.Optional(validator.Matches(validator.ValidateCurrency,
+			"expected an ISO 4217 code such as USD"))),
+		validator.Field("kind", r.Kind, validator.Optional(validator.OneOf("customer", "settlement"))),
+		validator.Field("metadata", r.Metadata, validMetadata),
+	}
+	if r.OwnerID != nil {
+		fields = append(fields, validator.Field("owner_id", *r.OwnerID, validator.Min(1)))
+	}
+	return validator.Validate(fields...)
+}
+
+// @Description Account partial update payload
+type AccountPatchRequest struct {
+	// Keys to set; a null value removes the key
+	Metadata map[string]*string `json:"metadata"`
+	// active, frozen or closed; a closed account cannot be reopened
+	// @example frozen
+	Status string `json:"status,omitempty"`
+}
+
+// Validate checks the metadata being set and the status; amount is unused
+func (r *AccountPatchRequest) Validate(amount validator.DecimalRule) error {
+	return validator.Validate(
+		validator.Field("metadata", r.Metadata, validMetadataPatch),
+		validator.Field("status", r.Status, validator.Optional(validator.OneOf("active", "frozen", "closed"))),
+	)
 }
 
 type InternalAccountRequest struct {
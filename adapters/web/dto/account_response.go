@@ -1,6 +1,22 @@
 package dto
 
+import "time"
+
 type AccountResponse struct {
-	AccountID int64  `json:"account_id"`
-	Balance   string `json:"balance"`
+	AccountID         int64             `json:"account_id"`
+	Balance           string            `json:"balance"`
+	ExternalReference string            `json:"external_reference,omitempty"`
+	Currency          string            `json:"currency"`
+	Kind              string            `json:"kind"`
+	Status            string            `json:"status"`
+	OwnerID           *int64            `json:"owner_id,omitempty"`
+	Metadata          map[string]string `json:"metadata"`
+	Version           int64             `json:"version"`
+	CreatedAt         time.Time         `json:"created_at"`
+}
+
+type AccountListResponse struct {
+	Accounts   []*AccountResponse `json:"accounts"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+	Total      *int64             `json:"total,omitempty"`
 }
@@ -0,0 +1,10 @@
+package dto
+
+import "time"
+
+type BalanceResponse struct {
+	AccountID int64     `json:"account_id"`
+	Currency  string    `json:"currency"`
+	Balance   string    `json:"balance"`
+	AsOf      time.Time `json:"as_of"`
+}
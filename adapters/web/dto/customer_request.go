@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"strings"
+
+	"transfer-system/pkg/validator"
+)
+
+// @Description Customer creation and update payload
+type CustomerRequest struct {
+	// @example Jane Doe
+	FullName string `json:"full_name"`
+	// @example jane@example.com
+	Email string `json:"email"`
+	// @example +6281234567890
+	Phone string `json:"phone,omitempty"`
+	// Date of birth in YYYY-MM-DD
+	// @example 1990-04-21
+	DateOfBirth string `json:"date_of_birth,omitempty"`
+	// ISO 3166-1 alpha-2 country code
+	// @example ID
+	Country string `json:"country,omitempty"`
+	// KYC tier, defaults to unverified
+	// @example basic
+	KYCTier string `json:"kyc_tier,omitempty" enums:"unverified,basic,full"`
+}
+
+// Validate checks every field; customers carry no amounts
+func (r *CustomerRequest) Validate(validator.DecimalRule) error {
+	return validator.Validate(
+		validator.Field("full_name", strings.TrimSpace(r.FullName), validator.Required, validator.MaxLength(200)),
+		validator.Field("email", r.Email, validator.Required, validator.Matches(validator.ValidateEmail, "expected an address such as jane@example.com")),
+		validator.Field("phone", r.Phone, validator.MaxLength(32)),
+		validator.Field("date_of_birth", r.DateOfBirth, validator.Optional(validator.Date)),
+		validator.Field("country", r.Country, validator.Optional(validator.Matches(validator.ValidateCountry,
+			"expected an ISO 3166-1 alpha-2 code such as ID"))),
+		validator.Field("kyc_tier", r.KYCTier, validator.Optional(validator.OneOf("unverified", "basic", "full"))),
+	)
+}
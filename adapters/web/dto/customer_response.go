@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+type CustomerResponse struct {
+	CustomerID  int64     `json:"customer_id"`
+	FullName    string    `json:"full_name"`
+	Email       string    `json:"email"`
+	Phone       string    `json:"phone,omitempty"`
+	DateOfBirth string    `json:"date_of_birth,omitempty"`
+	Country     string    `json:"country,omitempty"`
+	KYCTier     string    `json:"kyc_tier"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
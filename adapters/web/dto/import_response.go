@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+type ImportJobResponse struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+	// Rows committed so far; a resumed job continues after them
+	CommittedRows int `json:"committed_rows"`
+	TotalRows     int `json:"total_rows"`
+	// Line of the row that stopped a failed job, counting the header as line 1
+	FailedLine int       `json:"failed_line,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type ImportRowErrorResponse struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+type ImportValidationResponse struct {
+	ValidRows int                       `json:"valid_rows"`
+	Errors    []*ImportRowErrorResponse `json:"errors"`
+}
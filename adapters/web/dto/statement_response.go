@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+// StatementResponse documents the JSON statement, which is streamed rather than marshalled in one piece
+type StatementResponse struct {
+	AccountID      int64                    `json:"account_id"`
+	Currency       string                   `json:"currency"`
+	From           time.Time                `json:"from"`
+	To             time.Time                `json:"to"`
+	OpeningBalance string                   `json:"opening_balance"`
+	Lines          []*StatementLineResponse `json:"lines"`
+	ClosingBalance string                   `json:"closing_balance"`
+}
+
+type StatementLineResponse struct {
+	Transaction *TransactionResponse `json:"transaction"`
+	// Signed amount, negative for outgoing transfers
+	Amount                string `json:"amount"`
+	Balance               string `json:"balance"`
+	CounterpartyAccountID int64  `json:"counterparty_account_id"`
+}
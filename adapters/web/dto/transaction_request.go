@@ -1,5 +1,7 @@
 package dto
 
+import "transfer-system/pkg/validator"
+
 // @Description Transaction creation payload
 type TransactionRequest struct {
 	// @example 123
@@ -8,4 +10,76 @@ type TransactionRequest struct {
 	DestinationAccountID int64 `json:"destination_account_id"`
 	// @example 100.12345
 	Amount string `json:"amount"`
+	// Purpose shown on statements, up to 140 characters
+	// @example Invoice 2025-001 March rent
+	Description string `json:"description,omitempty"`
+	// Client reference, up to 35 characters
+	// @example PAY-000123
+	Reference  string             `json:"reference,omitempty"`
+	Remittance *RemittanceRequest `json:"remittance,omitempty"`
+	// Free-form key/value pairs, at most 50 keys
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Validate checks every field, amount bounds the transferred amount
+func (r *TransactionRequest) Validate(amount validator.DecimalRule) error {
+	fields := []validator.Errors{
+		validator.Field("source_account_id", r.SourceAccountID, validator.Min(1)),
+		validator.Field("destination_account_id", r.DestinationAccountID, validator.Min(1),
+			validator.NotEqual(r.SourceAccountID, "source_account_id")),
+		validator.Field("amount", r.Amount, validator.Required, validator.Decimal(amount), validator.Positive),
+		validator.Field("description", r.Description, validDescription),
+		validator.Field("reference", r.Reference, validPaymentReference),
+		validator.Field("metadata", r.Metadata, validMetadata),
+	}
+	if remittance := r.Remittance; remittance != nil {
+		fields = append(fields,
+			validator.Field("remittance.creditor_reference", remittance.CreditorReference, validator.Optional(validator.Matches(validator.ValidateCreditorReference,
+				"expected an ISO 11649 reference such as RF18539007547034"))),
+			validator.Field("remittance.invoice_number", remittance.InvoiceNumber, validator.Optional(validator.Matches(validator.ValidateInvoiceNumber,
+				"expected up to 35 letters, digits or / - ? : ( ) . , ' +"))),
+			validator.Field("remittance.invoice_date", remittance.InvoiceDate, validator.Optional(validator.Date)),
+		)
+	}
+	return validator.Validate(fields...)
+}
+
+// @Description Deposit or withdrawal payload
+type SettlementRequest struct {
+	// @example 100.12345
+	Amount string `json:"amount"`
+	// Settlement account the money passes through, defaults to the one configured for the currency
+	// @example 9001
+	SettlementAccountID int64 `json:"settlement_account_id,omitempty"`
+	// Purpose shown on statements, up to 140 characters
+	// @example Cash deposit
+	Description string `json:"description,omitempty"`
+	// Client reference, up to 35 characters
+	// @example WIRE-000123
+	Reference string `json:"reference,omitempty"`
+	// Free-form key/value pairs, at most 50 keys
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Validate checks every field, amount bounds the deposited or withdrawn amount
+func (r *SettlementRequest) Validate(amount validator.DecimalRule) error {
+	return validator.Validate(
+		validator.Field("amount", r.Amount, validator.Required, validator.Decimal(amount), validator.Positive),
+		validator.Field("settlement_account_id", r.SettlementAccountID, validator.Min(0)),
+		validator.Field("description", r.Description, validDescription),
+		validator.Field("reference", r.Reference, validPaymentReference),
+		validator.Field("metadata", r.Metadata, validMetadata),
+	)
+}
+
+// @Description Structured remittance information
+type RemittanceRequest struct {
+	// ISO 11649 creditor reference
+	// @example RF18539007547034
+	CreditorReference string `json:"creditor_reference,omitempty"`
+	// @example INV-2025-001
+	InvoiceNumber string `json:"invoice_number,omitempty"`
+	// Invoice date in YYYY-MM-DD
+	// @example 2025-03-01
+	InvoiceDate string `json:"invoice_date,omitempty"`
 }
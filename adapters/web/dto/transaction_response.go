@@ -1,7 +1,27 @@
 package dto
 
+import "time"
+
 type TransactionResponse struct {
-	SourceAccountID      int    `json:"source_account_id"`
-	DestinationAccountID int    `json:"destination_account_id"`
-	Amount               string `json:"amount"`
+	Id                   int64               `json:"id"`
+	Type                 string              `json:"type"`
+	SourceAccountID      int64               `json:"source_account_id"`
+	DestinationAccountID int64               `json:"destination_account_id"`
+	Amount               string              `json:"amount"`
+	Description          string              `json:"description,omitempty"`
+	Reference            string              `json:"reference,omitempty"`
+	Remittance           *RemittanceResponse `json:"remittance,omitempty"`
+	Metadata             map[string]string   `json:"metadata"`
+	CreatedAt            time.Time           `json:"created_at"`
+}
+
+type RemittanceResponse struct {
+	CreditorReference string `json:"creditor_reference,omitempty"`
+	InvoiceNumber     string `json:"invoice_number,omitempty"`
+	InvoiceDate       string `json:"invoice_date,omitempty"`
+}
+
+type TransactionListResponse struct {
+	Transactions []*TransactionResponse `json:"transactions"`
+	NextCursor   string                 `json:"next_cursor,omitempty"`
 }
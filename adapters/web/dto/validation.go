@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"transfer-system/domain/entities"
+	"transfer-system/pkg/validator"
+)
+
+// @Description Every invalid field of the request
+type ValidationErrorResponse struct {
+	Errors validator.Errors `json:"errors"`
+}
+
+// SEPA text rules shared by transfers, deposits and withdrawals
+var (
+	validDescription = validator.Optional(validator.Matches(validator.ValidateDescription,
+		"expected up to 140 letters, digits, spaces or / - ? : ( ) . , ' +"))
+	validPaymentReference = validator.Optional(validator.Matches(validator.ValidatePaymentReference,
+		"expected up to 35 letters, digits or / - ? : ( ) . , ' +"))
+)
+
+func validMetadata(metadata map[string]string) string {
+	if err := entities.Metadata(metadata).Validate(); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// validMetadataPatch checks only the keys being set, so stored keys that no
+// longer pass can still be removed; the key count of the merged result is
+// checked by the service
+func validMetadataPatch(patch map[string]*string) string {
+	setKeys := map[string]string{}
+	for key, value := range patch {
+		if value != nil {
+			setKeys[key] = *value
+		}
+	}
+	return validMetadata(setKeys)
+}
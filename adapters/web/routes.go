@@ -8,9 +8,37 @@ import (
 
 func AccountRouter(controller ports.AccountController, e *echo.Echo) {
 	e.POST("/accounts", controller.Create)
+	e.GET("/accounts", controller.List)
 	e.GET("/accounts/:accountId", controller.FindById)
+	e.PATCH("/accounts/:accountId", controller.Update)
 }
 
 func TransactionRouter(controller ports.TransactionController, e *echo.Echo) {
 	e.POST("/transactions", controller.Save)
+	e.GET("/transactions/:transactionId", controller.FindById)
+	e.GET("/accounts/:accountId/transactions", controller.ListByAccount)
+	e.POST("/accounts/:accountId/deposits", controller.Deposit)
+	e.POST("/accounts/:accountId/withdrawals", controller.Withdraw)
+}
+
+func CustomerRouter(controller ports.CustomerController, e *echo.Echo) {
+	e.POST("/customers", controller.Create)
+	e.GET("/customers/:customerId", controller.FindById)
+	e.PUT("/customers/:customerId", controller.Update)
+	e.DELETE("/customers/:customerId", controller.Delete)
+}
+
+func BalanceRouter(controller ports.BalanceController, e *echo.Echo) {
+	e.GET("/accounts/:accountId/balance", controller.BalanceAsOf)
+	e.GET("/accounts/:accountId/statement", controller.Statement)
+}
+
+func TransferBatchRouter(controller ports.TransferBatchController, e *echo.Echo) {
+	e.POST("/transactions/batch", controller.Import)
+}
+
+func ImportRouter(controller ports.ImportController, e *echo.Echo) {
+	e.POST("/imports/accounts", controller.ImportAccounts)
+	e.POST("/imports/transfers", controller.ImportTransfers)
+	e.GET("/imports/:jobId", controller.FindJob)
 }
@@ -2,6 +2,10 @@ package web
 
 import "github.com/labstack/echo/v4"
 
+// MaxImportBytes bounds a CSV upload, roughly a hundred thousand rows, and
+// with it every request body read whole
+const MaxImportBytes = 20 << 20
+
 func GetPayload(ctx echo.Context, result interface{}) error {
 	if err := ctx.Bind(result); err != nil {
 		return err
@@ -2,24 +2,37 @@ package main
 
 import (
 	"context"
+	"expvar"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"transfer-system/adapters/alerts"
+	"transfer-system/adapters/cli"
 	"transfer-system/adapters/controllers"
-	"transfer-system/adapters/repositories"
+	"transfer-system/adapters/jobs"
+	"transfer-system/adapters/jwtauth"
+	"transfer-system/adapters/metrics"
 	"transfer-system/adapters/utils"
 	"transfer-system/adapters/web"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
 	"transfer-system/domain/services"
 	"transfer-system/infrastructure/datastore"
 	"transfer-system/pkg/logger"
+	"transfer-system/pkg/validator"
 
 	_ "transfer-system/docs"
 
 	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
@@ -30,60 +43,300 @@ import (
 // @host localhost:8080
 // @BasePath /
 func main() {
-	err := godotenv.Load()
-	if err != nil {
+	// in memory mode the demo runs without a .env file
+	envErr := godotenv.Load()
+
+	storageKind := flag.String("storage", getEnv("STORAGE", storageDatabase), "where data is kept: database (DB_URL) or memory")
+	flag.Parse()
+	if envErr != nil && *storageKind != storageMemory {
 		log.Fatal("Failed load keys")
 	}
 
 	baseLogger := logger.NewLogger()
 
-	db, err := datastore.NewDatabase(os.Getenv("DB_URL"), baseLogger)
+	// read-only transactions go to DB_REPLICA_URLS when set, comma separated
+	store, err := newStorage(*storageKind, getEnv("DB_DRIVER", datastore.DriverPQ), os.Getenv("DB_URL"), parseList(os.Getenv("DB_REPLICA_URLS")), baseLogger)
 	if err != nil {
-		baseLogger.Fatal("Failed to connect to database: ", err)
+		baseLogger.Fatal("Failed to open storage: ", err)
 	}
+	db := store.DB
 	defer db.Close()
+	if *storageKind == storageMemory {
+		baseLogger.Warn("Using in-memory storage, data is lost when the process exits")
+	}
 
 	ctxTimeout := time.Duration(60) * time.Second
 
-	// Initialize repositories and services for account
-	accountRepository := &repositories.AccountRepositoryPostgre{
-		DB: db,
+	// request amounts may be held to fewer digits than the columns store
+	amountRule, err := parseAmountRule(os.Getenv("AMOUNT_PRECISION"), os.Getenv("AMOUNT_SCALE"))
+	if err != nil {
+		baseLogger.Fatal("Invalid AMOUNT_PRECISION or AMOUNT_SCALE: ", err)
+	}
+	binder := web.Binder{Amount: amountRule}
+
+	// Initialize repositories and services for customer
+	customerRepository := store.Customers
+	customerService := &services.CustomerServiceImpl{
+		DB:                 db,
+		CustomerRepository: customerRepository,
+		CtxTimeout:         ctxTimeout,
 	}
+	customerController := &controllers.CustomerController{
+		CustomerService: customerService,
+		Binder:          binder,
+	}
+
+	// Initialize repositories and services for account
+	accountRepository := store.Accounts
+	transactionRepository := store.Transactions
+	// opening balances are booked as transfers from the currency's equity account
 	accountService := &services.AccountServiceImpl{
-		DB:                db,
-		AccountRepository: accountRepository,
-		CtxTimeout:        ctxTimeout,
+		DB:                    db,
+		AccountRepository:     accountRepository,
+		CustomerRepository:    customerRepository,
+		TransactionRepository: transactionRepository,
+		CtxTimeout:            ctxTimeout,
 	}
 	accountController := &controllers.AccountController{
 		AccountService: accountService,
+		Binder:         binder,
 	}
 
-	// Initialize repositories and services for transaction
-	transactionRepository := &repositories.TransactionRepositoryPostgre{
-		DB: db,
+	// deposits and withdrawals without a settlement account use the one configured for the currency
+	settlementAccounts, err := parseSettlementAccounts(os.Getenv("SETTLEMENT_ACCOUNTS"))
+	if err != nil {
+		baseLogger.Fatal("Invalid SETTLEMENT_ACCOUNTS: ", err)
 	}
+
+	// Initialize services for transaction
 	transactionService := &services.TransactionServiceImpl{
 		DB:                    db,
 		TransactionRepository: transactionRepository,
 		AccountRepository:     accountRepository,
+		CustomerRepository:    customerRepository,
+		DailyLimits:           services.DefaultDailyLimits,
+		SettlementAccounts:    settlementAccounts,
 		CtxTimeout:            ctxTimeout,
 	}
 	transactionController := &controllers.TransactionController{
 		TransactionService: transactionService,
+		Binder:             binder,
+	}
+
+	// pain.001 batches are booked one transfer at a time through the transaction service
+	transferBatchService := &services.TransferBatchServiceImpl{
+		DB:                 db,
+		AccountRepository:  accountRepository,
+		TransactionService: transactionService,
+		CtxTimeout:         ctxTimeout,
+	}
+	transferBatchController := &controllers.TransferBatchController{
+		TransferBatchService: transferBatchService,
+	}
+
+	// CSV imports reuse the account and transfer checks inside their chunk transactions
+	importChunkSize, err := strconv.Atoi(getEnv("IMPORT_CHUNK_SIZE", strconv.Itoa(services.DefaultImportChunkSize)))
+	if err != nil || importChunkSize <= 0 {
+		baseLogger.Fatal("Invalid IMPORT_CHUNK_SIZE: ", getEnv("IMPORT_CHUNK_SIZE", ""))
+	}
+	importJobRepository := store.ImportJobs
+	importService := &services.ImportServiceImpl{
+		DB:                  db,
+		ImportJobRepository: importJobRepository,
+		AccountRepository:   accountRepository,
+		CustomerRepository:  customerRepository,
+		AccountService:      accountService,
+		TransactionService:  transactionService,
+		BulkAccounts:        store.BulkAccounts,
+		BulkTransactions:    store.BulkTransactions,
+		ChunkSize:           importChunkSize,
+		CtxTimeout:          ctxTimeout,
+	}
+	importController := &controllers.ImportController{
+		ImportService: importService,
+	}
+
+	// Initialize repositories and services for point-in-time balances
+	balanceSnapshotRepository := store.BalanceSnapshots
+	balanceService := &services.BalanceServiceImpl{
+		DB:                        db,
+		AccountRepository:         accountRepository,
+		TransactionRepository:     transactionRepository,
+		BalanceSnapshotRepository: balanceSnapshotRepository,
+		CtxTimeout:                ctxTimeout,
+	}
+	balanceController := &controllers.BalanceController{
+		BalanceService: balanceService,
+	}
+
+	// ledger checks publish metrics under /debug/vars and, when configured, alert a webhook on mismatches
+	ledgerReporters := []ports.LedgerReporter{metrics.Ledger}
+	if url := os.Getenv("LEDGER_ALERT_WEBHOOK_URL"); url != "" {
+		ledgerReporters = append(ledgerReporters, &alerts.Webhook{URL: url, Client: &http.Client{Timeout: 10 * time.Second}})
+	}
+	ledgerService := &services.LedgerServiceImpl{
+		DB:                    db,
+		AccountRepository:     accountRepository,
+		TransactionRepository: transactionRepository,
+		Reporters:             ledgerReporters,
+		CtxTimeout:            ctxTimeout,
+	}
+
+	// API keys are issued with the admin commands and checked by the middleware
+	apiKeyRepository := store.APIKeys
+	apiKeyService := &services.APIKeyServiceImpl{
+		DB:               db,
+		APIKeyRepository: apiKeyRepository,
+		CtxTimeout:       ctxTimeout,
+	}
+
+	// `transfer-system account show 42` and friends run one admin command instead of serving HTTP
+	if cli.IsCommand(flag.Args()) {
+		// stdout carries the command output, logs go to stderr
+		baseLogger.SetOutput(os.Stderr)
+		commands := &cli.CLI{
+			AccountService:       accountService,
+			TransactionService:   transactionService,
+			LedgerService:        ledgerService,
+			APIKeyService:        apiKeyService,
+			ImportService:        importService,
+			TransferBatchService: transferBatchService,
+			Logger:               baseLogger,
+			Stdout:               os.Stdout,
+			Stderr:               os.Stderr,
+		}
+		code := commands.Run(flag.Args())
+		db.Close()
+		os.Exit(code)
+	}
+
+	// snapshots trail the clock by twice the request timeout so in-flight transfers have committed
+	snapshotInterval, err := time.ParseDuration(getEnv("BALANCE_SNAPSHOT_INTERVAL", "1h"))
+	if err != nil {
+		baseLogger.Fatal("Invalid BALANCE_SNAPSHOT_INTERVAL: ", err)
+	}
+	snapshotJob := &jobs.PeriodicJob{
+		Name:     "balance-snapshot",
+		Interval: snapshotInterval,
+		Logger:   baseLogger,
+		Run: func(ctx context.Context) error {
+			_, err := balanceService.CreateSnapshots(ctx, time.Now().Add(-2*ctxTimeout))
+			return err
+		},
+	}
+	snapshotJob.Start(context.Background())
+
+	ledgerInterval, err := time.ParseDuration(getEnv("LEDGER_VERIFY_INTERVAL", "24h"))
+	if err != nil {
+		baseLogger.Fatal("Invalid LEDGER_VERIFY_INTERVAL: ", err)
+	}
+	ledgerJob := &jobs.PeriodicJob{
+		Name:     "ledger-verify",
+		Interval: ledgerInterval,
+		Logger:   baseLogger,
+		Run: func(ctx context.Context) error {
+			_, err := ledgerService.Verify(ctx)
+			return err
+		},
+	}
+	ledgerJob.Start(context.Background())
+
+	// RATE_LIMIT_READS, RATE_LIMIT_TRANSFERS and RATE_LIMIT_IP are budgets like 600/1m; unset leaves requests unlimited
+	rateLimits := utils.RateLimitConfig{}
+	if rateLimits.Reads, err = parseRateLimit(os.Getenv("RATE_LIMIT_READS")); err != nil {
+		baseLogger.Fatal("Invalid RATE_LIMIT_READS: ", err)
+	}
+	if rateLimits.Transfers, err = parseRateLimit(os.Getenv("RATE_LIMIT_TRANSFERS")); err != nil {
+		baseLogger.Fatal("Invalid RATE_LIMIT_TRANSFERS: ", err)
+	}
+	if rateLimits.IP, err = parseRateLimit(os.Getenv("RATE_LIMIT_IP")); err != nil {
+		baseLogger.Fatal("Invalid RATE_LIMIT_IP: ", err)
+	}
+	rateLimitStore, err := newRateLimitStore(getEnv("RATE_LIMIT_STORE", sharedStoreMemory), *storageKind, os.Getenv("DB_URL"), db)
+	if err != nil {
+		baseLogger.Fatal("Invalid RATE_LIMIT_STORE: ", err)
+	}
+	// a bucket untouched for its period is full again and can be forgotten
+	rateLimitPeriod := max(rateLimits.Reads.Period, rateLimits.Transfers.Period, rateLimits.IP.Period, time.Minute)
+	rateLimitJob := &jobs.PeriodicJob{
+		Name:     "rate-limit-sweep",
+		Interval: rateLimitPeriod,
+		Logger:   baseLogger,
+		Run: func(ctx context.Context) error {
+			return rateLimitStore.Sweep(ctx, time.Now().Add(-rateLimitPeriod))
+		},
+	}
+	rateLimitJob.Start(context.Background())
+
+	// keys issued with a signing secret must sign their requests, see utils.RequestSignature
+	apiKeyAuth := getEnv("API_KEY_AUTH", "false") == "true"
+	signatures := utils.SignatureConfig{Required: getEnv("REQUEST_SIGNING_REQUIRED", "false") == "true"}
+	if signatures.Required && !apiKeyAuth {
+		baseLogger.Fatal("REQUEST_SIGNING_REQUIRED needs API_KEY_AUTH=true")
+	}
+	signatures.MaxSkew, err = time.ParseDuration(getEnv("REQUEST_SIGNING_MAX_SKEW", utils.DefaultSignatureMaxSkew.String()))
+	if err != nil || signatures.MaxSkew <= 0 {
+		baseLogger.Fatal("Invalid REQUEST_SIGNING_MAX_SKEW: ", getEnv("REQUEST_SIGNING_MAX_SKEW", ""))
+	}
+	nonceStore, err := newNonceStore(getEnv("NONCE_STORE", sharedStoreMemory), *storageKind, os.Getenv("DB_URL"), db)
+	if err != nil {
+		baseLogger.Fatal("Invalid NONCE_STORE: ", err)
+	}
+	nonceJob := &jobs.PeriodicJob{
+		Name:     "nonce-sweep",
+		Interval: signatures.MaxSkew,
+		Logger:   baseLogger,
+		Run: func(ctx context.Context) error {
+			return nonceStore.Sweep(ctx, time.Now())
+		},
+	}
+	nonceJob.Start(context.Background())
+
+	// JWT_AUTH=true accepts bearer tokens signed with a key of the JWT_JWKS file or URL
+	jwtAuth := getEnv("JWT_AUTH", "false") == "true"
+	var tokenVerifier ports.TokenVerifier
+	if jwtAuth {
+		tokenVerifier, err = newTokenVerifier(baseLogger)
+		if err != nil {
+			baseLogger.Fatal("Invalid JWT configuration: ", err)
+		}
 	}
 
 	e := echo.New()
+	// client IPs come from the connection unless TRUSTED_PROXIES names the proxies whose X-Forwarded-For is believed
+	e.IPExtractor, err = newIPExtractor(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		baseLogger.Fatal("Invalid TRUSTED_PROXIES: ", err)
+	}
 	e.GET("/docs/*", echoSwagger.WrapHandler)
+	e.GET("/debug/vars", echo.WrapHandler(expvar.Handler()))
 
+	web.CustomerRouter(customerController, e)
 	web.AccountRouter(accountController, e)
 	web.TransactionRouter(transactionController, e)
+	web.TransferBatchRouter(transferBatchController, e)
+	web.BalanceRouter(balanceController, e)
+	web.ImportRouter(importController, e)
 
 	e.Use(logger.LogTrafficMiddleware)
+	e.Use(utils.ReadPrimaryMiddleware)
+	// ahead of authentication, so requests with bad credentials cannot hammer the database unthrottled
+	e.Use(utils.IPRateLimitMiddleware(rateLimitStore, rateLimits))
+	// with API keys enabled too, requests without a bearer token need an API key
+	if jwtAuth {
+		e.Use(utils.BearerTokenMiddleware(tokenVerifier, apiKeyAuth))
+	}
+	if apiKeyAuth {
+		e.Use(utils.APIKeyMiddleware(apiKeyService))
+		e.Use(utils.SignatureMiddleware(nonceStore, signatures))
+	}
+	e.Use(utils.RateLimitMiddleware(rateLimitStore, rateLimits))
 
-	// Run server in a goroutine
+	// Run server in a goroutine; a memory demo may run without .env, so the port has a default
+	port := getEnv("APP_PORT", "8080")
 	go func() {
-		log.Printf("Server is running on port %s", os.Getenv("APP_PORT"))
-		if err := e.Start(fmt.Sprintf(":%s", os.Getenv("APP_PORT"))); err != nil && err != http.ErrServerClosed {
+		log.Printf("Server is running on port %s", port)
+		if err := e.Start(fmt.Sprintf(":%s", port)); err != nil && err != http.ErrServerClosed {
 			e.Logger.Fatalf("HTTP server error: %v", err)
 		}
 	}()
@@ -96,7 +349,150 @@ func main() {
 		"http-server": func(ctx context.Context) error {
 			return e.Shutdown(ctx)
 		},
+		"balance-snapshot-job": snapshotJob.Stop,
+		"ledger-verify-job":    ledgerJob.Stop,
+		"rate-limit-sweep-job": rateLimitJob.Stop,
+		"nonce-sweep-job":      nonceJob.Stop,
 	})
 
 	<-wait
 }
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// parseList splits a comma separated value, dropping empty items
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// newTokenVerifier loads the JWKS named by JWT_JWKS and checks tokens against
+// JWT_ISSUER and JWT_AUDIENCE when set
+func newTokenVerifier(logger *logrus.Logger) (*jwtauth.Verifier, error) {
+	source := os.Getenv("JWT_JWKS")
+	if source == "" {
+		return nil, fmt.Errorf("JWT_JWKS must name a JWKS file or URL")
+	}
+	refresh, err := time.ParseDuration(getEnv("JWT_JWKS_REFRESH", jwtauth.DefaultRefreshInterval.String()))
+	if err != nil || refresh <= 0 {
+		return nil, fmt.Errorf("invalid JWT_JWKS_REFRESH %q", getEnv("JWT_JWKS_REFRESH", ""))
+	}
+	leeway, err := time.ParseDuration(getEnv("JWT_LEEWAY", "30s"))
+	if err != nil || leeway < 0 {
+		return nil, fmt.Errorf("invalid JWT_LEEWAY %q", getEnv("JWT_LEEWAY", ""))
+	}
+
+	keys := &jwtauth.KeySet{
+		Source:    source,
+		Client:    &http.Client{Timeout: 10 * time.Second},
+		Refresh:   refresh,
+		MinReload: jwtauth.DefaultMinReload,
+		Logger:    logger,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := keys.Load(ctx); err != nil {
+		return nil, fmt.Errorf("loading %s: %w", source, err)
+	}
+	return &jwtauth.Verifier{
+		Keys:        keys,
+		Issuer:      os.Getenv("JWT_ISSUER"),
+		Audience:    os.Getenv("JWT_AUDIENCE"),
+		TenantClaim: getEnv("JWT_TENANT_CLAIM", jwtauth.DefaultTenantClaim),
+		Leeway:      leeway,
+	}, nil
+}
+
+// newIPExtractor takes client IPs from the connection, or with proxies, a
+// comma separated list of CIDR ranges, from the X-Forwarded-For entries those
+// proxies appended
+func newIPExtractor(proxies string) (echo.IPExtractor, error) {
+	ranges := parseList(proxies)
+	if len(ranges) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, value := range ranges {
+		_, ipRange, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
+// parseRateLimit reads a budget of REQUESTS/PERIOD, e.g. 600/1m; empty gives no limit
+func parseRateLimit(value string) (entities.RateLimit, error) {
+	if value == "" {
+		return entities.RateLimit{}, nil
+	}
+	requests, period, found := strings.Cut(value, "/")
+	burst, err := strconv.Atoi(strings.TrimSpace(requests))
+	if !found || err != nil || burst <= 0 {
+		return entities.RateLimit{}, fmt.Errorf("expected REQUESTS/PERIOD, got %q", value)
+	}
+	duration, err := time.ParseDuration(strings.TrimSpace(period))
+	if err != nil || duration <= 0 {
+		return entities.RateLimit{}, fmt.Errorf("invalid period in %q", value)
+	}
+	return entities.RateLimit{Burst: burst, Period: duration}, nil
+}
+
+// parseAmountRule reads the digits request amounts may have. The rule cannot be
+// wider than validator.Money, which matches the NUMERIC(20, 5) columns; an unset
+// scale keeps Money's and an unset precision keeps its integer digits
+func parseAmountRule(precision, scale string) (validator.DecimalRule, error) {
+	rule := validator.Money
+	if scale != "" {
+		s, err := strconv.Atoi(strings.TrimSpace(scale))
+		if err != nil {
+			return validator.DecimalRule{}, fmt.Errorf("invalid scale %q", scale)
+		}
+		rule = validator.DecimalRule{Precision: validator.Money.Precision - validator.Money.Scale + s, Scale: s}
+	}
+	if precision != "" {
+		p, err := strconv.Atoi(strings.TrimSpace(precision))
+		if err != nil {
+			return validator.DecimalRule{}, fmt.Errorf("invalid precision %q", precision)
+		}
+		rule.Precision = p
+	}
+	if rule.Scale < 0 || rule.Scale > validator.Money.Scale || rule.Precision <= rule.Scale ||
+		rule.Precision-rule.Scale > validator.Money.Precision-validator.Money.Scale {
+		return validator.DecimalRule{}, fmt.Errorf("expected at most %d integer and %d fraction digits, got precision %d and scale %d",
+			validator.Money.Precision-validator.Money.Scale, validator.Money.Scale, rule.Precision, rule.Scale)
+	}
+	return rule, nil
+}
+
+// parseSettlementAccounts reads CURRENCY=ID pairs separated by commas, e.g. USD=9001,EUR=9002
+func parseSettlementAccounts(value string) (map[string]int64, error) {
+	accounts := map[string]int64{}
+	if value == "" {
+		return accounts, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		currency, id, found := strings.Cut(strings.TrimSpace(pair), "=")
+		currency = strings.ToUpper(strings.TrimSpace(currency))
+		if !found || !validator.ValidateCurrency(currency) {
+			return nil, fmt.Errorf("expected CURRENCY=ID, got %q", pair)
+		}
+		accountID, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64)
+		if err != nil || accountID <= 0 {
+			return nil, fmt.Errorf("invalid account id in %q", pair)
+		}
+		accounts[currency] = accountID
+	}
+	return accounts, nil
+}
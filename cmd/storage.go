@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"transfer-system/adapters/nonces"
+	"transfer-system/adapters/ratelimit"
+	"transfer-system/adapters/repositories"
+	"transfer-system/domain/ports"
+	"transfer-system/infrastructure/datastore"
+	"transfer-system/infrastructure/memstore"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// storageDatabase uses the database named by DB_URL, PostgreSQL or SQLite
+	storageDatabase = "database"
+	// storageMemory keeps everything in process memory, for demos; data is lost on exit
+	storageMemory = "memory"
+)
+
+const (
+	// sharedStoreMemory keeps rate limit buckets and request nonces per instance
+	sharedStoreMemory = "memory"
+	// sharedStoreDatabase shares them between instances through PostgreSQL
+	sharedStoreDatabase = "database"
+)
+
+// storage is the database and the repositories built on it
+type storage struct {
+	DB               ports.Database
+	Customers        ports.CustomerRepository
+	Accounts         ports.AccountRepository
+	Transactions     ports.TransactionRepository
+	ImportJobs       ports.ImportJobRepository
+	BalanceSnapshots ports.BalanceSnapshotRepository
+	APIKeys          ports.APIKeyRepository
+	// BulkAccounts and BulkTransactions are set when the driver can COPY
+	BulkAccounts     ports.AccountBulkRepository
+	BulkTransactions ports.TransactionBulkRepository
+}
+
+// newStorage opens the storage of the kind; driver picks the PostgreSQL driver
+// and replicaURLs the read replicas, see datastore.Open
+func newStorage(kind, driver, dbURL string, replicaURLs []string, logger *logrus.Logger) (*storage, error) {
+	switch kind {
+	case storageDatabase:
+		db, err := datastore.Open(dbURL, driver, logger, replicaURLs...)
+		if err != nil {
+			return nil, err
+		}
+		if datastore.IsSQLite(dbURL) {
+			return &storage{
+				DB:               db,
+				Customers:        &repositories.CustomerRepositorySQLite{DB: db},
+				Accounts:         &repositories.AccountRepositorySQLite{DB: db},
+				Transactions:     &repositories.TransactionRepositorySQLite{DB: db},
+				ImportJobs:       &repositories.ImportJobRepositorySQLite{DB: db},
+				BalanceSnapshots: &repositories.BalanceSnapshotRepositorySQLite{DB: db},
+				APIKeys:          &repositories.APIKeyRepositorySQLite{DB: db},
+			}, nil
+		}
+		accounts := &repositories.AccountRepositoryPostgre{DB: db}
+		transactions := &repositories.TransactionRepositoryPostgre{DB: db}
+		store := &storage{
+			DB:               db,
+			Customers:        &repositories.CustomerRepositoryPostgre{DB: db},
+			Accounts:         accounts,
+			Transactions:     transactions,
+			ImportJobs:       &repositories.ImportJobRepositoryPostgre{DB: db},
+			BalanceSnapshots: &repositories.BalanceSnapshotRepositoryPostgre{DB: db},
+			APIKeys:          &repositories.APIKeyRepositoryPostgre{DB: db},
+		}
+		if driver == datastore.DriverPgx {
+			store.BulkAccounts = accounts
+			store.BulkTransactions = transactions
+		}
+		return store, nil
+	case storageMemory:
+		db := memstore.New()
+		return &storage{
+			DB:               db,
+			Customers:        &repositories.CustomerRepositoryMemory{DB: db},
+			Accounts:         &repositories.AccountRepositoryMemory{DB: db},
+			Transactions:     &repositories.TransactionRepositoryMemory{DB: db},
+			ImportJobs:       &repositories.ImportJobRepositoryMemory{DB: db},
+			BalanceSnapshots: &repositories.BalanceSnapshotRepositoryMemory{DB: db},
+			APIKeys:          &repositories.APIKeyRepositoryMemory{DB: db},
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown storage %q, expected %s or %s", kind, storageDatabase, storageMemory)
+}
+
+// newRateLimitStore builds the store of the rate limiter; the database store
+// needs PostgreSQL storage
+func newRateLimitStore(kind, storageKind, dbURL string, db ports.Database) (ports.RateLimitStore, error) {
+	switch kind {
+	case sharedStoreMemory:
+		return ratelimit.NewMemoryStore(), nil
+	case sharedStoreDatabase:
+		if storageKind != storageDatabase || datastore.IsSQLite(dbURL) {
+			return nil, fmt.Errorf("the %s rate limit store needs a postgres:// DB_URL", sharedStoreDatabase)
+		}
+		return &ratelimit.PostgreStore{DB: db, CtxTimeout: time.Second}, nil
+	}
+	return nil, fmt.Errorf("unknown rate limit store %q, expected %s or %s", kind, sharedStoreMemory, sharedStoreDatabase)
+}
+
+// newNonceStore builds the store remembering signed request nonces; the
+// database store needs PostgreSQL storage
+func newNonceStore(kind, storageKind, dbURL string, db ports.Database) (ports.NonceStore, error) {
+	switch kind {
+	case sharedStoreMemory:
+		return nonces.NewMemoryStore(), nil
+	case sharedStoreDatabase:
+		if storageKind != storageDatabase || datastore.IsSQLite(dbURL) {
+			return nil, fmt.Errorf("the %s nonce store needs a postgres:// DB_URL", sharedStoreDatabase)
+		}
+		return &nonces.PostgreStore{DB: db, CtxTimeout: time.Second}, nil
+	}
+	return nil, fmt.Errorf("unknown nonce store %q, expected %s or %s", kind, sharedStoreMemory, sharedStoreDatabase)
+}
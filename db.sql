@@ -1,13 +1,109 @@
+CREATE TABLE customers (
+    id serial primary key,
+    full_name varchar(200) NOT NULL,
+    email varchar(254) NOT NULL CONSTRAINT unique_customer_email UNIQUE,
+    phone varchar(32),
+    date_of_birth DATE,
+    country char(2),
+    kyc_tier varchar(16) NOT NULL DEFAULT 'unverified' CONSTRAINT valid_kyc_tier CHECK (kyc_tier IN ('unverified', 'basic', 'full')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- new accounts start at zero and are funded by an opening transfer from the
+-- currency's equity account, whose balance goes negative as money is issued.
+-- initial_balance is only set on accounts credited directly before that
 create table accounts (
-    id integer primary key,
-    balance NUMERIC(20, 5) NOT NULL DEFAULT 0.00000 CONSTRAINT positive_balance CHECK (balance >= 0),
-    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    id serial primary key,
+    balance NUMERIC(20, 5) NOT NULL DEFAULT 0.00000,
+    initial_balance NUMERIC(20, 5) NOT NULL DEFAULT 0.00000,
+    external_reference varchar(64) CONSTRAINT unique_external_reference UNIQUE,
+    currency char(3) NOT NULL DEFAULT 'USD',
+    kind varchar(16) NOT NULL DEFAULT 'customer' CONSTRAINT valid_kind CHECK (kind IN ('customer', 'equity', 'settlement')),
+    status varchar(16) NOT NULL DEFAULT 'active' CONSTRAINT valid_status CHECK (status IN ('active', 'frozen', 'closed')),
+    owner_id integer references customers(id),
+    metadata jsonb NOT NULL DEFAULT '{}',
+    -- bumped by every update of the row, balance updates included; served as the ETag
+    version bigint NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_balance CHECK (balance >= 0 OR kind <> 'customer')
 );
 
+-- one equity account per currency; settlement accounts mirror external accounts, so a currency may have several
+CREATE UNIQUE INDEX accounts_system_kind_currency_idx ON accounts (kind, currency) WHERE kind = 'equity';
+
+-- keyset pagination indexes for GET /accounts
+CREATE INDEX accounts_balance_id_idx ON accounts (balance, id);
+CREATE INDEX accounts_created_at_id_idx ON accounts (created_at, id);
+CREATE INDEX accounts_owner_id_idx ON accounts (owner_id);
+CREATE INDEX accounts_metadata_idx ON accounts USING GIN (metadata jsonb_path_ops);
+
 CREATE TABLE transactions (
     id serial primary key,
+    type varchar(16) NOT NULL DEFAULT 'transfer' CONSTRAINT valid_type CHECK (type IN ('transfer', 'deposit', 'withdrawal', 'fee', 'reversal')),
     source_id integer not null references accounts(id),
     destination_id integer not null references accounts(id),
     amount NUMERIC(20, 5) NOT NULL DEFAULT 0.00000 CONSTRAINT min_amount CHECK (amount > 0),
+    description varchar(140),
+    reference varchar(35),
+    remittance_creditor_reference varchar(25),
+    remittance_invoice_number varchar(35),
+    remittance_invoice_date date,
+    metadata jsonb NOT NULL DEFAULT '{}',
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
+
+-- daily limit checks sum a customer's outgoing transfers
+CREATE INDEX transactions_source_id_created_at_idx ON transactions (source_id, created_at);
+-- account history reads both sides of a transfer
+CREATE INDEX transactions_destination_id_created_at_idx ON transactions (destination_id, created_at);
+CREATE INDEX transactions_reference_idx ON transactions (reference);
+
+-- balance of an account at as_of, including transfers created at or before it;
+-- point-in-time queries start from the latest snapshot instead of the initial balance
+CREATE TABLE account_balance_snapshots (
+    account_id integer not null references accounts(id),
+    as_of TIMESTAMP NOT NULL,
+    balance NUMERIC(20, 5) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    primary key (account_id, as_of)
+);
+
+-- bulk CSV imports; committed_rows advances with each committed chunk so a job can be resumed
+CREATE TABLE import_jobs (
+    id uuid primary key,
+    kind varchar(16) NOT NULL CONSTRAINT valid_import_kind CHECK (kind IN ('accounts', 'transfers')),
+    status varchar(16) NOT NULL CONSTRAINT valid_import_status CHECK (status IN ('running', 'completed', 'failed')),
+    checksum char(64) NOT NULL,
+    total_rows integer NOT NULL,
+    committed_rows integer NOT NULL DEFAULT 0,
+    failed_line integer,
+    error text,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- API keys; only the SHA-256 hash of the secret is stored
+CREATE TABLE api_keys (
+    id serial primary key,
+    name varchar(100) NOT NULL,
+    prefix varchar(16) NOT NULL,
+    hash char(64) NOT NULL CONSTRAINT unique_api_key_hash UNIQUE,
+    -- requests made with a key that has a signing secret must carry an HMAC signature
+    signing_secret varchar(64),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+-- token buckets of the rate limiter when RATE_LIMIT_STORE=database, keyed by budget and client
+CREATE TABLE rate_limit_buckets (
+    key text primary key,
+    tokens double precision NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- nonces of signed requests, kept until their timestamp leaves the allowed clock skew
+CREATE TABLE request_nonces (
+    nonce varchar(200) primary key,
+    expires_at TIMESTAMP NOT NULL
+);
@@ -1,8 +1,87 @@
 package entities
 
-import "github.com/shopspring/decimal"
+import (
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const DefaultCurrency = "USD"
+
+type AccountStatus string
+
+const (
+	AccountStatusActive AccountStatus = "active"
+	AccountStatusFrozen AccountStatus = "frozen"
+	AccountStatusClosed AccountStatus = "closed"
+)
+
+// ErrAccountNotActive is returned when a transfer touches a frozen or closed account
+var ErrAccountNotActive = errors.New("account is not active")
+
+// ErrSystemAccount is returned when a client transfer touches a system account
+var ErrSystemAccount = errors.New("system account")
+
+// ErrVersionMismatch is returned when an account changed since the version an update was based on
+var ErrVersionMismatch = errors.New("account version mismatch")
+
+// ErrNoSettlementAccount is returned when a deposit or withdrawal names no
+// settlement account and none is configured for the currency
+var ErrNoSettlementAccount = errors.New("no settlement account")
+
+func (s AccountStatus) IsValid() bool {
+	switch s {
+	case AccountStatusActive, AccountStatusFrozen, AccountStatusClosed:
+		return true
+	}
+	return false
+}
+
+// AccountKind separates customer accounts from the accounts the system books against
+type AccountKind string
+
+const (
+	AccountKindCustomer AccountKind = "customer"
+	// AccountKindEquity funds opening balances. There is one per currency and
+	// its balance is minus the money issued in that currency
+	AccountKindEquity AccountKind = "equity"
+	// AccountKindSettlement mirrors an account held outside the system, such as
+	// a bank account; deposits and withdrawals pass through it
+	AccountKindSettlement AccountKind = "settlement"
+)
+
+// OpeningBalanceDescription marks the transfer that funds a new account from equity
+const OpeningBalanceDescription = "Opening balance"
+
+// IsSystem reports whether only the system books transfers against the account
+func (k AccountKind) IsSystem() bool {
+	return k != "" && k != AccountKindCustomer
+}
 
 type Account struct {
 	AccountID int64           `json:"id"`
 	Balance   decimal.Decimal `json:"balance"`
+	// InitialBalance is zero for accounts funded by an opening transfer; older
+	// accounts were credited directly
+	InitialBalance    decimal.Decimal `json:"initial_balance"`
+	ExternalReference string          `json:"external_reference,omitempty"`
+	Currency          string          `json:"currency"`
+	Kind              AccountKind     `json:"kind"`
+	Status            AccountStatus   `json:"status"`
+	OwnerID           *int64          `json:"owner_id,omitempty"`
+	Metadata          Metadata        `json:"metadata"`
+	// Version starts at 1 and goes up with every change to the account, its balance included
+	Version   int64     `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AccountPatch lists the attributes a PATCH may change; nil fields are left untouched
+type AccountPatch struct {
+	// Metadata is merged into the existing metadata, a nil value removes the key
+	Metadata map[string]*string
+	// Status freezes, unfreezes or closes the account
+	Status *AccountStatus
+	// Version, when set, applies the patch only if the account is still at that version
+	Version *int64
 }
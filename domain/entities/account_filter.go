@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor was not issued by a previous page
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type AccountSortField string
+
+const (
+	AccountSortById        AccountSortField = "id"
+	AccountSortByBalance   AccountSortField = "balance"
+	AccountSortByCreatedAt AccountSortField = "created_at"
+)
+
+// AccountFilter narrows an account listing. Nil or empty fields are not applied
+type AccountFilter struct {
+	Status      AccountStatus
+	Currency    string
+	MinBalance  *decimal.Decimal
+	MaxBalance  *decimal.Decimal
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	OwnerID     *int64
+	// Metadata matches accounts holding every listed key/value pair
+	Metadata     Metadata
+	SortBy       AccountSortField
+	SortDesc     bool
+	Cursor       string
+	Limit        int
+	IncludeTotal bool
+}
+
+// AccountPage is one page of a listing; NextCursor is empty on the last page
+type AccountPage struct {
+	Accounts   []*Account
+	NextCursor string
+	Total      *int64
+}
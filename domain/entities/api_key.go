@@ -0,0 +1,19 @@
+package entities
+
+import "time"
+
+// APIKey identifies a client of the API. Only the SHA-256 hash of the secret
+// is stored; the secret itself is shown once, when the key is issued
+type APIKey struct {
+	ID   int64
+	Name string
+	// Prefix is the start of the secret, enough to tell keys apart in listings and logs
+	Prefix string
+	Hash   string
+	// SigningSecret, when set, is the secret requests made with the key are
+	// signed with. Verifying a signature needs it, so unlike the key's secret
+	// it is stored as is
+	SigningSecret string
+	CreatedAt     time.Time
+	RevokedAt     *time.Time
+}
@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// AccountBalance is the balance of an account at a point in time
+type AccountBalance struct {
+	AccountID int64
+	Currency  string
+	Balance   decimal.Decimal
+	AsOf      time.Time
+}
+
+// BalanceSnapshot records the balance of an account including every transfer created at or before AsOf
+type BalanceSnapshot struct {
+	AccountID int64
+	AsOf      time.Time
+	Balance   decimal.Decimal
+}
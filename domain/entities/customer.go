@@ -0,0 +1,39 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type KYCTier string
+
+const (
+	KYCTierUnverified KYCTier = "unverified"
+	KYCTierBasic      KYCTier = "basic"
+	KYCTierFull       KYCTier = "full"
+)
+
+func (t KYCTier) IsValid() bool {
+	switch t {
+	case KYCTierUnverified, KYCTierBasic, KYCTierFull:
+		return true
+	}
+	return false
+}
+
+// KYCTierLimits caps the amount a customer may send per day, per tier.
+// Tiers missing from the map are not limited
+type KYCTierLimits map[KYCTier]decimal.Decimal
+
+type Customer struct {
+	CustomerID  int64      `json:"id"`
+	FullName    string     `json:"full_name"`
+	Email       string     `json:"email"`
+	Phone       string     `json:"phone,omitempty"`
+	DateOfBirth *time.Time `json:"date_of_birth,omitempty"`
+	Country     string     `json:"country,omitempty"`
+	KYCTier     KYCTier    `json:"kyc_tier"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
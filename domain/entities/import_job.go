@@ -0,0 +1,55 @@
+package entities
+
+import "time"
+
+type ImportKind string
+
+const (
+	ImportKindAccounts  ImportKind = "accounts"
+	ImportKindTransfers ImportKind = "transfers"
+)
+
+type ImportStatus string
+
+const (
+	ImportStatusRunning   ImportStatus = "running"
+	ImportStatusCompleted ImportStatus = "completed"
+	ImportStatusFailed    ImportStatus = "failed"
+)
+
+// ImportJob tracks a bulk import committed in chunks. CommittedRows only moves
+// forward with a committed chunk, so a failed or interrupted job resumes after it
+type ImportJob struct {
+	ID     string
+	Kind   ImportKind
+	Status ImportStatus
+	// Checksum is the SHA-256 of the uploaded file; a resumed job must be given the same file
+	Checksum      string
+	TotalRows     int
+	CommittedRows int
+	// FailedLine and Error describe the row that stopped the job
+	FailedLine int
+	Error      string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// ImportFile is a parsed upload; rows hold accounts or transfers depending on Kind
+type ImportFile struct {
+	Kind     ImportKind
+	Checksum string
+	Rows     []*ImportRow
+}
+
+// ImportRow is one data row; Line counts the header as line 1
+type ImportRow struct {
+	Line     int
+	Account  *Account
+	Transfer *Transaction
+}
+
+// ImportRowError reports why a row cannot be imported
+type ImportRowError struct {
+	Line    int
+	Message string
+}
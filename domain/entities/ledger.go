@@ -0,0 +1,56 @@
+package entities
+
+import "github.com/shopspring/decimal"
+
+// BalanceDrift is an account whose stored balance differs from its initial
+// balance plus the transfers it sent and received
+type BalanceDrift struct {
+	AccountID int64
+	Currency  string
+	Recorded  decimal.Decimal
+	Expected  decimal.Decimal
+}
+
+// Difference is how much the stored balance exceeds the expected one
+func (d *BalanceDrift) Difference() decimal.Decimal {
+	return d.Recorded.Sub(d.Expected)
+}
+
+// CurrencyTotal sums the accounts of one currency. Transfers only move money
+// between accounts of the same currency, so Balance must equal InitialBalance
+type CurrencyTotal struct {
+	Currency       string
+	Accounts       int
+	InitialBalance decimal.Decimal
+	Balance        decimal.Decimal
+}
+
+// Difference is how much money appeared, or vanished when negative, in the currency
+func (t *CurrencyTotal) Difference() decimal.Decimal {
+	return t.Balance.Sub(t.InitialBalance)
+}
+
+// Conserved reports whether no money was created or destroyed in the currency
+func (t *CurrencyTotal) Conserved() bool {
+	return t.Balance.Equal(t.InitialBalance)
+}
+
+// LedgerReport is the outcome of checking every account balance against the transfers
+type LedgerReport struct {
+	CheckedAccounts int
+	Drifts          []*BalanceDrift
+	Totals          []*CurrencyTotal
+}
+
+// Consistent reports whether no balance drifted and every currency conserved its money
+func (r *LedgerReport) Consistent() bool {
+	if len(r.Drifts) > 0 {
+		return false
+	}
+	for _, total := range r.Totals {
+		if !total.Conserved() {
+			return false
+		}
+	}
+	return true
+}
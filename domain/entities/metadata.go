@@ -0,0 +1,83 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+const (
+	MaxMetadataKeys        = 50
+	MaxMetadataKeyLength   = 40
+	MaxMetadataValueLength = 500
+)
+
+var metadataKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Metadata holds client defined key/value pairs (cost center, product code, ...)
+// attached to accounts and transactions. It is stored as a JSONB object
+type Metadata map[string]string
+
+// Validate enforces the key count and size limits
+func (m Metadata) Validate() error {
+	if len(m) > MaxMetadataKeys {
+		return fmt.Errorf("metadata can hold at most %d keys", MaxMetadataKeys)
+	}
+	for key, value := range m {
+		if len(key) > MaxMetadataKeyLength || !metadataKeyRegex.MatchString(key) {
+			return fmt.Errorf("metadata key %q must be 1-%d characters of letters, digits, _ or -", key, MaxMetadataKeyLength)
+		}
+		if utf8.RuneCountInString(value) > MaxMetadataValueLength {
+			return fmt.Errorf("metadata value for %q must be at most %d characters", key, MaxMetadataValueLength)
+		}
+	}
+	return nil
+}
+
+// Merge applies a patch: a nil value removes the key, any other value sets it
+func (m Metadata) Merge(patch map[string]*string) Metadata {
+	merged := Metadata{}
+	for key, value := range m {
+		merged[key] = value
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = *value
+	}
+	return merged
+}
+
+func (m Metadata) Value() (driver.Value, error) {
+	if m == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(m)
+}
+
+func (m *Metadata) Scan(src interface{}) error {
+	var raw []byte
+	switch value := src.(type) {
+	case nil:
+		*m = Metadata{}
+		return nil
+	case []byte:
+		raw = value
+	case string:
+		raw = []byte(value)
+	default:
+		return errors.New("metadata: unsupported column type")
+	}
+
+	decoded := Metadata{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return err
+	}
+	*m = decoded
+	return nil
+}
@@ -0,0 +1,24 @@
+package entities
+
+import "slices"
+
+// Scopes a bearer token needs for the account and transaction endpoints;
+// customers are covered by the account scopes
+const (
+	ScopeAccountsRead      = "accounts:read"
+	ScopeAccountsWrite     = "accounts:write"
+	ScopeTransactionsRead  = "transactions:read"
+	ScopeTransactionsWrite = "transactions:write"
+)
+
+// Principal is the caller a verified bearer token was issued to
+type Principal struct {
+	Subject string
+	// Tenant is empty when the token carries none
+	Tenant string
+	Scopes []string
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
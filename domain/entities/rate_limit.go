@@ -0,0 +1,58 @@
+package entities
+
+import (
+	"math"
+	"time"
+)
+
+// RateLimit is a token bucket budget: up to Burst requests at once, refilled
+// at Burst requests per Period
+type RateLimit struct {
+	Burst  int
+	Period time.Duration
+}
+
+// RateLimitDecision is the outcome of taking a token from a bucket
+type RateLimitDecision struct {
+	Allowed   bool
+	Remaining int
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is how long until the next token, zero when Allowed
+	RetryAfter time.Duration
+}
+
+// TokenBucket is the state of one client's budget. The zero value is a full bucket
+type TokenBucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// Take refills the bucket for the time passed since UpdatedAt and removes one
+// token from it if there is a whole one
+func (b *TokenBucket) Take(limit RateLimit, now time.Time) RateLimitDecision {
+	burst := float64(limit.Burst)
+	perSecond := burst / limit.Period.Seconds()
+
+	if b.UpdatedAt.IsZero() {
+		b.Tokens = burst
+	} else if elapsed := now.Sub(b.UpdatedAt).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(burst, b.Tokens+elapsed*perSecond)
+	}
+	b.UpdatedAt = now
+
+	decision := RateLimitDecision{}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = seconds((1 - b.Tokens) / perSecond)
+	}
+	decision.Remaining = int(b.Tokens)
+	decision.Reset = seconds((burst - b.Tokens) / perSecond)
+	return decision
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
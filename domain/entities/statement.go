@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// StatementHeader opens a statement for the period (From, To]
+type StatementHeader struct {
+	AccountID      int64
+	Currency       string
+	From           time.Time
+	To             time.Time
+	OpeningBalance decimal.Decimal
+	// ClosingBalance is known up front for formats that list balances before the entries
+	ClosingBalance decimal.Decimal
+}
+
+// StatementLine is one movement; Amount is signed from the account's point of view
+type StatementLine struct {
+	Transaction    *Transaction
+	Amount         decimal.Decimal
+	RunningBalance decimal.Decimal
+}
+
+// CounterpartyID is the other account of the transfer
+func (l StatementLine) CounterpartyID() int64 {
+	if l.Amount.IsNegative() {
+		return l.Transaction.DestinationAccountID
+	}
+	return l.Transaction.SourceAccountID
+}
@@ -1,10 +1,53 @@
 package entities
 
-import "github.com/shopspring/decimal"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TransactionType tells what a booking is for; every type moves Amount from the source to the destination account
+type TransactionType string
+
+const (
+	// TransactionTypeTransfer moves money between two customer accounts
+	TransactionTypeTransfer TransactionType = "transfer"
+	// TransactionTypeDeposit credits a customer account with money arriving from outside, through a settlement
+	// account, or funds its opening balance from equity
+	TransactionTypeDeposit TransactionType = "deposit"
+	// TransactionTypeWithdrawal debits a customer account with money leaving through a settlement account
+	TransactionTypeWithdrawal TransactionType = "withdrawal"
+	TransactionTypeFee        TransactionType = "fee"
+	TransactionTypeReversal   TransactionType = "reversal"
+)
+
+func (t TransactionType) IsValid() bool {
+	switch t {
+	case TransactionTypeTransfer, TransactionTypeDeposit, TransactionTypeWithdrawal, TransactionTypeFee, TransactionTypeReversal:
+		return true
+	}
+	return false
+}
 
 type Transaction struct {
 	Id                   int64
+	Type                 TransactionType
 	SourceAccountID      int64
 	DestinationAccountID int64
 	Amount               decimal.Decimal
+	// Description is the free-text purpose shown on statements
+	Description string
+	// Reference is the client's own reference for the transfer
+	Reference  string
+	Remittance *Remittance
+	Metadata   Metadata
+	CreatedAt  time.Time
+}
+
+// Remittance is structured remittance information identifying what the transfer pays for
+type Remittance struct {
+	// CreditorReference is an ISO 11649 creditor reference, e.g. RF18539007547034
+	CreditorReference string
+	InvoiceNumber     string
+	InvoiceDate       *time.Time
 }
@@ -0,0 +1,17 @@
+package entities
+
+// TransactionFilter narrows the transfer history of an account, newest first
+type TransactionFilter struct {
+	AccountID int64
+	Reference string
+	// Types keeps only transactions of these types; empty keeps all
+	Types  []TransactionType
+	Cursor string
+	Limit  int
+}
+
+// TransactionPage is one page of history; NextCursor is empty on the last page
+type TransactionPage struct {
+	Transactions []*Transaction
+	NextCursor   string
+}
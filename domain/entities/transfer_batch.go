@@ -0,0 +1,47 @@
+package entities
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+// Reasons a transfer is refused, wrapped by the service errors so batch reports can name them
+var (
+	ErrUnknownAccount      = errors.New("unknown account")
+	ErrCurrencyMismatch    = errors.New("currency mismatch")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrDailyLimitExceeded  = errors.New("daily limit exceeded")
+	ErrSelfTransfer        = errors.New("source and destination are the same account")
+	ErrInvalidAmount       = errors.New("amount is not positive")
+)
+
+// TransferBatch is a set of transfer instructions submitted in one message, such as a pain.001 file
+type TransferBatch struct {
+	MessageID    string
+	Instructions []*TransferInstruction
+}
+
+// TransferInstruction asks for one transfer between accounts named by the client
+type TransferInstruction struct {
+	// PaymentInformationID and InstructionID identify the instruction within the batch
+	PaymentInformationID string
+	InstructionID        string
+	// DebtorAccount and CreditorAccount hold an account external reference or account id
+	DebtorAccount   string
+	CreditorAccount string
+	Amount          decimal.Decimal
+	Currency        string
+	Description     string
+	Reference       string
+	Remittance      *Remittance
+}
+
+// TransferInstructionResult reports the outcome of one instruction of a batch
+type TransferInstructionResult struct {
+	Instruction *TransferInstruction
+	// Transaction is set when the transfer was booked
+	Transaction *Transaction
+	// Err explains why the instruction was rejected
+	Err error
+}
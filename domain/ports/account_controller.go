@@ -7,4 +7,6 @@ import (
 type AccountController interface {
 	Create(ctx echo.Context) error
 	FindById(ctx echo.Context) error
+	Update(ctx echo.Context) error
+	List(ctx echo.Context) error
 }
@@ -7,6 +7,28 @@ import (
 )
 
 type AccountRepository interface {
+	// Save inserts the account, generating its id when AccountID is zero
 	Save(ctx context.Context, tx Transaction, account *entities.Account) (*entities.Account, error)
+	// FindById reads the account without locking it
 	FindById(ctx context.Context, tx Transaction, id int64) (*entities.Account, error)
+	// FindByIdForUpdate reads and locks the account until tx ends, so its balance can be checked then changed
+	FindByIdForUpdate(ctx context.Context, tx Transaction, id int64) (*entities.Account, error)
+	FindByExternalReference(ctx context.Context, tx Transaction, reference string) (*entities.Account, error)
+	// SystemAccount returns the system account of the kind and currency, creating it on first use.
+	// Only kinds with a single account per currency, such as equity, qualify
+	SystemAccount(ctx context.Context, tx Transaction, kind entities.AccountKind, currency string) (*entities.Account, error)
+	// Update persists the mutable attributes (metadata, status) of an existing account and bumps
+	// its version. It returns entities.ErrVersionMismatch unless the account is still at account.Version
+	Update(ctx context.Context, tx Transaction, account *entities.Account) (*entities.Account, error)
+	// List returns one page of accounts matching filter, ordered by filter.SortBy then id
+	List(ctx context.Context, tx Transaction, filter entities.AccountFilter) (*entities.AccountPage, error)
+	// SumByCurrency totals the initial and current balances per currency, ordered by currency
+	SumByCurrency(ctx context.Context, tx Transaction) ([]*entities.CurrencyTotal, error)
+}
+
+// AccountBulkRepository inserts many accounts in one round trip, for imports
+type AccountBulkRepository interface {
+	// SaveMany inserts the accounts as given, balance and initial balance
+	// included, generating the ids left at zero. CreatedAt is not read back
+	SaveMany(ctx context.Context, tx Transaction, accounts []*entities.Account) error
 }
@@ -7,6 +7,9 @@ import (
 )
 
 type AccountService interface {
-	Save(ctx context.Context, request *entities.Account) error
+	Save(ctx context.Context, request *entities.Account) (*entities.Account, error)
 	FindById(ctx context.Context, id int64) (*entities.Account, error)
+	FindByExternalReference(ctx context.Context, reference string) (*entities.Account, error)
+	Update(ctx context.Context, id int64, patch entities.AccountPatch) (*entities.Account, error)
+	List(ctx context.Context, filter entities.AccountFilter) (*entities.AccountPage, error)
 }
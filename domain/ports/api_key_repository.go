@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+
+	"transfer-system/domain/entities"
+)
+
+type APIKeyRepository interface {
+	Save(ctx context.Context, tx Transaction, key *entities.APIKey) (*entities.APIKey, error)
+	FindByHash(ctx context.Context, tx Transaction, hash string) (*entities.APIKey, error)
+}
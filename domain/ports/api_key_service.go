@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+
+	"transfer-system/domain/entities"
+)
+
+type APIKeyService interface {
+	// Issue creates a key and returns it with its secret, which cannot be retrieved later.
+	// With signing the key also gets a SigningSecret its requests must be signed with
+	Issue(ctx context.Context, name string, signing bool) (*entities.APIKey, string, error)
+	// Authenticate returns the active key the secret belongs to
+	Authenticate(ctx context.Context, secret string) (*entities.APIKey, error)
+}
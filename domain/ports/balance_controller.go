@@ -0,0 +1,10 @@
+package ports
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+type BalanceController interface {
+	BalanceAsOf(ctx echo.Context) error
+	Statement(ctx echo.Context) error
+}
@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"transfer-system/domain/entities"
+)
+
+type BalanceService interface {
+	// BalanceAsOf returns the balance of the account including every transfer created at or before asOf
+	BalanceAsOf(ctx context.Context, accountID int64, asOf time.Time) (*entities.AccountBalance, error)
+	// Statement streams the movements of the account created in (from, to] to writer
+	Statement(ctx context.Context, accountID int64, from, to time.Time, writer StatementWriter) error
+	// CreateSnapshots snapshots every account with transfers since its latest snapshot and returns how many were written
+	CreateSnapshots(ctx context.Context, asOf time.Time) (int, error)
+}
@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"transfer-system/domain/entities"
+)
+
+type BalanceSnapshotRepository interface {
+	// Save stores the snapshot; saving the same account and AsOf twice is a no-op
+	Save(ctx context.Context, tx Transaction, snapshot *entities.BalanceSnapshot) error
+	// FindLatest returns the newest snapshot taken at or before asOf, sql.ErrNoRows when there is none
+	FindLatest(ctx context.Context, tx Transaction, accountID int64, asOf time.Time) (*entities.BalanceSnapshot, error)
+	// ListStaleAccounts returns ids above afterID of accounts with transfers newer than their latest snapshot, up to asOf
+	ListStaleAccounts(ctx context.Context, tx Transaction, asOf time.Time, afterID int64, limit int) ([]int64, error)
+}
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+
+	"transfer-system/domain/entities"
+)
+
+type CustomerRepository interface {
+	Save(ctx context.Context, tx Transaction, customer *entities.Customer) (*entities.Customer, error)
+	FindById(ctx context.Context, tx Transaction, id int64) (*entities.Customer, error)
+	// FindByIdForUpdate locks the customer row until tx ends
+	FindByIdForUpdate(ctx context.Context, tx Transaction, id int64) (*entities.Customer, error)
+	Update(ctx context.Context, tx Transaction, customer *entities.Customer) (*entities.Customer, error)
+	Delete(ctx context.Context, tx Transaction, id int64) error
+}
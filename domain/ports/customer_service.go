@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+
+	"transfer-system/domain/entities"
+)
+
+type CustomerService interface {
+	Save(ctx context.Context, request *entities.Customer) (*entities.Customer, error)
+	FindById(ctx context.Context, id int64) (*entities.Customer, error)
+	Update(ctx context.Context, request *entities.Customer) (*entities.Customer, error)
+	Delete(ctx context.Context, id int64) error
+}
@@ -3,13 +3,28 @@ package ports
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
-// DBTX interface for database operations (shared by *sql.DB and *sql.Tx)
+// Row is the result of QueryRowContext; query errors surface on Scan,
+// sql.ErrNoRows when there was no row. *sql.Row satisfies it
+type Row interface {
+	Scan(dest ...interface{}) error
+}
+
+// Rows iterates over the result of QueryContext. *sql.Rows satisfies it
+type Rows interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+	Close() error
+}
+
+// DBTX interface for database operations (database/sql transactions and pgx transactions)
 type DBTX interface {
 	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
-	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
-	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (Rows, error)
 }
 
 // Transaction interface (wraps DBTX)
@@ -19,8 +34,49 @@ type Transaction interface {
 	Rollback() error
 }
 
+// Copier is implemented by transactions that can load many rows in one
+// statement, COPY FROM on the pgx driver
+type Copier interface {
+	CopyFrom(ctx context.Context, table string, columns []string, rows [][]interface{}) (int64, error)
+}
+
+// SingleConnection is implemented by databases that run one transaction at a
+// time, SQLite. Callers that write to a client while reading buffer the rows
+// and commit first, so a slow client does not stall every other request
+type SingleConnection interface {
+	SingleConnection()
+}
+
+// IsolationLevel of a transaction; the zero value keeps the database's default
+type IsolationLevel int
+
+const (
+	IsolationDefault IsolationLevel = iota
+	IsolationReadCommitted
+	IsolationRepeatableRead
+	IsolationSerializable
+)
+
+// TxOptions tune a transaction; the zero value gives the same transaction as no options
+type TxOptions struct {
+	Isolation IsolationLevel
+	// ReadOnly transactions reject writes and row locks, and see a snapshot
+	// that in-flight transfers do not block. They may be served by a replica
+	ReadOnly bool
+	// LockTimeout bounds each wait for a row lock, failing the statement with
+	// SQLSTATE 55P03; zero waits until the context ends
+	LockTimeout time.Duration
+}
+
+// ReadPrimaryContextKey, set to true on a context, keeps the read-only
+// transactions begun with it on the primary, so a client reads its own writes
+// without waiting for replicas to catch up
+const ReadPrimaryContextKey string = "read_primary"
+
 // Database interface
 type Database interface {
-	BeginTx(ctx context.Context) (Transaction, error)
+	// BeginTx starts a transaction; only the first options given are used.
+	// Without options the transaction is read-write at repeatable read
+	BeginTx(ctx context.Context, opts ...TxOptions) (Transaction, error)
 	Close() error
 }
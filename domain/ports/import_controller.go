@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+type ImportController interface {
+	ImportAccounts(ctx echo.Context) error
+	ImportTransfers(ctx echo.Context) error
+	FindJob(ctx echo.Context) error
+}
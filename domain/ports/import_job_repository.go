@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+
+	"transfer-system/domain/entities"
+)
+
+type ImportJobRepository interface {
+	Save(ctx context.Context, tx Transaction, job *entities.ImportJob) (*entities.ImportJob, error)
+	// FindById locks the job so concurrent resumes of the same job are serialized
+	FindById(ctx context.Context, tx Transaction, id string) (*entities.ImportJob, error)
+	// Update persists the status and progress of the job
+	Update(ctx context.Context, tx Transaction, job *entities.ImportJob) error
+}
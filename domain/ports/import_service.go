@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+
+	"transfer-system/domain/entities"
+)
+
+type ImportService interface {
+	// Validate checks the rows against existing data without writing anything
+	Validate(ctx context.Context, file *entities.ImportFile) ([]entities.ImportRowError, error)
+	// Import commits the rows in chunks as a new job, or continues jobID after its last committed chunk.
+	// The job is returned with its progress even when a chunk fails
+	Import(ctx context.Context, file *entities.ImportFile, jobID string) (*entities.ImportJob, error)
+	FindJob(ctx context.Context, id string) (*entities.ImportJob, error)
+}
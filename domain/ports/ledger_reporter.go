@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+
+	"transfer-system/domain/entities"
+)
+
+// LedgerReporter publishes the outcome of a ledger check, as metrics or alerts
+type LedgerReporter interface {
+	Report(ctx context.Context, report *entities.LedgerReport) error
+}
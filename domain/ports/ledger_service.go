@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+
+	"transfer-system/domain/entities"
+)
+
+type LedgerService interface {
+	// Verify recomputes every balance from the initial balance and the transfers,
+	// reports the accounts that differ and checks that each currency's total is conserved
+	Verify(ctx context.Context) (*entities.LedgerReport, error)
+}
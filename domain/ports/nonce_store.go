@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// NonceStore remembers the nonces of signed requests so none is accepted twice.
+// Instances sharing a store reject each other's replays
+type NonceStore interface {
+	// Remember records nonce until expires and reports whether it was new;
+	// a nonce whose earlier use has expired is new again
+	Remember(ctx context.Context, nonce string, expires time.Time) (bool, error)
+	// Sweep forgets nonces that expired before
+	Sweep(ctx context.Context, before time.Time) error
+}
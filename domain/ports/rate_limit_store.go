@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"transfer-system/domain/entities"
+)
+
+// RateLimitStore keeps the token buckets of the rate limiter. Instances
+// sharing a store share their clients' budgets
+type RateLimitStore interface {
+	// Take removes one token from the bucket named key, creating it full
+	Take(ctx context.Context, key string, limit entities.RateLimit) (entities.RateLimitDecision, error)
+	// Sweep forgets buckets untouched since before, which have refilled by now
+	Sweep(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"github.com/shopspring/decimal"
+
+	"transfer-system/domain/entities"
+)
+
+// StatementWriter renders a statement as it is produced, so a statement of any
+// length is never held in memory, except on a SingleConnection database. Begin is called once, then WriteLine for every
+// movement oldest first, then End with the closing balance
+type StatementWriter interface {
+	Begin(header entities.StatementHeader) error
+	WriteLine(line entities.StatementLine) error
+	End(closingBalance decimal.Decimal) error
+}
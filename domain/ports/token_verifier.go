@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+
+	"transfer-system/domain/entities"
+)
+
+// PrincipalContextKey holds the *entities.Principal of a request authenticated
+// with a bearer token
+const PrincipalContextKey string = "principal"
+
+type TokenVerifier interface {
+	// Verify checks the signature and claims of a bearer token and returns who it was issued to
+	Verify(ctx context.Context, token string) (*entities.Principal, error)
+}
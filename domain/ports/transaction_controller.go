@@ -6,4 +6,8 @@ import (
 
 type TransactionController interface {
 	Save(ctx echo.Context) error
+	FindById(ctx echo.Context) error
+	ListByAccount(ctx echo.Context) error
+	Deposit(ctx echo.Context) error
+	Withdraw(ctx echo.Context) error
 }
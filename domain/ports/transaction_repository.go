@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"transfer-system/domain/entities"
 
@@ -10,5 +11,21 @@ import (
 
 type TransactionRepository interface {
 	Save(ctx context.Context, tx Transaction, transaction *entities.Transaction) (*entities.Transaction, error)
+	FindById(ctx context.Context, tx Transaction, id int64) (*entities.Transaction, error)
+	// ListByAccount pages through transfers sent or received by the account, newest first
+	ListByAccount(ctx context.Context, tx Transaction, filter entities.TransactionFilter) (*entities.TransactionPage, error)
 	UpdateBalance(ctx context.Context, tx Transaction, accountID int64, amount decimal.Decimal) error
+	// StreamByAccount calls fn for every transfer of the account created in (after, until], oldest first,
+	// without loading them all into memory. Iteration stops at the first error returned by fn
+	StreamByAccount(ctx context.Context, tx Transaction, accountID int64, after, until time.Time, fn func(*entities.Transaction) error) error
+	// NetMovement sums incoming minus outgoing transfers of the account created in (after, until]; a nil after starts at the first transfer
+	NetMovement(ctx context.Context, tx Transaction, accountID int64, after *time.Time, until time.Time) (decimal.Decimal, error)
+	// SumOutgoingByOwner totals transfers sent since the given time from every account of the customer
+	SumOutgoingByOwner(ctx context.Context, tx Transaction, ownerID int64, since time.Time) (decimal.Decimal, error)
+}
+
+// TransactionBulkRepository inserts many transactions in one round trip, for imports
+type TransactionBulkRepository interface {
+	// SaveMany inserts the transactions without touching balances; their ids are not read back
+	SaveMany(ctx context.Context, tx Transaction, transactions []*entities.Transaction) error
 }
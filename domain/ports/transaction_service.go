@@ -6,5 +6,11 @@ import (
 )
 
 type TransactionService interface {
-	Save(ctx context.Context, request *entities.Transaction) error
+	Save(ctx context.Context, request *entities.Transaction) (*entities.Transaction, error)
+	FindById(ctx context.Context, id int64) (*entities.Transaction, error)
+	ListByAccount(ctx context.Context, filter entities.TransactionFilter) (*entities.TransactionPage, error)
+	// Deposit credits DestinationAccountID through the settlement account SourceAccountID, zero picks the currency's default
+	Deposit(ctx context.Context, request *entities.Transaction) (*entities.Transaction, error)
+	// Withdraw debits SourceAccountID through the settlement account DestinationAccountID, zero picks the currency's default
+	Withdraw(ctx context.Context, request *entities.Transaction) (*entities.Transaction, error)
 }
@@ -0,0 +1,9 @@
+package ports
+
+import (
+	"github.com/labstack/echo/v4"
+)
+
+type TransferBatchController interface {
+	Import(ctx echo.Context) error
+}
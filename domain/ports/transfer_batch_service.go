@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+
+	"transfer-system/domain/entities"
+)
+
+type TransferBatchService interface {
+	// Execute books every instruction of the batch on its own and reports the outcome of each, in order
+	Execute(ctx context.Context, batch *entities.TransferBatch) ([]*entities.TransferInstructionResult, error)
+}
@@ -11,24 +11,45 @@ import (
 	appErrors "transfer-system/pkg/errors"
 	"transfer-system/pkg/logger"
 
+	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	DefaultAccountPageSize = 50
+	MaxAccountPageSize     = 200
+)
+
+// readSnapshot is for transactions that only read: they take no row locks,
+// every statement sees the same snapshot and a replica may serve them
+var readSnapshot = ports.TxOptions{Isolation: ports.IsolationRepeatableRead, ReadOnly: true}
+
+// readCommitted is for transactions that wait on row locks and must then read
+// what the lock holder committed, such as balances and daily totals
+var readCommitted = ports.TxOptions{Isolation: ports.IsolationReadCommitted}
+
 type AccountServiceImpl struct {
-	DB                ports.Database
-	AccountRepository ports.AccountRepository
-	CtxTimeout        time.Duration
+	DB                    ports.Database
+	AccountRepository     ports.AccountRepository
+	CustomerRepository    ports.CustomerRepository
+	TransactionRepository ports.TransactionRepository
+	CtxTimeout            time.Duration
 }
 
-func (s *AccountServiceImpl) Save(c context.Context, request *entities.Account) error {
+// Save creates the account. A zero AccountID lets the database pick the id,
+// which is returned on the created account
+func (s *AccountServiceImpl) Save(c context.Context, request *entities.Account) (*entities.Account, error) {
 	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
 
 	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
 	defer cancel()
 
-	tx, err := s.DB.BeginTx(ctx)
+	// read committed, so concurrent creations in a currency queue on the
+	// equity account's row lock rather than failing to serialize
+	tx, err := s.DB.BeginTx(ctx, readCommitted)
 	if err != nil {
-		return err
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
 	}
 
 	defer func() {
@@ -37,46 +58,170 @@ func (s *AccountServiceImpl) Save(c context.Context, request *entities.Account)
 		}
 	}()
 
-	_, err = s.AccountRepository.FindById(ctx, tx, request.AccountID)
+	account, err := s.create(ctx, tx, request)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, databaseError(err)
+	}
+
+	return account, nil
+}
+
+// create checks and inserts the account within tx, leaving the commit to the caller
+func (s *AccountServiceImpl) create(ctx context.Context, tx ports.Transaction, request *entities.Account) (*entities.Account, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	account, err := s.prepare(ctx, tx, request)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.AccountRepository.Save(ctx, tx, account)
+	if err != nil {
+		if isUniqueViolation(err) {
+			logger.WithError(err).Error("Account already exists")
+			return nil, appErrors.NewConflictError("Account already exists", err)
+		}
+		logger.WithError(err).Error("Database error")
+		return nil, databaseError(err)
+	}
+
+	if request.Balance.IsPositive() {
+		if err = s.fund(ctx, tx, account, request.Balance); err != nil {
+			return nil, err
+		}
+	}
+
+	return account, nil
+}
+
+// prepare runs the checks of create and returns the account to insert, with
+// a zero balance: the requested one arrives with the opening transfer
+func (s *AccountServiceImpl) prepare(ctx context.Context, tx ports.Transaction, request *entities.Account) (*entities.Account, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	if request.AccountID != 0 {
+		_, err := s.AccountRepository.FindById(ctx, tx, request.AccountID)
+		if err == nil {
+			logger.Errorf("AccountID %d already exists", request.AccountID)
+			return nil, appErrors.NewConflictError("AccountId already exists", nil)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.WithError(err).Error("Database error")
+			return nil, databaseError(err)
+		}
+	}
 
-		} else {
+	if request.ExternalReference != "" {
+		_, err := s.AccountRepository.FindByExternalReference(ctx, tx, request.ExternalReference)
+		if err == nil {
+			logger.Errorf("External reference %s already exists", request.ExternalReference)
+			return nil, appErrors.NewConflictError("External reference already exists", nil)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
 			logger.WithError(err).Error("Database error")
-			return appErrors.NewInternalServerError("Currently we're facing an issue", err)
+			return nil, databaseError(err)
 		}
-	} else {
-		logger.Errorf("AccountID %d already exists", request.AccountID)
-		return appErrors.NewBadRequestError("AccountId already exists", err)
 	}
 
-	account := entities.Account{
-		AccountID: request.AccountID,
-		Balance:   request.Balance,
+	// settlement accounts are opened by operators; equity accounts only by the system
+	kind := request.Kind
+	if kind == "" {
+		kind = entities.AccountKindCustomer
+	}
+	if kind != entities.AccountKindCustomer && kind != entities.AccountKindSettlement {
+		logger.Errorf("Cannot create a %s account", kind)
+		return nil, appErrors.NewBadRequestError("Invalid kind, expected customer or settlement", nil)
+	}
+	if kind.IsSystem() && request.Balance.IsPositive() {
+		logger.Errorf("Cannot fund a new %s account", kind)
+		return nil, appErrors.NewBadRequestError("System accounts start with a zero balance", nil)
 	}
-	_, err = s.AccountRepository.Save(ctx, tx, &account)
 
+	if request.OwnerID != nil {
+		_, err := s.CustomerRepository.FindById(ctx, tx, *request.OwnerID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logger.Errorf("Owner CustomerID %d not found", *request.OwnerID)
+				return nil, appErrors.NewUnprocessableError("Owner customer not found", err)
+			}
+			logger.WithError(err).Error("Database error")
+			return nil, databaseError(err)
+		}
+	}
+
+	account := &entities.Account{
+		AccountID:         request.AccountID,
+		Balance:           decimal.Zero,
+		ExternalReference: request.ExternalReference,
+		Currency:          request.Currency,
+		Kind:              kind,
+		Status:            entities.AccountStatusActive,
+		OwnerID:           request.OwnerID,
+		Metadata:          request.Metadata,
+	}
+	if account.Currency == "" {
+		account.Currency = entities.DefaultCurrency
+	}
+
+	return account, nil
+}
+
+// fund books the opening balance as a transfer from the currency's equity
+// account, so the transactions explain the whole balance. Every funded
+// creation in a currency debits that one account, so it is locked first and
+// the creations take turns; tx must be read committed for them to succeed
+func (s *AccountServiceImpl) fund(ctx context.Context, tx ports.Transaction, account *entities.Account, amount decimal.Decimal) error {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	equity, err := s.AccountRepository.SystemAccount(ctx, tx, entities.AccountKindEquity, account.Currency)
 	if err != nil {
-		logger.WithError(err).Error("Database error")
-		return err
+		logger.WithError(err).Errorf("Failed to get the %s equity account", account.Currency)
+		return databaseError(err)
+	}
+	if equity, err = s.AccountRepository.FindByIdForUpdate(ctx, tx, equity.AccountID); err != nil {
+		logger.WithError(err).Errorf("Failed to lock the %s equity account", account.Currency)
+		return databaseError(err)
 	}
 
-	if err := tx.Commit(); err != nil {
-		return err
+	opening := &entities.Transaction{
+		Type:                 entities.TransactionTypeDeposit,
+		SourceAccountID:      equity.AccountID,
+		DestinationAccountID: account.AccountID,
+		Amount:               amount,
+		Description:          entities.OpeningBalanceDescription,
+	}
+	if _, err = s.TransactionRepository.Save(ctx, tx, opening); err != nil {
+		logger.WithError(err).Error("Failed to save opening transaction")
+		return databaseError(err)
+	}
+	if err = s.TransactionRepository.UpdateBalance(ctx, tx, equity.AccountID, amount.Neg()); err != nil {
+		logger.WithError(err).Error("Failed to update equity account balance")
+		return databaseError(err)
+	}
+	if err = s.TransactionRepository.UpdateBalance(ctx, tx, account.AccountID, amount); err != nil {
+		logger.WithError(err).Error("Failed to update account balance")
+		return databaseError(err)
 	}
 
+	account.Balance = amount
 	return nil
 }
 
+// FindById reads the account from a read-only snapshot, without waiting for transfers in flight
 func (s *AccountServiceImpl) FindById(c context.Context, id int64) (*entities.Account, error) {
 	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
 
 	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
 	defer cancel()
 
-	tx, err := s.DB.BeginTx(ctx)
+	tx, err := s.DB.BeginTx(ctx, readSnapshot)
 	if err != nil {
-		return nil, err
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
 	}
 
 	defer func() {
@@ -89,21 +234,183 @@ func (s *AccountServiceImpl) FindById(c context.Context, id int64) (*entities.Ac
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			logger.Errorf("AccountID %d not found", id)
-			return nil, appErrors.NewBadRequestError("Account not found", err)
+			return nil, appErrors.NewNotFoundError("Account not found", err)
 		}
 
 		logger.WithError(err).Error("Database error")
-		return nil, err
+		return nil, databaseError(err)
 	}
 
-	accountResponse := &entities.Account{
-		AccountID: accountResult.AccountID,
-		Balance:   accountResult.Balance,
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, databaseError(err)
 	}
 
-	if err := tx.Commit(); err != nil {
+	return accountResult, nil
+}
+
+func (s *AccountServiceImpl) FindByExternalReference(c context.Context, reference string) (*entities.Account, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx, readSnapshot)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	account, err := s.AccountRepository.FindByExternalReference(ctx, tx, reference)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf("External reference %s not found", reference)
+			return nil, appErrors.NewNotFoundError("Account not found", err)
+		}
+
+		logger.WithError(err).Error("Database error")
+		return nil, databaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, databaseError(err)
+	}
+
+	return account, nil
+}
+
+// Update applies a partial update to the account attributes. With patch.Version
+// the account is read without a lock and only written if no one changed it
+// meanwhile, else it fails with 412. Without it the row is locked while it is
+// read and written, so the update always applies
+func (s *AccountServiceImpl) Update(c context.Context, id int64, patch entities.AccountPatch) (*entities.Account, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	// read committed, so the versioned UPDATE sees a concurrent change and
+	// matches no row rather than failing to serialize
+	tx, err := s.DB.BeginTx(ctx, readCommitted)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	find := s.AccountRepository.FindByIdForUpdate
+	if patch.Version != nil {
+		find = s.AccountRepository.FindById
+	}
+	account, err := find(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf("AccountID %d not found", id)
+			return nil, appErrors.NewNotFoundError("Account not found", err)
+		}
+		logger.WithError(err).Error("Database error")
+		return nil, databaseError(err)
+	}
+
+	if patch.Version != nil && *patch.Version != account.Version {
+		logger.Errorf("AccountID %d is at version %d, not %d", id, account.Version, *patch.Version)
+		err = appErrors.NewPreconditionFailedError("Account was modified, fetch it again and retry", entities.ErrVersionMismatch)
 		return nil, err
 	}
 
-	return accountResponse, nil
+	if patch.Metadata != nil {
+		account.Metadata = account.Metadata.Merge(patch.Metadata)
+		if validationErr := account.Metadata.Validate(); validationErr != nil {
+			logger.WithError(validationErr).Errorf("Invalid metadata for AccountID %d", id)
+			err = appErrors.NewUnprocessableError(validationErr.Error(), validationErr)
+			return nil, err
+		}
+	}
+
+	if patch.Status != nil {
+		if !patch.Status.IsValid() {
+			logger.Errorf("Invalid status %q for AccountID %d", *patch.Status, id)
+			err = appErrors.NewBadRequestError("Invalid status, expected active, frozen or closed", nil)
+			return nil, err
+		}
+		if account.Status == entities.AccountStatusClosed && *patch.Status != entities.AccountStatusClosed {
+			logger.Errorf("AccountID %d is closed", id)
+			err = appErrors.NewConflictError("Account is closed", entities.ErrAccountNotActive)
+			return nil, err
+		}
+		account.Status = *patch.Status
+	}
+
+	updated, err := s.AccountRepository.Update(ctx, tx, account)
+	if err != nil {
+		if errors.Is(err, entities.ErrVersionMismatch) {
+			logger.Errorf("AccountID %d was modified during the update", id)
+			return nil, appErrors.NewPreconditionFailedError("Account was modified, fetch it again and retry", err)
+		}
+		logger.WithError(err).Error("Database error")
+		return nil, databaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, databaseError(err)
+	}
+
+	return updated, nil
+}
+
+func (s *AccountServiceImpl) List(c context.Context, filter entities.AccountFilter) (*entities.AccountPage, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultAccountPageSize
+	}
+	if filter.Limit > MaxAccountPageSize {
+		filter.Limit = MaxAccountPageSize
+	}
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx, readSnapshot)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	page, err := s.AccountRepository.List(ctx, tx, filter)
+	if err != nil {
+		if errors.Is(err, entities.ErrInvalidCursor) {
+			logger.Errorf("Invalid cursor %s", filter.Cursor)
+			return nil, appErrors.NewBadRequestError("Invalid cursor", err)
+		}
+
+		logger.WithError(err).Error("Database error")
+		return nil, databaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, databaseError(err)
+	}
+
+	return page, nil
 }
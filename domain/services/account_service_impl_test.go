@@ -3,10 +3,14 @@ package services_test
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
 	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
 	"transfer-system/domain/services"
 	"transfer-system/mocks"
 	"transfer-system/pkg/logger"
@@ -24,32 +28,78 @@ func TestAccountService_Save_Success(t *testing.T) {
 
 	mockDB := new(mocks.MockDatabase)
 	mockRepo := new(mocks.MockAccountRepository)
+	mockTxRepo := new(mocks.MockTransactionRepository)
 	mockTx := new(mocks.MockTransaction)
 
 	service := &services.AccountServiceImpl{
-		DB:                mockDB,
-		AccountRepository: mockRepo,
-		CtxTimeout:        2 * time.Second,
+		DB:                    mockDB,
+		AccountRepository:     mockRepo,
+		TransactionRepository: mockTxRepo,
+		CtxTimeout:            2 * time.Second,
 	}
 
 	account := &entities.Account{
 		AccountID: 12345,
 		Balance:   decimal.NewFromFloat(100.23344),
+		Currency:  "USD",
+		Status:    entities.AccountStatusActive,
 	}
+	equity := &entities.Account{AccountID: 1, Currency: "USD", Kind: entities.AccountKindEquity}
 
-	mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
 	mockRepo.On("FindById", mock.Anything, mockTx, account.AccountID).Return(nil, sql.ErrNoRows)
-	mockRepo.On("Save", mock.Anything, mockTx, account).Return(account, nil)
+	// the account is inserted empty and funded by the opening transfer
+	mockRepo.On("Save", mock.Anything, mockTx, mock.MatchedBy(func(saved *entities.Account) bool {
+		return saved.AccountID == account.AccountID && saved.Balance.IsZero() && saved.Kind == entities.AccountKindCustomer
+	})).Return(account, nil)
+	mockRepo.On("SystemAccount", mock.Anything, mockTx, entities.AccountKindEquity, "USD").Return(equity, nil)
+	// every funded creation in USD debits the equity account, so it is locked first
+	mockRepo.On("FindByIdForUpdate", mock.Anything, mockTx, equity.AccountID).Return(equity, nil)
+	mockTxRepo.On("Save", mock.Anything, mockTx, mock.MatchedBy(func(transaction *entities.Transaction) bool {
+		return transaction.SourceAccountID == equity.AccountID && transaction.DestinationAccountID == account.AccountID &&
+			transaction.Amount.Equal(account.Balance) && transaction.Description == entities.OpeningBalanceDescription
+	})).Return(&entities.Transaction{Id: 1}, nil)
+	mockTxRepo.On("UpdateBalance", mock.Anything, mockTx, equity.AccountID, account.Balance.Neg()).Return(nil)
+	mockTxRepo.On("UpdateBalance", mock.Anything, mockTx, account.AccountID, account.Balance).Return(nil)
 	mockTx.On("Commit").Return(nil)
 
-	err := service.Save(ctx, account)
+	saved, err := service.Save(ctx, account)
 	assert.NoError(t, err)
+	assert.Equal(t, account.AccountID, saved.AccountID)
+	assert.True(t, account.Balance.Equal(saved.Balance))
 
 	mockDB.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
+	mockTxRepo.AssertExpectations(t)
 	mockTx.AssertExpectations(t)
 }
 
+func TestAccountService_Save_ZeroBalanceHasNoOpeningTransfer(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockAccountRepository)
+	mockTxRepo := new(mocks.MockTransactionRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.AccountServiceImpl{
+		DB:                    mockDB,
+		AccountRepository:     mockRepo,
+		TransactionRepository: mockTxRepo,
+		CtxTimeout:            2 * time.Second,
+	}
+
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockRepo.On("Save", mock.Anything, mockTx, mock.Anything).Return(&entities.Account{AccountID: 9}, nil)
+	mockTx.On("Commit").Return(nil)
+
+	saved, err := service.Save(ctx, &entities.Account{Balance: decimal.Zero, Currency: "EUR"})
+	assert.NoError(t, err)
+	assert.True(t, saved.Balance.IsZero())
+	mockRepo.AssertNotCalled(t, "SystemAccount", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockTxRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestAccountService_Save_AccountExists(t *testing.T) {
 	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
 
@@ -64,20 +114,105 @@ func TestAccountService_Save_AccountExists(t *testing.T) {
 	}
 
 	existingAcc := &entities.Account{AccountID: 12345, Balance: decimal.NewFromFloat(100.23344)}
-	mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
 	mockRepo.On("FindById", mock.Anything, mockTx, existingAcc.AccountID).Return(existingAcc, nil)
 	mockTx.On("Commit").Return(nil)
 	mockTx.On("Rollback").Return(nil) // Ensure it's set even if not called (defer func includes it)
 
-	err := service.Save(ctx, existingAcc)
+	_, err := service.Save(ctx, existingAcc)
 	assert.Error(t, err)
 
 	appErr, ok := err.(*appErrors.AppError)
 	assert.True(t, ok)
 	assert.Equal(t, "AccountId already exists", appErr.Message)
-	assert.Equal(t, 400, appErr.StatusCode)
+	assert.Equal(t, http.StatusConflict, appErr.StatusCode)
 }
 
+func TestAccountService_Save_GeneratedId(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockAccountRepository)
+	mockTxRepo := new(mocks.MockTransactionRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.AccountServiceImpl{
+		DB:                    mockDB,
+		AccountRepository:     mockRepo,
+		TransactionRepository: mockTxRepo,
+		CtxTimeout:            2 * time.Second,
+	}
+
+	request := &entities.Account{
+		Balance:           decimal.NewFromFloat(100.23344),
+		ExternalReference: "crm-000123",
+		Currency:          "USD",
+		Status:            entities.AccountStatusActive,
+	}
+
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockRepo.On("FindByExternalReference", mock.Anything, mockTx, "crm-000123").Return(nil, sql.ErrNoRows)
+	mockRepo.On("Save", mock.Anything, mockTx, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(2).(*entities.Account).AccountID = 42
+	}).Return(request, nil)
+	// the opening transfer credits the generated id
+	mockRepo.On("SystemAccount", mock.Anything, mockTx, entities.AccountKindEquity, "USD").Return(&entities.Account{AccountID: 1}, nil)
+	mockRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(1)).Return(&entities.Account{AccountID: 1}, nil)
+	mockTxRepo.On("Save", mock.Anything, mockTx, mock.MatchedBy(func(transaction *entities.Transaction) bool {
+		return transaction.DestinationAccountID == 42
+	})).Return(&entities.Transaction{Id: 1}, nil)
+	mockTxRepo.On("UpdateBalance", mock.Anything, mockTx, int64(1), request.Balance.Neg()).Return(nil)
+	mockTxRepo.On("UpdateBalance", mock.Anything, mockTx, int64(42), request.Balance).Return(nil)
+	mockTx.On("Commit").Return(nil)
+
+	saved, err := service.Save(ctx, request)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), saved.AccountID)
+	assert.Equal(t, "crm-000123", saved.ExternalReference)
+
+	mockRepo.AssertNotCalled(t, "FindById", mock.Anything, mock.Anything, mock.Anything)
+	mockDB.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+	mockTxRepo.AssertExpectations(t)
+	mockTx.AssertExpectations(t)
+}
+
+func TestAccountService_Save_ExternalReferenceExists(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockAccountRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.AccountServiceImpl{
+		DB:                mockDB,
+		AccountRepository: mockRepo,
+		CtxTimeout:        2 * time.Second,
+	}
+
+	existingAcc := &entities.Account{AccountID: 7, Balance: decimal.Zero, ExternalReference: "crm-000123"}
+	request := &entities.Account{Balance: decimal.Zero, ExternalReference: "crm-000123"}
+
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockRepo.On("FindByExternalReference", mock.Anything, mockTx, "crm-000123").Return(existingAcc, nil)
+	mockTx.On("Rollback").Return(nil)
+
+	_, err := service.Save(ctx, request)
+
+	appErr, ok := err.(*appErrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, "External reference already exists", appErr.Message)
+	assert.Equal(t, http.StatusConflict, appErr.StatusCode)
+	mockTx.AssertCalled(t, "Rollback")
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// readSnapshot is the options FindById opens its transaction with
+var readSnapshot = ports.TxOptions{Isolation: ports.IsolationRepeatableRead, ReadOnly: true}
+
+// readCommitted is the options Update opens its transaction with
+var readCommitted = ports.TxOptions{Isolation: ports.IsolationReadCommitted}
+
 func TestAccountService_FindById_Success(t *testing.T) {
 	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
 
@@ -96,7 +231,7 @@ func TestAccountService_FindById_Success(t *testing.T) {
 		Balance:   decimal.NewFromFloat(100.23344),
 	}
 
-	mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
 	mockRepo.On("FindById", mock.Anything, mockTx, acc.AccountID).Return(acc, nil)
 	mockTx.On("Commit").Return(nil)
 	mockTx.On("Rollback").Return(nil)
@@ -123,7 +258,7 @@ func TestAccountService_FindById_NotFound(t *testing.T) {
 
 	accID := int64(1)
 
-	mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
 	mockRepo.On("FindById", mock.Anything, mockTx, accID).Return(&entities.Account{}, sql.ErrNoRows)
 	mockTx.On("Commit").Return(nil)
 	mockTx.On("Rollback").Return(nil)
@@ -134,4 +269,411 @@ func TestAccountService_FindById_NotFound(t *testing.T) {
 	appErr, ok := err.(*appErrors.AppError)
 	assert.True(t, ok)
 	assert.Equal(t, "Account not found", appErr.Message)
+	assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+}
+
+func TestAccountService_FindById_DatabaseUnavailable(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockAccountRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.AccountServiceImpl{
+		DB:                mockDB,
+		AccountRepository: mockRepo,
+		CtxTimeout:        time.Second * 2,
+	}
+
+	accID := int64(1)
+
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
+	mockRepo.On("FindById", mock.Anything, mockTx, accID).Return(nil, driver.ErrBadConn)
+	mockTx.On("Rollback").Return(nil)
+
+	resp, err := service.FindById(ctx, accID)
+	assert.Nil(t, resp)
+
+	appErr, ok := err.(*appErrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusServiceUnavailable, appErr.StatusCode)
+	mockTx.AssertCalled(t, "Rollback")
+}
+
+func TestAccountService_FindById_Timeout(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockAccountRepository)
+
+	service := &services.AccountServiceImpl{
+		DB:                mockDB,
+		AccountRepository: mockRepo,
+		CtxTimeout:        time.Second * 2,
+	}
+
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return((*mocks.MockTransaction)(nil), context.DeadlineExceeded)
+
+	resp, err := service.FindById(ctx, 1)
+	assert.Nil(t, resp)
+
+	appErr, ok := err.(*appErrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusGatewayTimeout, appErr.StatusCode)
+}
+
+func TestAccountService_FindByExternalReference_NotFound(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockAccountRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.AccountServiceImpl{
+		DB:                mockDB,
+		AccountRepository: mockRepo,
+		CtxTimeout:        time.Second * 2,
+	}
+
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
+	mockRepo.On("FindByExternalReference", mock.Anything, mockTx, "missing").Return(nil, sql.ErrNoRows)
+	mockTx.On("Rollback").Return(nil)
+
+	resp, err := service.FindByExternalReference(ctx, "missing")
+	assert.Nil(t, resp)
+
+	appErr, ok := err.(*appErrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+}
+
+func TestAccountService_List_DefaultLimit(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockAccountRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.AccountServiceImpl{
+		DB:                mockDB,
+		AccountRepository: mockRepo,
+		CtxTimeout:        time.Second * 2,
+	}
+
+	page := &entities.AccountPage{Accounts: []*entities.Account{{AccountID: 1}}, NextCursor: "next"}
+	expectedFilter := entities.AccountFilter{Currency: "USD", Limit: services.DefaultAccountPageSize}
+
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
+	mockRepo.On("List", mock.Anything, mockTx, expectedFilter).Return(page, nil)
+	mockTx.On("Commit").Return(nil)
+
+	resp, err := service.List(ctx, entities.AccountFilter{Currency: "USD"})
+	assert.NoError(t, err)
+	assert.Equal(t, page, resp)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAccountService_List_LimitCapped(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockAccountRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.AccountServiceImpl{
+		DB:                mockDB,
+		AccountRepository: mockRepo,
+		CtxTimeout:        time.Second * 2,
+	}
+
+	expectedFilter := entities.AccountFilter{Limit: services.MaxAccountPageSize}
+
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
+	mockRepo.On("List", mock.Anything, mockTx, expectedFilter).Return(&entities.AccountPage{}, nil)
+	mockTx.On("Commit").Return(nil)
+
+	_, err := service.List(ctx, entities.AccountFilter{Limit: 10000})
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAccountService_List_InvalidCursor(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockAccountRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.AccountServiceImpl{
+		DB:                mockDB,
+		AccountRepository: mockRepo,
+		CtxTimeout:        time.Second * 2,
+	}
+
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
+	mockRepo.On("List", mock.Anything, mockTx, mock.Anything).Return(nil, entities.ErrInvalidCursor)
+	mockTx.On("Rollback").Return(nil)
+
+	resp, err := service.List(ctx, entities.AccountFilter{Cursor: "garbage"})
+	assert.Nil(t, resp)
+
+	appErr, ok := err.(*appErrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+}
+
+func TestAccountService_Save_OwnerNotFound(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockAccountRepository)
+	mockCustRepo := new(mocks.MockCustomerRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.AccountServiceImpl{
+		DB:                 mockDB,
+		AccountRepository:  mockRepo,
+		CustomerRepository: mockCustRepo,
+		CtxTimeout:         2 * time.Second,
+	}
+
+	ownerID := int64(7)
+	request := &entities.Account{Balance: decimal.Zero, OwnerID: &ownerID}
+
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockCustRepo.On("FindById", mock.Anything, mockTx, ownerID).Return(nil, sql.ErrNoRows)
+	mockTx.On("Rollback").Return(nil)
+
+	_, err := service.Save(ctx, request)
+
+	appErr, ok := err.(*appErrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, "Owner customer not found", appErr.Message)
+	assert.Equal(t, http.StatusUnprocessableEntity, appErr.StatusCode)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAccountService_Update_MergesMetadata(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockAccountRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.AccountServiceImpl{
+		DB:                mockDB,
+		AccountRepository: mockRepo,
+		CtxTimeout:        2 * time.Second,
+	}
+
+	account := &entities.Account{
+		AccountID: 12345,
+		Balance:   decimal.NewFromInt(10),
+		Metadata:  entities.Metadata{"cost_center": "CC-1", "legacy_code": "X"},
+	}
+	costCenter := "CC-42"
+	patch := entities.AccountPatch{Metadata: map[string]*string{"cost_center": &costCenter, "legacy_code": nil}}
+	expected := entities.Metadata{"cost_center": "CC-42"}
+
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockRepo.On("FindByIdForUpdate", mock.Anything, mockTx, account.AccountID).Return(account, nil)
+	mockRepo.On("Update", mock.Anything, mockTx, mock.MatchedBy(func(a *entities.Account) bool {
+		return assert.ObjectsAreEqual(expected, a.Metadata)
+	})).Return(account, nil)
+	mockTx.On("Commit").Return(nil)
+
+	updated, err := service.Update(ctx, account.AccountID, patch)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, updated.Metadata)
+
+	mockDB.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+	mockTx.AssertExpectations(t)
+}
+
+func TestAccountService_Update_NotFound(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockAccountRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.AccountServiceImpl{
+		DB:                mockDB,
+		AccountRepository: mockRepo,
+		CtxTimeout:        2 * time.Second,
+	}
+
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(999)).Return(nil, sql.ErrNoRows)
+	mockTx.On("Rollback").Return(nil)
+
+	_, err := service.Update(ctx, 999, entities.AccountPatch{})
+
+	var appErr *appErrors.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAccountService_Update_TooManyMetadataKeys(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockAccountRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.AccountServiceImpl{
+		DB:                mockDB,
+		AccountRepository: mockRepo,
+		CtxTimeout:        2 * time.Second,
+	}
+
+	metadata := entities.Metadata{}
+	for i := 0; i < entities.MaxMetadataKeys; i++ {
+		metadata[fmt.Sprintf("key_%d", i)] = "value"
+	}
+	account := &entities.Account{AccountID: 12345, Balance: decimal.NewFromInt(10), Metadata: metadata}
+	extra := "one too many"
+
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockRepo.On("FindByIdForUpdate", mock.Anything, mockTx, account.AccountID).Return(account, nil)
+	mockTx.On("Rollback").Return(nil)
+
+	_, err := service.Update(ctx, account.AccountID, entities.AccountPatch{Metadata: map[string]*string{"extra": &extra}})
+
+	var appErr *appErrors.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, appErr.StatusCode)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	mockTx.AssertExpectations(t)
+}
+
+func TestAccountService_Update_Freeze(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockAccountRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.AccountServiceImpl{
+		DB:                mockDB,
+		AccountRepository: mockRepo,
+		CtxTimeout:        2 * time.Second,
+	}
+
+	account := &entities.Account{AccountID: 12345, Balance: decimal.NewFromInt(10), Status: entities.AccountStatusActive}
+	frozen := entities.AccountStatusFrozen
+
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockRepo.On("FindByIdForUpdate", mock.Anything, mockTx, account.AccountID).Return(account, nil)
+	mockRepo.On("Update", mock.Anything, mockTx, mock.MatchedBy(func(a *entities.Account) bool {
+		return a.Status == entities.AccountStatusFrozen
+	})).Return(account, nil)
+	mockTx.On("Commit").Return(nil)
+
+	updated, err := service.Update(ctx, account.AccountID, entities.AccountPatch{Status: &frozen})
+	assert.NoError(t, err)
+	assert.Equal(t, entities.AccountStatusFrozen, updated.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAccountService_Update_ClosedAccountStaysClosed(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockAccountRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.AccountServiceImpl{
+		DB:                mockDB,
+		AccountRepository: mockRepo,
+		CtxTimeout:        2 * time.Second,
+	}
+
+	account := &entities.Account{AccountID: 12345, Status: entities.AccountStatusClosed}
+	active := entities.AccountStatusActive
+
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockRepo.On("FindByIdForUpdate", mock.Anything, mockTx, account.AccountID).Return(account, nil)
+	mockTx.On("Rollback").Return(nil)
+
+	_, err := service.Update(ctx, account.AccountID, entities.AccountPatch{Status: &active})
+
+	var appErr *appErrors.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusConflict, appErr.StatusCode)
+	assert.ErrorIs(t, err, entities.ErrAccountNotActive)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAccountService_Update_Versioned(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	costCenter := "CC-42"
+
+	newService := func() (*services.AccountServiceImpl, *mocks.MockDatabase, *mocks.MockAccountRepository, *mocks.MockTransaction) {
+		mockDB := new(mocks.MockDatabase)
+		mockRepo := new(mocks.MockAccountRepository)
+		mockTx := new(mocks.MockTransaction)
+		mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+		mockTx.On("Commit").Return(nil)
+		mockTx.On("Rollback").Return(nil)
+		return &services.AccountServiceImpl{DB: mockDB, AccountRepository: mockRepo, CtxTimeout: 2 * time.Second}, mockDB, mockRepo, mockTx
+	}
+
+	t.Run("the version read is the one expected", func(t *testing.T) {
+		service, _, mockRepo, mockTx := newService()
+		version := int64(3)
+		mockRepo.On("FindById", mock.Anything, mockTx, int64(1)).Return(&entities.Account{AccountID: 1, Version: 3}, nil)
+		mockRepo.On("Update", mock.Anything, mockTx, mock.MatchedBy(func(a *entities.Account) bool {
+			return a.Version == 3
+		})).Return(&entities.Account{AccountID: 1, Version: 4}, nil)
+
+		updated, err := service.Update(ctx, 1, entities.AccountPatch{Metadata: map[string]*string{"cost_center": &costCenter}, Version: &version})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), updated.Version)
+		// a versioned update takes no row lock
+		mockRepo.AssertNotCalled(t, "FindByIdForUpdate", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("the account moved on before it was read", func(t *testing.T) {
+		service, _, mockRepo, mockTx := newService()
+		version := int64(2)
+		mockRepo.On("FindById", mock.Anything, mockTx, int64(1)).Return(&entities.Account{AccountID: 1, Version: 3}, nil)
+
+		_, err := service.Update(ctx, 1, entities.AccountPatch{Metadata: map[string]*string{"cost_center": &costCenter}, Version: &version})
+		appErr, ok := err.(*appErrors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusPreconditionFailed, appErr.StatusCode)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+		mockTx.AssertCalled(t, "Rollback")
+	})
+
+	t.Run("the account moved on while it was updated", func(t *testing.T) {
+		service, _, mockRepo, mockTx := newService()
+		version := int64(3)
+		mockRepo.On("FindById", mock.Anything, mockTx, int64(1)).Return(&entities.Account{AccountID: 1, Version: 3}, nil)
+		mockRepo.On("Update", mock.Anything, mockTx, mock.Anything).Return(nil, entities.ErrVersionMismatch)
+
+		_, err := service.Update(ctx, 1, entities.AccountPatch{Metadata: map[string]*string{"cost_center": &costCenter}, Version: &version})
+		appErr, ok := err.(*appErrors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusPreconditionFailed, appErr.StatusCode)
+		mockTx.AssertCalled(t, "Rollback")
+	})
+	t.Run("a stale status change is refused", func(t *testing.T) {
+		service, _, mockRepo, mockTx := newService()
+		version := int64(2)
+		frozen := entities.AccountStatusFrozen
+		mockRepo.On("FindById", mock.Anything, mockTx, int64(1)).Return(&entities.Account{AccountID: 1, Status: entities.AccountStatusActive, Version: 3}, nil)
+
+		_, err := service.Update(ctx, 1, entities.AccountPatch{Status: &frozen, Version: &version})
+		appErr, ok := err.(*appErrors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusPreconditionFailed, appErr.StatusCode)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+		mockTx.AssertCalled(t, "Rollback")
+	})
 }
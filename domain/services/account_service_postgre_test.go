@@ -0,0 +1,87 @@
+package services_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"transfer-system/adapters/repositories"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/services"
+	"transfer-system/internal/testutils"
+	"transfer-system/pkg/logger"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createConcurrently opens n funded accounts in currency at once and returns
+// their ids; each creation must succeed
+func createConcurrently(t *testing.T, accountService *services.AccountServiceImpl, currency string, n int) []int64 {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	ids := []int64{}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			account, err := accountService.Save(ctx, &entities.Account{Balance: decimal.NewFromInt(10), Currency: currency})
+			if !assert.NoError(t, err) {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			ids = append(ids, account.AccountID)
+		}()
+	}
+	wg.Wait()
+	require.Len(t, ids, n)
+	return ids
+}
+
+func TestAccountService_Postgre_ConcurrentFundedCreations(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	// closed after the cleanup below, which runs first
+	t.Cleanup(func() { db.Close() })
+
+	accountRepository := &repositories.AccountRepositoryPostgre{DB: db}
+	accountService := &services.AccountServiceImpl{
+		DB:                    db,
+		AccountRepository:     accountRepository,
+		CustomerRepository:    &repositories.CustomerRepositoryPostgre{DB: db},
+		TransactionRepository: &repositories.TransactionRepositoryPostgre{DB: db},
+		CtxTimeout:            10 * time.Second,
+	}
+
+	// every creation debits the one CHF equity account
+	ids := createConcurrently(t, accountService, "CHF", 20)
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	tx := testutils.SetupTestTx(t, db)
+	defer tx.Rollback()
+	equity, err := accountRepository.SystemAccount(ctx, tx, entities.AccountKindEquity, "CHF")
+	require.NoError(t, err)
+	for _, id := range ids {
+		account, err := accountRepository.FindById(ctx, tx, id)
+		require.NoError(t, err)
+		assert.True(t, decimal.NewFromInt(10).Equal(account.Balance))
+	}
+
+	t.Cleanup(func() {
+		cleanup := testutils.SetupTestTx(t, db)
+		for _, id := range ids {
+			_, err := cleanup.ExecContext(ctx, "DELETE FROM transactions WHERE destination_id = $1", id)
+			require.NoError(t, err)
+			_, err = cleanup.ExecContext(ctx, "DELETE FROM accounts WHERE id = $1", id)
+			require.NoError(t, err)
+		}
+		_, err := cleanup.ExecContext(ctx, "UPDATE accounts SET balance = balance + $1 WHERE id = $2", 10*len(ids), equity.AccountID)
+		require.NoError(t, err)
+		require.NoError(t, cleanup.Commit())
+	})
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// apiKeyPrefix marks secrets issued by this system so leaked keys are easy to scan for
+const apiKeyPrefix = "tsk_"
+
+// signingSecretPrefix marks request signing secrets, see APIKey.SigningSecret
+const signingSecretPrefix = "tss_"
+
+// apiKeyPrefixLength is how much of the secret is kept in clear to identify the key
+const apiKeyPrefixLength = 12
+
+type APIKeyServiceImpl struct {
+	DB               ports.Database
+	APIKeyRepository ports.APIKeyRepository
+	CtxTimeout       time.Duration
+}
+
+func (s *APIKeyServiceImpl) Issue(c context.Context, name string, signing bool) (*entities.APIKey, string, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return nil, "", appErrors.NewBadRequestError("API key name must be 1 to 100 characters", nil)
+	}
+
+	secret, err := randomSecret(apiKeyPrefix)
+	if err != nil {
+		logger.WithError(err).Error("Failed to generate API key")
+		return nil, "", appErrors.NewInternalServerError("Currently we're facing an issue", err)
+	}
+	var signingSecret string
+	if signing {
+		if signingSecret, err = randomSecret(signingSecretPrefix); err != nil {
+			logger.WithError(err).Error("Failed to generate signing secret")
+			return nil, "", appErrors.NewInternalServerError("Currently we're facing an issue", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, "", databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	key, err := s.APIKeyRepository.Save(ctx, tx, &entities.APIKey{
+		Name:          name,
+		Prefix:        secret[:apiKeyPrefixLength],
+		Hash:          hashAPIKey(secret),
+		SigningSecret: signingSecret,
+	})
+	if err != nil {
+		logger.WithError(err).Error("Database error")
+		return nil, "", databaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, "", databaseError(err)
+	}
+
+	return key, secret, nil
+}
+
+func (s *APIKeyServiceImpl) Authenticate(c context.Context, secret string) (*entities.APIKey, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	if !strings.HasPrefix(secret, apiKeyPrefix) {
+		return nil, appErrors.NewUnauthorizedError("Invalid API key", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// the lookup is by hash, so comparing secrets takes no time that depends on their content
+	key, err := s.APIKeyRepository.FindByHash(ctx, tx, hashAPIKey(secret))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Error("Unknown API key")
+			return nil, appErrors.NewUnauthorizedError("Invalid API key", err)
+		}
+		logger.WithError(err).Error("Database error")
+		return nil, databaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, databaseError(err)
+	}
+
+	if key.RevokedAt != nil {
+		logger.Errorf("API key %s was revoked", key.Prefix)
+		return nil, appErrors.NewUnauthorizedError("Invalid API key", nil)
+	}
+
+	return key, nil
+}
+
+// randomSecret is prefix followed by 32 random bytes, base64url encoded
+func randomSecret(prefix string) (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return prefix + base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,133 @@
+package services_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/services"
+	"transfer-system/mocks"
+	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newAPIKeyService() (*services.APIKeyServiceImpl, *mocks.MockDatabase, *mocks.MockAPIKeyRepository) {
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockAPIKeyRepository)
+
+	service := &services.APIKeyServiceImpl{
+		DB:               mockDB,
+		APIKeyRepository: mockRepo,
+		CtxTimeout:       2 * time.Second,
+	}
+	return service, mockDB, mockRepo
+}
+
+func TestAPIKeyService_Issue(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	service, mockDB, mockRepo := newAPIKeyService()
+	mockTx := new(mocks.MockTransaction)
+
+	var stored *entities.APIKey
+	saved := &entities.APIKey{ID: 5, Name: "payroll"}
+	mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+	mockRepo.On("Save", mock.Anything, mockTx, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(2).(*entities.APIKey)
+	}).Return(saved, nil)
+	mockTx.On("Commit").Return(nil)
+
+	key, secret, err := service.Issue(ctx, " payroll ", false)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, "tsk_"))
+	assert.Equal(t, "payroll", stored.Name)
+	assert.Equal(t, secret[:12], stored.Prefix)
+	sum := sha256.Sum256([]byte(secret))
+	assert.Equal(t, hex.EncodeToString(sum[:]), stored.Hash)
+	assert.Same(t, saved, key)
+	assert.Empty(t, stored.SigningSecret)
+}
+
+func TestAPIKeyService_Issue_Signing(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	service, mockDB, mockRepo := newAPIKeyService()
+	mockTx := new(mocks.MockTransaction)
+
+	var stored *entities.APIKey
+	mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+	mockRepo.On("Save", mock.Anything, mockTx, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(2).(*entities.APIKey)
+	}).Return(&entities.APIKey{ID: 6}, nil)
+	mockTx.On("Commit").Return(nil)
+
+	_, secret, err := service.Issue(ctx, "partner", true)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, "tsk_"))
+	assert.True(t, strings.HasPrefix(stored.SigningSecret, "tss_"))
+	assert.Len(t, stored.SigningSecret, 47)
+}
+
+func TestAPIKeyService_Issue_InvalidName(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	service, mockDB, _ := newAPIKeyService()
+
+	_, _, err := service.Issue(ctx, "  ", false)
+
+	var appErr *appErrors.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+	mockDB.AssertNotCalled(t, "BeginTx", mock.Anything)
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	secret := "tsk_c2VjcmV0"
+	sum := sha256.Sum256([]byte(secret))
+	hash := hex.EncodeToString(sum[:])
+	revokedAt := time.Now()
+
+	tests := []struct {
+		name   string
+		secret string
+		found  *entities.APIKey
+		err    error
+		status int
+	}{
+		{name: "active key", secret: secret, found: &entities.APIKey{ID: 1, Hash: hash}},
+		{name: "unknown key", secret: secret, err: sql.ErrNoRows, status: http.StatusUnauthorized},
+		{name: "revoked key", secret: secret, found: &entities.APIKey{ID: 1, Hash: hash, RevokedAt: &revokedAt}, status: http.StatusUnauthorized},
+		{name: "missing header", secret: "", status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockDB, mockRepo := newAPIKeyService()
+			mockTx := new(mocks.MockTransaction)
+
+			mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+			mockRepo.On("FindByHash", mock.Anything, mockTx, hash).Return(tt.found, tt.err)
+			mockTx.On("Commit").Return(nil)
+			mockTx.On("Rollback").Return(nil)
+
+			key, err := service.Authenticate(ctx, tt.secret)
+			if tt.status == 0 {
+				assert.NoError(t, err)
+				assert.Equal(t, int64(1), key.ID)
+				return
+			}
+			assert.Nil(t, key)
+			var appErr *appErrors.AppError
+			assert.ErrorAs(t, err, &appErr)
+			assert.Equal(t, tt.status, appErr.StatusCode)
+		})
+	}
+}
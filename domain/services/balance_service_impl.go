@@ -0,0 +1,357 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/logger"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// snapshotBatchSize is how many stale account ids are fetched per query
+const snapshotBatchSize = 100
+
+type BalanceServiceImpl struct {
+	DB                        ports.Database
+	AccountRepository         ports.AccountRepository
+	TransactionRepository     ports.TransactionRepository
+	BalanceSnapshotRepository ports.BalanceSnapshotRepository
+	CtxTimeout                time.Duration
+}
+
+func (s *BalanceServiceImpl) BalanceAsOf(c context.Context, accountID int64, asOf time.Time) (*entities.AccountBalance, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx, readSnapshot)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	account, err := s.AccountRepository.FindById(ctx, tx, accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf("AccountID %d not found", accountID)
+			return nil, appErrors.NewNotFoundError("Account not found", err)
+		}
+		logger.WithError(err).Error("Database error")
+		return nil, databaseError(err)
+	}
+
+	if asOf.Before(account.CreatedAt) {
+		logger.Errorf("AccountID %d did not exist at %s", accountID, asOf)
+		err = appErrors.NewUnprocessableError("Account did not exist at the requested time", nil)
+		return nil, err
+	}
+
+	balance, err := s.balanceAt(ctx, tx, account, asOf)
+	if err != nil {
+		logger.WithError(err).Error("Failed to compute balance")
+		return nil, databaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, databaseError(err)
+	}
+
+	return &entities.AccountBalance{
+		AccountID: account.AccountID,
+		Currency:  account.Currency,
+		Balance:   balance.Balance,
+		AsOf:      asOf,
+	}, nil
+}
+
+// Statement writes the opening balance, every movement in (from, to] with its
+// running balance, and the closing balance. Statements only read, from a snapshot,
+// and may be served by a replica
+func (s *BalanceServiceImpl) Statement(c context.Context, accountID int64, from, to time.Time, writer ports.StatementWriter) error {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	header, err := s.statementHeader(c, accountID, from, to)
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Begin(*header); err != nil {
+		logger.WithError(err).Error("Failed to write statement header")
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx, readSnapshot)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// on a single connection the period is read into memory and written after
+	// the commit; streaming to a slow client would hold the only connection
+	writeLine := writer.WriteLine
+	buffered := []entities.StatementLine{}
+	if _, ok := s.DB.(ports.SingleConnection); ok {
+		writeLine = func(line entities.StatementLine) error {
+			buffered = append(buffered, line)
+			return nil
+		}
+	}
+
+	balance := header.OpeningBalance
+	err = s.TransactionRepository.StreamByAccount(ctx, tx, accountID, header.From, header.To, func(transaction *entities.Transaction) error {
+		line := entities.StatementLine{Transaction: transaction, Amount: signedAmount(transaction, accountID)}
+		balance = balance.Add(line.Amount)
+		line.RunningBalance = balance
+		return writeLine(line)
+	})
+	if err != nil {
+		logger.WithError(err).Errorf("Failed to stream statement of AccountID %d", accountID)
+		return databaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return databaseError(err)
+	}
+
+	// a transfer in the period committed between reading the header and streaming
+	if !balance.Equal(header.ClosingBalance) {
+		logger.Errorf("Statement of AccountID %d closed at %s, expected %s", accountID, balance, header.ClosingBalance)
+		return appErrors.NewServiceUnavailableError("Statement changed while it was produced, please retry", nil)
+	}
+
+	for _, line := range buffered {
+		if err := writer.WriteLine(line); err != nil {
+			return err
+		}
+	}
+
+	return writer.End(balance)
+}
+
+// statementHeader resolves the account and its opening balance. A period starting
+// before the account was opened starts at opening, with the initial balance
+func (s *BalanceServiceImpl) statementHeader(c context.Context, accountID int64, from, to time.Time) (*entities.StatementHeader, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx, readSnapshot)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	account, err := s.AccountRepository.FindById(ctx, tx, accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf("AccountID %d not found", accountID)
+			return nil, appErrors.NewNotFoundError("Account not found", err)
+		}
+		logger.WithError(err).Error("Database error")
+		return nil, databaseError(err)
+	}
+
+	if !to.After(account.CreatedAt) {
+		logger.Errorf("AccountID %d did not exist before %s", accountID, to)
+		err = appErrors.NewUnprocessableError("Account did not exist in the requested period", nil)
+		return nil, err
+	}
+
+	header := &entities.StatementHeader{
+		AccountID:      account.AccountID,
+		Currency:       account.Currency,
+		From:           from,
+		To:             to,
+		OpeningBalance: account.InitialBalance,
+	}
+	if from.Before(account.CreatedAt) {
+		header.From = account.CreatedAt
+	} else {
+		var opening *entities.BalanceSnapshot
+		opening, err = s.balanceAt(ctx, tx, account, from)
+		if err != nil {
+			logger.WithError(err).Error("Failed to compute opening balance")
+			return nil, databaseError(err)
+		}
+		header.OpeningBalance = opening.Balance
+	}
+
+	var closing *entities.BalanceSnapshot
+	closing, err = s.balanceAt(ctx, tx, account, to)
+	if err != nil {
+		logger.WithError(err).Error("Failed to compute closing balance")
+		return nil, databaseError(err)
+	}
+	header.ClosingBalance = closing.Balance
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, databaseError(err)
+	}
+
+	return header, nil
+}
+
+// signedAmount is the effect of the transfer on the account's balance
+func signedAmount(transaction *entities.Transaction, accountID int64) decimal.Decimal {
+	switch {
+	case transaction.SourceAccountID == transaction.DestinationAccountID:
+		return decimal.Zero
+	case transaction.SourceAccountID == accountID:
+		return transaction.Amount.Neg()
+	default:
+		return transaction.Amount
+	}
+}
+
+// balanceAt replays the transfers after the latest snapshot, or after opening when
+// the account has none, on top of that starting balance
+func (s *BalanceServiceImpl) balanceAt(ctx context.Context, tx ports.Transaction, account *entities.Account, asOf time.Time) (*entities.BalanceSnapshot, error) {
+	start := &entities.BalanceSnapshot{AccountID: account.AccountID, Balance: account.InitialBalance}
+	var after *time.Time
+
+	snapshot, err := s.BalanceSnapshotRepository.FindLatest(ctx, tx, account.AccountID, asOf)
+	switch {
+	case err == nil:
+		start, after = snapshot, &snapshot.AsOf
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+
+	movement, err := s.TransactionRepository.NetMovement(ctx, tx, account.AccountID, after, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.BalanceSnapshot{
+		AccountID: account.AccountID,
+		AsOf:      asOf,
+		Balance:   start.Balance.Add(movement),
+	}, nil
+}
+
+// CreateSnapshots is run periodically. asOf should trail the clock by more than the
+// longest transfer, because a transfer is stamped when its database transaction
+// starts and could otherwise commit behind a snapshot that already covers it
+func (s *BalanceServiceImpl) CreateSnapshots(c context.Context, asOf time.Time) (int, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	written := 0
+	afterID := int64(0)
+	for {
+		ids, err := s.listStaleAccounts(c, asOf, afterID)
+		if err != nil {
+			logger.WithError(err).Errorf("Failed to list accounts needing a snapshot after AccountID %d", afterID)
+			return written, err
+		}
+
+		// one transaction per account so the job never holds more than one account lock
+		for _, id := range ids {
+			if err := s.snapshotAccount(c, id, asOf); err != nil {
+				logger.WithError(err).Errorf("Failed to snapshot balance of AccountID %d", id)
+				return written, err
+			}
+			written++
+		}
+
+		if len(ids) < snapshotBatchSize {
+			return written, nil
+		}
+		afterID = ids[len(ids)-1]
+	}
+}
+
+func (s *BalanceServiceImpl) listStaleAccounts(c context.Context, asOf time.Time, afterID int64) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx)
+	if err != nil {
+		return nil, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	ids, err := s.BalanceSnapshotRepository.ListStaleAccounts(ctx, tx, asOf, afterID, snapshotBatchSize)
+	if err != nil {
+		return nil, databaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, databaseError(err)
+	}
+
+	return ids, nil
+}
+
+func (s *BalanceServiceImpl) snapshotAccount(c context.Context, accountID int64, asOf time.Time) error {
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx)
+	if err != nil {
+		return databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	account, err := s.AccountRepository.FindByIdForUpdate(ctx, tx, accountID)
+	if err != nil {
+		return databaseError(err)
+	}
+
+	snapshot, err := s.balanceAt(ctx, tx, account, asOf)
+	if err != nil {
+		return databaseError(err)
+	}
+
+	if err = s.BalanceSnapshotRepository.Save(ctx, tx, snapshot); err != nil {
+		return databaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return databaseError(err)
+	}
+
+	return nil
+}
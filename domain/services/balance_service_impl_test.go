@@ -0,0 +1,315 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/services"
+	"transfer-system/mocks"
+	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/logger"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newBalanceService() (*services.BalanceServiceImpl, *mocks.MockDatabase, *mocks.MockAccountRepository, *mocks.MockTransactionRepository, *mocks.MockBalanceSnapshotRepository) {
+	mockDB := new(mocks.MockDatabase)
+	mockAccRepo := new(mocks.MockAccountRepository)
+	mockTransRepo := new(mocks.MockTransactionRepository)
+	mockSnapshotRepo := new(mocks.MockBalanceSnapshotRepository)
+
+	service := &services.BalanceServiceImpl{
+		DB:                        mockDB,
+		AccountRepository:         mockAccRepo,
+		TransactionRepository:     mockTransRepo,
+		BalanceSnapshotRepository: mockSnapshotRepo,
+		CtxTimeout:                2 * time.Second,
+	}
+	return service, mockDB, mockAccRepo, mockTransRepo, mockSnapshotRepo
+}
+
+func TestBalanceService_BalanceAsOf_FromInitialBalance(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	service, mockDB, mockAccRepo, mockTransRepo, mockSnapshotRepo := newBalanceService()
+	mockTx := new(mocks.MockTransaction)
+
+	asOf := time.Date(2025, 3, 31, 23, 59, 59, 0, time.UTC)
+	account := &entities.Account{
+		AccountID:      123,
+		Balance:        decimal.NewFromInt(500),
+		InitialBalance: decimal.NewFromInt(100),
+		Currency:       "USD",
+		CreatedAt:      asOf.AddDate(0, -1, 0),
+	}
+
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
+	mockAccRepo.On("FindById", mock.Anything, mockTx, int64(123)).Return(account, nil)
+	mockSnapshotRepo.On("FindLatest", mock.Anything, mockTx, int64(123), asOf).Return(nil, sql.ErrNoRows)
+	mockTransRepo.On("NetMovement", mock.Anything, mockTx, int64(123), (*time.Time)(nil), asOf).Return(decimal.NewFromInt(-40), nil)
+	mockTx.On("Commit").Return(nil)
+
+	balance, err := service.BalanceAsOf(ctx, 123, asOf)
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(60).Equal(balance.Balance))
+	assert.Equal(t, "USD", balance.Currency)
+	assert.Equal(t, asOf, balance.AsOf)
+
+	mockTransRepo.AssertExpectations(t)
+	mockTx.AssertExpectations(t)
+}
+
+func TestBalanceService_BalanceAsOf_FromSnapshot(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	service, mockDB, mockAccRepo, mockTransRepo, mockSnapshotRepo := newBalanceService()
+	mockTx := new(mocks.MockTransaction)
+
+	asOf := time.Date(2025, 3, 31, 23, 59, 59, 0, time.UTC)
+	snapshotAt := asOf.Add(-time.Hour)
+	account := &entities.Account{AccountID: 123, InitialBalance: decimal.NewFromInt(100), CreatedAt: asOf.AddDate(-1, 0, 0)}
+	snapshot := &entities.BalanceSnapshot{AccountID: 123, AsOf: snapshotAt, Balance: decimal.NewFromInt(1000)}
+
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
+	mockAccRepo.On("FindById", mock.Anything, mockTx, int64(123)).Return(account, nil)
+	mockSnapshotRepo.On("FindLatest", mock.Anything, mockTx, int64(123), asOf).Return(snapshot, nil)
+	mockTransRepo.On("NetMovement", mock.Anything, mockTx, int64(123), &snapshotAt, asOf).Return(decimal.NewFromInt(25), nil)
+	mockTx.On("Commit").Return(nil)
+
+	balance, err := service.BalanceAsOf(ctx, 123, asOf)
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(1025).Equal(balance.Balance))
+
+	mockTransRepo.AssertExpectations(t)
+}
+
+func TestBalanceService_BalanceAsOf_BeforeAccountCreated(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	service, mockDB, mockAccRepo, mockTransRepo, _ := newBalanceService()
+	mockTx := new(mocks.MockTransaction)
+
+	createdAt := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
+	mockAccRepo.On("FindById", mock.Anything, mockTx, int64(123)).Return(&entities.Account{AccountID: 123, CreatedAt: createdAt}, nil)
+	mockTx.On("Rollback").Return(nil).Once()
+
+	_, err := service.BalanceAsOf(ctx, 123, createdAt.Add(-time.Second))
+
+	var appErr *appErrors.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, appErr.StatusCode)
+	mockTransRepo.AssertNotCalled(t, "NetMovement", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockTx.AssertExpectations(t)
+}
+
+func TestBalanceService_BalanceAsOf_AccountNotFound(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	service, mockDB, mockAccRepo, _, _ := newBalanceService()
+	mockTx := new(mocks.MockTransaction)
+
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
+	mockAccRepo.On("FindById", mock.Anything, mockTx, int64(999)).Return(nil, sql.ErrNoRows)
+	mockTx.On("Rollback").Return(nil).Once()
+
+	_, err := service.BalanceAsOf(ctx, 999, time.Now())
+
+	var appErr *appErrors.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+}
+
+func TestBalanceService_CreateSnapshots(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	service, mockDB, mockAccRepo, mockTransRepo, mockSnapshotRepo := newBalanceService()
+	mockTx := new(mocks.MockTransaction)
+
+	asOf := time.Date(2025, 3, 31, 23, 0, 0, 0, time.UTC)
+	mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+	mockTx.On("Commit").Return(nil)
+	mockSnapshotRepo.On("ListStaleAccounts", mock.Anything, mockTx, asOf, int64(0), mock.Anything).Return([]int64{1, 2}, nil)
+	for _, id := range []int64{1, 2} {
+		mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, id).Return(&entities.Account{AccountID: id, InitialBalance: decimal.NewFromInt(10)}, nil)
+		mockSnapshotRepo.On("FindLatest", mock.Anything, mockTx, id, asOf).Return(nil, sql.ErrNoRows)
+		mockTransRepo.On("NetMovement", mock.Anything, mockTx, id, (*time.Time)(nil), asOf).Return(decimal.NewFromInt(5), nil)
+		mockSnapshotRepo.On("Save", mock.Anything, mockTx, mock.MatchedBy(func(s *entities.BalanceSnapshot) bool {
+			return s.AccountID == id && s.AsOf.Equal(asOf) && s.Balance.Equal(decimal.NewFromInt(15))
+		})).Return(nil).Once()
+	}
+
+	written, err := service.CreateSnapshots(ctx, asOf)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, written)
+
+	mockSnapshotRepo.AssertExpectations(t)
+}
+
+// recordingStatementWriter keeps everything written so tests can inspect it
+type recordingStatementWriter struct {
+	header  entities.StatementHeader
+	lines   []entities.StatementLine
+	closing decimal.Decimal
+}
+
+func (w *recordingStatementWriter) Begin(header entities.StatementHeader) error {
+	w.header = header
+	return nil
+}
+
+func (w *recordingStatementWriter) WriteLine(line entities.StatementLine) error {
+	w.lines = append(w.lines, line)
+	return nil
+}
+
+func (w *recordingStatementWriter) End(closingBalance decimal.Decimal) error {
+	w.closing = closingBalance
+	return nil
+}
+
+func TestBalanceService_Statement_RunningBalance(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	service, mockDB, mockAccRepo, mockTransRepo, mockSnapshotRepo := newBalanceService()
+	mockTx := new(mocks.MockTransaction)
+
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	account := &entities.Account{AccountID: 123, Currency: "USD", InitialBalance: decimal.NewFromInt(100), CreatedAt: from.AddDate(-1, 0, 0)}
+	transactions := []*entities.Transaction{
+		{Id: 1, SourceAccountID: 456, DestinationAccountID: 123, Amount: decimal.NewFromInt(50)},
+		{Id: 2, SourceAccountID: 123, DestinationAccountID: 789, Amount: decimal.NewFromInt(30)},
+	}
+
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
+	mockTx.On("Commit").Return(nil)
+	mockAccRepo.On("FindById", mock.Anything, mockTx, int64(123)).Return(account, nil)
+	mockSnapshotRepo.On("FindLatest", mock.Anything, mockTx, int64(123), from).Return(nil, sql.ErrNoRows)
+	mockTransRepo.On("NetMovement", mock.Anything, mockTx, int64(123), (*time.Time)(nil), from).Return(decimal.NewFromInt(100), nil)
+	mockSnapshotRepo.On("FindLatest", mock.Anything, mockTx, int64(123), to).Return(nil, sql.ErrNoRows)
+	mockTransRepo.On("NetMovement", mock.Anything, mockTx, int64(123), (*time.Time)(nil), to).Return(decimal.NewFromInt(120), nil)
+	mockTransRepo.On("StreamByAccount", mock.Anything, mockTx, int64(123), from, to, mock.Anything).Return(transactions, nil)
+
+	writer := &recordingStatementWriter{}
+	err := service.Statement(ctx, 123, from, to, writer)
+	assert.NoError(t, err)
+
+	assert.True(t, decimal.NewFromInt(200).Equal(writer.header.OpeningBalance))
+	assert.True(t, decimal.NewFromInt(220).Equal(writer.header.ClosingBalance))
+	assert.Len(t, writer.lines, 2)
+	assert.True(t, decimal.NewFromInt(50).Equal(writer.lines[0].Amount))
+	assert.True(t, decimal.NewFromInt(250).Equal(writer.lines[0].RunningBalance))
+	assert.True(t, decimal.NewFromInt(-30).Equal(writer.lines[1].Amount))
+	assert.Equal(t, int64(789), writer.lines[1].CounterpartyID())
+	assert.True(t, decimal.NewFromInt(220).Equal(writer.closing))
+}
+
+// singleConnectionDatabase is a mock database with the SQLite pool of one connection
+type singleConnectionDatabase struct {
+	*mocks.MockDatabase
+}
+
+func (d singleConnectionDatabase) SingleConnection() {}
+
+// committedStatementWriter fails lines written before the transaction committed
+type committedStatementWriter struct {
+	recordingStatementWriter
+	committed *bool
+}
+
+func (w *committedStatementWriter) WriteLine(line entities.StatementLine) error {
+	if !*w.committed {
+		return errors.New("line written while the transaction is open")
+	}
+	return w.recordingStatementWriter.WriteLine(line)
+}
+
+func TestBalanceService_Statement_SingleConnectionWritesAfterCommit(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	service, mockDB, mockAccRepo, mockTransRepo, mockSnapshotRepo := newBalanceService()
+	service.DB = singleConnectionDatabase{mockDB}
+	mockTx := new(mocks.MockTransaction)
+
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	account := &entities.Account{AccountID: 123, Currency: "USD", InitialBalance: decimal.NewFromInt(100), CreatedAt: from.AddDate(-1, 0, 0)}
+	transactions := []*entities.Transaction{
+		{Id: 1, SourceAccountID: 456, DestinationAccountID: 123, Amount: decimal.NewFromInt(50)},
+		{Id: 2, SourceAccountID: 123, DestinationAccountID: 789, Amount: decimal.NewFromInt(30)},
+	}
+
+	committed := false
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
+	mockTx.On("Commit").Return(nil).Run(func(mock.Arguments) { committed = true })
+	mockAccRepo.On("FindById", mock.Anything, mockTx, int64(123)).Return(account, nil)
+	mockSnapshotRepo.On("FindLatest", mock.Anything, mockTx, int64(123), mock.Anything).Return(nil, sql.ErrNoRows)
+	mockTransRepo.On("NetMovement", mock.Anything, mockTx, int64(123), (*time.Time)(nil), from).Return(decimal.NewFromInt(100), nil)
+	mockTransRepo.On("NetMovement", mock.Anything, mockTx, int64(123), (*time.Time)(nil), to).Return(decimal.NewFromInt(120), nil)
+	mockTransRepo.On("StreamByAccount", mock.Anything, mockTx, int64(123), from, to, mock.Anything).Return(transactions, nil)
+
+	writer := &committedStatementWriter{committed: &committed}
+	err := service.Statement(ctx, 123, from, to, writer)
+	assert.NoError(t, err)
+
+	assert.Len(t, writer.lines, 2)
+	assert.True(t, decimal.NewFromInt(250).Equal(writer.lines[0].RunningBalance))
+	assert.True(t, decimal.NewFromInt(220).Equal(writer.lines[1].RunningBalance))
+	assert.True(t, decimal.NewFromInt(220).Equal(writer.closing))
+}
+
+func TestBalanceService_Statement_PeriodStartsBeforeOpening(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	service, mockDB, mockAccRepo, mockTransRepo, mockSnapshotRepo := newBalanceService()
+	mockTx := new(mocks.MockTransaction)
+
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	account := &entities.Account{AccountID: 123, Currency: "USD", InitialBalance: decimal.NewFromInt(100), CreatedAt: createdAt}
+
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
+	mockTx.On("Commit").Return(nil)
+	mockAccRepo.On("FindById", mock.Anything, mockTx, int64(123)).Return(account, nil)
+	mockSnapshotRepo.On("FindLatest", mock.Anything, mockTx, int64(123), to).Return(nil, sql.ErrNoRows)
+	mockTransRepo.On("NetMovement", mock.Anything, mockTx, int64(123), (*time.Time)(nil), to).Return(decimal.Zero, nil)
+	mockTransRepo.On("StreamByAccount", mock.Anything, mockTx, int64(123), createdAt, to, mock.Anything).Return(nil, nil)
+
+	writer := &recordingStatementWriter{}
+	err := service.Statement(ctx, 123, from, to, writer)
+	assert.NoError(t, err)
+
+	assert.Equal(t, createdAt, writer.header.From)
+	assert.True(t, decimal.NewFromInt(100).Equal(writer.header.OpeningBalance))
+	assert.True(t, decimal.NewFromInt(100).Equal(writer.closing))
+	mockSnapshotRepo.AssertNotCalled(t, "FindLatest", mock.Anything, mock.Anything, mock.Anything, from)
+}
+
+func TestBalanceService_Statement_ChangedWhileStreaming(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	service, mockDB, mockAccRepo, mockTransRepo, mockSnapshotRepo := newBalanceService()
+	mockTx := new(mocks.MockTransaction)
+
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	account := &entities.Account{AccountID: 123, Currency: "USD", InitialBalance: decimal.NewFromInt(100), CreatedAt: from.AddDate(-1, 0, 0)}
+	// a transfer committed after the closing balance was read
+	late := []*entities.Transaction{{Id: 9, SourceAccountID: 456, DestinationAccountID: 123, Amount: decimal.NewFromInt(5)}}
+
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
+	mockTx.On("Commit").Return(nil)
+	mockAccRepo.On("FindById", mock.Anything, mockTx, int64(123)).Return(account, nil)
+	mockSnapshotRepo.On("FindLatest", mock.Anything, mockTx, int64(123), mock.Anything).Return(nil, sql.ErrNoRows)
+	mockTransRepo.On("NetMovement", mock.Anything, mockTx, int64(123), (*time.Time)(nil), mock.Anything).Return(decimal.Zero, nil)
+	mockTransRepo.On("StreamByAccount", mock.Anything, mockTx, int64(123), from, to, mock.Anything).Return(late, nil)
+
+	writer := &recordingStatementWriter{}
+	err := service.Statement(ctx, 123, from, to, writer)
+
+	var appErr *appErrors.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusServiceUnavailable, appErr.StatusCode)
+	assert.True(t, writer.closing.IsZero(), "End must not be called")
+}
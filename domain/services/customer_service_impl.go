@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+type CustomerServiceImpl struct {
+	DB                 ports.Database
+	CustomerRepository ports.CustomerRepository
+	CtxTimeout         time.Duration
+}
+
+func (s *CustomerServiceImpl) Save(c context.Context, request *entities.Customer) (*entities.Customer, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	customer := *request
+	if customer.KYCTier == "" {
+		customer.KYCTier = entities.KYCTierUnverified
+	}
+
+	saved, err := s.CustomerRepository.Save(ctx, tx, &customer)
+	if err != nil {
+		if isUniqueViolation(err) {
+			logger.Errorf("Customer email %s already exists", request.Email)
+			return nil, appErrors.NewConflictError("Customer email already exists", err)
+		}
+		logger.WithError(err).Error("Database error")
+		return nil, databaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, databaseError(err)
+	}
+
+	return saved, nil
+}
+
+func (s *CustomerServiceImpl) FindById(c context.Context, id int64) (*entities.Customer, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	customer, err := s.CustomerRepository.FindById(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf("CustomerID %d not found", id)
+			return nil, appErrors.NewNotFoundError("Customer not found", err)
+		}
+		logger.WithError(err).Error("Database error")
+		return nil, databaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, databaseError(err)
+	}
+
+	return customer, nil
+}
+
+func (s *CustomerServiceImpl) Update(c context.Context, request *entities.Customer) (*entities.Customer, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	customer := *request
+	if customer.KYCTier == "" {
+		customer.KYCTier = entities.KYCTierUnverified
+	}
+
+	updated, err := s.CustomerRepository.Update(ctx, tx, &customer)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf("CustomerID %d not found", request.CustomerID)
+			return nil, appErrors.NewNotFoundError("Customer not found", err)
+		}
+		if isUniqueViolation(err) {
+			logger.Errorf("Customer email %s already exists", request.Email)
+			return nil, appErrors.NewConflictError("Customer email already exists", err)
+		}
+		logger.WithError(err).Error("Database error")
+		return nil, databaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, databaseError(err)
+	}
+
+	return updated, nil
+}
+
+func (s *CustomerServiceImpl) Delete(c context.Context, id int64) error {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	err = s.CustomerRepository.Delete(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf("CustomerID %d not found", id)
+			return appErrors.NewNotFoundError("Customer not found", err)
+		}
+		if isForeignKeyViolation(err) {
+			logger.Errorf("CustomerID %d still owns accounts", id)
+			return appErrors.NewConflictError("Customer still owns accounts", err)
+		}
+		logger.WithError(err).Error("Database error")
+		return databaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return databaseError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/services"
+	"transfer-system/mocks"
+	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/logger"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCustomerService_Save_DefaultsToUnverified(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockCustomerRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.CustomerServiceImpl{
+		DB:                 mockDB,
+		CustomerRepository: mockRepo,
+		CtxTimeout:         2 * time.Second,
+	}
+
+	request := &entities.Customer{FullName: "Jane Doe", Email: "jane@example.com"}
+	expected := &entities.Customer{FullName: "Jane Doe", Email: "jane@example.com", KYCTier: entities.KYCTierUnverified}
+	saved := &entities.Customer{CustomerID: 7, FullName: "Jane Doe", Email: "jane@example.com", KYCTier: entities.KYCTierUnverified}
+
+	mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+	mockRepo.On("Save", mock.Anything, mockTx, expected).Return(saved, nil)
+	mockTx.On("Commit").Return(nil)
+
+	resp, err := service.Save(ctx, request)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), resp.CustomerID)
+
+	mockDB.AssertExpectations(t)
+	mockRepo.AssertExpectations(t)
+	mockTx.AssertExpectations(t)
+}
+
+func TestCustomerService_Save_DuplicateEmail(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockCustomerRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.CustomerServiceImpl{
+		DB:                 mockDB,
+		CustomerRepository: mockRepo,
+		CtxTimeout:         2 * time.Second,
+	}
+
+	mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+	mockRepo.On("Save", mock.Anything, mockTx, mock.Anything).Return(nil, &pq.Error{Code: "23505"})
+	mockTx.On("Rollback").Return(nil)
+
+	_, err := service.Save(ctx, &entities.Customer{FullName: "Jane Doe", Email: "jane@example.com"})
+
+	appErr, ok := err.(*appErrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusConflict, appErr.StatusCode)
+	mockTx.AssertCalled(t, "Rollback")
+}
+
+func TestCustomerService_FindById_NotFound(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockCustomerRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.CustomerServiceImpl{
+		DB:                 mockDB,
+		CustomerRepository: mockRepo,
+		CtxTimeout:         2 * time.Second,
+	}
+
+	mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+	mockRepo.On("FindById", mock.Anything, mockTx, int64(7)).Return(nil, sql.ErrNoRows)
+	mockTx.On("Rollback").Return(nil)
+
+	resp, err := service.FindById(ctx, 7)
+	assert.Nil(t, resp)
+
+	appErr, ok := err.(*appErrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, "Customer not found", appErr.Message)
+	assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+}
+
+func TestCustomerService_Update_Success(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockCustomerRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.CustomerServiceImpl{
+		DB:                 mockDB,
+		CustomerRepository: mockRepo,
+		CtxTimeout:         2 * time.Second,
+	}
+
+	request := &entities.Customer{CustomerID: 7, FullName: "Jane Doe", Email: "jane@example.com", KYCTier: entities.KYCTierFull}
+
+	mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+	mockRepo.On("Update", mock.Anything, mockTx, request).Return(request, nil)
+	mockTx.On("Commit").Return(nil)
+
+	resp, err := service.Update(ctx, request)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.KYCTierFull, resp.KYCTier)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCustomerService_Delete_StillOwnsAccounts(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockCustomerRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.CustomerServiceImpl{
+		DB:                 mockDB,
+		CustomerRepository: mockRepo,
+		CtxTimeout:         2 * time.Second,
+	}
+
+	mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+	mockRepo.On("Delete", mock.Anything, mockTx, int64(7)).Return(&pq.Error{Code: "23503"})
+	mockTx.On("Rollback").Return(nil)
+
+	err := service.Delete(ctx, 7)
+
+	appErr, ok := err.(*appErrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, "Customer still owns accounts", appErr.Message)
+	assert.Equal(t, http.StatusConflict, appErr.StatusCode)
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+
+	appErrors "transfer-system/pkg/errors"
+)
+
+// sqlStateError is implemented by driver errors that carry a SQLSTATE code (lib/pq, pgx)
+type sqlStateError interface {
+	SQLState() string
+}
+
+func sqlState(err error) string {
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		return stateErr.SQLState()
+	}
+	return ""
+}
+
+// isUniqueViolation reports whether err was raised by a unique constraint
+func isUniqueViolation(err error) bool {
+	return sqlState(err) == "23505"
+}
+
+// isForeignKeyViolation reports whether err was raised by a foreign key constraint
+func isForeignKeyViolation(err error) bool {
+	return sqlState(err) == "23503"
+}
+
+// isConcurrentUpdate reports whether err is a serialization failure or a
+// deadlock, which a new transaction may not run into
+func isConcurrentUpdate(err error) bool {
+	state := sqlState(err)
+	return state == "40001" || state == "40P01"
+}
+
+// databaseError maps a storage failure to an AppError so clients can tell
+// retryable outages (5xx) apart from problems with their request (4xx)
+func databaseError(err error) *appErrors.AppError {
+	var appErr *appErrors.AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	state := sqlState(err)
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), state == "57014":
+		return appErrors.NewTimeoutError("Request timed out, please retry", err)
+	case errors.Is(err, context.Canceled):
+		return appErrors.NewServiceUnavailableError("Request was cancelled, please retry", err)
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone), errors.As(err, &netErr):
+		return appErrors.NewServiceUnavailableError("Service temporarily unavailable, please retry", err)
+	case state == "40001", state == "40P01":
+		return appErrors.NewServiceUnavailableError("Concurrent update detected, please retry", err)
+	case state == "55P03":
+		return appErrors.NewServiceUnavailableError("Resource is locked by another request, please retry", err)
+	case strings.HasPrefix(state, "08"), strings.HasPrefix(state, "53"), strings.HasPrefix(state, "57P"):
+		return appErrors.NewServiceUnavailableError("Service temporarily unavailable, please retry", err)
+	default:
+		return appErrors.NewInternalServerError("Currently we're facing an issue", err)
+	}
+}
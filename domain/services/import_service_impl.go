@@ -0,0 +1,636 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/logger"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultImportChunkSize is how many rows are committed per database transaction
+const DefaultImportChunkSize = 500
+
+// errImportJobMoved means another request committed a chunk of the job first
+var errImportJobMoved = errors.New("import job progressed concurrently")
+
+// ImportServiceImpl commits CSV imports in chunks. Rows go through the same
+// checks as the single account and transfer endpoints, run inside the chunk's
+// transaction, so a chunk is written entirely or not at all.
+//
+// With BulkAccounts and BulkTransactions set, a chunk's rows are checked one
+// by one but written together at its end: one bulk insert per table and one
+// balance update per account touched
+type ImportServiceImpl struct {
+	DB                  ports.Database
+	ImportJobRepository ports.ImportJobRepository
+	AccountRepository   ports.AccountRepository
+	CustomerRepository  ports.CustomerRepository
+	AccountService      *AccountServiceImpl
+	TransactionService  *TransactionServiceImpl
+	BulkAccounts        ports.AccountBulkRepository
+	BulkTransactions    ports.TransactionBulkRepository
+	ChunkSize           int
+	CtxTimeout          time.Duration
+}
+
+// Validate reports rows that would be rejected: duplicates within the file,
+// clashes with existing accounts, unknown accounts and owners, frozen and system accounts,
+// and transfers that would overdraw an account given the rows before them
+func (s *ImportServiceImpl) Validate(c context.Context, file *entities.ImportFile) ([]entities.ImportRowError, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var rowErrors []entities.ImportRowError
+	switch file.Kind {
+	case entities.ImportKindAccounts:
+		rowErrors, err = s.validateAccounts(ctx, tx, file.Rows)
+	case entities.ImportKindTransfers:
+		rowErrors, err = s.validateTransfers(ctx, tx, file.Rows)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, databaseError(err)
+	}
+
+	return rowErrors, nil
+}
+
+func (s *ImportServiceImpl) validateAccounts(ctx context.Context, tx ports.Transaction, rows []*entities.ImportRow) ([]entities.ImportRowError, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	rowErrors := []entities.ImportRowError{}
+	ids := map[int64]int{}
+	references := map[string]int{}
+	owners := map[int64]bool{}
+	for _, row := range rows {
+		account := row.Account
+
+		if account.AccountID != 0 {
+			if line, seen := ids[account.AccountID]; seen {
+				rowErrors = append(rowErrors, entities.ImportRowError{Line: row.Line, Message: fmt.Sprintf("account_id %d repeats line %d", account.AccountID, line)})
+				continue
+			}
+			ids[account.AccountID] = row.Line
+
+			_, err := s.AccountRepository.FindById(ctx, tx, account.AccountID)
+			if err == nil {
+				rowErrors = append(rowErrors, entities.ImportRowError{Line: row.Line, Message: fmt.Sprintf("account_id %d already exists", account.AccountID)})
+				continue
+			}
+			if !errors.Is(err, sql.ErrNoRows) {
+				logger.WithError(err).Error("Database error")
+				return nil, databaseError(err)
+			}
+		}
+
+		if account.ExternalReference != "" {
+			if line, seen := references[account.ExternalReference]; seen {
+				rowErrors = append(rowErrors, entities.ImportRowError{Line: row.Line, Message: fmt.Sprintf("external_reference %s repeats line %d", account.ExternalReference, line)})
+				continue
+			}
+			references[account.ExternalReference] = row.Line
+
+			_, err := s.AccountRepository.FindByExternalReference(ctx, tx, account.ExternalReference)
+			if err == nil {
+				rowErrors = append(rowErrors, entities.ImportRowError{Line: row.Line, Message: fmt.Sprintf("external_reference %s already exists", account.ExternalReference)})
+				continue
+			}
+			if !errors.Is(err, sql.ErrNoRows) {
+				logger.WithError(err).Error("Database error")
+				return nil, databaseError(err)
+			}
+		}
+
+		if account.OwnerID != nil {
+			exists, checked := owners[*account.OwnerID]
+			if !checked {
+				_, err := s.CustomerRepository.FindById(ctx, tx, *account.OwnerID)
+				if err != nil && !errors.Is(err, sql.ErrNoRows) {
+					logger.WithError(err).Error("Database error")
+					return nil, databaseError(err)
+				}
+				exists = err == nil
+				owners[*account.OwnerID] = exists
+			}
+			if !exists {
+				rowErrors = append(rowErrors, entities.ImportRowError{Line: row.Line, Message: fmt.Sprintf("owner_id %d not found", *account.OwnerID)})
+			}
+		}
+	}
+
+	return rowErrors, nil
+}
+
+func (s *ImportServiceImpl) validateTransfers(ctx context.Context, tx ports.Transaction, rows []*entities.ImportRow) ([]entities.ImportRowError, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	rowErrors := []entities.ImportRowError{}
+	// accounts holds the balance each account would have after the rows so far, nil for unknown accounts
+	accounts := map[int64]*entities.Account{}
+	lookup := func(id int64) (*entities.Account, error) {
+		account, seen := accounts[id]
+		if seen {
+			return account, nil
+		}
+		account, err := s.AccountRepository.FindById(ctx, tx, id)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				logger.WithError(err).Error("Database error")
+				return nil, databaseError(err)
+			}
+			account = nil
+		}
+		accounts[id] = account
+		return account, nil
+	}
+
+	for _, row := range rows {
+		transfer := row.Transfer
+
+		source, err := lookup(transfer.SourceAccountID)
+		if err != nil {
+			return nil, err
+		}
+		destination, err := lookup(transfer.DestinationAccountID)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case source == nil:
+			rowErrors = append(rowErrors, entities.ImportRowError{Line: row.Line, Message: fmt.Sprintf("source_account_id %d not found", transfer.SourceAccountID)})
+		case destination == nil:
+			rowErrors = append(rowErrors, entities.ImportRowError{Line: row.Line, Message: fmt.Sprintf("destination_account_id %d not found", transfer.DestinationAccountID)})
+		case source.Kind.IsSystem():
+			rowErrors = append(rowErrors, entities.ImportRowError{Line: row.Line, Message: fmt.Sprintf("account %d is a system account", source.AccountID)})
+		case destination.Kind.IsSystem():
+			rowErrors = append(rowErrors, entities.ImportRowError{Line: row.Line, Message: fmt.Sprintf("account %d is a system account", destination.AccountID)})
+		case source.Status == entities.AccountStatusFrozen || source.Status == entities.AccountStatusClosed:
+			rowErrors = append(rowErrors, entities.ImportRowError{Line: row.Line, Message: fmt.Sprintf("account %d is %s", source.AccountID, source.Status)})
+		case destination.Status == entities.AccountStatusFrozen || destination.Status == entities.AccountStatusClosed:
+			rowErrors = append(rowErrors, entities.ImportRowError{Line: row.Line, Message: fmt.Sprintf("account %d is %s", destination.AccountID, destination.Status)})
+		case source.Currency != destination.Currency:
+			rowErrors = append(rowErrors, entities.ImportRowError{Line: row.Line, Message: "accounts have different currencies"})
+		case source.Balance.LessThan(transfer.Amount):
+			rowErrors = append(rowErrors, entities.ImportRowError{Line: row.Line, Message: fmt.Sprintf("insufficient balance, account %d would hold %s", source.AccountID, source.Balance)})
+		default:
+			source.Balance = source.Balance.Sub(transfer.Amount)
+			destination.Balance = destination.Balance.Add(transfer.Amount)
+		}
+	}
+
+	return rowErrors, nil
+}
+
+// Import starts a job for the file, or resumes jobID, and commits the
+// remaining rows chunk by chunk. A failing row rolls back its chunk and stops
+// the job; the returned job then names the line and keeps the rows committed
+// before it. Once the cause is dealt with (say an account is topped up) the
+// unchanged file is resubmitted with the job id to continue
+func (s *ImportServiceImpl) Import(c context.Context, file *entities.ImportFile, jobID string) (*entities.ImportJob, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	if len(file.Rows) == 0 {
+		return nil, appErrors.NewBadRequestError("Import file has no rows", nil)
+	}
+
+	job, err := s.startJob(c, file, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	chunkSize := s.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = DefaultImportChunkSize
+	}
+	for job.CommittedRows < job.TotalRows {
+		end := job.CommittedRows + chunkSize
+		if end > job.TotalRows {
+			end = job.TotalRows
+		}
+
+		failedLine, err := s.commitChunk(c, job, file.Rows[job.CommittedRows:end])
+		if err != nil {
+			if errors.Is(err, errImportJobMoved) {
+				return job, err
+			}
+			logger.WithError(err).Errorf("Import job %s stopped at line %d", job.ID, failedLine)
+			s.failJob(c, job, failedLine, err)
+			return job, err
+		}
+	}
+
+	return job, nil
+}
+
+func (s *ImportServiceImpl) startJob(c context.Context, file *entities.ImportFile, jobID string) (*entities.ImportJob, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var job *entities.ImportJob
+	if jobID == "" {
+		job, err = s.ImportJobRepository.Save(ctx, tx, &entities.ImportJob{
+			ID:        uuid.New().String(),
+			Kind:      file.Kind,
+			Status:    entities.ImportStatusRunning,
+			Checksum:  file.Checksum,
+			TotalRows: len(file.Rows),
+		})
+		if err != nil {
+			logger.WithError(err).Error("Failed to create import job")
+			return nil, databaseError(err)
+		}
+	} else {
+		job, err = s.ImportJobRepository.FindById(ctx, tx, jobID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				logger.Errorf("Import job %s not found", jobID)
+				return nil, appErrors.NewNotFoundError("Import job not found", err)
+			}
+			logger.WithError(err).Error("Database error")
+			return nil, databaseError(err)
+		}
+
+		if job.Kind != file.Kind || job.Checksum != file.Checksum {
+			logger.Errorf("Import job %s was started with a different %s file", jobID, job.Kind)
+			err = appErrors.NewConflictError(fmt.Sprintf("Import job %s was started with a different %s file", jobID, job.Kind), nil)
+			return nil, err
+		}
+
+		job.Status = entities.ImportStatusRunning
+		if job.CommittedRows == job.TotalRows {
+			job.Status = entities.ImportStatusCompleted
+		}
+		job.FailedLine = 0
+		job.Error = ""
+		if err = s.ImportJobRepository.Update(ctx, tx, job); err != nil {
+			logger.WithError(err).Error("Failed to update import job")
+			return nil, databaseError(err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, databaseError(err)
+	}
+
+	return job, nil
+}
+
+// commitChunk writes rows and the job progress in one transaction, returning the line of a failing row
+func (s *ImportServiceImpl) commitChunk(c context.Context, job *entities.ImportJob, rows []*entities.ImportRow) (int, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	// read committed like AccountService.Save: account rows debit the shared
+	// equity account, whose lock orders them with other creations
+	tx, err := s.DB.BeginTx(ctx, readCommitted)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return 0, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// the job row lock serializes resumes of the same job
+	locked, err := s.ImportJobRepository.FindById(ctx, tx, job.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to lock import job")
+		return 0, databaseError(err)
+	}
+	if locked.CommittedRows != job.CommittedRows {
+		logger.Errorf("Import job %s is at row %d, expected %d", job.ID, locked.CommittedRows, job.CommittedRows)
+		err = appErrors.NewConflictError("Import job is being resumed by another request", errImportJobMoved)
+		return 0, err
+	}
+
+	var failedLine int
+	bulk := s.BulkAccounts != nil && s.BulkTransactions != nil
+	switch {
+	case bulk && job.Kind == entities.ImportKindAccounts:
+		failedLine, err = s.copyAccounts(ctx, tx, rows)
+	case bulk && job.Kind == entities.ImportKindTransfers:
+		failedLine, err = s.copyTransfers(ctx, tx, rows)
+	default:
+		failedLine, err = s.applyRows(ctx, tx, job.Kind, rows)
+	}
+	if err != nil {
+		return failedLine, err
+	}
+
+	progress := *job
+	progress.CommittedRows += len(rows)
+	if progress.CommittedRows == progress.TotalRows {
+		progress.Status = entities.ImportStatusCompleted
+	}
+	if err = s.ImportJobRepository.Update(ctx, tx, &progress); err != nil {
+		logger.WithError(err).Error("Failed to update import job")
+		return 0, databaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return 0, databaseError(err)
+	}
+
+	*job = progress
+	return 0, nil
+}
+
+// applyRows creates or books each row in turn, as the single endpoints do
+func (s *ImportServiceImpl) applyRows(ctx context.Context, tx ports.Transaction, kind entities.ImportKind, rows []*entities.ImportRow) (int, error) {
+	for _, row := range rows {
+		var err error
+		switch kind {
+		case entities.ImportKindAccounts:
+			_, err = s.AccountService.create(ctx, tx, row.Account)
+		case entities.ImportKindTransfers:
+			_, err = s.TransactionService.transfer(ctx, tx, row.Transfer)
+		}
+		if err != nil {
+			return row.Line, importRowError(row.Line, err)
+		}
+	}
+	return 0, nil
+}
+
+// copyAccounts checks every row, then inserts the accounts with their
+// balances in one go and books the opening deposits from each currency's
+// equity account
+func (s *ImportServiceImpl) copyAccounts(ctx context.Context, tx ports.Transaction, rows []*entities.ImportRow) (int, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	accounts := make([]*entities.Account, 0, len(rows))
+	ids := map[int64]bool{}
+	references := map[string]bool{}
+	for _, row := range rows {
+		// the rows before it are not in the table yet, so look for clashes with them here
+		if row.Account.AccountID != 0 && ids[row.Account.AccountID] {
+			return row.Line, importRowError(row.Line, appErrors.NewConflictError("AccountId already exists", nil))
+		}
+		if row.Account.ExternalReference != "" && references[row.Account.ExternalReference] {
+			return row.Line, importRowError(row.Line, appErrors.NewConflictError("External reference already exists", nil))
+		}
+
+		account, err := s.AccountService.prepare(ctx, tx, row.Account)
+		if err != nil {
+			return row.Line, importRowError(row.Line, err)
+		}
+		ids[account.AccountID] = true
+		references[account.ExternalReference] = true
+
+		// the balance is written with the row; the opening deposit below explains it
+		if row.Account.Balance.IsPositive() {
+			account.Balance = row.Account.Balance
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err := s.BulkAccounts.SaveMany(ctx, tx, accounts); err != nil {
+		if isUniqueViolation(err) {
+			logger.WithError(err).Error("Account already exists")
+			return 0, appErrors.NewConflictError("Account already exists", err)
+		}
+		logger.WithError(err).Error("Failed to insert accounts")
+		return 0, databaseError(err)
+	}
+
+	equities := map[string]*entities.Account{}
+	debits := map[string]decimal.Decimal{}
+	currencies := []string{}
+	openings := []*entities.Transaction{}
+	for _, account := range accounts {
+		if !account.Balance.IsPositive() {
+			continue
+		}
+		equity, ok := equities[account.Currency]
+		if !ok {
+			var err error
+			equity, err = s.AccountRepository.SystemAccount(ctx, tx, entities.AccountKindEquity, account.Currency)
+			if err != nil {
+				logger.WithError(err).Errorf("Failed to get the %s equity account", account.Currency)
+				return 0, databaseError(err)
+			}
+			// locked like AccountService.fund does, so concurrent creations queue behind the import
+			if equity, err = s.AccountRepository.FindByIdForUpdate(ctx, tx, equity.AccountID); err != nil {
+				logger.WithError(err).Errorf("Failed to lock the %s equity account", account.Currency)
+				return 0, databaseError(err)
+			}
+			equities[account.Currency] = equity
+			currencies = append(currencies, account.Currency)
+		}
+		openings = append(openings, &entities.Transaction{
+			Type:                 entities.TransactionTypeDeposit,
+			SourceAccountID:      equity.AccountID,
+			DestinationAccountID: account.AccountID,
+			Amount:               account.Balance,
+			Description:          entities.OpeningBalanceDescription,
+		})
+		debits[account.Currency] = debits[account.Currency].Add(account.Balance)
+	}
+	if len(openings) == 0 {
+		return 0, nil
+	}
+
+	if err := s.BulkTransactions.SaveMany(ctx, tx, openings); err != nil {
+		logger.WithError(err).Error("Failed to save opening transactions")
+		return 0, databaseError(err)
+	}
+	for _, currency := range currencies {
+		if err := s.AccountService.TransactionRepository.UpdateBalance(ctx, tx, equities[currency].AccountID, debits[currency].Neg()); err != nil {
+			logger.WithError(err).Error("Failed to update equity account balance")
+			return 0, databaseError(err)
+		}
+	}
+
+	return 0, nil
+}
+
+// copyTransfers checks every row against the balances and daily totals left
+// by the rows before it, then inserts the transfers in one go and applies
+// the net change of each account
+func (s *ImportServiceImpl) copyTransfers(ctx context.Context, tx ports.Transaction, rows []*entities.ImportRow) (int, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	// accounts are read, and locked, once; later rows see the balances earlier rows left
+	accounts := map[int64]*entities.Account{}
+	lookup := func(ctx context.Context, tx ports.Transaction, id int64) (*entities.Account, error) {
+		if account, ok := accounts[id]; ok {
+			return account, nil
+		}
+		account, err := s.AccountRepository.FindByIdForUpdate(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		accounts[id] = account
+		return account, nil
+	}
+
+	sent := map[int64]decimal.Decimal{}
+	deltas := map[int64]decimal.Decimal{}
+	transactions := make([]*entities.Transaction, 0, len(rows))
+	for _, row := range rows {
+		source, destination, err := s.TransactionService.validateTransfer(ctx, tx, row.Transfer, lookup, sent)
+		if err != nil {
+			return row.Line, importRowError(row.Line, err)
+		}
+
+		amount := row.Transfer.Amount
+		source.Balance = source.Balance.Sub(amount)
+		destination.Balance = destination.Balance.Add(amount)
+		deltas[source.AccountID] = deltas[source.AccountID].Sub(amount)
+		deltas[destination.AccountID] = deltas[destination.AccountID].Add(amount)
+		if source.OwnerID != nil {
+			sent[*source.OwnerID] = sent[*source.OwnerID].Add(amount)
+		}
+		transactions = append(transactions, newTransaction(entities.TransactionTypeTransfer, row.Transfer))
+	}
+
+	if err := s.BulkTransactions.SaveMany(ctx, tx, transactions); err != nil {
+		logger.WithError(err).Error("Failed to save transactions")
+		return 0, databaseError(err)
+	}
+
+	// a fixed order keeps concurrent chunks from taking row locks in opposite orders
+	ids := make([]int64, 0, len(deltas))
+	for id, delta := range deltas {
+		if !delta.IsZero() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		if err := s.TransactionService.TransactionRepository.UpdateBalance(ctx, tx, id, deltas[id]); err != nil {
+			logger.WithError(err).Errorf("Failed to update balance of account %d", id)
+			return 0, databaseError(err)
+		}
+	}
+
+	return 0, nil
+}
+
+// failJob records why the job stopped; the job is returned to the client either way
+func (s *ImportServiceImpl) failJob(c context.Context, job *entities.ImportJob, failedLine int, cause error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	job.Status = entities.ImportStatusFailed
+	job.FailedLine = failedLine
+	job.Error = cause.Error()
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c), s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = s.ImportJobRepository.Update(ctx, tx, job); err != nil {
+		logger.WithError(err).Error("Failed to record import job failure")
+		return
+	}
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+	}
+}
+
+func (s *ImportServiceImpl) FindJob(c context.Context, id string) (*entities.ImportJob, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	job, err := s.ImportJobRepository.FindById(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf("Import job %s not found", id)
+			return nil, appErrors.NewNotFoundError("Import job not found", err)
+		}
+		logger.WithError(err).Error("Database error")
+		return nil, databaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, databaseError(err)
+	}
+
+	return job, nil
+}
+
+// importRowError prefixes the row's line to the client facing message, keeping the status code
+func importRowError(line int, err error) error {
+	var appErr *appErrors.AppError
+	if errors.As(err, &appErr) {
+		return &appErrors.AppError{Message: fmt.Sprintf("line %d: %s", line, appErr.Message), StatusCode: appErr.StatusCode, Err: err}
+	}
+	return err
+}
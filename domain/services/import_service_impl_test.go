@@ -0,0 +1,361 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/services"
+	"transfer-system/mocks"
+	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/logger"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newImportService() (*services.ImportServiceImpl, *mocks.MockDatabase, *mocks.MockImportJobRepository, *mocks.MockAccountRepository, *mocks.MockCustomerRepository) {
+	mockDB := new(mocks.MockDatabase)
+	mockJobRepo := new(mocks.MockImportJobRepository)
+	mockAccRepo := new(mocks.MockAccountRepository)
+	mockCustRepo := new(mocks.MockCustomerRepository)
+	mockTxRepo := new(mocks.MockTransactionRepository)
+
+	// imported accounts are funded by opening transfers like any other
+	mockAccRepo.On("SystemAccount", mock.Anything, mock.Anything, entities.AccountKindEquity, mock.Anything).Return(&entities.Account{AccountID: 1000}, nil).Maybe()
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mock.Anything, int64(1000)).Return(&entities.Account{AccountID: 1000}, nil).Maybe()
+	mockTxRepo.On("Save", mock.Anything, mock.Anything, mock.Anything).Return(&entities.Transaction{}, nil).Maybe()
+	mockTxRepo.On("UpdateBalance", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	service := &services.ImportServiceImpl{
+		DB:                  mockDB,
+		ImportJobRepository: mockJobRepo,
+		AccountRepository:   mockAccRepo,
+		CustomerRepository:  mockCustRepo,
+		AccountService: &services.AccountServiceImpl{
+			DB:                    mockDB,
+			AccountRepository:     mockAccRepo,
+			CustomerRepository:    mockCustRepo,
+			TransactionRepository: mockTxRepo,
+			CtxTimeout:            2 * time.Second,
+		},
+		ChunkSize:  2,
+		CtxTimeout: 2 * time.Second,
+	}
+	return service, mockDB, mockJobRepo, mockAccRepo, mockCustRepo
+}
+
+func accountImportFile(ids ...int64) *entities.ImportFile {
+	file := &entities.ImportFile{Kind: entities.ImportKindAccounts, Checksum: "c0ffee"}
+	for i, id := range ids {
+		file.Rows = append(file.Rows, &entities.ImportRow{Line: i + 2, Account: &entities.Account{AccountID: id, Balance: decimal.NewFromInt(100)}})
+	}
+	return file
+}
+
+func TestImportService_Validate(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	t.Run("accounts", func(t *testing.T) {
+		service, mockDB, _, mockAccRepo, mockCustRepo := newImportService()
+		mockTx := new(mocks.MockTransaction)
+		ownerID := int64(9)
+
+		file := accountImportFile(1, 1, 2)
+		file.Rows = append(file.Rows, &entities.ImportRow{Line: 5, Account: &entities.Account{Balance: decimal.Zero, OwnerID: &ownerID}})
+
+		mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+		mockAccRepo.On("FindById", mock.Anything, mockTx, int64(1)).Return(nil, sql.ErrNoRows)
+		mockAccRepo.On("FindById", mock.Anything, mockTx, int64(2)).Return(&entities.Account{AccountID: 2}, nil)
+		mockCustRepo.On("FindById", mock.Anything, mockTx, ownerID).Return(nil, sql.ErrNoRows)
+		mockTx.On("Commit").Return(nil)
+
+		rowErrors, err := service.Validate(ctx, file)
+		assert.NoError(t, err)
+		assert.Equal(t, []entities.ImportRowError{
+			{Line: 3, Message: "account_id 1 repeats line 2"},
+			{Line: 4, Message: "account_id 2 already exists"},
+			{Line: 5, Message: "owner_id 9 not found"},
+		}, rowErrors)
+		mockAccRepo.AssertNumberOfCalls(t, "FindById", 2)
+	})
+
+	t.Run("transfers use the balance left by earlier rows", func(t *testing.T) {
+		service, mockDB, _, mockAccRepo, _ := newImportService()
+		mockTx := new(mocks.MockTransaction)
+
+		file := &entities.ImportFile{Kind: entities.ImportKindTransfers, Rows: []*entities.ImportRow{
+			{Line: 2, Transfer: &entities.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(80)}},
+			{Line: 3, Transfer: &entities.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(30)}},
+			{Line: 4, Transfer: &entities.Transaction{SourceAccountID: 2, DestinationAccountID: 3, Amount: decimal.NewFromInt(1)}},
+		}}
+
+		mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+		mockAccRepo.On("FindById", mock.Anything, mockTx, int64(1)).Return(&entities.Account{AccountID: 1, Balance: decimal.NewFromInt(100), Currency: "USD"}, nil).Once()
+		mockAccRepo.On("FindById", mock.Anything, mockTx, int64(2)).Return(&entities.Account{AccountID: 2, Balance: decimal.Zero, Currency: "USD"}, nil).Once()
+		mockAccRepo.On("FindById", mock.Anything, mockTx, int64(3)).Return(nil, sql.ErrNoRows).Once()
+		mockTx.On("Commit").Return(nil)
+
+		rowErrors, err := service.Validate(ctx, file)
+		assert.NoError(t, err)
+		assert.Equal(t, []entities.ImportRowError{
+			{Line: 3, Message: "insufficient balance, account 1 would hold 20"},
+			{Line: 4, Message: "destination_account_id 3 not found"},
+		}, rowErrors)
+	})
+}
+
+func TestImportService_Import(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	t.Run("commits in chunks", func(t *testing.T) {
+		service, mockDB, mockJobRepo, mockAccRepo, _ := newImportService()
+		mockTx := new(mocks.MockTransaction)
+		file := accountImportFile(1, 2, 3)
+
+		job := &entities.ImportJob{ID: "job", Kind: entities.ImportKindAccounts, Status: entities.ImportStatusRunning, Checksum: "c0ffee", TotalRows: 3}
+		mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+		mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+		mockJobRepo.On("Save", mock.Anything, mockTx, mock.MatchedBy(func(saved *entities.ImportJob) bool {
+			return saved.TotalRows == 3 && saved.Checksum == "c0ffee" && saved.ID != ""
+		})).Return(job, nil)
+		mockJobRepo.On("FindById", mock.Anything, mockTx, "job").Return(&entities.ImportJob{ID: "job", CommittedRows: 0}, nil).Once()
+		mockJobRepo.On("FindById", mock.Anything, mockTx, "job").Return(&entities.ImportJob{ID: "job", CommittedRows: 2}, nil).Once()
+		mockJobRepo.On("Update", mock.Anything, mockTx, mock.Anything).Return(nil)
+		mockAccRepo.On("FindById", mock.Anything, mockTx, mock.Anything).Return(nil, sql.ErrNoRows)
+		mockAccRepo.On("Save", mock.Anything, mockTx, mock.Anything).Return(&entities.Account{}, nil)
+		mockTx.On("Commit").Return(nil)
+
+		result, err := service.Import(ctx, file, "")
+		assert.NoError(t, err)
+		assert.Equal(t, entities.ImportStatusCompleted, result.Status)
+		assert.Equal(t, 3, result.CommittedRows)
+		mockAccRepo.AssertNumberOfCalls(t, "Save", 3)
+		mockJobRepo.AssertNumberOfCalls(t, "Update", 2)
+		mockTx.AssertNumberOfCalls(t, "Commit", 3)
+	})
+
+	t.Run("a failing row stops the job", func(t *testing.T) {
+		service, mockDB, mockJobRepo, mockAccRepo, _ := newImportService()
+		mockTx := new(mocks.MockTransaction)
+		file := accountImportFile(1, 2, 3)
+
+		mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+		mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+		mockJobRepo.On("FindById", mock.Anything, mockTx, "job").Return(&entities.ImportJob{ID: "job", Kind: entities.ImportKindAccounts, Status: entities.ImportStatusFailed, Checksum: "c0ffee", TotalRows: 3, CommittedRows: 2, FailedLine: 4}, nil).Once()
+		mockJobRepo.On("FindById", mock.Anything, mockTx, "job").Return(&entities.ImportJob{ID: "job", CommittedRows: 2}, nil).Once()
+		mockJobRepo.On("Update", mock.Anything, mockTx, mock.Anything).Return(nil)
+		mockAccRepo.On("FindById", mock.Anything, mockTx, int64(3)).Return(&entities.Account{AccountID: 3}, nil)
+		mockTx.On("Commit").Return(nil)
+		mockTx.On("Rollback").Return(nil)
+
+		result, err := service.Import(ctx, file, "job")
+		assert.Error(t, err)
+		appErr, ok := err.(*appErrors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusConflict, appErr.StatusCode)
+		assert.Equal(t, "line 4: AccountId already exists", appErr.Message)
+
+		assert.Equal(t, entities.ImportStatusFailed, result.Status)
+		assert.Equal(t, 2, result.CommittedRows)
+		assert.Equal(t, 4, result.FailedLine)
+		mockJobRepo.AssertCalled(t, "Update", mock.Anything, mockTx, mock.MatchedBy(func(updated *entities.ImportJob) bool {
+			return updated.Status == entities.ImportStatusFailed && updated.FailedLine == 4
+		}))
+		mockAccRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("resuming with another file", func(t *testing.T) {
+		service, mockDB, mockJobRepo, _, _ := newImportService()
+		mockTx := new(mocks.MockTransaction)
+
+		mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+		mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+		mockJobRepo.On("FindById", mock.Anything, mockTx, "job").Return(&entities.ImportJob{ID: "job", Kind: entities.ImportKindAccounts, Checksum: "another", TotalRows: 3}, nil)
+		mockTx.On("Rollback").Return(nil)
+
+		result, err := service.Import(ctx, accountImportFile(1, 2, 3), "job")
+		assert.Nil(t, result)
+		appErr, ok := err.(*appErrors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusConflict, appErr.StatusCode)
+		mockJobRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("job moved by another request", func(t *testing.T) {
+		service, mockDB, mockJobRepo, _, _ := newImportService()
+		mockTx := new(mocks.MockTransaction)
+
+		mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+		mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+		mockJobRepo.On("FindById", mock.Anything, mockTx, "job").Return(&entities.ImportJob{ID: "job", Kind: entities.ImportKindAccounts, Checksum: "c0ffee", TotalRows: 3}, nil).Once()
+		mockJobRepo.On("FindById", mock.Anything, mockTx, "job").Return(&entities.ImportJob{ID: "job", CommittedRows: 2}, nil).Once()
+		mockJobRepo.On("Update", mock.Anything, mockTx, mock.Anything).Return(nil).Once()
+		mockTx.On("Commit").Return(nil)
+		mockTx.On("Rollback").Return(nil)
+
+		result, err := service.Import(ctx, accountImportFile(1, 2, 3), "job")
+		appErr, ok := err.(*appErrors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusConflict, appErr.StatusCode)
+		assert.Equal(t, entities.ImportStatusRunning, result.Status)
+		mockJobRepo.AssertNumberOfCalls(t, "Update", 1)
+	})
+}
+
+func newBulkImportService() (*services.ImportServiceImpl, *mocks.MockDatabase, *mocks.MockImportJobRepository, *mocks.MockAccountRepository, *mocks.MockTransactionRepository) {
+	mockDB := new(mocks.MockDatabase)
+	mockJobRepo := new(mocks.MockImportJobRepository)
+	mockAccRepo := new(mocks.MockAccountRepository)
+	mockCustRepo := new(mocks.MockCustomerRepository)
+	mockTxRepo := new(mocks.MockTransactionRepository)
+
+	service := &services.ImportServiceImpl{
+		DB:                  mockDB,
+		ImportJobRepository: mockJobRepo,
+		AccountRepository:   mockAccRepo,
+		CustomerRepository:  mockCustRepo,
+		AccountService: &services.AccountServiceImpl{
+			DB:                    mockDB,
+			AccountRepository:     mockAccRepo,
+			CustomerRepository:    mockCustRepo,
+			TransactionRepository: mockTxRepo,
+			CtxTimeout:            2 * time.Second,
+		},
+		TransactionService: &services.TransactionServiceImpl{
+			DB:                    mockDB,
+			TransactionRepository: mockTxRepo,
+			AccountRepository:     mockAccRepo,
+			CustomerRepository:    mockCustRepo,
+			CtxTimeout:            2 * time.Second,
+		},
+		BulkAccounts:     mockAccRepo,
+		BulkTransactions: mockTxRepo,
+		ChunkSize:        10,
+		CtxTimeout:       2 * time.Second,
+	}
+	return service, mockDB, mockJobRepo, mockAccRepo, mockTxRepo
+}
+
+func TestImportService_Import_Bulk(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	t.Run("accounts are inserted together with their opening deposits", func(t *testing.T) {
+		service, mockDB, mockJobRepo, mockAccRepo, mockTxRepo := newBulkImportService()
+		mockTx := new(mocks.MockTransaction)
+		file := accountImportFile(1, 2, 3)
+		file.Rows[2].Account.Balance = decimal.Zero
+
+		mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+		mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+		mockJobRepo.On("Save", mock.Anything, mockTx, mock.Anything).Return(&entities.ImportJob{ID: "job", Kind: entities.ImportKindAccounts, TotalRows: 3}, nil)
+		mockJobRepo.On("FindById", mock.Anything, mockTx, "job").Return(&entities.ImportJob{ID: "job"}, nil)
+		mockJobRepo.On("Update", mock.Anything, mockTx, mock.Anything).Return(nil)
+		mockAccRepo.On("FindById", mock.Anything, mockTx, mock.Anything).Return(nil, sql.ErrNoRows)
+		mockAccRepo.On("SaveMany", mock.Anything, mockTx, mock.MatchedBy(func(accounts []*entities.Account) bool {
+			return len(accounts) == 3 && accounts[0].Balance.Equal(decimal.NewFromInt(100)) && accounts[2].Balance.IsZero() &&
+				accounts[0].InitialBalance.IsZero()
+		})).Return(nil)
+		mockAccRepo.On("SystemAccount", mock.Anything, mockTx, entities.AccountKindEquity, entities.DefaultCurrency).Return(&entities.Account{AccountID: 1000}, nil).Once()
+		mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(1000)).Return(&entities.Account{AccountID: 1000}, nil).Once()
+		mockTxRepo.On("SaveMany", mock.Anything, mockTx, mock.MatchedBy(func(openings []*entities.Transaction) bool {
+			return len(openings) == 2 && openings[0].SourceAccountID == 1000 && openings[1].DestinationAccountID == 2 &&
+				openings[0].Type == entities.TransactionTypeDeposit && openings[0].Description == entities.OpeningBalanceDescription
+		})).Return(nil)
+		mockTxRepo.On("UpdateBalance", mock.Anything, mockTx, int64(1000), decimal.NewFromInt(-200)).Return(nil).Once()
+		mockTx.On("Commit").Return(nil)
+
+		result, err := service.Import(ctx, file, "")
+		assert.NoError(t, err)
+		assert.Equal(t, entities.ImportStatusCompleted, result.Status)
+		mockAccRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything, mock.Anything)
+		mockTxRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything, mock.Anything)
+		mockTxRepo.AssertNumberOfCalls(t, "UpdateBalance", 1)
+	})
+
+	t.Run("an account repeated within the chunk", func(t *testing.T) {
+		service, mockDB, mockJobRepo, mockAccRepo, _ := newBulkImportService()
+		mockTx := new(mocks.MockTransaction)
+
+		mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+		mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+		mockJobRepo.On("Save", mock.Anything, mockTx, mock.Anything).Return(&entities.ImportJob{ID: "job", Kind: entities.ImportKindAccounts, TotalRows: 2}, nil)
+		mockJobRepo.On("FindById", mock.Anything, mockTx, "job").Return(&entities.ImportJob{ID: "job"}, nil)
+		mockJobRepo.On("Update", mock.Anything, mockTx, mock.Anything).Return(nil)
+		mockAccRepo.On("FindById", mock.Anything, mockTx, int64(1)).Return(nil, sql.ErrNoRows)
+		mockTx.On("Commit").Return(nil)
+		mockTx.On("Rollback").Return(nil)
+
+		result, err := service.Import(ctx, accountImportFile(1, 1), "")
+		appErr, ok := err.(*appErrors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusConflict, appErr.StatusCode)
+		assert.Equal(t, "line 3: AccountId already exists", appErr.Message)
+		assert.Equal(t, 3, result.FailedLine)
+		mockAccRepo.AssertNotCalled(t, "SaveMany", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("transfers apply one balance change per account", func(t *testing.T) {
+		service, mockDB, mockJobRepo, mockAccRepo, mockTxRepo := newBulkImportService()
+		mockTx := new(mocks.MockTransaction)
+		file := &entities.ImportFile{Kind: entities.ImportKindTransfers, Rows: []*entities.ImportRow{
+			{Line: 2, Transfer: &entities.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(80)}},
+			{Line: 3, Transfer: &entities.Transaction{SourceAccountID: 2, DestinationAccountID: 3, Amount: decimal.NewFromInt(50)}},
+			{Line: 4, Transfer: &entities.Transaction{SourceAccountID: 3, DestinationAccountID: 1, Amount: decimal.NewFromInt(50)}},
+		}}
+
+		mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+		mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+		mockJobRepo.On("Save", mock.Anything, mockTx, mock.Anything).Return(&entities.ImportJob{ID: "job", Kind: entities.ImportKindTransfers, TotalRows: 3}, nil)
+		mockJobRepo.On("FindById", mock.Anything, mockTx, "job").Return(&entities.ImportJob{ID: "job"}, nil)
+		mockJobRepo.On("Update", mock.Anything, mockTx, mock.Anything).Return(nil)
+		// the second and third rows spend money that only the rows before them bring
+		mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(1)).Return(&entities.Account{AccountID: 1, Balance: decimal.NewFromInt(100), Currency: "USD"}, nil).Once()
+		mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(2)).Return(&entities.Account{AccountID: 2, Balance: decimal.Zero, Currency: "USD"}, nil).Once()
+		mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(3)).Return(&entities.Account{AccountID: 3, Balance: decimal.Zero, Currency: "USD"}, nil).Once()
+		mockTxRepo.On("SaveMany", mock.Anything, mockTx, mock.MatchedBy(func(transactions []*entities.Transaction) bool {
+			return len(transactions) == 3 && transactions[1].Type == entities.TransactionTypeTransfer
+		})).Return(nil)
+		mockTxRepo.On("UpdateBalance", mock.Anything, mockTx, int64(1), decimal.NewFromInt(-30)).Return(nil).Once()
+		mockTxRepo.On("UpdateBalance", mock.Anything, mockTx, int64(2), decimal.NewFromInt(30)).Return(nil).Once()
+		mockTx.On("Commit").Return(nil)
+
+		result, err := service.Import(ctx, file, "")
+		assert.NoError(t, err)
+		assert.Equal(t, 3, result.CommittedRows)
+		// account 3 received and sent 50, so its balance is left alone
+		mockTxRepo.AssertNumberOfCalls(t, "UpdateBalance", 2)
+		mockAccRepo.AssertNumberOfCalls(t, "FindByIdForUpdate", 3)
+	})
+
+	t.Run("a transfer overdrawing the balance left by earlier rows", func(t *testing.T) {
+		service, mockDB, mockJobRepo, mockAccRepo, mockTxRepo := newBulkImportService()
+		mockTx := new(mocks.MockTransaction)
+		file := &entities.ImportFile{Kind: entities.ImportKindTransfers, Rows: []*entities.ImportRow{
+			{Line: 2, Transfer: &entities.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(80)}},
+			{Line: 3, Transfer: &entities.Transaction{SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(30)}},
+		}}
+
+		mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+		mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+		mockJobRepo.On("Save", mock.Anything, mockTx, mock.Anything).Return(&entities.ImportJob{ID: "job", Kind: entities.ImportKindTransfers, TotalRows: 2}, nil)
+		mockJobRepo.On("FindById", mock.Anything, mockTx, "job").Return(&entities.ImportJob{ID: "job"}, nil)
+		mockJobRepo.On("Update", mock.Anything, mockTx, mock.Anything).Return(nil)
+		mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(1)).Return(&entities.Account{AccountID: 1, Balance: decimal.NewFromInt(100), Currency: "USD"}, nil).Once()
+		mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(2)).Return(&entities.Account{AccountID: 2, Balance: decimal.Zero, Currency: "USD"}, nil).Once()
+		mockTx.On("Commit").Return(nil)
+		mockTx.On("Rollback").Return(nil)
+
+		result, err := service.Import(ctx, file, "")
+		appErr, ok := err.(*appErrors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusUnprocessableEntity, appErr.StatusCode)
+		assert.Equal(t, 3, result.FailedLine)
+		mockTxRepo.AssertNotCalled(t, "SaveMany", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/pkg/logger"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+// ledgerPageSize is how many accounts are listed per database transaction
+const ledgerPageSize = 100
+
+// ledgerCheckAttempts is how often an account is checked before a run fails,
+// when the check keeps losing races with transfers
+const ledgerCheckAttempts = 3
+
+// endOfLedger bounds the movement sums so that every stored transfer counts,
+// including one stamped by a database clock running ahead of this host
+var endOfLedger = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+type LedgerServiceImpl struct {
+	DB                    ports.Database
+	AccountRepository     ports.AccountRepository
+	TransactionRepository ports.TransactionRepository
+	// Reporters receive every finished report; their failures are logged and do not fail Verify
+	Reporters  []ports.LedgerReporter
+	CtxTimeout time.Duration
+}
+
+// Verify walks the accounts a page at a time and checks each in its own short
+// transaction, so CtxTimeout applies per page and per account rather than to
+// the whole ledger, and transfers wait on at most one locked account
+func (s *LedgerServiceImpl) Verify(c context.Context) (*entities.LedgerReport, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	report := &entities.LedgerReport{Drifts: []*entities.BalanceDrift{}}
+
+	cursor := ""
+	for {
+		next, err := s.verifyPage(c, cursor, report)
+		if err != nil {
+			return nil, err
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	totals, err := s.sumTotals(c)
+	if err != nil {
+		return nil, err
+	}
+	report.Totals = totals
+	for _, total := range totals {
+		if !total.Conserved() {
+			logger.Errorf("%s accounts hold %s but started with %s", total.Currency, total.Balance, total.InitialBalance)
+		}
+	}
+
+	for _, reporter := range s.Reporters {
+		if err := reporter.Report(c, report); err != nil {
+			logger.WithError(err).Error("Failed to report ledger check")
+		}
+	}
+
+	return report, nil
+}
+
+// sumTotals checks conservation in its own transaction; the per account pages
+// cannot, since transfers between pages commit while they are walked
+func (s *LedgerServiceImpl) sumTotals(c context.Context) ([]*entities.CurrencyTotal, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	totals, err := s.AccountRepository.SumByCurrency(ctx, tx)
+	if err != nil {
+		logger.WithError(err).Error("Failed to sum balances by currency")
+		return nil, databaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, databaseError(err)
+	}
+
+	return totals, nil
+}
+
+func (s *LedgerServiceImpl) verifyPage(c context.Context, cursor string, report *entities.LedgerReport) (string, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	page, err := s.listPage(c, cursor)
+	if err != nil {
+		return "", err
+	}
+
+	for _, listed := range page.Accounts {
+		var account *entities.Account
+		var movement decimal.Decimal
+		for attempt := 1; ; attempt++ {
+			account, movement, err = s.checkAccount(c, listed.AccountID)
+			if err == nil || !isConcurrentUpdate(err) || attempt == ledgerCheckAttempts {
+				break
+			}
+			logger.WithError(err).Warnf("Check of AccountID %d ran into a transfer, retrying", listed.AccountID)
+		}
+		if err != nil {
+			return "", databaseError(err)
+		}
+
+		report.CheckedAccounts++
+		expected := account.InitialBalance.Add(movement)
+		if !expected.Equal(account.Balance) {
+			logger.Errorf("AccountID %d holds %s but its transfers add up to %s", account.AccountID, account.Balance, expected)
+			report.Drifts = append(report.Drifts, &entities.BalanceDrift{
+				AccountID: account.AccountID,
+				Currency:  account.Currency,
+				Recorded:  account.Balance,
+				Expected:  expected,
+			})
+		}
+	}
+
+	return page.NextCursor, nil
+}
+
+// listPage lists the accounts after cursor; it only reads and locks nothing
+func (s *LedgerServiceImpl) listPage(c context.Context, cursor string) (*entities.AccountPage, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx, readSnapshot)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	page, err := s.AccountRepository.List(ctx, tx, entities.AccountFilter{
+		SortBy: entities.AccountSortById,
+		Cursor: cursor,
+		Limit:  ledgerPageSize,
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to list accounts")
+		return nil, databaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, databaseError(err)
+	}
+
+	return page, nil
+}
+
+// checkAccount reads the balance of an account and the sum of its transfers
+// with the row locked, so no transfer touches the account in between. At read
+// committed the lock waits for a transfer in flight and then reads its result
+func (s *LedgerServiceImpl) checkAccount(c context.Context, accountID int64) (*entities.Account, decimal.Decimal, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx, readCommitted)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, decimal.Zero, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	account, err := s.AccountRepository.FindByIdForUpdate(ctx, tx, accountID)
+	if err != nil {
+		logger.WithError(err).Errorf("Failed to lock AccountID %d", accountID)
+		return nil, decimal.Zero, err
+	}
+	movement, err := s.TransactionRepository.NetMovement(ctx, tx, accountID, nil, endOfLedger)
+	if err != nil {
+		logger.WithError(err).Errorf("Failed to sum transfers of AccountID %d", accountID)
+		return nil, decimal.Zero, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, decimal.Zero, err
+	}
+
+	return account, movement, nil
+}
@@ -0,0 +1,159 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	"transfer-system/domain/services"
+	"transfer-system/infrastructure/memstore"
+	"transfer-system/mocks"
+	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/logger"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLedgerService_Verify(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockAccRepo := new(mocks.MockAccountRepository)
+	mockRepo := new(mocks.MockTransactionRepository)
+	mockTx := new(mocks.MockTransaction)
+	mockReporter := new(mocks.MockLedgerReporter)
+
+	service := &services.LedgerServiceImpl{
+		DB:                    mockDB,
+		AccountRepository:     mockAccRepo,
+		TransactionRepository: mockRepo,
+		Reporters:             []ports.LedgerReporter{mockReporter},
+		CtxTimeout:            2 * time.Second,
+	}
+
+	balanced := &entities.Account{AccountID: 1, Balance: decimal.NewFromInt(70), InitialBalance: decimal.NewFromInt(100), Currency: "USD"}
+	drifted := &entities.Account{AccountID: 2, Balance: decimal.NewFromInt(40), InitialBalance: decimal.Zero, Currency: "USD"}
+	untouched := &entities.Account{AccountID: 3, Balance: decimal.NewFromInt(5), InitialBalance: decimal.NewFromInt(5), Currency: "EUR"}
+
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+	mockAccRepo.On("List", mock.Anything, mockTx, mock.MatchedBy(func(filter entities.AccountFilter) bool {
+		return filter.Cursor == "" && filter.SortBy == entities.AccountSortById
+	})).Return(&entities.AccountPage{Accounts: []*entities.Account{balanced, drifted}, NextCursor: "page-2"}, nil).Once()
+	mockAccRepo.On("List", mock.Anything, mockTx, mock.MatchedBy(func(filter entities.AccountFilter) bool {
+		return filter.Cursor == "page-2"
+	})).Return(&entities.AccountPage{Accounts: []*entities.Account{untouched}}, nil).Once()
+	for _, account := range []*entities.Account{balanced, drifted, untouched} {
+		mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, account.AccountID).Return(account, nil)
+	}
+	mockRepo.On("NetMovement", mock.Anything, mockTx, int64(1), (*time.Time)(nil), mock.Anything).Return(decimal.NewFromInt(-30), nil)
+	mockRepo.On("NetMovement", mock.Anything, mockTx, int64(2), (*time.Time)(nil), mock.Anything).Return(decimal.NewFromInt(30), nil)
+	mockRepo.On("NetMovement", mock.Anything, mockTx, int64(3), (*time.Time)(nil), mock.Anything).Return(decimal.Zero, nil)
+	mockAccRepo.On("SumByCurrency", mock.Anything, mockTx).Return([]*entities.CurrencyTotal{
+		{Currency: "EUR", Accounts: 1, InitialBalance: decimal.NewFromInt(5), Balance: decimal.NewFromInt(5)},
+		{Currency: "USD", Accounts: 2, InitialBalance: decimal.NewFromInt(100), Balance: decimal.NewFromInt(110)},
+	}, nil)
+	mockTx.On("Commit").Return(nil)
+	mockReporter.On("Report", mock.Anything, mock.Anything).Return(errors.New("webhook unreachable"))
+
+	report, err := service.Verify(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.CheckedAccounts)
+	if assert.Len(t, report.Drifts, 1) {
+		drift := report.Drifts[0]
+		assert.Equal(t, int64(2), drift.AccountID)
+		assert.True(t, decimal.NewFromInt(30).Equal(drift.Expected))
+		assert.True(t, decimal.NewFromInt(10).Equal(drift.Difference()))
+	}
+	if assert.Len(t, report.Totals, 2) {
+		assert.True(t, report.Totals[0].Conserved())
+		assert.False(t, report.Totals[1].Conserved())
+		assert.True(t, decimal.NewFromInt(10).Equal(report.Totals[1].Difference()))
+	}
+	assert.False(t, report.Consistent())
+	// two pages, three accounts and the totals
+	mockTx.AssertNumberOfCalls(t, "Commit", 6)
+	mockReporter.AssertCalled(t, "Report", mock.Anything, report)
+}
+
+func TestLedgerService_Verify_Consistent(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockAccRepo := new(mocks.MockAccountRepository)
+	mockRepo := new(mocks.MockTransactionRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.LedgerServiceImpl{
+		DB:                    mockDB,
+		AccountRepository:     mockAccRepo,
+		TransactionRepository: mockRepo,
+		CtxTimeout:            2 * time.Second,
+	}
+
+	account := &entities.Account{AccountID: 1, Balance: decimal.NewFromInt(70), InitialBalance: decimal.NewFromInt(100), Currency: "USD"}
+
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+	mockAccRepo.On("List", mock.Anything, mockTx, mock.Anything).Return(&entities.AccountPage{Accounts: []*entities.Account{account}}, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(1)).Return(account, nil)
+	mockRepo.On("NetMovement", mock.Anything, mockTx, int64(1), (*time.Time)(nil), mock.Anything).Return(decimal.NewFromInt(-30), nil)
+	mockAccRepo.On("SumByCurrency", mock.Anything, mockTx).Return([]*entities.CurrencyTotal{
+		{Currency: "USD", Accounts: 2, InitialBalance: decimal.NewFromInt(100), Balance: decimal.NewFromInt(100)},
+	}, nil)
+	mockTx.On("Commit").Return(nil)
+
+	report, err := service.Verify(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Drifts)
+	assert.True(t, report.Consistent())
+}
+
+func TestLedgerService_Verify_RetriesConcurrentUpdates(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockAccRepo := new(mocks.MockAccountRepository)
+	mockRepo := new(mocks.MockTransactionRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.LedgerServiceImpl{
+		DB:                    mockDB,
+		AccountRepository:     mockAccRepo,
+		TransactionRepository: mockRepo,
+		CtxTimeout:            2 * time.Second,
+	}
+
+	busy := &entities.Account{AccountID: 1, Balance: decimal.NewFromInt(70), InitialBalance: decimal.NewFromInt(100), Currency: "USD"}
+	deadlocked := &entities.Account{AccountID: 2, Balance: decimal.NewFromInt(5), InitialBalance: decimal.NewFromInt(5), Currency: "USD"}
+
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+	mockAccRepo.On("List", mock.Anything, mockTx, mock.Anything).Return(&entities.AccountPage{Accounts: []*entities.Account{busy, deadlocked}}, nil)
+	// the first account races a transfer once, the second on every attempt
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(1)).Return(nil, &memstore.Error{State: "40001"}).Once()
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(1)).Return(busy, nil).Once()
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(2)).Return(nil, &memstore.Error{State: memstore.StateDeadlockDetected})
+	mockRepo.On("NetMovement", mock.Anything, mockTx, int64(1), (*time.Time)(nil), mock.Anything).Return(decimal.NewFromInt(-30), nil)
+	mockTx.On("Commit").Return(nil)
+	mockTx.On("Rollback").Return(nil)
+
+	_, err := service.Verify(ctx)
+
+	var appErr *appErrors.AppError
+	if assert.ErrorAs(t, err, &appErr) {
+		assert.Equal(t, http.StatusServiceUnavailable, appErr.StatusCode)
+	}
+	mockAccRepo.AssertNumberOfCalls(t, "FindByIdForUpdate", 5)
+	mockRepo.AssertNumberOfCalls(t, "NetMovement", 1)
+}
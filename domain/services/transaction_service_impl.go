@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"transfer-system/domain/entities"
@@ -11,26 +12,44 @@ import (
 	appErrors "transfer-system/pkg/errors"
 	"transfer-system/pkg/logger"
 
+	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 )
 
+// DefaultDailyLimits applies when no limits are configured; full KYC customers are not capped
+var DefaultDailyLimits = entities.KYCTierLimits{
+	entities.KYCTierUnverified: decimal.NewFromInt(1000),
+	entities.KYCTierBasic:      decimal.NewFromInt(10000),
+}
+
+const (
+	DefaultTransactionPageSize = 50
+	MaxTransactionPageSize     = 200
+)
+
 type TransactionServiceImpl struct {
 	DB                    ports.Database
 	TransactionRepository ports.TransactionRepository
 	AccountRepository     ports.AccountRepository
-	CtxTimeout            time.Duration
+	CustomerRepository    ports.CustomerRepository
+	DailyLimits           entities.KYCTierLimits
+	// SettlementAccounts maps a currency to the settlement account deposits and
+	// withdrawals use when the request names none
+	SettlementAccounts map[string]int64
+	CtxTimeout         time.Duration
 }
 
-func (s *TransactionServiceImpl) Save(c context.Context, request *entities.Transaction) error {
+func (s *TransactionServiceImpl) Save(c context.Context, request *entities.Transaction) (*entities.Transaction, error) {
 	// TODO: improvment to add identifier id for each transaction from client side to make it idempotent
 	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
 
 	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
 	defer cancel()
 
-	tx, err := s.DB.BeginTx(ctx)
+	tx, err := s.DB.BeginTx(ctx, readCommitted)
 	if err != nil {
-		return err
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
 	}
 	// handle panic gracefully
 	defer func() {
@@ -41,56 +60,121 @@ func (s *TransactionServiceImpl) Save(c context.Context, request *entities.Trans
 		}
 	}()
 
+	transaction, err := s.transfer(ctx, tx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, databaseError(err)
+	}
+
+	return transaction, nil
+}
+
+// transfer checks and books the transfer within tx, leaving the commit to the caller
+func (s *TransactionServiceImpl) transfer(ctx context.Context, tx ports.Transaction, request *entities.Transaction) (*entities.Transaction, error) {
+	if _, _, err := s.validateTransfer(ctx, tx, request, s.AccountRepository.FindByIdForUpdate, nil); err != nil {
+		return nil, err
+	}
+
+	return s.book(ctx, tx, entities.TransactionTypeTransfer, request)
+}
+
+// accountLookup finds an account for validateTransfer, sql.ErrNoRows when there is none
+type accountLookup func(ctx context.Context, tx ports.Transaction, id int64) (*entities.Account, error)
+
+// validateTransfer runs the checks of a transfer without booking it. Accounts
+// come from lookup, and pending adds amounts already sent today by customer id
+// that are not yet in the transactions table
+func (s *TransactionServiceImpl) validateTransfer(ctx context.Context, tx ports.Transaction, request *entities.Transaction,
+	lookup accountLookup, pending map[int64]decimal.Decimal) (*entities.Account, *entities.Account, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	// the HTTP binder rejects these too, batches and imports reach the service directly
+	if request.SourceAccountID == request.DestinationAccountID {
+		logger.Errorf("AccountID %d transfers to itself", request.SourceAccountID)
+		return nil, nil, appErrors.NewBadRequestError("Source and destination accounts must differ", entities.ErrSelfTransfer)
+	}
+	if !request.Amount.IsPositive() {
+		logger.Errorf("Amount %s is not positive", request.Amount)
+		return nil, nil, appErrors.NewBadRequestError("Amount must be greater than zero", entities.ErrInvalidAmount)
+	}
+
 	// check source account exist
-	sourceAccount, err := s.AccountRepository.FindById(ctx, tx, request.SourceAccountID)
+	sourceAccount, err := lookup(ctx, tx, request.SourceAccountID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			logger.Errorf("AccountID %d not found", request.SourceAccountID)
-			return appErrors.NewBadRequestError("Account Not Found", err)
+			return nil, nil, appErrors.NewNotFoundError("Account Not Found", err)
 		}
 		logger.WithError(err).Error("Database error")
-		return appErrors.NewInternalServerError("Currently we're facing an issue", err)
+		return nil, nil, databaseError(err)
 	}
 
 	// check destination account exist
-	_, err = s.AccountRepository.FindById(ctx, tx, request.DestinationAccountID)
+	destinationAccount, err := lookup(ctx, tx, request.DestinationAccountID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			logger.Errorf("AccountID %d not found", request.SourceAccountID)
-			return appErrors.NewBadRequestError("Account Not Found", err)
+			logger.Errorf("AccountID %d not found", request.DestinationAccountID)
+			return nil, nil, appErrors.NewNotFoundError("Account Not Found", err)
 		}
 		logger.WithError(err).Error("Database error")
-		return appErrors.NewInternalServerError("Currently we're facing an issue", err)
+		return nil, nil, databaseError(err)
+	}
+
+	for _, account := range []*entities.Account{sourceAccount, destinationAccount} {
+		// system accounts move money only through the bookings that own them, such as opening balances
+		if account.Kind.IsSystem() {
+			logger.Errorf("AccountID %d is a %s account", account.AccountID, account.Kind)
+			return nil, nil, appErrors.NewUnprocessableError(fmt.Sprintf("Account %d is a system account", account.AccountID), entities.ErrSystemAccount)
+		}
+		if account.Status == entities.AccountStatusFrozen || account.Status == entities.AccountStatusClosed {
+			logger.Errorf("AccountID %d is %s", account.AccountID, account.Status)
+			return nil, nil, appErrors.NewUnprocessableError(fmt.Sprintf("Account %d is %s", account.AccountID, account.Status), entities.ErrAccountNotActive)
+		}
+	}
+
+	if err := checkCurrency(logger, sourceAccount, destinationAccount); err != nil {
+		return nil, nil, err
 	}
 
 	// check if source account has sufficient balance
 	if sourceAccount.Balance.LessThan(request.Amount) {
 		logger.Errorf("Insufficient balance in source account id %d", request.SourceAccountID)
-		// to trigger rollback
-		err = appErrors.NewBadRequestError("Insufficient balance", nil)
-		return err
+		return nil, nil, appErrors.NewUnprocessableError("Insufficient balance", entities.ErrInsufficientBalance)
 	}
 
-	transaction := entities.Transaction{
-		Id:                   0,
-		SourceAccountID:      request.SourceAccountID,
-		DestinationAccountID: request.DestinationAccountID,
-		Amount:               request.Amount,
+	// accounts without an owner predate customers and are not subject to tier limits
+	if sourceAccount.OwnerID != nil {
+		err = s.checkDailyLimit(ctx, tx, *sourceAccount.OwnerID, request.Amount.Add(pending[*sourceAccount.OwnerID]))
+		if err != nil {
+			return nil, nil, err
+		}
 	}
-	_, err = s.TransactionRepository.Save(ctx, tx, &transaction)
 
+	return sourceAccount, destinationAccount, nil
+}
+
+// book saves the transaction of the given type and moves its amount between
+// the balances, once the caller has checked both accounts
+func (s *TransactionServiceImpl) book(ctx context.Context, tx ports.Transaction, transactionType entities.TransactionType, request *entities.Transaction) (*entities.Transaction, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	transaction := newTransaction(transactionType, request)
+	_, err := s.TransactionRepository.Save(ctx, tx, transaction)
 	if err != nil {
 		logger.WithError(err).Error("Failed to save transaction")
-		return err
+		return nil, databaseError(err)
 	}
-	// logger.Debugf("DEBUG: Calling UpdateBalance for Destination. Tx type: %T, Tx value: %#v, AccountID: %d, Amount: %s", tx, tx, request.DestinationAccountID, request.Amount.String())
 
 	// update balance of source account
 	err = s.TransactionRepository.UpdateBalance(ctx, tx, request.SourceAccountID, request.Amount.Neg())
 
 	if err != nil {
 		logger.WithError(err).Error("Failed to update source account balance")
-		return err
+		return nil, databaseError(err)
 	}
 
 	// update balance of destination account
@@ -98,12 +182,296 @@ func (s *TransactionServiceImpl) Save(c context.Context, request *entities.Trans
 
 	if err != nil {
 		logger.WithError(err).Error("Failed to update destination account balance")
-		return err
+		return nil, databaseError(err)
 	}
 
-	if err := tx.Commit(); err != nil {
+	return transaction, nil
+}
+
+// newTransaction is the transaction book saves for request
+func newTransaction(transactionType entities.TransactionType, request *entities.Transaction) *entities.Transaction {
+	return &entities.Transaction{
+		Type:                 transactionType,
+		SourceAccountID:      request.SourceAccountID,
+		DestinationAccountID: request.DestinationAccountID,
+		Amount:               request.Amount,
+		Description:          request.Description,
+		Reference:            request.Reference,
+		Remittance:           request.Remittance,
+		Metadata:             request.Metadata,
+	}
+}
+
+// Deposit credits DestinationAccountID with money arriving through the
+// settlement account SourceAccountID, or the one configured for the account's
+// currency when SourceAccountID is zero
+func (s *TransactionServiceImpl) Deposit(c context.Context, request *entities.Transaction) (*entities.Transaction, error) {
+	return s.settle(c, entities.TransactionTypeDeposit, request)
+}
+
+// Withdraw debits SourceAccountID with money leaving through the settlement
+// account DestinationAccountID, or the one configured for the account's
+// currency when DestinationAccountID is zero
+func (s *TransactionServiceImpl) Withdraw(c context.Context, request *entities.Transaction) (*entities.Transaction, error) {
+	return s.settle(c, entities.TransactionTypeWithdrawal, request)
+}
+
+func (s *TransactionServiceImpl) settle(c context.Context, transactionType entities.TransactionType, request *entities.Transaction) (*entities.Transaction, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx, readCommitted)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	transaction, err := s.settlement(ctx, tx, transactionType, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
 		logger.WithError(err).Error("Failed to commit transaction")
-		return err
+		return nil, databaseError(err)
+	}
+
+	return transaction, nil
+}
+
+// settlement checks and books a deposit or withdrawal within tx, leaving the commit to the caller
+func (s *TransactionServiceImpl) settlement(ctx context.Context, tx ports.Transaction, transactionType entities.TransactionType, request *entities.Transaction) (*entities.Transaction, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	if !request.Amount.IsPositive() {
+		logger.Errorf("Amount %s is not positive", request.Amount)
+		return nil, appErrors.NewBadRequestError("Amount must be greater than zero", entities.ErrInvalidAmount)
+	}
+
+	accountID, settlementID := request.DestinationAccountID, request.SourceAccountID
+	if transactionType == entities.TransactionTypeWithdrawal {
+		accountID, settlementID = request.SourceAccountID, request.DestinationAccountID
+	}
+
+	account, err := s.AccountRepository.FindByIdForUpdate(ctx, tx, accountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf("AccountID %d not found", accountID)
+			return nil, appErrors.NewNotFoundError("Account Not Found", err)
+		}
+		logger.WithError(err).Error("Database error")
+		return nil, databaseError(err)
+	}
+	if account.Kind.IsSystem() {
+		logger.Errorf("AccountID %d is a %s account", account.AccountID, account.Kind)
+		return nil, appErrors.NewUnprocessableError(fmt.Sprintf("Account %d is a system account", account.AccountID), entities.ErrSystemAccount)
+	}
+
+	if settlementID == 0 {
+		configured, ok := s.SettlementAccounts[account.Currency]
+		if !ok {
+			logger.Errorf("No settlement account configured for %s", account.Currency)
+			return nil, appErrors.NewUnprocessableError(fmt.Sprintf("No settlement account for %s", account.Currency), entities.ErrNoSettlementAccount)
+		}
+		settlementID = configured
+	}
+
+	settlementAccount, err := s.AccountRepository.FindByIdForUpdate(ctx, tx, settlementID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf("Settlement AccountID %d not found", settlementID)
+			return nil, appErrors.NewNotFoundError("Settlement account not found", err)
+		}
+		logger.WithError(err).Error("Database error")
+		return nil, databaseError(err)
+	}
+	if settlementAccount.Kind != entities.AccountKindSettlement {
+		logger.Errorf("AccountID %d is a %s account, not a settlement account", settlementID, settlementAccount.Kind)
+		return nil, appErrors.NewUnprocessableError(fmt.Sprintf("Account %d is not a settlement account", settlementID), entities.ErrNoSettlementAccount)
+	}
+
+	for _, checked := range []*entities.Account{account, settlementAccount} {
+		if checked.Status == entities.AccountStatusFrozen || checked.Status == entities.AccountStatusClosed {
+			logger.Errorf("AccountID %d is %s", checked.AccountID, checked.Status)
+			return nil, appErrors.NewUnprocessableError(fmt.Sprintf("Account %d is %s", checked.AccountID, checked.Status), entities.ErrAccountNotActive)
+		}
+	}
+
+	if err := checkCurrency(logger, account, settlementAccount); err != nil {
+		return nil, err
+	}
+
+	booking := *request
+	booking.DestinationAccountID, booking.SourceAccountID = account.AccountID, settlementAccount.AccountID
+	if transactionType == entities.TransactionTypeWithdrawal {
+		booking.SourceAccountID, booking.DestinationAccountID = account.AccountID, settlementAccount.AccountID
+
+		// settlement accounts may go negative, the customer's may not
+		if account.Balance.LessThan(request.Amount) {
+			logger.Errorf("Insufficient balance in account id %d", account.AccountID)
+			return nil, appErrors.NewUnprocessableError("Insufficient balance", entities.ErrInsufficientBalance)
+		}
+		// withdrawals count towards the daily limit like outgoing transfers
+		if account.OwnerID != nil {
+			err = s.checkDailyLimit(ctx, tx, *account.OwnerID, request.Amount)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return s.book(ctx, tx, transactionType, &booking)
+}
+
+func (s *TransactionServiceImpl) FindById(c context.Context, id int64) (*entities.Transaction, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx, readSnapshot)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	transaction, err := s.TransactionRepository.FindById(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf("TransactionID %d not found", id)
+			return nil, appErrors.NewNotFoundError("Transaction not found", err)
+		}
+		logger.WithError(err).Error("Database error")
+		return nil, databaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, databaseError(err)
+	}
+
+	return transaction, nil
+}
+
+// ListByAccount returns the transfer history of an account, newest first
+func (s *TransactionServiceImpl) ListByAccount(c context.Context, filter entities.TransactionFilter) (*entities.TransactionPage, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultTransactionPageSize
+	}
+	if filter.Limit > MaxTransactionPageSize {
+		filter.Limit = MaxTransactionPageSize
+	}
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx, readSnapshot)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// an unknown account is a 404 rather than an empty history
+	_, err = s.AccountRepository.FindById(ctx, tx, filter.AccountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf("AccountID %d not found", filter.AccountID)
+			return nil, appErrors.NewNotFoundError("Account not found", err)
+		}
+		logger.WithError(err).Error("Database error")
+		return nil, databaseError(err)
+	}
+
+	page, err := s.TransactionRepository.ListByAccount(ctx, tx, filter)
+	if err != nil {
+		if errors.Is(err, entities.ErrInvalidCursor) {
+			logger.Errorf("Invalid cursor %s", filter.Cursor)
+			return nil, appErrors.NewBadRequestError("Invalid cursor", err)
+		}
+
+		logger.WithError(err).Error("Database error")
+		return nil, databaseError(err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, databaseError(err)
+	}
+
+	return page, nil
+}
+
+// checkCurrency rejects moving money between accounts of different currencies.
+// There is no conversion: a transfer books the same amount on both sides, so
+// per currency the balances keep adding up to the money issued
+func checkCurrency(logger logrus.FieldLogger, account, counterparty *entities.Account) error {
+	if account.Currency == counterparty.Currency {
+		return nil
+	}
+	logger.Errorf("Currency mismatch between account id %d (%s) and %d (%s)",
+		account.AccountID, account.Currency, counterparty.AccountID, counterparty.Currency)
+	return appErrors.NewUnprocessableError("Accounts have different currencies", entities.ErrCurrencyMismatch)
+}
+
+// checkDailyLimit rejects the transfer when it would take the owner over the
+// daily limit of their KYC tier. The customer row stays locked until the
+// transaction ends, and tx must be read committed: the sum is then read after
+// the lock is granted and counts transfers from sibling accounts that committed
+// while this one waited
+func (s *TransactionServiceImpl) checkDailyLimit(ctx context.Context, tx ports.Transaction, ownerID int64, amount decimal.Decimal) error {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	customer, err := s.CustomerRepository.FindByIdForUpdate(ctx, tx, ownerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf("Owner CustomerID %d not found", ownerID)
+			return appErrors.NewUnprocessableError("Account owner not found", err)
+		}
+		logger.WithError(err).Error("Database error")
+		return databaseError(err)
+	}
+
+	limits := s.DailyLimits
+	if limits == nil {
+		limits = DefaultDailyLimits
+	}
+	limit, limited := limits[customer.KYCTier]
+	if !limited {
+		return nil
+	}
+
+	startOfDay := time.Now().UTC().Truncate(24 * time.Hour)
+	sent, err := s.TransactionRepository.SumOutgoingByOwner(ctx, tx, ownerID, startOfDay)
+	if err != nil {
+		logger.WithError(err).Error("Failed to sum outgoing transfers")
+		return databaseError(err)
+	}
+
+	if sent.Add(amount).GreaterThan(limit) {
+		logger.Errorf("Daily limit %s exceeded for CustomerID %d (%s tier), already sent %s", limit, ownerID, customer.KYCTier, sent)
+		return appErrors.NewUnprocessableError("Daily transfer limit exceeded for the customer's KYC tier", entities.ErrDailyLimitExceeded)
 	}
 
 	return nil
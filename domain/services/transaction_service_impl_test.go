@@ -3,6 +3,7 @@ package services_test
 import (
 	"context"
 	"database/sql"
+	"net/http"
 	"testing"
 	"time"
 
@@ -46,18 +47,23 @@ func TestTransactionService_Save_Success(t *testing.T) {
 		SourceAccountID:      123,
 		DestinationAccountID: 456,
 		Amount:               decimal.NewFromFloat(100.23344),
+		Metadata:             entities.Metadata{"cost_center": "CC-42"},
 	}
 
-	mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
-	mockAccRepo.On("FindById", mock.Anything, mockTx, transaction.SourceAccountID).Return(sourceAccount, nil)
-	mockAccRepo.On("FindById", mock.Anything, mockTx, transaction.DestinationAccountID).Return(destinationAccount, nil)
-	mockRepo.On("Save", mock.Anything, mock.Anything, transaction).Return(transaction, nil).Once()
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, transaction.SourceAccountID).Return(sourceAccount, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, transaction.DestinationAccountID).Return(destinationAccount, nil)
+	mockRepo.On("Save", mock.Anything, mock.Anything, mock.MatchedBy(func(saved *entities.Transaction) bool {
+		return saved.Type == entities.TransactionTypeTransfer && saved.SourceAccountID == 123 && saved.DestinationAccountID == 456
+	})).Return(transaction, nil).Once()
 	mockRepo.On("UpdateBalance", mock.Anything, mockTx, transaction.SourceAccountID, transaction.Amount.Neg()).Return(nil)
 	mockRepo.On("UpdateBalance", mock.Anything, mockTx, transaction.DestinationAccountID, transaction.Amount).Return(nil)
 	mockTx.On("Commit").Return(nil)
 
-	err := service.Save(ctx, transaction)
+	saved, err := service.Save(ctx, transaction)
 	assert.NoError(t, err)
+	assert.Equal(t, transaction.Metadata, saved.Metadata)
+	assert.Equal(t, entities.TransactionTypeTransfer, saved.Type)
 
 	mockDB.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
@@ -86,17 +92,18 @@ func TestTransactionService_Save_AccountNotFound(t *testing.T) {
 		Amount:               decimal.NewFromFloat(100.23344),
 	}
 
-	mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
-	mockAccRepo.On("FindById", mock.Anything, mockTx, transaction.SourceAccountID).Return(nil, sql.ErrNoRows)
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, transaction.SourceAccountID).Return(nil, sql.ErrNoRows)
 	mockTx.On("Rollback").Return(nil)
 
-	err := service.Save(ctx, transaction)
+	_, err := service.Save(ctx, transaction)
 
 	assert.Error(t, err)
 
 	appErr, ok := err.(*appErrors.AppError)
 	assert.True(t, ok)
 	assert.Equal(t, "Account Not Found", appErr.Message)
+	assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
 
 	mockDB.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
@@ -134,20 +141,527 @@ func TestTransactionService_Save_InsufficientBalance(t *testing.T) {
 		Amount:               decimal.NewFromFloat(100.23344),
 	}
 
-	mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
-	mockAccRepo.On("FindById", mock.Anything, mockTx, transaction.SourceAccountID).Return(sourceAccount, nil)
-	mockAccRepo.On("FindById", mock.Anything, mockTx, transaction.DestinationAccountID).Return(destinationAccount, nil)
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, transaction.SourceAccountID).Return(sourceAccount, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, transaction.DestinationAccountID).Return(destinationAccount, nil)
 	mockTx.On("Rollback").Return(nil).Once()
 
-	err := service.Save(ctx, transaction)
+	_, err := service.Save(ctx, transaction)
 	assert.Error(t, err)
 
 	appErr, ok := err.(*appErrors.AppError)
 	assert.True(t, ok)
 	assert.Equal(t, "Insufficient balance", appErr.Message)
+	assert.Equal(t, http.StatusUnprocessableEntity, appErr.StatusCode)
 
 	mockDB.AssertExpectations(t)
 	mockRepo.AssertExpectations(t)
 	mockAccRepo.AssertExpectations(t)
 	mockTx.AssertExpectations(t)
 }
+
+func TestTransactionService_Save_CurrencyMismatch(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockAccRepo := new(mocks.MockAccountRepository)
+	mockRepo := new(mocks.MockTransactionRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.TransactionServiceImpl{
+		DB:                    mockDB,
+		TransactionRepository: mockRepo,
+		AccountRepository:     mockAccRepo,
+		CtxTimeout:            2 * time.Second,
+	}
+
+	sourceAccount := &entities.Account{AccountID: 123, Balance: decimal.NewFromFloat(500), Currency: "USD"}
+	destinationAccount := &entities.Account{AccountID: 456, Balance: decimal.Zero, Currency: "EUR"}
+
+	transaction := &entities.Transaction{
+		SourceAccountID:      123,
+		DestinationAccountID: 456,
+		Amount:               decimal.NewFromFloat(100),
+	}
+
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, transaction.SourceAccountID).Return(sourceAccount, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, transaction.DestinationAccountID).Return(destinationAccount, nil)
+	mockTx.On("Rollback").Return(nil).Once()
+
+	_, err := service.Save(ctx, transaction)
+
+	appErr, ok := err.(*appErrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, "Accounts have different currencies", appErr.Message)
+	assert.Equal(t, http.StatusUnprocessableEntity, appErr.StatusCode)
+	assert.ErrorIs(t, err, entities.ErrCurrencyMismatch)
+
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything, mock.Anything)
+	mockTx.AssertExpectations(t)
+}
+
+func TestTransactionService_Save_FrozenAccount(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockAccRepo := new(mocks.MockAccountRepository)
+	mockRepo := new(mocks.MockTransactionRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.TransactionServiceImpl{
+		DB:                    mockDB,
+		TransactionRepository: mockRepo,
+		AccountRepository:     mockAccRepo,
+		CtxTimeout:            2 * time.Second,
+	}
+
+	sourceAccount := &entities.Account{AccountID: 123, Balance: decimal.NewFromFloat(500), Currency: "USD", Status: entities.AccountStatusActive}
+	destinationAccount := &entities.Account{AccountID: 456, Balance: decimal.Zero, Currency: "USD", Status: entities.AccountStatusFrozen}
+
+	transaction := &entities.Transaction{
+		SourceAccountID:      123,
+		DestinationAccountID: 456,
+		Amount:               decimal.NewFromFloat(100),
+	}
+
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, transaction.SourceAccountID).Return(sourceAccount, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, transaction.DestinationAccountID).Return(destinationAccount, nil)
+	mockTx.On("Rollback").Return(nil).Once()
+
+	_, err := service.Save(ctx, transaction)
+
+	appErr, ok := err.(*appErrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, "Account 456 is frozen", appErr.Message)
+	assert.Equal(t, http.StatusUnprocessableEntity, appErr.StatusCode)
+	assert.ErrorIs(t, err, entities.ErrAccountNotActive)
+
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything, mock.Anything)
+	mockTx.AssertExpectations(t)
+}
+
+func TestTransactionService_Save_InvalidRequest(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	tests := []struct {
+		name        string
+		transaction *entities.Transaction
+		message     string
+		reason      error
+	}{
+		{"self transfer", &entities.Transaction{SourceAccountID: 7, DestinationAccountID: 7, Amount: decimal.NewFromInt(1)}, "Source and destination accounts must differ", entities.ErrSelfTransfer},
+		{"zero amount", &entities.Transaction{SourceAccountID: 7, DestinationAccountID: 8, Amount: decimal.Zero}, "Amount must be greater than zero", entities.ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := new(mocks.MockDatabase)
+			mockAccRepo := new(mocks.MockAccountRepository)
+			mockTx := new(mocks.MockTransaction)
+
+			service := &services.TransactionServiceImpl{
+				DB:                mockDB,
+				AccountRepository: mockAccRepo,
+				CtxTimeout:        2 * time.Second,
+			}
+
+			mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+			mockTx.On("Rollback").Return(nil).Once()
+
+			_, err := service.Save(ctx, tt.transaction)
+
+			appErr, ok := err.(*appErrors.AppError)
+			assert.True(t, ok)
+			assert.Equal(t, tt.message, appErr.Message)
+			assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+			assert.ErrorIs(t, err, tt.reason)
+			mockAccRepo.AssertNotCalled(t, "FindByIdForUpdate", mock.Anything, mock.Anything, mock.Anything)
+			mockTx.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTransactionService_Save_SystemAccount(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockAccRepo := new(mocks.MockAccountRepository)
+	mockRepo := new(mocks.MockTransactionRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.TransactionServiceImpl{
+		DB:                    mockDB,
+		TransactionRepository: mockRepo,
+		AccountRepository:     mockAccRepo,
+		CtxTimeout:            2 * time.Second,
+	}
+
+	// the equity account's negative balance must not be spendable by clients
+	sourceAccount := &entities.Account{AccountID: 123, Balance: decimal.NewFromFloat(-500), Currency: "USD", Kind: entities.AccountKindEquity, Status: entities.AccountStatusActive}
+	destinationAccount := &entities.Account{AccountID: 456, Balance: decimal.Zero, Currency: "USD", Kind: entities.AccountKindCustomer, Status: entities.AccountStatusActive}
+
+	transaction := &entities.Transaction{
+		SourceAccountID:      123,
+		DestinationAccountID: 456,
+		Amount:               decimal.NewFromFloat(100),
+	}
+
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, transaction.SourceAccountID).Return(sourceAccount, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, transaction.DestinationAccountID).Return(destinationAccount, nil)
+	mockTx.On("Rollback").Return(nil).Once()
+
+	_, err := service.Save(ctx, transaction)
+
+	appErr, ok := err.(*appErrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, "Account 123 is a system account", appErr.Message)
+	assert.Equal(t, http.StatusUnprocessableEntity, appErr.StatusCode)
+	assert.ErrorIs(t, err, entities.ErrSystemAccount)
+
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything, mock.Anything)
+	mockTx.AssertExpectations(t)
+}
+
+func TestTransactionService_Save_DailyLimitExceeded(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockAccRepo := new(mocks.MockAccountRepository)
+	mockCustRepo := new(mocks.MockCustomerRepository)
+	mockRepo := new(mocks.MockTransactionRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.TransactionServiceImpl{
+		DB:                    mockDB,
+		TransactionRepository: mockRepo,
+		AccountRepository:     mockAccRepo,
+		CustomerRepository:    mockCustRepo,
+		DailyLimits: entities.KYCTierLimits{
+			entities.KYCTierUnverified: decimal.NewFromInt(1000),
+		},
+		CtxTimeout: 2 * time.Second,
+	}
+
+	ownerID := int64(7)
+	sourceAccount := &entities.Account{AccountID: 123, Balance: decimal.NewFromInt(5000), OwnerID: &ownerID}
+	destinationAccount := &entities.Account{AccountID: 456, Balance: decimal.Zero}
+	customer := &entities.Customer{CustomerID: ownerID, KYCTier: entities.KYCTierUnverified}
+
+	transaction := &entities.Transaction{
+		SourceAccountID:      123,
+		DestinationAccountID: 456,
+		Amount:               decimal.NewFromInt(300),
+	}
+
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, transaction.SourceAccountID).Return(sourceAccount, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, transaction.DestinationAccountID).Return(destinationAccount, nil)
+	mockCustRepo.On("FindByIdForUpdate", mock.Anything, mockTx, ownerID).Return(customer, nil)
+	mockRepo.On("SumOutgoingByOwner", mock.Anything, mockTx, ownerID, mock.Anything).Return(decimal.NewFromInt(800), nil)
+	mockTx.On("Rollback").Return(nil).Once()
+
+	_, err := service.Save(ctx, transaction)
+
+	appErr, ok := err.(*appErrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusUnprocessableEntity, appErr.StatusCode)
+	assert.Equal(t, "Daily transfer limit exceeded for the customer's KYC tier", appErr.Message)
+
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything, mock.Anything)
+	mockTx.AssertExpectations(t)
+}
+
+func TestTransactionService_Save_UnlimitedTier(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockAccRepo := new(mocks.MockAccountRepository)
+	mockCustRepo := new(mocks.MockCustomerRepository)
+	mockRepo := new(mocks.MockTransactionRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.TransactionServiceImpl{
+		DB:                    mockDB,
+		TransactionRepository: mockRepo,
+		AccountRepository:     mockAccRepo,
+		CustomerRepository:    mockCustRepo,
+		DailyLimits: entities.KYCTierLimits{
+			entities.KYCTierUnverified: decimal.NewFromInt(1000),
+		},
+		CtxTimeout: 2 * time.Second,
+	}
+
+	ownerID := int64(7)
+	sourceAccount := &entities.Account{AccountID: 123, Balance: decimal.NewFromInt(50000), OwnerID: &ownerID}
+	destinationAccount := &entities.Account{AccountID: 456, Balance: decimal.Zero}
+	customer := &entities.Customer{CustomerID: ownerID, KYCTier: entities.KYCTierFull}
+
+	transaction := &entities.Transaction{
+		SourceAccountID:      123,
+		DestinationAccountID: 456,
+		Amount:               decimal.NewFromInt(20000),
+	}
+
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, transaction.SourceAccountID).Return(sourceAccount, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, transaction.DestinationAccountID).Return(destinationAccount, nil)
+	mockCustRepo.On("FindByIdForUpdate", mock.Anything, mockTx, ownerID).Return(customer, nil)
+	mockRepo.On("Save", mock.Anything, mockTx, mock.AnythingOfType("*entities.Transaction")).Return(transaction, nil)
+	mockRepo.On("UpdateBalance", mock.Anything, mockTx, transaction.SourceAccountID, transaction.Amount.Neg()).Return(nil)
+	mockRepo.On("UpdateBalance", mock.Anything, mockTx, transaction.DestinationAccountID, transaction.Amount).Return(nil)
+	mockTx.On("Commit").Return(nil)
+
+	_, err := service.Save(ctx, transaction)
+	assert.NoError(t, err)
+
+	mockRepo.AssertNotCalled(t, "SumOutgoingByOwner", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockTx.AssertExpectations(t)
+}
+
+func TestTransactionService_FindById_NotFound(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockRepo := new(mocks.MockTransactionRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.TransactionServiceImpl{
+		DB:                    mockDB,
+		TransactionRepository: mockRepo,
+		CtxTimeout:            2 * time.Second,
+	}
+
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
+	mockRepo.On("FindById", mock.Anything, mockTx, int64(42)).Return(nil, sql.ErrNoRows)
+	mockTx.On("Rollback").Return(nil).Once()
+
+	_, err := service.FindById(ctx, 42)
+
+	var appErr *appErrors.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+	assert.Equal(t, "Transaction not found", appErr.Message)
+	mockTx.AssertExpectations(t)
+}
+
+func TestTransactionService_ListByAccount_DefaultLimit(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockAccRepo := new(mocks.MockAccountRepository)
+	mockRepo := new(mocks.MockTransactionRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.TransactionServiceImpl{
+		DB:                    mockDB,
+		TransactionRepository: mockRepo,
+		AccountRepository:     mockAccRepo,
+		CtxTimeout:            2 * time.Second,
+	}
+
+	page := &entities.TransactionPage{Transactions: []*entities.Transaction{{Id: 1, Description: "March rent"}}}
+	expectedFilter := entities.TransactionFilter{AccountID: 123, Limit: services.DefaultTransactionPageSize}
+
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
+	mockAccRepo.On("FindById", mock.Anything, mockTx, int64(123)).Return(&entities.Account{AccountID: 123}, nil)
+	mockRepo.On("ListByAccount", mock.Anything, mockTx, expectedFilter).Return(page, nil)
+	mockTx.On("Commit").Return(nil)
+
+	resp, err := service.ListByAccount(ctx, entities.TransactionFilter{AccountID: 123})
+	assert.NoError(t, err)
+	assert.Equal(t, page, resp)
+
+	mockRepo.AssertExpectations(t)
+	mockTx.AssertExpectations(t)
+}
+
+func TestTransactionService_ListByAccount_AccountNotFound(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockAccRepo := new(mocks.MockAccountRepository)
+	mockRepo := new(mocks.MockTransactionRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.TransactionServiceImpl{
+		DB:                    mockDB,
+		TransactionRepository: mockRepo,
+		AccountRepository:     mockAccRepo,
+		CtxTimeout:            2 * time.Second,
+	}
+
+	mockDB.On("BeginTx", mock.Anything, readSnapshot).Return(mockTx, nil)
+	mockAccRepo.On("FindById", mock.Anything, mockTx, int64(999)).Return(nil, sql.ErrNoRows)
+	mockTx.On("Rollback").Return(nil).Once()
+
+	_, err := service.ListByAccount(ctx, entities.TransactionFilter{AccountID: 999})
+
+	var appErr *appErrors.AppError
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusNotFound, appErr.StatusCode)
+	mockRepo.AssertNotCalled(t, "ListByAccount", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransactionService_Deposit(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockAccRepo := new(mocks.MockAccountRepository)
+	mockRepo := new(mocks.MockTransactionRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.TransactionServiceImpl{
+		DB:                    mockDB,
+		TransactionRepository: mockRepo,
+		AccountRepository:     mockAccRepo,
+		SettlementAccounts:    map[string]int64{"USD": 900},
+		CtxTimeout:            2 * time.Second,
+	}
+
+	account := &entities.Account{AccountID: 42, Balance: decimal.Zero, Currency: "USD", Kind: entities.AccountKindCustomer, Status: entities.AccountStatusActive}
+	settlement := &entities.Account{AccountID: 900, Balance: decimal.NewFromInt(-50), Currency: "USD", Kind: entities.AccountKindSettlement, Status: entities.AccountStatusActive}
+	amount := decimal.NewFromInt(25)
+
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(42)).Return(account, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(900)).Return(settlement, nil)
+	mockRepo.On("Save", mock.Anything, mockTx, mock.MatchedBy(func(saved *entities.Transaction) bool {
+		return saved.Type == entities.TransactionTypeDeposit && saved.SourceAccountID == 900 && saved.DestinationAccountID == 42 && saved.Reference == "WIRE-1"
+	})).Return(&entities.Transaction{}, nil)
+	mockRepo.On("UpdateBalance", mock.Anything, mockTx, int64(900), amount.Neg()).Return(nil)
+	mockRepo.On("UpdateBalance", mock.Anything, mockTx, int64(42), amount).Return(nil)
+	mockTx.On("Commit").Return(nil)
+
+	deposit, err := service.Deposit(ctx, &entities.Transaction{DestinationAccountID: 42, Amount: amount, Reference: "WIRE-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TransactionTypeDeposit, deposit.Type)
+	assert.Equal(t, int64(900), deposit.SourceAccountID)
+
+	mockRepo.AssertExpectations(t)
+	mockTx.AssertExpectations(t)
+}
+
+func TestTransactionService_Deposit_NoSettlementAccount(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockAccRepo := new(mocks.MockAccountRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.TransactionServiceImpl{
+		DB:                mockDB,
+		AccountRepository: mockAccRepo,
+		CtxTimeout:        2 * time.Second,
+	}
+
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(42)).Return(&entities.Account{AccountID: 42, Currency: "EUR", Kind: entities.AccountKindCustomer}, nil)
+	mockTx.On("Rollback").Return(nil).Once()
+
+	_, err := service.Deposit(ctx, &entities.Transaction{DestinationAccountID: 42, Amount: decimal.NewFromInt(1)})
+
+	appErr, ok := err.(*appErrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, "No settlement account for EUR", appErr.Message)
+	assert.Equal(t, http.StatusUnprocessableEntity, appErr.StatusCode)
+	assert.ErrorIs(t, err, entities.ErrNoSettlementAccount)
+	mockTx.AssertExpectations(t)
+}
+
+func TestTransactionService_Deposit_CurrencyMismatch(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	mockDB := new(mocks.MockDatabase)
+	mockAccRepo := new(mocks.MockAccountRepository)
+	mockRepo := new(mocks.MockTransactionRepository)
+	mockTx := new(mocks.MockTransaction)
+
+	service := &services.TransactionServiceImpl{
+		DB:                    mockDB,
+		TransactionRepository: mockRepo,
+		AccountRepository:     mockAccRepo,
+		CtxTimeout:            2 * time.Second,
+	}
+
+	account := &entities.Account{AccountID: 42, Currency: "USD", Kind: entities.AccountKindCustomer, Status: entities.AccountStatusActive}
+	settlement := &entities.Account{AccountID: 901, Currency: "EUR", Kind: entities.AccountKindSettlement, Status: entities.AccountStatusActive}
+
+	mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(42)).Return(account, nil)
+	mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(901)).Return(settlement, nil)
+	mockTx.On("Rollback").Return(nil).Once()
+
+	// the named settlement account holds euros, the customer account dollars
+	_, err := service.Deposit(ctx, &entities.Transaction{SourceAccountID: 901, DestinationAccountID: 42, Amount: decimal.NewFromInt(25)})
+
+	appErr, ok := err.(*appErrors.AppError)
+	assert.True(t, ok)
+	assert.Equal(t, "Accounts have different currencies", appErr.Message)
+	assert.Equal(t, http.StatusUnprocessableEntity, appErr.StatusCode)
+	assert.ErrorIs(t, err, entities.ErrCurrencyMismatch)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything, mock.Anything)
+	mockTx.AssertExpectations(t)
+}
+
+func TestTransactionService_Withdraw(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+
+	account := &entities.Account{AccountID: 42, Balance: decimal.NewFromInt(10), Currency: "USD", Kind: entities.AccountKindCustomer, Status: entities.AccountStatusActive}
+	settlement := &entities.Account{AccountID: 901, Balance: decimal.Zero, Currency: "USD", Kind: entities.AccountKindSettlement, Status: entities.AccountStatusActive}
+
+	newService := func() (*services.TransactionServiceImpl, *mocks.MockAccountRepository, *mocks.MockTransactionRepository, *mocks.MockTransaction) {
+		mockDB := new(mocks.MockDatabase)
+		mockAccRepo := new(mocks.MockAccountRepository)
+		mockRepo := new(mocks.MockTransactionRepository)
+		mockTx := new(mocks.MockTransaction)
+		mockDB.On("BeginTx", mock.Anything, readCommitted).Return(mockTx, nil)
+		mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(42)).Return(account, nil)
+		mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(901)).Return(settlement, nil)
+		mockAccRepo.On("FindByIdForUpdate", mock.Anything, mockTx, int64(7)).Return(&entities.Account{AccountID: 7, Currency: "USD", Kind: entities.AccountKindCustomer}, nil)
+		return &services.TransactionServiceImpl{
+			DB:                    mockDB,
+			TransactionRepository: mockRepo,
+			AccountRepository:     mockAccRepo,
+			CtxTimeout:            2 * time.Second,
+		}, mockAccRepo, mockRepo, mockTx
+	}
+
+	t.Run("named settlement account", func(t *testing.T) {
+		service, _, mockRepo, mockTx := newService()
+		mockRepo.On("Save", mock.Anything, mockTx, mock.MatchedBy(func(saved *entities.Transaction) bool {
+			return saved.Type == entities.TransactionTypeWithdrawal && saved.SourceAccountID == 42 && saved.DestinationAccountID == 901
+		})).Return(&entities.Transaction{}, nil)
+		mockRepo.On("UpdateBalance", mock.Anything, mockTx, int64(42), decimal.NewFromInt(-10)).Return(nil)
+		mockRepo.On("UpdateBalance", mock.Anything, mockTx, int64(901), decimal.NewFromInt(10)).Return(nil)
+		mockTx.On("Commit").Return(nil)
+
+		withdrawal, err := service.Withdraw(ctx, &entities.Transaction{SourceAccountID: 42, DestinationAccountID: 901, Amount: decimal.NewFromInt(10)})
+		assert.NoError(t, err)
+		assert.Equal(t, entities.TransactionTypeWithdrawal, withdrawal.Type)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("insufficient balance", func(t *testing.T) {
+		service, _, mockRepo, mockTx := newService()
+		mockTx.On("Rollback").Return(nil).Once()
+
+		_, err := service.Withdraw(ctx, &entities.Transaction{SourceAccountID: 42, DestinationAccountID: 901, Amount: decimal.NewFromInt(11)})
+		assert.ErrorIs(t, err, entities.ErrInsufficientBalance)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("not a settlement account", func(t *testing.T) {
+		service, _, mockRepo, mockTx := newService()
+		mockTx.On("Rollback").Return(nil).Once()
+
+		_, err := service.Withdraw(ctx, &entities.Transaction{SourceAccountID: 42, DestinationAccountID: 7, Amount: decimal.NewFromInt(1)})
+
+		appErr, ok := err.(*appErrors.AppError)
+		assert.True(t, ok)
+		assert.Equal(t, "Account 7 is not a settlement account", appErr.Message)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
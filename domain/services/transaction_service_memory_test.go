@@ -0,0 +1,126 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"transfer-system/adapters/repositories"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/services"
+	"transfer-system/infrastructure/memstore"
+	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/logger"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryServices wires the account and transaction services to a fresh memory store
+func memoryServices() (*services.AccountServiceImpl, *services.TransactionServiceImpl) {
+	db := memstore.New()
+	accountRepository := &repositories.AccountRepositoryMemory{DB: db}
+	transactionRepository := &repositories.TransactionRepositoryMemory{DB: db}
+	customerRepository := &repositories.CustomerRepositoryMemory{DB: db}
+
+	accountService := &services.AccountServiceImpl{
+		DB:                    db,
+		AccountRepository:     accountRepository,
+		CustomerRepository:    customerRepository,
+		TransactionRepository: transactionRepository,
+		CtxTimeout:            2 * time.Second,
+	}
+	transactionService := &services.TransactionServiceImpl{
+		DB:                    db,
+		TransactionRepository: transactionRepository,
+		AccountRepository:     accountRepository,
+		CustomerRepository:    customerRepository,
+		CtxTimeout:            2 * time.Second,
+	}
+	return accountService, transactionService
+}
+
+func TestTransactionService_Memory_ConcurrentTransfersNeverOverdraw(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	accountService, transactionService := memoryServices()
+
+	_, err := accountService.Save(ctx, &entities.Account{AccountID: 101, Balance: decimal.NewFromInt(100)})
+	require.NoError(t, err)
+	_, err = accountService.Save(ctx, &entities.Account{AccountID: 102})
+	require.NoError(t, err)
+
+	// 30 transfers of 5 race for a balance of 100, the locked balance check lets 20 through
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded, insufficient := 0, 0
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := transactionService.Save(ctx, &entities.Transaction{
+				SourceAccountID:      101,
+				DestinationAccountID: 102,
+				Amount:               decimal.NewFromInt(5),
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			var appErr *appErrors.AppError
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.As(err, &appErr) && errors.Is(err, entities.ErrInsufficientBalance):
+				insufficient++
+			default:
+				t.Errorf("unexpected transfer error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 20, succeeded)
+	assert.Equal(t, 10, insufficient)
+
+	source, err := accountService.FindById(ctx, 101)
+	require.NoError(t, err)
+	destination, err := accountService.FindById(ctx, 102)
+	require.NoError(t, err)
+	assert.True(t, source.Balance.IsZero(), source.Balance.String())
+	assert.True(t, destination.Balance.Equal(decimal.NewFromInt(100)), destination.Balance.String())
+}
+
+func TestTransactionService_Memory_RejectedTransferBooksNothing(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	accountService, transactionService := memoryServices()
+
+	_, err := accountService.Save(ctx, &entities.Account{AccountID: 101, Balance: decimal.NewFromInt(10)})
+	require.NoError(t, err)
+	_, err = accountService.Save(ctx, &entities.Account{AccountID: 102, Currency: "EUR"})
+	require.NoError(t, err)
+
+	_, err = transactionService.Save(ctx, &entities.Transaction{
+		SourceAccountID:      101,
+		DestinationAccountID: 102,
+		Amount:               decimal.NewFromInt(5),
+	})
+	var appErr *appErrors.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.Equal(t, http.StatusUnprocessableEntity, appErr.StatusCode)
+
+	page, err := transactionService.ListByAccount(ctx, entities.TransactionFilter{AccountID: 102, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, page.Transactions)
+
+	source, err := accountService.FindById(ctx, 101)
+	require.NoError(t, err)
+	assert.True(t, source.Balance.Equal(decimal.NewFromInt(10)), source.Balance.String())
+}
+
+func TestAccountService_Memory_ConcurrentFundedCreations(t *testing.T) {
+	accountService, _ := memoryServices()
+	createConcurrently(t, accountService, "EUR", 20)
+}
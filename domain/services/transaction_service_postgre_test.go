@@ -0,0 +1,109 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"transfer-system/adapters/repositories"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/services"
+	"transfer-system/internal/testutils"
+	"transfer-system/pkg/logger"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionService_Postgre_ConcurrentSiblingTransfersShareTheDailyLimit(t *testing.T) {
+	db := testutils.SetupTestDB(t)
+	// closed after the cleanup below, which runs first
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	accountRepository := &repositories.AccountRepositoryPostgre{DB: db}
+	customerRepository := &repositories.CustomerRepositoryPostgre{DB: db}
+	transactionRepository := &repositories.TransactionRepositoryPostgre{DB: db}
+	accountService := &services.AccountServiceImpl{
+		DB:                    db,
+		AccountRepository:     accountRepository,
+		CustomerRepository:    customerRepository,
+		TransactionRepository: transactionRepository,
+		CtxTimeout:            10 * time.Second,
+	}
+	transactionService := &services.TransactionServiceImpl{
+		DB:                    db,
+		TransactionRepository: transactionRepository,
+		AccountRepository:     accountRepository,
+		CustomerRepository:    customerRepository,
+		CtxTimeout:            10 * time.Second,
+	}
+
+	tx := testutils.SetupTestTx(t, db)
+	customer, err := customerRepository.Save(ctx, tx, &entities.Customer{
+		FullName: "Sibling Accounts",
+		Email:    fmt.Sprintf("siblings-%d@example.com", time.Now().UnixNano()),
+		KYCTier:  entities.KYCTierUnverified,
+	})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	// two accounts of the customer and an unowned destination, funded from CHF equity
+	ids := []int64{}
+	t.Cleanup(func() {
+		cleanup := testutils.SetupTestTx(t, db)
+		for _, id := range ids {
+			_, err := cleanup.ExecContext(ctx, "DELETE FROM transactions WHERE source_id = $1 OR destination_id = $1", id)
+			require.NoError(t, err)
+		}
+		for _, id := range ids {
+			_, err := cleanup.ExecContext(ctx, "DELETE FROM accounts WHERE id = $1", id)
+			require.NoError(t, err)
+		}
+		_, err := cleanup.ExecContext(ctx, "UPDATE accounts SET balance = balance + $1 WHERE kind = 'equity' AND currency = 'CHF'", 800*len(ids))
+		require.NoError(t, err)
+		_, err = cleanup.ExecContext(ctx, "DELETE FROM customers WHERE id = $1", customer.CustomerID)
+		require.NoError(t, err)
+		require.NoError(t, cleanup.Commit())
+	})
+	for _, ownerID := range []*int64{&customer.CustomerID, &customer.CustomerID, nil} {
+		account, err := accountService.Save(ctx, &entities.Account{Balance: decimal.NewFromInt(800), Currency: "CHF", OwnerID: ownerID})
+		require.NoError(t, err)
+		ids = append(ids, account.AccountID)
+	}
+
+	// both siblings send 600 at once, the unverified limit of 1,000 lets one through
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = transactionService.Save(ctx, &entities.Transaction{
+				SourceAccountID:      ids[i],
+				DestinationAccountID: ids[2],
+				Amount:               decimal.NewFromInt(600),
+			})
+		}()
+	}
+	wg.Wait()
+
+	succeeded, limited := 0, 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, entities.ErrDailyLimitExceeded):
+			limited++
+		default:
+			t.Errorf("unexpected transfer error: %v", err)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+	assert.Equal(t, 1, limited)
+}
@@ -0,0 +1,96 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"transfer-system/adapters/repositories"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/services"
+	"transfer-system/infrastructure/datastore"
+	"transfer-system/pkg/logger"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sqliteServices wires the account and transaction services to a fresh SQLite file
+func sqliteServices(t *testing.T) (*services.AccountServiceImpl, *services.TransactionServiceImpl) {
+	db, err := datastore.NewDatabase("sqlite:"+filepath.Join(t.TempDir(), "transfer.db"), logrus.New())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	accountRepository := &repositories.AccountRepositorySQLite{DB: db}
+	transactionRepository := &repositories.TransactionRepositorySQLite{DB: db}
+	customerRepository := &repositories.CustomerRepositorySQLite{DB: db}
+
+	accountService := &services.AccountServiceImpl{
+		DB:                    db,
+		AccountRepository:     accountRepository,
+		CustomerRepository:    customerRepository,
+		TransactionRepository: transactionRepository,
+		CtxTimeout:            5 * time.Second,
+	}
+	transactionService := &services.TransactionServiceImpl{
+		DB:                    db,
+		TransactionRepository: transactionRepository,
+		AccountRepository:     accountRepository,
+		CustomerRepository:    customerRepository,
+		CtxTimeout:            5 * time.Second,
+	}
+	return accountService, transactionService
+}
+
+func TestTransactionService_SQLite_ConcurrentTransfersNeverOverdraw(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	accountService, transactionService := sqliteServices(t)
+
+	_, err := accountService.Save(ctx, &entities.Account{AccountID: 101, Balance: decimal.RequireFromString("1.00")})
+	require.NoError(t, err)
+	// the equity account funding 101 takes the next generated id, 102
+	_, err = accountService.Save(ctx, &entities.Account{AccountID: 201})
+	require.NoError(t, err)
+
+	// 15 transfers of 0.1 race for a balance of 1, exactly 10 fit
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded, insufficient := 0, 0
+	for i := 0; i < 15; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := transactionService.Save(ctx, &entities.Transaction{
+				SourceAccountID:      101,
+				DestinationAccountID: 201,
+				Amount:               decimal.RequireFromString("0.1"),
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.Is(err, entities.ErrInsufficientBalance):
+				insufficient++
+			default:
+				t.Errorf("unexpected transfer error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, succeeded)
+	assert.Equal(t, 5, insufficient)
+
+	source, err := accountService.FindById(ctx, 101)
+	require.NoError(t, err)
+	destination, err := accountService.FindById(ctx, 201)
+	require.NoError(t, err)
+	assert.True(t, source.Balance.IsZero(), source.Balance.String())
+	assert.Equal(t, "1", destination.Balance.String())
+}
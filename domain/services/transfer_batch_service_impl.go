@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/logger"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MaxTransferBatchSize caps the instructions of one batch so it completes within a request
+const MaxTransferBatchSize = 1000
+
+type TransferBatchServiceImpl struct {
+	DB                 ports.Database
+	AccountRepository  ports.AccountRepository
+	TransactionService ports.TransactionService
+	CtxTimeout         time.Duration
+}
+
+// Execute books each instruction as a separate transfer, so one rejected
+// instruction does not undo the others. Accounts are named by their external
+// reference, or by their id when no account has that reference
+func (s *TransferBatchServiceImpl) Execute(c context.Context, batch *entities.TransferBatch) ([]*entities.TransferInstructionResult, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	if len(batch.Instructions) == 0 {
+		return nil, appErrors.NewBadRequestError("Batch has no instructions", nil)
+	}
+	if len(batch.Instructions) > MaxTransferBatchSize {
+		logger.Errorf("Batch %s has %d instructions", batch.MessageID, len(batch.Instructions))
+		return nil, appErrors.NewBadRequestError(fmt.Sprintf("Batch has more than %d instructions", MaxTransferBatchSize), nil)
+	}
+
+	accounts := map[string]*entities.Account{}
+	results := make([]*entities.TransferInstructionResult, 0, len(batch.Instructions))
+	for _, instruction := range batch.Instructions {
+		result := &entities.TransferInstructionResult{Instruction: instruction}
+		results = append(results, result)
+
+		// a client that went away gets no report, stop booking on its behalf
+		if err := c.Err(); err != nil {
+			result.Err = appErrors.NewTimeoutError("Batch interrupted before the instruction was processed", err)
+			continue
+		}
+
+		source, destination, err := s.resolveAccounts(c, accounts, instruction)
+		if err != nil {
+			result.Err = err
+			continue
+		}
+		if source.Currency != instruction.Currency {
+			logger.Errorf("Instruction %s is in %s but account id %d holds %s", instruction.InstructionID, instruction.Currency, source.AccountID, source.Currency)
+			result.Err = appErrors.NewUnprocessableError("Instructed currency differs from the debtor account currency", entities.ErrCurrencyMismatch)
+			continue
+		}
+
+		result.Transaction, result.Err = s.TransactionService.Save(c, &entities.Transaction{
+			SourceAccountID:      source.AccountID,
+			DestinationAccountID: destination.AccountID,
+			Amount:               instruction.Amount,
+			Description:          instruction.Description,
+			Reference:            instruction.Reference,
+			Remittance:           instruction.Remittance,
+		})
+	}
+
+	return results, nil
+}
+
+// resolveAccounts looks up the debtor and creditor accounts, reusing earlier lookups of the batch
+func (s *TransferBatchServiceImpl) resolveAccounts(c context.Context, accounts map[string]*entities.Account, instruction *entities.TransferInstruction) (*entities.Account, *entities.Account, error) {
+	logger, _ := c.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	ctx, cancel := context.WithTimeout(c, s.CtxTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Failed to begin transaction")
+		return nil, nil, databaseError(err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil || err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	resolved := make([]*entities.Account, 0, 2)
+	for _, identifier := range []string{instruction.DebtorAccount, instruction.CreditorAccount} {
+		account, cached := accounts[identifier]
+		if !cached {
+			account, err = s.findAccount(ctx, tx, identifier)
+			if err != nil {
+				return nil, nil, err
+			}
+			accounts[identifier] = account
+		}
+		resolved = append(resolved, account)
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.WithError(err).Error("Failed to commit transaction")
+		return nil, nil, databaseError(err)
+	}
+
+	return resolved[0], resolved[1], nil
+}
+
+func (s *TransferBatchServiceImpl) findAccount(ctx context.Context, tx ports.Transaction, identifier string) (*entities.Account, error) {
+	logger, _ := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger)
+
+	account, err := s.AccountRepository.FindByExternalReference(ctx, tx, identifier)
+	if err == nil {
+		return account, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		logger.WithError(err).Error("Database error")
+		return nil, databaseError(err)
+	}
+
+	id, parseErr := strconv.ParseInt(identifier, 10, 64)
+	if parseErr != nil {
+		logger.Errorf("Account %s not found", identifier)
+		return nil, appErrors.NewNotFoundError("Account "+identifier+" not found", entities.ErrUnknownAccount)
+	}
+	account, err = s.AccountRepository.FindById(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Errorf("Account %s not found", identifier)
+			return nil, appErrors.NewNotFoundError("Account "+identifier+" not found", entities.ErrUnknownAccount)
+		}
+		logger.WithError(err).Error("Database error")
+		return nil, databaseError(err)
+	}
+
+	return account, nil
+}
@@ -0,0 +1,105 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"transfer-system/domain/entities"
+	"transfer-system/domain/services"
+	"transfer-system/mocks"
+	appErrors "transfer-system/pkg/errors"
+	"transfer-system/pkg/logger"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTransferBatchService() (*services.TransferBatchServiceImpl, *mocks.MockDatabase, *mocks.MockAccountRepository, *mocks.MockTransactionService) {
+	mockDB := new(mocks.MockDatabase)
+	mockAccRepo := new(mocks.MockAccountRepository)
+	mockTransactionService := new(mocks.MockTransactionService)
+
+	service := &services.TransferBatchServiceImpl{
+		DB:                 mockDB,
+		AccountRepository:  mockAccRepo,
+		TransactionService: mockTransactionService,
+		CtxTimeout:         2 * time.Second,
+	}
+	return service, mockDB, mockAccRepo, mockTransactionService
+}
+
+func TestTransferBatchService_Execute(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	service, mockDB, mockAccRepo, mockTransactionService := newTransferBatchService()
+	mockTx := new(mocks.MockTransaction)
+
+	payer := &entities.Account{AccountID: 1, Currency: "EUR", ExternalReference: "DE89370400440532013000"}
+	payee := &entities.Account{AccountID: 2, Currency: "EUR"}
+
+	batch := &entities.TransferBatch{
+		MessageID: "ACME-001",
+		Instructions: []*entities.TransferInstruction{
+			{InstructionID: "1", DebtorAccount: "DE89370400440532013000", CreditorAccount: "2", Amount: decimal.NewFromInt(100), Currency: "EUR", Reference: "SALARY-0042"},
+			{InstructionID: "2", DebtorAccount: "DE89370400440532013000", CreditorAccount: "2", Amount: decimal.NewFromInt(900), Currency: "EUR"},
+			{InstructionID: "3", DebtorAccount: "DE89370400440532013000", CreditorAccount: "FR7630006000011234567890189", Amount: decimal.NewFromInt(5), Currency: "EUR"},
+			{InstructionID: "4", DebtorAccount: "DE89370400440532013000", CreditorAccount: "2", Amount: decimal.NewFromInt(5), Currency: "USD"},
+		},
+	}
+
+	mockDB.On("BeginTx", mock.Anything).Return(mockTx, nil)
+	mockAccRepo.On("FindByExternalReference", mock.Anything, mockTx, "DE89370400440532013000").Return(payer, nil).Once()
+	mockAccRepo.On("FindByExternalReference", mock.Anything, mockTx, "2").Return(nil, sql.ErrNoRows).Once()
+	mockAccRepo.On("FindById", mock.Anything, mockTx, int64(2)).Return(payee, nil).Once()
+	mockAccRepo.On("FindByExternalReference", mock.Anything, mockTx, "FR7630006000011234567890189").Return(nil, sql.ErrNoRows)
+	mockTx.On("Commit").Return(nil)
+	mockTx.On("Rollback").Return(nil)
+
+	mockTransactionService.On("Save", mock.Anything, mock.MatchedBy(func(transaction *entities.Transaction) bool {
+		return transaction.Amount.Equal(decimal.NewFromInt(100))
+	})).Return(&entities.Transaction{Id: 501, SourceAccountID: 1, DestinationAccountID: 2, Amount: decimal.NewFromInt(100), Reference: "SALARY-0042"}, nil)
+	mockTransactionService.On("Save", mock.Anything, mock.MatchedBy(func(transaction *entities.Transaction) bool {
+		return transaction.Amount.Equal(decimal.NewFromInt(900))
+	})).Return(nil, appErrors.NewUnprocessableError("Insufficient balance", entities.ErrInsufficientBalance))
+
+	results, err := service.Execute(ctx, batch)
+	assert.NoError(t, err)
+	assert.Len(t, results, 4)
+
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, int64(501), results[0].Transaction.Id)
+	assert.True(t, errors.Is(results[1].Err, entities.ErrInsufficientBalance))
+	assert.True(t, errors.Is(results[2].Err, entities.ErrUnknownAccount))
+	assert.True(t, errors.Is(results[3].Err, entities.ErrCurrencyMismatch))
+	for i, result := range results {
+		assert.Same(t, batch.Instructions[i], result.Instruction)
+	}
+
+	// both accounts are looked up once for the whole batch
+	mockAccRepo.AssertNumberOfCalls(t, "FindById", 1)
+	mockTransactionService.AssertNumberOfCalls(t, "Save", 2)
+	mockTransactionService.AssertCalled(t, "Save", mock.Anything, mock.MatchedBy(func(transaction *entities.Transaction) bool {
+		return transaction.SourceAccountID == 1 && transaction.DestinationAccountID == 2 && transaction.Reference == "SALARY-0042"
+	}))
+}
+
+func TestTransferBatchService_Execute_TooManyInstructions(t *testing.T) {
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.NewEntry(logrus.New()))
+	service, mockDB, _, mockTransactionService := newTransferBatchService()
+
+	batch := &entities.TransferBatch{MessageID: "ACME-002", Instructions: make([]*entities.TransferInstruction, services.MaxTransferBatchSize+1)}
+
+	results, err := service.Execute(ctx, batch)
+	assert.Nil(t, results)
+	var appErr *appErrors.AppError
+	assert.True(t, errors.As(err, &appErr))
+	assert.Equal(t, http.StatusBadRequest, appErr.StatusCode)
+
+	mockDB.AssertNotCalled(t, "BeginTx")
+	mockTransactionService.AssertNotCalled(t, "Save")
+}
@@ -3,6 +3,8 @@ package datastore
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"net/url"
 	"time"
 	"transfer-system/domain/ports"
@@ -20,7 +22,22 @@ type Database struct {
 	db *sql.DB
 }
 
-func NewDatabase(dbURL string, logger *logrus.Logger) (ports.Database, error) {
+// NewDatabase connects to the database named by dbURL; its scheme picks the
+// driver, postgres:// for PostgreSQL or sqlite: for a SQLite file. Read-only
+// transactions go to the PostgreSQL replicas given, see ReplicatedDatabase
+func NewDatabase(dbURL string, logger *logrus.Logger, replicaURLs ...string) (ports.Database, error) {
+	if IsSQLite(dbURL) {
+		if len(replicaURLs) > 0 {
+			return nil, errors.New("read replicas need a postgres:// DB_URL")
+		}
+		return NewSQLiteDatabase(dbURL, logger)
+	}
+	return withReplicas(dbURL, replicaURLs, logger, func(dbURL string) (ports.Database, error) {
+		return newPostgresDatabase(dbURL, logger)
+	})
+}
+
+func newPostgresDatabase(dbURL string, logger *logrus.Logger) (ports.Database, error) {
 	parseDBUrl, _ := url.Parse(dbURL)
 	dbLogger := logger.WithFields(logrus.Fields{
 		"layer":  "database",
@@ -46,14 +63,78 @@ func NewDatabase(dbURL string, logger *logrus.Logger) (ports.Database, error) {
 	return &Database{db: db}, nil
 }
 
+// PostgreSQL drivers selectable with DB_DRIVER
+const (
+	DriverPQ  = "pq"
+	DriverPgx = "pgx"
+)
+
+// Open connects to dbURL through the named PostgreSQL driver: pq, the
+// default, goes through database/sql; pgx uses a native pool that supports
+// COPY. SQLite URLs only work with pq, which stands for database/sql there.
+// Replicas are opened with the same driver
+func Open(dbURL, driver string, logger *logrus.Logger, replicaURLs ...string) (ports.Database, error) {
+	switch driver {
+	case "", DriverPQ:
+		return NewDatabase(dbURL, logger, replicaURLs...)
+	case DriverPgx:
+		if IsSQLite(dbURL) {
+			return nil, fmt.Errorf("the %s driver needs a postgres:// DB_URL", DriverPgx)
+		}
+		return withReplicas(dbURL, replicaURLs, logger, func(dbURL string) (ports.Database, error) {
+			return NewPgxDatabase(dbURL, logger)
+		})
+	}
+	return nil, fmt.Errorf("unknown database driver %q, expected %s or %s", driver, DriverPQ, DriverPgx)
+}
+
+// withReplicas opens the primary and every replica; a replica that cannot be
+// reached fails the start, as the primary does
+func withReplicas(dbURL string, replicaURLs []string, logger *logrus.Logger, open func(string) (ports.Database, error)) (ports.Database, error) {
+	primary, err := open(dbURL)
+	if err != nil || len(replicaURLs) == 0 {
+		return primary, err
+	}
+
+	replicas := make([]ports.Database, 0, len(replicaURLs))
+	for _, replicaURL := range replicaURLs {
+		replica, err := open(replicaURL)
+		if err != nil {
+			for _, opened := range replicas {
+				opened.Close()
+			}
+			primary.Close()
+			return nil, fmt.Errorf("replica %d: %w", len(replicas), err)
+		}
+		replicas = append(replicas, replica)
+	}
+
+	dbLogger := logger.WithField("layer", "database")
+	return NewReplicatedDatabase(primary, replicas, dbLogger, DefaultHealthCheckInterval), nil
+}
+
+// IsSQLite reports whether dbURL names a SQLite file rather than a PostgreSQL server
+func IsSQLite(dbURL string) bool {
+	parsed, err := url.Parse(dbURL)
+	return err == nil && parsed.Scheme == "sqlite"
+}
+
 // Only connection methods for Database
-func (p *Database) BeginTx(ctx context.Context) (ports.Transaction, error) {
+func (p *Database) BeginTx(ctx context.Context, opts ...ports.TxOptions) (ports.Transaction, error) {
+	options := txOptions(opts)
 	tx, err := p.db.BeginTx(ctx, &sql.TxOptions{
-		Isolation: sql.LevelRepeatableRead, // isolation during transaction
+		Isolation: sqlIsolation(options.Isolation), // isolation during transaction
+		ReadOnly:  options.ReadOnly,
 	})
 	if err != nil {
 		return nil, err
 	}
+	if options.LockTimeout > 0 {
+		if _, err = tx.ExecContext(ctx, lockTimeoutStatement(options.LockTimeout)); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
 	return &Transaction{tx: tx}, nil
 }
 
@@ -70,12 +151,16 @@ func (t *Transaction) ExecContext(ctx context.Context, query string, args ...int
 	return t.tx.ExecContext(ctx, query, args...)
 }
 
-func (t *Transaction) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+func (t *Transaction) QueryRowContext(ctx context.Context, query string, args ...interface{}) ports.Row {
 	return t.tx.QueryRowContext(ctx, query, args...)
 }
 
-func (t *Transaction) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	return t.tx.QueryContext(ctx, query, args...)
+func (t *Transaction) QueryContext(ctx context.Context, query string, args ...interface{}) (ports.Rows, error) {
+	rows, err := t.tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
 }
 
 func (t *Transaction) Commit() error {
@@ -0,0 +1,84 @@
+-- SQLite counterpart of db.sql. Amounts are TEXT compared with the decimal
+-- collation and added with decimal_add/decimal_sum, so they stay exact.
+-- Timestamps are UTC TEXT in 'YYYY-MM-DD HH:MM:SS.ffffff', which sorts chronologically
+CREATE TABLE customers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    full_name TEXT NOT NULL,
+    email TEXT NOT NULL CONSTRAINT unique_customer_email UNIQUE,
+    phone TEXT,
+    date_of_birth DATE,
+    country TEXT,
+    kyc_tier TEXT NOT NULL DEFAULT 'unverified' CONSTRAINT valid_kyc_tier CHECK (kyc_tier IN ('unverified', 'basic', 'full')),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- AUTOINCREMENT never hands out an id at or below the highest one used, so
+-- generated ids cannot collide with ids clients picked explicitly
+CREATE TABLE accounts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    balance TEXT NOT NULL DEFAULT '0' COLLATE decimal,
+    initial_balance TEXT NOT NULL DEFAULT '0' COLLATE decimal,
+    external_reference TEXT CONSTRAINT unique_external_reference UNIQUE,
+    currency TEXT NOT NULL DEFAULT 'USD',
+    kind TEXT NOT NULL DEFAULT 'customer' CONSTRAINT valid_kind CHECK (kind IN ('customer', 'equity', 'settlement')),
+    status TEXT NOT NULL DEFAULT 'active' CONSTRAINT valid_status CHECK (status IN ('active', 'frozen', 'closed')),
+    owner_id INTEGER REFERENCES customers(id),
+    metadata TEXT NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL,
+    CONSTRAINT positive_balance CHECK (balance >= '0' OR kind <> 'customer')
+);
+
+CREATE UNIQUE INDEX accounts_system_kind_currency_idx ON accounts (kind, currency) WHERE kind = 'equity';
+CREATE INDEX accounts_balance_id_idx ON accounts (balance, id);
+CREATE INDEX accounts_created_at_id_idx ON accounts (created_at, id);
+CREATE INDEX accounts_owner_id_idx ON accounts (owner_id);
+
+CREATE TABLE transactions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    type TEXT NOT NULL DEFAULT 'transfer' CONSTRAINT valid_type CHECK (type IN ('transfer', 'deposit', 'withdrawal', 'fee', 'reversal')),
+    source_id INTEGER NOT NULL REFERENCES accounts(id),
+    destination_id INTEGER NOT NULL REFERENCES accounts(id),
+    amount TEXT NOT NULL COLLATE decimal CONSTRAINT min_amount CHECK (amount > '0'),
+    description TEXT,
+    reference TEXT,
+    remittance_creditor_reference TEXT,
+    remittance_invoice_number TEXT,
+    remittance_invoice_date DATE,
+    metadata TEXT NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX transactions_source_id_created_at_idx ON transactions (source_id, created_at);
+CREATE INDEX transactions_destination_id_created_at_idx ON transactions (destination_id, created_at);
+CREATE INDEX transactions_reference_idx ON transactions (reference);
+
+CREATE TABLE account_balance_snapshots (
+    account_id INTEGER NOT NULL REFERENCES accounts(id),
+    as_of TIMESTAMP NOT NULL,
+    balance TEXT NOT NULL COLLATE decimal,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (account_id, as_of)
+);
+
+CREATE TABLE import_jobs (
+    id TEXT PRIMARY KEY,
+    kind TEXT NOT NULL CONSTRAINT valid_import_kind CHECK (kind IN ('accounts', 'transfers')),
+    status TEXT NOT NULL CONSTRAINT valid_import_status CHECK (status IN ('running', 'completed', 'failed')),
+    checksum TEXT NOT NULL,
+    total_rows INTEGER NOT NULL,
+    committed_rows INTEGER NOT NULL DEFAULT 0,
+    failed_line INTEGER,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE api_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,
+    hash TEXT NOT NULL CONSTRAINT unique_api_key_hash UNIQUE,
+    created_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP
+);
@@ -0,0 +1,2 @@
+-- bumped by every update of the row, balance updates included; served as the ETag
+ALTER TABLE accounts ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
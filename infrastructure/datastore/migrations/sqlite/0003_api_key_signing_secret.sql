@@ -0,0 +1,2 @@
+-- kept as is, verifying HMAC request signatures needs it
+ALTER TABLE api_keys ADD COLUMN signing_secret TEXT;
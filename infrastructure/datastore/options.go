@@ -0,0 +1,51 @@
+package datastore
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"transfer-system/domain/ports"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// txOptions returns the options BeginTx was given, the first of them if any
+func txOptions(opts []ports.TxOptions) ports.TxOptions {
+	if len(opts) == 0 {
+		return ports.TxOptions{}
+	}
+	return opts[0]
+}
+
+// sqlIsolation maps the isolation level for database/sql; the default is repeatable read
+func sqlIsolation(level ports.IsolationLevel) sql.IsolationLevel {
+	switch level {
+	case ports.IsolationReadCommitted:
+		return sql.LevelReadCommitted
+	case ports.IsolationSerializable:
+		return sql.LevelSerializable
+	}
+	return sql.LevelRepeatableRead
+}
+
+// pgxIsolation maps the isolation level for pgx; the default is repeatable read
+func pgxIsolation(level ports.IsolationLevel) pgx.TxIsoLevel {
+	switch level {
+	case ports.IsolationReadCommitted:
+		return pgx.ReadCommitted
+	case ports.IsolationSerializable:
+		return pgx.Serializable
+	}
+	return pgx.RepeatableRead
+}
+
+// lockTimeoutStatement sets lock_timeout for the rest of the transaction.
+// SET takes no bind parameters; the value is an integer of milliseconds
+func lockTimeoutStatement(timeout time.Duration) string {
+	millis := timeout.Milliseconds()
+	if millis < 1 {
+		millis = 1
+	}
+	return fmt.Sprintf("SET LOCAL lock_timeout = %d", millis)
+}
@@ -0,0 +1,256 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"transfer-system/domain/ports"
+	"transfer-system/pkg/logger"
+
+	pgxdecimal "github.com/jackc/pgx-shopspring-decimal"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/multitracer"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// compile-time interface check
+var _ ports.Transaction = (*PgxTransaction)(nil)
+var _ ports.Copier = (*PgxTransaction)(nil)
+var _ ports.Database = (*PgxDatabase)(nil)
+var _ pgx.QueryTracer = (*PgxTracer)(nil)
+var _ pgx.CopyFromTracer = (*PgxTracer)(nil)
+
+// DefaultSlowQuery is the duration above which PgxTracer logs a statement as a warning
+const DefaultSlowQuery = 500 * time.Millisecond
+
+// PgxDatabase talks to PostgreSQL through a pgx pool instead of database/sql.
+// NUMERIC columns decode straight into decimal.Decimal, and its transactions
+// implement ports.Copier for bulk loads with COPY FROM
+type PgxDatabase struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgxDatabase opens a pool on a postgres:// URL. Statements are traced by a
+// PgxTracer logging through logger, then by any extra tracers given; those
+// that also implement pgx.CopyFromTracer see COPY FROM as well
+func NewPgxDatabase(dbURL string, logger *logrus.Logger, tracers ...pgx.QueryTracer) (*PgxDatabase, error) {
+	dbLogger := logger.WithFields(logrus.Fields{
+		"layer":  "database",
+		"driver": "pgx",
+	})
+
+	config, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		dbLogger.Info("Invalid database URL")
+		return nil, err
+	}
+	// same limits as the database/sql pool
+	config.MaxConns = 20
+	config.MaxConnLifetime = 60 * time.Minute
+	config.MaxConnIdleTime = 10 * time.Minute
+	config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		pgxdecimal.Register(conn.TypeMap())
+		return nil
+	}
+	config.ConnConfig.Tracer = multitracer.New(append([]pgx.QueryTracer{&PgxTracer{Logger: dbLogger, SlowQuery: DefaultSlowQuery}}, tracers...)...)
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		dbLogger.Info("Failed connect to database")
+		return nil, err
+	}
+
+	if err = pool.Ping(context.Background()); err != nil {
+		pool.Close()
+		dbLogger.Info("Failed connect to database (PING)")
+		return nil, err
+	}
+
+	return &PgxDatabase{pool: pool}, nil
+}
+
+func (p *PgxDatabase) BeginTx(ctx context.Context, opts ...ports.TxOptions) (ports.Transaction, error) {
+	options := txOptions(opts)
+	pgxOptions := pgx.TxOptions{
+		IsoLevel: pgxIsolation(options.Isolation), // isolation during transaction
+	}
+	if options.ReadOnly {
+		pgxOptions.AccessMode = pgx.ReadOnly
+	}
+	tx, err := p.pool.BeginTx(ctx, pgxOptions)
+	if err != nil {
+		return nil, err
+	}
+	if options.LockTimeout > 0 {
+		if _, err = tx.Exec(ctx, lockTimeoutStatement(options.LockTimeout)); err != nil {
+			tx.Rollback(context.Background())
+			return nil, err
+		}
+	}
+	return &PgxTransaction{tx: tx}, nil
+}
+
+func (p *PgxDatabase) Close() error {
+	p.pool.Close()
+	return nil
+}
+
+// PgxTransaction adapts pgx.Tx to ports.Transaction, reporting a missing row
+// and a finished transaction with the database/sql errors repositories compare against
+type PgxTransaction struct {
+	tx pgx.Tx
+}
+
+func (t *PgxTransaction) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	tag, err := t.tx.Exec(ctx, query, args...)
+	if err != nil {
+		return nil, pgxError(err)
+	}
+	return pgxResult{tag: tag}, nil
+}
+
+func (t *PgxTransaction) QueryRowContext(ctx context.Context, query string, args ...interface{}) ports.Row {
+	return pgxRow{row: t.tx.QueryRow(ctx, query, args...)}
+}
+
+func (t *PgxTransaction) QueryContext(ctx context.Context, query string, args ...interface{}) (ports.Rows, error) {
+	rows, err := t.tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, pgxError(err)
+	}
+	return pgxRows{rows: rows}, nil
+}
+
+// CopyFrom loads rows into table with the COPY protocol, returning the number of rows copied
+func (t *PgxTransaction) CopyFrom(ctx context.Context, table string, columns []string, rows [][]interface{}) (int64, error) {
+	copied, err := t.tx.CopyFrom(ctx, pgx.Identifier{table}, columns, pgx.CopyFromRows(rows))
+	return copied, pgxError(err)
+}
+
+// Commit and Rollback do not take a context, as in database/sql; the
+// transaction already ends when the context given to BeginTx is cancelled
+func (t *PgxTransaction) Commit() error {
+	return pgxError(t.tx.Commit(context.Background()))
+}
+
+func (t *PgxTransaction) Rollback() error {
+	return pgxError(t.tx.Rollback(context.Background()))
+}
+
+// pgxError maps the pgx errors with a database/sql counterpart; PostgreSQL
+// errors keep their SQLSTATE through *pgconn.PgError
+func pgxError(err error) error {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return sql.ErrNoRows
+	case errors.Is(err, pgx.ErrTxClosed):
+		return sql.ErrTxDone
+	}
+	return err
+}
+
+type pgxRow struct {
+	row pgx.Row
+}
+
+func (r pgxRow) Scan(dest ...interface{}) error {
+	return pgxError(r.row.Scan(dest...))
+}
+
+type pgxRows struct {
+	rows pgx.Rows
+}
+
+func (r pgxRows) Next() bool {
+	return r.rows.Next()
+}
+
+func (r pgxRows) Scan(dest ...interface{}) error {
+	return pgxError(r.rows.Scan(dest...))
+}
+
+func (r pgxRows) Err() error {
+	return pgxError(r.rows.Err())
+}
+
+func (r pgxRows) Close() error {
+	r.rows.Close()
+	return pgxError(r.rows.Err())
+}
+
+// pgxResult reports the rows affected from the command tag; PostgreSQL has no last insert id
+type pgxResult struct {
+	tag pgconn.CommandTag
+}
+
+func (r pgxResult) LastInsertId() (int64, error) {
+	return 0, errors.New("LastInsertId is not supported by PostgreSQL, use RETURNING")
+}
+
+func (r pgxResult) RowsAffected() (int64, error) {
+	return r.tag.RowsAffected(), nil
+}
+
+type pgxTraceKey struct{}
+
+type pgxTrace struct {
+	start time.Time
+	sql   string
+}
+
+// PgxTracer logs every statement and COPY FROM at debug level with its
+// duration and rows affected; those slower than SlowQuery, or failing, are
+// logged as warnings. The request logger in the context is preferred to Logger
+type PgxTracer struct {
+	Logger    logrus.FieldLogger
+	SlowQuery time.Duration
+}
+
+func (t *PgxTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, pgxTraceKey{}, pgxTrace{start: time.Now(), sql: data.SQL})
+}
+
+func (t *PgxTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	t.log(ctx, data.CommandTag, data.Err)
+}
+
+func (t *PgxTracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	return context.WithValue(ctx, pgxTraceKey{}, pgxTrace{start: time.Now(), sql: "COPY " + data.TableName.Sanitize() + " FROM STDIN"})
+}
+
+func (t *PgxTracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	t.log(ctx, data.CommandTag, data.Err)
+}
+
+func (t *PgxTracer) log(ctx context.Context, tag pgconn.CommandTag, err error) {
+	trace, ok := ctx.Value(pgxTraceKey{}).(pgxTrace)
+	if !ok {
+		return
+	}
+	log := t.Logger
+	if requestLogger, ok := ctx.Value(logger.LoggerContextKey).(logrus.FieldLogger); ok {
+		log = requestLogger
+	}
+	if log == nil {
+		return
+	}
+
+	duration := time.Since(trace.start)
+	entry := log.WithFields(logrus.Fields{
+		"sql":           trace.sql,
+		"duration_ms":   float64(duration.Microseconds()) / 1000,
+		"rows_affected": tag.RowsAffected(),
+	})
+	switch {
+	case err != nil && !errors.Is(err, pgx.ErrNoRows):
+		entry.WithError(err).Warn("Query failed")
+	case t.SlowQuery > 0 && duration > t.SlowQuery:
+		entry.Warn("Slow query")
+	default:
+		entry.Debug("Query")
+	}
+}
@@ -0,0 +1,83 @@
+package datastore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"transfer-system/infrastructure/datastore"
+	"transfer-system/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen_Drivers(t *testing.T) {
+	_, err := datastore.Open("postgres://localhost/transfer", "mysql", logrus.New())
+	assert.EqualError(t, err, `unknown database driver "mysql", expected pq or pgx`)
+
+	_, err = datastore.Open("sqlite:transfer.db", datastore.DriverPgx, logrus.New())
+	assert.EqualError(t, err, "the pgx driver needs a postgres:// DB_URL")
+
+	_, err = datastore.Open("postgres://localhost:notaport/transfer", datastore.DriverPgx, logrus.New())
+	assert.Error(t, err)
+}
+
+func TestPgxTracer(t *testing.T) {
+	log, hook := test.NewNullLogger()
+	log.SetLevel(logrus.DebugLevel)
+	tracer := &datastore.PgxTracer{Logger: log, SlowQuery: 50 * time.Millisecond}
+
+	trace := func(ctx context.Context, delay time.Duration, tag string, err error) *logrus.Entry {
+		ctx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "UPDATE accounts SET balance = balance + $1 WHERE id = $2"})
+		time.Sleep(delay)
+		tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag(tag), Err: err})
+		return hook.LastEntry()
+	}
+
+	entry := trace(context.Background(), 0, "UPDATE 1", nil)
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.DebugLevel, entry.Level)
+	assert.Equal(t, "UPDATE accounts SET balance = balance + $1 WHERE id = $2", entry.Data["sql"])
+	assert.Equal(t, int64(1), entry.Data["rows_affected"])
+
+	entry = trace(context.Background(), 60*time.Millisecond, "UPDATE 1", nil)
+	assert.Equal(t, logrus.WarnLevel, entry.Level)
+	assert.Equal(t, "Slow query", entry.Message)
+
+	entry = trace(context.Background(), 0, "", errors.New("deadlock detected"))
+	assert.Equal(t, logrus.WarnLevel, entry.Level)
+	assert.Equal(t, "Query failed", entry.Message)
+
+	// a missing row is an answer, not a failure
+	entry = trace(context.Background(), 0, "SELECT 0", pgx.ErrNoRows)
+	assert.Equal(t, logrus.DebugLevel, entry.Level)
+
+	// the request logger carries the request id, so it wins over the tracer's own
+	requestLog, requestHook := test.NewNullLogger()
+	requestLog.SetLevel(logrus.DebugLevel)
+	hook.Reset()
+	ctx := context.WithValue(context.Background(), logger.LoggerContextKey, logrus.FieldLogger(requestLog.WithField("RequestID", "r-1")))
+	trace(ctx, 0, "UPDATE 1", nil)
+	assert.Empty(t, hook.AllEntries())
+	require.NotNil(t, requestHook.LastEntry())
+	assert.Equal(t, "r-1", requestHook.LastEntry().Data["RequestID"])
+}
+
+func TestPgxTracer_CopyFrom(t *testing.T) {
+	log, hook := test.NewNullLogger()
+	log.SetLevel(logrus.DebugLevel)
+	tracer := &datastore.PgxTracer{Logger: log}
+
+	ctx := tracer.TraceCopyFromStart(context.Background(), nil, pgx.TraceCopyFromStartData{TableName: pgx.Identifier{"transactions"}, ColumnNames: []string{"amount"}})
+	tracer.TraceCopyFromEnd(ctx, nil, pgx.TraceCopyFromEndData{CommandTag: pgconn.NewCommandTag("COPY 500")})
+
+	require.NotNil(t, hook.LastEntry())
+	assert.Equal(t, `COPY "transactions" FROM STDIN`, hook.LastEntry().Data["sql"])
+	assert.Equal(t, int64(500), hook.LastEntry().Data["rows_affected"])
+}
@@ -0,0 +1,158 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"transfer-system/domain/ports"
+
+	"github.com/sirupsen/logrus"
+)
+
+// compile-time interface check
+var _ ports.Database = (*ReplicatedDatabase)(nil)
+
+// DefaultHealthCheckInterval is how often ReplicatedDatabase checks its replicas
+const DefaultHealthCheckInterval = 5 * time.Second
+
+// ReplicatedDatabase sends read-only transactions to its replicas in turn and
+// everything else to the primary. A replica failing to begin a transaction is
+// skipped until a health check finds it up again; with no replica up, reads
+// go to the primary. Serializable reads and contexts carrying
+// ports.ReadPrimaryContextKey stay on the primary
+type ReplicatedDatabase struct {
+	primary  ports.Database
+	replicas []*replica
+	next     atomic.Uint64
+	logger   logrus.FieldLogger
+
+	stop      chan struct{}
+	checks    sync.WaitGroup
+	closeOnce sync.Once
+}
+
+type replica struct {
+	index   int
+	db      ports.Database
+	healthy atomic.Bool
+}
+
+// NewReplicatedDatabase routes between primary and replicas, all assumed up,
+// and checks the replicas every interval until Close
+func NewReplicatedDatabase(primary ports.Database, replicas []ports.Database, logger logrus.FieldLogger, interval time.Duration) *ReplicatedDatabase {
+	r := &ReplicatedDatabase{
+		primary: primary,
+		logger:  logger,
+		stop:    make(chan struct{}),
+	}
+	for i, db := range replicas {
+		replica := &replica{index: i, db: db}
+		replica.healthy.Store(true)
+		r.replicas = append(r.replicas, replica)
+	}
+
+	r.checks.Add(1)
+	go r.watch(interval)
+	return r
+}
+
+func (r *ReplicatedDatabase) BeginTx(ctx context.Context, opts ...ports.TxOptions) (ports.Transaction, error) {
+	options := txOptions(opts)
+	// hot standbys cannot run serializable transactions
+	if options.ReadOnly && options.Isolation != ports.IsolationSerializable && !readPrimary(ctx) {
+		if tx, ok := r.beginOnReplica(ctx, options); ok {
+			return tx, nil
+		}
+	}
+	return r.primary.BeginTx(ctx, opts...)
+}
+
+func (r *ReplicatedDatabase) beginOnReplica(ctx context.Context, options ports.TxOptions) (ports.Transaction, bool) {
+	start := r.next.Add(1)
+	for i := range r.replicas {
+		replica := r.replicas[(start+uint64(i))%uint64(len(r.replicas))]
+		if !replica.healthy.Load() {
+			continue
+		}
+		tx, err := replica.db.BeginTx(ctx, options)
+		if err == nil {
+			return tx, true
+		}
+		if ctx.Err() != nil {
+			return nil, false
+		}
+		r.markDown(replica, err)
+	}
+	return nil, false
+}
+
+// Healthy reports how many replicas currently take reads
+func (r *ReplicatedDatabase) Healthy() int {
+	healthy := 0
+	for _, replica := range r.replicas {
+		if replica.healthy.Load() {
+			healthy++
+		}
+	}
+	return healthy
+}
+
+// CheckReplicas begins and rolls back a read-only transaction on every
+// replica, taking those that succeed back into rotation
+func (r *ReplicatedDatabase) CheckReplicas(ctx context.Context) {
+	for _, replica := range r.replicas {
+		tx, err := replica.db.BeginTx(ctx, ports.TxOptions{ReadOnly: true})
+		if err != nil {
+			r.markDown(replica, err)
+			continue
+		}
+		tx.Rollback()
+		if !replica.healthy.Swap(true) {
+			r.logger.WithField("replica", replica.index).Info("Replica is back in rotation")
+		}
+	}
+}
+
+func (r *ReplicatedDatabase) markDown(replica *replica, err error) {
+	if replica.healthy.Swap(false) {
+		r.logger.WithField("replica", replica.index).WithError(err).Warn("Replica is down, reading from the primary")
+	}
+}
+
+func (r *ReplicatedDatabase) watch(interval time.Duration) {
+	defer r.checks.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			r.CheckReplicas(ctx)
+			cancel()
+		}
+	}
+}
+
+// Close stops the health checks and closes the replicas and the primary
+func (r *ReplicatedDatabase) Close() error {
+	var errs []error
+	r.closeOnce.Do(func() {
+		close(r.stop)
+		r.checks.Wait()
+		for _, replica := range r.replicas {
+			errs = append(errs, replica.db.Close())
+		}
+		errs = append(errs, r.primary.Close())
+	})
+	return errors.Join(errs...)
+}
+
+func readPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(ports.ReadPrimaryContextKey).(bool)
+	return primary
+}
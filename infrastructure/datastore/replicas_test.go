@@ -0,0 +1,110 @@
+package datastore_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"transfer-system/domain/ports"
+	"transfer-system/infrastructure/datastore"
+	"transfer-system/mocks"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var readOnly = ports.TxOptions{ReadOnly: true}
+
+func newReplicated(replicas int) (*datastore.ReplicatedDatabase, *mocks.MockDatabase, []*mocks.MockDatabase) {
+	primary := new(mocks.MockDatabase)
+	replicaMocks := make([]*mocks.MockDatabase, replicas)
+	databases := make([]ports.Database, replicas)
+	for i := range replicaMocks {
+		replicaMocks[i] = new(mocks.MockDatabase)
+		databases[i] = replicaMocks[i]
+	}
+	// the health checks are run by the tests
+	db := datastore.NewReplicatedDatabase(primary, databases, logrus.New(), time.Hour)
+	return db, primary, replicaMocks
+}
+
+func TestReplicatedDatabase_RoutesReadOnlyToReplicas(t *testing.T) {
+	db, primary, replicas := newReplicated(2)
+	first, second, writer := new(mocks.MockTransaction), new(mocks.MockTransaction), new(mocks.MockTransaction)
+	replicas[0].On("BeginTx", mock.Anything, readOnly).Return(first, nil)
+	replicas[1].On("BeginTx", mock.Anything, readOnly).Return(second, nil)
+	primary.On("BeginTx", mock.Anything).Return(writer, nil)
+	primary.On("BeginTx", mock.Anything, mock.Anything).Return(writer, nil)
+	ctx := context.Background()
+
+	// replicas take reads in turn
+	a, err := db.BeginTx(ctx, readOnly)
+	require.NoError(t, err)
+	b, err := db.BeginTx(ctx, readOnly)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []ports.Transaction{first, second}, []ports.Transaction{a, b})
+
+	tx, err := db.BeginTx(ctx)
+	require.NoError(t, err)
+	assert.Same(t, writer, tx)
+
+	tx, err = db.BeginTx(context.WithValue(ctx, ports.ReadPrimaryContextKey, true), readOnly)
+	require.NoError(t, err)
+	assert.Same(t, writer, tx)
+
+	tx, err = db.BeginTx(ctx, ports.TxOptions{ReadOnly: true, Isolation: ports.IsolationSerializable})
+	require.NoError(t, err)
+	assert.Same(t, writer, tx)
+}
+
+func TestReplicatedDatabase_FallsBackToPrimary(t *testing.T) {
+	db, primary, replicas := newReplicated(1)
+	fromReplica, fromPrimary := new(mocks.MockTransaction), new(mocks.MockTransaction)
+	replicas[0].On("BeginTx", mock.Anything, readOnly).Return((*mocks.MockTransaction)(nil), driver.ErrBadConn).Once()
+	primary.On("BeginTx", mock.Anything, readOnly).Return(fromPrimary, nil)
+	ctx := context.Background()
+
+	tx, err := db.BeginTx(ctx, readOnly)
+	require.NoError(t, err)
+	assert.Same(t, fromPrimary, tx)
+	assert.Equal(t, 0, db.Healthy())
+
+	// a replica that is down is not tried again until a check finds it up
+	tx, err = db.BeginTx(ctx, readOnly)
+	require.NoError(t, err)
+	assert.Same(t, fromPrimary, tx)
+	replicas[0].AssertNumberOfCalls(t, "BeginTx", 1)
+
+	check := new(mocks.MockTransaction)
+	check.On("Rollback").Return(nil)
+	replicas[0].On("BeginTx", mock.Anything, readOnly).Return(check, nil).Once()
+	db.CheckReplicas(ctx)
+	assert.Equal(t, 1, db.Healthy())
+
+	replicas[0].On("BeginTx", mock.Anything, readOnly).Return(fromReplica, nil).Once()
+	tx, err = db.BeginTx(ctx, readOnly)
+	require.NoError(t, err)
+	assert.Same(t, fromReplica, tx)
+}
+
+func TestReplicatedDatabase_Close(t *testing.T) {
+	db, primary, replicas := newReplicated(2)
+	primary.On("Close").Return(nil).Once()
+	replicas[0].On("Close").Return(nil).Once()
+	replicas[1].On("Close").Return(nil).Once()
+
+	assert.NoError(t, db.Close())
+	// closing twice, as main does on shutdown, closes the databases once
+	assert.NoError(t, db.Close())
+	primary.AssertExpectations(t)
+	replicas[0].AssertExpectations(t)
+	replicas[1].AssertExpectations(t)
+}
+
+func TestNewDatabase_SQLiteReplicas(t *testing.T) {
+	_, err := datastore.NewDatabase("sqlite:transfer.db", logrus.New(), "sqlite:replica.db")
+	assert.EqualError(t, err, "read replicas need a postgres:// DB_URL")
+}
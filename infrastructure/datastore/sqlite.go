@@ -0,0 +1,437 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"embed"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"transfer-system/domain/ports"
+
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// compile-time interface check
+var _ ports.Transaction = (*SQLiteTransaction)(nil)
+var _ ports.Database = (*SQLiteDatabase)(nil)
+var _ ports.SingleConnection = (*SQLiteDatabase)(nil)
+
+//go:embed migrations/sqlite/*.sql
+var sqliteMigrations embed.FS
+
+// sqliteDefaultPragmas apply unless DB_URL sets them: foreign keys are off in
+// SQLite by default, and the busy timeout lets the admin commands and the
+// server share one file
+var sqliteDefaultPragmas = map[string]string{
+	"foreign_keys": "foreign_keys(1)",
+	"busy_timeout": "busy_timeout(5000)",
+	"journal_mode": "journal_mode(WAL)",
+}
+
+func init() {
+	// amounts are stored as text; these keep comparisons and sums exact
+	sqlite.MustRegisterCollationUtf8("decimal", compareDecimals)
+	sqlite.MustRegisterDeterministicScalarFunction("decimal_add", 2, addDecimals)
+	sqlite.MustRegisterFunction("decimal_sum", &sqlite.FunctionImpl{
+		NArgs:         1,
+		Deterministic: true,
+		MakeAggregate: func(sqlite.FunctionContext) (sqlite.AggregateFunction, error) {
+			return &decimalSum{}, nil
+		},
+	})
+}
+
+// SQLiteDatabase keeps the data in a single SQLite file. SQLite has no row
+// locks, so every transaction takes the write lock when it begins (BEGIN
+// IMMEDIATE): a transfer's balance check and update cannot interleave with another
+type SQLiteDatabase struct {
+	db *sql.DB
+}
+
+// SingleConnection marks the pool of one connection, see NewSQLiteDatabase
+func (d *SQLiteDatabase) SingleConnection() {}
+
+// NewSQLiteDatabase opens the file named by a sqlite:path URL, e.g.
+// sqlite:transfer.db or sqlite:///var/lib/transfer/transfer.db, and applies
+// pending migrations. Query parameters are passed to the driver
+func NewSQLiteDatabase(dbURL string, logger *logrus.Logger) (*SQLiteDatabase, error) {
+	dbLogger := logger.WithFields(logrus.Fields{
+		"layer":  "database",
+		"driver": "sqlite",
+	})
+
+	dsn, err := sqliteDSN(dbURL)
+	if err != nil {
+		dbLogger.Info("Invalid SQLite URL")
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		dbLogger.Info("Failed connect to database")
+		return nil, err
+	}
+
+	// one connection: writers queue in database/sql, where they honour the
+	// request deadline, instead of polling the file lock
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxIdleTime(0)
+
+	if err = db.Ping(); err != nil {
+		db.Close()
+		dbLogger.Info("Failed connect to database (PING)")
+		return nil, err
+	}
+
+	if err = migrateSQLite(context.Background(), db, dbLogger); err != nil {
+		db.Close()
+		dbLogger.WithError(err).Info("Failed to migrate database")
+		return nil, err
+	}
+
+	return &SQLiteDatabase{db: db}, nil
+}
+
+func sqliteDSN(dbURL string) (string, error) {
+	parsed, err := url.Parse(dbURL)
+	if err != nil {
+		return "", err
+	}
+	path := parsed.Opaque
+	if path == "" {
+		path = parsed.Host + parsed.Path
+	}
+	if path == "" {
+		return "", fmt.Errorf("sqlite URL %q names no file", dbURL)
+	}
+
+	query := parsed.Query()
+	for name, pragma := range sqliteDefaultPragmas {
+		set := false
+		for _, given := range query["_pragma"] {
+			set = set || strings.HasPrefix(strings.ToLower(strings.TrimSpace(given)), name)
+		}
+		if !set {
+			query.Add("_pragma", pragma)
+		}
+	}
+	// the write lock at BEGIN is what keeps read-check-update transactions safe
+	query.Set("_txlock", "immediate")
+
+	return path + "?" + query.Encode(), nil
+}
+
+// migrateSQLite applies the embedded migrations not yet recorded in schema_migrations, each in its own transaction
+func migrateSQLite(ctx context.Context, db *sql.DB, logger logrus.FieldLogger) error {
+	_, err := db.ExecContext(ctx, `
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version INTEGER PRIMARY KEY,
+				applied_at TIMESTAMP NOT NULL
+			)`)
+	if err != nil {
+		return err
+	}
+
+	entries, err := sqliteMigrations.ReadDir("migrations/sqlite")
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for _, entry := range entries {
+		// files are named NNNN_description.sql
+		prefix, _, _ := strings.Cut(entry.Name(), "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return fmt.Errorf("migration %s: name must start with its version", entry.Name())
+		}
+		script, err := sqliteMigrations.ReadFile("migrations/sqlite/" + entry.Name())
+		if err != nil {
+			return err
+		}
+		if err := applySQLiteMigration(ctx, db, version, string(script)); err != nil {
+			return fmt.Errorf("migration %s: %w", entry.Name(), err)
+		}
+		logger.Debugf("Migration %s is applied", entry.Name())
+	}
+
+	return nil
+}
+
+func applySQLiteMigration(ctx context.Context, db *sql.DB, version int, script string) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var applied int
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM schema_migrations WHERE version = ?", version).Scan(&applied)
+	if err != nil || applied > 0 {
+		if err == nil {
+			return tx.Rollback()
+		}
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)",
+		version, time.Now().UTC().Format("2006-01-02 15:04:05.000000"))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// BeginTx takes the write lock at BEGIN unless the transaction is read-only.
+// SQLite is always serializable, so the isolation level is ignored. With a
+// single connection, waiting for it is waiting for the lock: LockTimeout
+// bounds that wait
+func (s *SQLiteDatabase) BeginTx(ctx context.Context, opts ...ports.TxOptions) (ports.Transaction, error) {
+	options := txOptions(opts)
+
+	connCtx := ctx
+	if options.LockTimeout > 0 {
+		var cancel context.CancelFunc
+		connCtx, cancel = context.WithTimeout(ctx, options.LockTimeout)
+		defer cancel()
+	}
+	conn, err := s.db.Conn(connCtx)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, &sqliteStateError{state: "55P03", err: fmt.Errorf("lock not available after %s: %w", options.LockTimeout, err)}
+		}
+		return nil, SQLiteError(err)
+	}
+
+	// a read-only transaction begins DEFERRED and takes no write lock; the
+	// driver does not refuse writes in it, query_only does until it ends
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: options.ReadOnly})
+	if err != nil {
+		conn.Close()
+		return nil, SQLiteError(err)
+	}
+	if options.ReadOnly {
+		if _, err = tx.ExecContext(ctx, "PRAGMA query_only = ON"); err != nil {
+			tx.Rollback()
+			conn.Close()
+			return nil, SQLiteError(err)
+		}
+	}
+	return &SQLiteTransaction{tx: tx, conn: conn, readOnly: options.ReadOnly}, nil
+}
+
+func (s *SQLiteDatabase) Close() error {
+	return s.db.Close()
+}
+
+// SQLiteTransaction reports failures with the SQLSTATE codes of the matching
+// Postgres errors, so services map both databases' errors alike
+type SQLiteTransaction struct {
+	tx *sql.Tx
+	// conn goes back to the pool when the transaction ends
+	conn     *sql.Conn
+	readOnly bool
+}
+
+func (t *SQLiteTransaction) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	result, err := t.tx.ExecContext(ctx, query, args...)
+	return result, SQLiteError(err)
+}
+
+func (t *SQLiteTransaction) QueryRowContext(ctx context.Context, query string, args ...interface{}) ports.Row {
+	return sqliteRow{row: t.tx.QueryRowContext(ctx, query, args...)}
+}
+
+func (t *SQLiteTransaction) QueryContext(ctx context.Context, query string, args ...interface{}) (ports.Rows, error) {
+	rows, err := t.tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, SQLiteError(err)
+	}
+	return sqliteRows{Rows: rows}, nil
+}
+
+func (t *SQLiteTransaction) Commit() error {
+	defer t.release()
+	return SQLiteError(t.tx.Commit())
+}
+
+func (t *SQLiteTransaction) Rollback() error {
+	defer t.release()
+	return t.tx.Rollback()
+}
+
+// release hands the connection back to the pool writable again
+func (t *SQLiteTransaction) release() {
+	if t.readOnly {
+		t.conn.ExecContext(context.Background(), "PRAGMA query_only = OFF")
+	}
+	t.conn.Close()
+}
+
+// sqliteRow translates the errors QueryRowContext defers to Scan
+type sqliteRow struct {
+	row *sql.Row
+}
+
+func (r sqliteRow) Scan(dest ...interface{}) error {
+	return SQLiteError(r.row.Scan(dest...))
+}
+
+// sqliteRows translates errors met while iterating, e.g. a constraint failing in RETURNING
+type sqliteRows struct {
+	*sql.Rows
+}
+
+func (r sqliteRows) Scan(dest ...interface{}) error {
+	return SQLiteError(r.Rows.Scan(dest...))
+}
+
+func (r sqliteRows) Err() error {
+	return SQLiteError(r.Rows.Err())
+}
+
+// sqliteStateError is a SQLite error carrying the SQLSTATE of its Postgres counterpart
+type sqliteStateError struct {
+	state string
+	err   error
+}
+
+func (e *sqliteStateError) Error() string {
+	return e.err.Error()
+}
+
+func (e *sqliteStateError) SQLState() string {
+	return e.state
+}
+
+func (e *sqliteStateError) Unwrap() error {
+	return e.err
+}
+
+// SQLiteError adds a SQLSTATE to constraint, lock and interrupt errors of
+// SQLite; other errors are returned unchanged
+func SQLiteError(err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+
+	state := ""
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		state = "23505"
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		state = "23503"
+	case sqlite3.SQLITE_CONSTRAINT_CHECK:
+		state = "23514"
+	case sqlite3.SQLITE_CONSTRAINT_NOTNULL:
+		state = "23502"
+	case sqlite3.SQLITE_INTERRUPT:
+		state = "57014"
+	case sqlite3.SQLITE_READONLY:
+		state = "25006"
+	default:
+		// the write lock stayed taken past busy_timeout; retrying may succeed
+		if primary := sqliteErr.Code() & 0xff; primary == sqlite3.SQLITE_BUSY || primary == sqlite3.SQLITE_LOCKED {
+			state = "40001"
+		}
+	}
+	if state == "" {
+		return err
+	}
+	return &sqliteStateError{state: state, err: err}
+}
+
+// compareDecimals orders decimal strings by value; anything else sorts as plain text after them
+func compareDecimals(left, right string) int {
+	a, errA := decimal.NewFromString(left)
+	b, errB := decimal.NewFromString(right)
+	switch {
+	case errA == nil && errB == nil:
+		return a.Cmp(b)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(left, right)
+}
+
+func decimalArg(value driver.Value) (decimal.Decimal, error) {
+	switch v := value.(type) {
+	case string:
+		return decimal.NewFromString(v)
+	case []byte:
+		return decimal.NewFromString(string(v))
+	case int64:
+		return decimal.NewFromInt(v), nil
+	case nil:
+		return decimal.Zero, errors.New("decimal argument is NULL")
+	}
+	return decimal.Zero, fmt.Errorf("unsupported decimal argument %T", value)
+}
+
+// addDecimals implements decimal_add(a, b)
+func addDecimals(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	a, err := decimalArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+	b, err := decimalArg(args[1])
+	if err != nil {
+		return nil, err
+	}
+	return a.Add(b).String(), nil
+}
+
+// decimalSum implements decimal_sum(x); NULLs are skipped and no rows sum to 0
+type decimalSum struct {
+	total decimal.Decimal
+}
+
+func (s *decimalSum) Step(_ *sqlite.FunctionContext, args []driver.Value) error {
+	if args[0] == nil {
+		return nil
+	}
+	value, err := decimalArg(args[0])
+	if err != nil {
+		return err
+	}
+	s.total = s.total.Add(value)
+	return nil
+}
+
+func (s *decimalSum) WindowInverse(_ *sqlite.FunctionContext, args []driver.Value) error {
+	if args[0] == nil {
+		return nil
+	}
+	value, err := decimalArg(args[0])
+	if err != nil {
+		return err
+	}
+	s.total = s.total.Sub(value)
+	return nil
+}
+
+func (s *decimalSum) WindowValue(*sqlite.FunctionContext) (driver.Value, error) {
+	return s.total.String(), nil
+}
+
+func (s *decimalSum) Final(*sqlite.FunctionContext) {}
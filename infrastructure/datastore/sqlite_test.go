@@ -0,0 +1,154 @@
+package datastore_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"transfer-system/domain/ports"
+	"transfer-system/infrastructure/datastore"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openSQLite(t *testing.T, path string) ports.Database {
+	db, err := datastore.NewDatabase("sqlite:"+path, logrus.New())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func sqlStateOf(err error) string {
+	var stateErr interface{ SQLState() string }
+	if errors.As(err, &stateErr) {
+		return stateErr.SQLState()
+	}
+	return ""
+}
+
+func TestNewDatabase_SQLite_MigratesOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transfer.db")
+	db := openSQLite(t, path)
+	require.NoError(t, db.Close())
+
+	// reopening finds the schema in place and applies nothing twice
+	db = openSQLite(t, path)
+	tx, err := db.BeginTx(context.Background())
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	var applied int
+	require.NoError(t, tx.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM schema_migrations").Scan(&applied))
+	assert.Equal(t, 3, applied)
+}
+
+func TestIsSQLite(t *testing.T) {
+	assert.True(t, datastore.IsSQLite("sqlite:transfer.db"))
+	assert.True(t, datastore.IsSQLite("sqlite:///var/lib/transfer/transfer.db"))
+	assert.False(t, datastore.IsSQLite("postgres://postgres@localhost:5432/transfer"))
+}
+
+func TestSQLite_DecimalsAreExact(t *testing.T) {
+	db := openSQLite(t, filepath.Join(t.TempDir(), "transfer.db"))
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE amounts (amount TEXT NOT NULL COLLATE decimal)")
+	require.NoError(t, err)
+	for _, amount := range []string{"0.1", "0.2", "10", "9.99", "-1"} {
+		_, err = tx.ExecContext(ctx, "INSERT INTO amounts (amount) VALUES (?)", amount)
+		require.NoError(t, err)
+	}
+
+	// floating point would give 19.290000000000003
+	var total string
+	require.NoError(t, tx.QueryRowContext(ctx, "SELECT decimal_sum(amount) FROM amounts").Scan(&total))
+	assert.Equal(t, "19.29", total)
+
+	var sum string
+	require.NoError(t, tx.QueryRowContext(ctx, "SELECT decimal_add('0.1', '0.2')").Scan(&sum))
+	assert.Equal(t, "0.3", sum)
+
+	// text would order 10 before 9.99
+	rows, err := tx.QueryContext(ctx, "SELECT amount FROM amounts WHERE amount >= '0' ORDER BY amount")
+	require.NoError(t, err)
+	defer rows.Close()
+	ordered := []string{}
+	for rows.Next() {
+		var amount string
+		require.NoError(t, rows.Scan(&amount))
+		ordered = append(ordered, amount)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{"0.1", "0.2", "9.99", "10"}, ordered)
+
+	var empty string
+	require.NoError(t, tx.QueryRowContext(ctx, "SELECT decimal_sum(amount) FROM amounts WHERE amount > '100'").Scan(&empty))
+	assert.Equal(t, "0", empty)
+}
+
+func TestSQLite_ErrorsCarrySQLState(t *testing.T) {
+	db := openSQLite(t, filepath.Join(t.TempDir(), "transfer.db"))
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	insert := "INSERT INTO accounts (id, balance, owner_id, created_at) VALUES (?, ?, ?, '2024-01-01 00:00:00.000000')"
+	_, err = tx.ExecContext(ctx, insert, 1, "10", nil)
+	require.NoError(t, err)
+
+	_, err = tx.ExecContext(ctx, insert, 1, "10", nil)
+	assert.Equal(t, "23505", sqlStateOf(err))
+
+	_, err = tx.ExecContext(ctx, insert, 2, "10", 99)
+	assert.Equal(t, "23503", sqlStateOf(err))
+
+	_, err = tx.ExecContext(ctx, insert, 3, "-0.01", nil)
+	assert.Equal(t, "23514", sqlStateOf(err))
+}
+
+func TestSQLite_CancelledContext(t *testing.T) {
+	db := openSQLite(t, filepath.Join(t.TempDir(), "transfer.db"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := db.BeginTx(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestSQLite_ReadOnlyTransaction(t *testing.T) {
+	db := openSQLite(t, filepath.Join(t.TempDir(), "transfer.db"))
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, ports.TxOptions{ReadOnly: true})
+	require.NoError(t, err)
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO accounts (id, balance, created_at) VALUES (1, '10', '2024-01-01 00:00:00.000000')")
+	assert.Equal(t, "25006", sqlStateOf(err))
+	require.NoError(t, tx.Rollback())
+
+	// the connection is writable again for the next transaction
+	tx, err = db.BeginTx(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	_, err = tx.ExecContext(ctx, "INSERT INTO accounts (id, balance, created_at) VALUES (1, '10', '2024-01-01 00:00:00.000000')")
+	assert.NoError(t, err)
+}
+
+func TestSQLite_LockTimeout(t *testing.T) {
+	db := openSQLite(t, filepath.Join(t.TempDir(), "transfer.db"))
+	ctx := context.Background()
+	owner, err := db.BeginTx(ctx)
+	require.NoError(t, err)
+	defer owner.Rollback()
+
+	// the only connection is held by owner
+	_, err = db.BeginTx(ctx, ports.TxOptions{LockTimeout: 10 * time.Millisecond})
+	assert.Equal(t, "55P03", sqlStateOf(err))
+}
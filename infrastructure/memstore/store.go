@@ -0,0 +1,326 @@
+// Package memstore keeps the data of the memory repositories. It implements
+// ports.Database with transactions that buffer their writes until commit and
+// lock rows until they end, like SELECT ... FOR UPDATE in Postgres
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"transfer-system/domain/ports"
+)
+
+// compile-time interface check
+var _ ports.Transaction = (*Tx)(nil)
+var _ ports.Database = (*Store)(nil)
+
+// ErrNoSQL is returned by the SQL methods of Tx; use the memory repositories instead
+var ErrNoSQL = errors.New("memstore: SQL is not supported")
+
+var errReadOnly = &Error{State: StateReadOnlyTransaction, Message: "cannot write or lock in a read-only transaction"}
+
+// SQLSTATE codes of the failures the store reports, so services map them as they do Postgres errors
+const (
+	StateUniqueViolation     = "23505"
+	StateForeignKeyViolation = "23503"
+	StateCheckViolation      = "23514"
+	StateDeadlockDetected    = "40P01"
+	StateLockNotAvailable    = "55P03"
+	StateReadOnlyTransaction = "25006"
+)
+
+// Error is a constraint or concurrency failure
+type Error struct {
+	State   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return "memstore: " + e.Message
+}
+
+// SQLState returns the SQLSTATE code Postgres reports for the same failure
+func (e *Error) SQLState() string {
+	return e.State
+}
+
+type lockKey struct {
+	table string
+	key   any
+}
+
+// deleted marks a row removed by a transaction that has not committed yet
+type deleted struct{}
+
+// Store holds committed rows by table and key. Reads see committed rows plus
+// the transaction's own writes (read committed)
+type Store struct {
+	mu        sync.Mutex
+	tables    map[string]map[any]any
+	sequences map[string]int64
+	locks     map[lockKey]*Tx
+}
+
+func New() *Store {
+	return &Store{
+		tables:    map[string]map[any]any{},
+		sequences: map[string]int64{},
+		locks:     map[lockKey]*Tx{},
+	}
+}
+
+// BeginTx starts a transaction. Reads are always read committed, whatever
+// the isolation level asked for; read-only transactions fail on writes and
+// locks as Postgres does
+func (s *Store) BeginTx(ctx context.Context, opts ...ports.TxOptions) (ports.Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var options ports.TxOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	return &Tx{
+		store:       s,
+		writes:      map[string]map[any]any{},
+		done:        make(chan struct{}),
+		startAt:     time.Now().UTC().Truncate(time.Microsecond),
+		readOnly:    options.ReadOnly,
+		lockTimeout: options.LockTimeout,
+	}, nil
+}
+
+func (s *Store) Close() error {
+	return nil
+}
+
+// Tx is a transaction of the store
+type Tx struct {
+	store  *Store
+	writes map[string]map[any]any
+	held   []lockKey
+	// done is closed when the transaction ends and its locks are released
+	done     chan struct{}
+	finished bool
+	// waitingFor is the transaction holding the lock this one waits for
+	waitingFor  *Tx
+	startAt     time.Time
+	readOnly    bool
+	lockTimeout time.Duration
+}
+
+// Unwrap returns the memory transaction behind tx
+func Unwrap(tx ports.Transaction) (*Tx, error) {
+	memoryTx, ok := tx.(*Tx)
+	if !ok {
+		return nil, errors.New("memstore: not a memory transaction")
+	}
+	return memoryTx, nil
+}
+
+func (t *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, ErrNoSQL
+}
+
+// QueryRowContext returns a row whose Scan fails with ErrNoSQL
+func (t *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) ports.Row {
+	return noSQLRow{}
+}
+
+func (t *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (ports.Rows, error) {
+	return nil, ErrNoSQL
+}
+
+// noSQLRow is the row of a query the memory store cannot run
+type noSQLRow struct{}
+
+func (noSQLRow) Scan(dest ...interface{}) error {
+	return ErrNoSQL
+}
+
+// Now is the start time of the transaction, as CURRENT_TIMESTAMP in Postgres
+func (t *Tx) Now() time.Time {
+	return t.startAt
+}
+
+// Get returns the row stored under key in table
+func (t *Tx) Get(table string, key any) (any, bool) {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	if row, written := t.writes[table][key]; written {
+		_, isDeleted := row.(deleted)
+		return row, !isDeleted
+	}
+	row, ok := t.store.tables[table][key]
+	return row, ok
+}
+
+// Rows returns every row of table, in no particular order
+func (t *Tx) Rows(table string) []any {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	rows := make([]any, 0, len(t.store.tables[table])+len(t.writes[table]))
+	for key, row := range t.store.tables[table] {
+		if _, written := t.writes[table][key]; !written {
+			rows = append(rows, row)
+		}
+	}
+	for _, row := range t.writes[table] {
+		if _, isDeleted := row.(deleted); !isDeleted {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// Put stores row under key in table once the transaction commits. Rows are
+// shared with later readers, so callers must not modify them afterwards
+func (t *Tx) Put(table string, key any, row any) error {
+	return t.write(table, key, row)
+}
+
+// Delete removes the row under key from table once the transaction commits
+func (t *Tx) Delete(table string, key any) error {
+	return t.write(table, key, deleted{})
+}
+
+func (t *Tx) write(table string, key any, row any) error {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	if t.finished {
+		return sql.ErrTxDone
+	}
+	if t.readOnly {
+		return errReadOnly
+	}
+	if t.writes[table] == nil {
+		t.writes[table] = map[any]any{}
+	}
+	t.writes[table][key] = row
+	return nil
+}
+
+// NextVal returns the next value of the sequence. Like Postgres sequences it
+// is not rolled back with the transaction
+func (t *Tx) NextVal(sequence string) int64 {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	t.store.sequences[sequence]++
+	return t.store.sequences[sequence]
+}
+
+// Lock takes the lock on key in table until the transaction ends, waiting
+// while another transaction holds it. Keys need not exist, so unique values
+// can be locked before they are checked and inserted. A wait longer than the
+// LockTimeout of the transaction fails with StateLockNotAvailable
+func (t *Tx) Lock(ctx context.Context, table string, key any) error {
+	if t.readOnly {
+		return errReadOnly
+	}
+	s := t.store
+	lock := lockKey{table: table, key: key}
+	var timeout <-chan time.Time
+	if t.lockTimeout > 0 {
+		timer := time.NewTimer(t.lockTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	for {
+		s.mu.Lock()
+		if t.finished {
+			s.mu.Unlock()
+			return sql.ErrTxDone
+		}
+		owner, locked := s.locks[lock]
+		if !locked || owner == t {
+			if !locked {
+				s.locks[lock] = t
+				t.held = append(t.held, lock)
+			}
+			t.waitingFor = nil
+			s.mu.Unlock()
+			return nil
+		}
+
+		// waiting would close a cycle of transactions waiting for each other
+		for blocker := owner; blocker != nil && !blocker.finished; blocker = blocker.waitingFor {
+			if blocker == t {
+				t.waitingFor = nil
+				s.mu.Unlock()
+				return &Error{State: StateDeadlockDetected, Message: "deadlock detected"}
+			}
+		}
+		t.waitingFor = owner
+		released := owner.done
+		s.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-timeout:
+			s.mu.Lock()
+			t.waitingFor = nil
+			s.mu.Unlock()
+			return &Error{State: StateLockNotAvailable, Message: "lock timeout"}
+		case <-ctx.Done():
+			s.mu.Lock()
+			t.waitingFor = nil
+			s.mu.Unlock()
+			return ctx.Err()
+		}
+	}
+}
+
+func (t *Tx) Commit() error {
+	s := t.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t.finished {
+		return sql.ErrTxDone
+	}
+	for table, rows := range t.writes {
+		if s.tables[table] == nil {
+			s.tables[table] = map[any]any{}
+		}
+		for key, row := range rows {
+			if _, isDeleted := row.(deleted); isDeleted {
+				delete(s.tables[table], key)
+				continue
+			}
+			s.tables[table][key] = row
+		}
+	}
+	t.end()
+	return nil
+}
+
+func (t *Tx) Rollback() error {
+	s := t.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t.finished {
+		return sql.ErrTxDone
+	}
+	t.end()
+	return nil
+}
+
+// end releases the locks and wakes the transactions waiting for them; the store mutex must be held
+func (t *Tx) end() {
+	for _, lock := range t.held {
+		delete(t.store.locks, lock)
+	}
+	t.held = nil
+	t.writes = nil
+	t.waitingFor = nil
+	t.finished = true
+	close(t.done)
+}
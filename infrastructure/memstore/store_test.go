@@ -0,0 +1,206 @@
+package memstore_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"transfer-system/domain/ports"
+	"transfer-system/infrastructure/memstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func begin(t *testing.T, store *memstore.Store) *memstore.Tx {
+	tx, err := store.BeginTx(context.Background())
+	require.NoError(t, err)
+	memoryTx, err := memstore.Unwrap(tx)
+	require.NoError(t, err)
+	return memoryTx
+}
+
+func TestStore_Commit_MakesWritesVisible(t *testing.T) {
+	store := memstore.New()
+	writer := begin(t, store)
+	reader := begin(t, store)
+	defer reader.Rollback()
+
+	require.NoError(t, writer.Put("accounts", int64(1), "alice"))
+
+	// own writes are visible, uncommitted writes of others are not
+	row, ok := writer.Get("accounts", int64(1))
+	assert.True(t, ok)
+	assert.Equal(t, "alice", row)
+	_, ok = reader.Get("accounts", int64(1))
+	assert.False(t, ok)
+
+	require.NoError(t, writer.Commit())
+
+	row, ok = reader.Get("accounts", int64(1))
+	assert.True(t, ok)
+	assert.Equal(t, "alice", row)
+	assert.Equal(t, []any{"alice"}, reader.Rows("accounts"))
+}
+
+func TestStore_Rollback_DiscardsWrites(t *testing.T) {
+	store := memstore.New()
+	seed := begin(t, store)
+	require.NoError(t, seed.Put("accounts", int64(1), "alice"))
+	require.NoError(t, seed.Commit())
+
+	tx := begin(t, store)
+	require.NoError(t, tx.Put("accounts", int64(2), "bob"))
+	require.NoError(t, tx.Delete("accounts", int64(1)))
+	assert.Equal(t, []any{"bob"}, tx.Rows("accounts"))
+	_, ok := tx.Get("accounts", int64(1))
+	assert.False(t, ok)
+	require.NoError(t, tx.Rollback())
+
+	after := begin(t, store)
+	defer after.Rollback()
+	assert.Equal(t, []any{"alice"}, after.Rows("accounts"))
+}
+
+func TestStore_EndedTransaction(t *testing.T) {
+	store := memstore.New()
+	tx := begin(t, store)
+	require.NoError(t, tx.Commit())
+
+	assert.ErrorIs(t, tx.Commit(), sql.ErrTxDone)
+	assert.ErrorIs(t, tx.Rollback(), sql.ErrTxDone)
+	assert.ErrorIs(t, tx.Put("accounts", int64(1), "alice"), sql.ErrTxDone)
+	assert.ErrorIs(t, tx.Lock(context.Background(), "accounts", int64(1)), sql.ErrTxDone)
+}
+
+func TestStore_BeginTx_CancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := memstore.New().BeginTx(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestStore_Lock_WaitsForOwner(t *testing.T) {
+	store := memstore.New()
+	first := begin(t, store)
+	second := begin(t, store)
+	defer second.Rollback()
+
+	require.NoError(t, first.Lock(context.Background(), "accounts", int64(1)))
+	// taking a lock twice does not wait for yourself
+	require.NoError(t, first.Lock(context.Background(), "accounts", int64(1)))
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- second.Lock(context.Background(), "accounts", int64(1))
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("lock acquired while another transaction holds it")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	require.NoError(t, first.Put("accounts", int64(1), "alice"))
+	require.NoError(t, first.Commit())
+	require.NoError(t, <-acquired)
+
+	// the waiter sees the committed row once it holds the lock
+	row, ok := second.Get("accounts", int64(1))
+	assert.True(t, ok)
+	assert.Equal(t, "alice", row)
+}
+
+func TestStore_Lock_ContextTimeout(t *testing.T) {
+	store := memstore.New()
+	owner := begin(t, store)
+	defer owner.Rollback()
+	waiter := begin(t, store)
+	defer waiter.Rollback()
+
+	require.NoError(t, owner.Lock(context.Background(), "accounts", int64(1)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := waiter.Lock(ctx, "accounts", int64(1))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestStore_Lock_DetectsDeadlock(t *testing.T) {
+	store := memstore.New()
+	first := begin(t, store)
+	second := begin(t, store)
+	defer second.Rollback()
+
+	require.NoError(t, first.Lock(context.Background(), "accounts", int64(1)))
+	require.NoError(t, second.Lock(context.Background(), "accounts", int64(2)))
+
+	waiting := make(chan error)
+	go func() {
+		waiting <- first.Lock(context.Background(), "accounts", int64(2))
+	}()
+	// let first start waiting for second
+	time.Sleep(20 * time.Millisecond)
+
+	err := second.Lock(context.Background(), "accounts", int64(1))
+	var storeErr *memstore.Error
+	require.True(t, errors.As(err, &storeErr))
+	assert.Equal(t, memstore.StateDeadlockDetected, storeErr.SQLState())
+
+	// the victim rolls back, letting the other transaction go on
+	require.NoError(t, second.Rollback())
+	require.NoError(t, <-waiting)
+	require.NoError(t, first.Commit())
+}
+
+func TestStore_NextVal_SurvivesRollback(t *testing.T) {
+	store := memstore.New()
+	tx := begin(t, store)
+	assert.Equal(t, int64(1), tx.NextVal("accounts_id_seq"))
+	require.NoError(t, tx.Rollback())
+
+	next := begin(t, store)
+	defer next.Rollback()
+	assert.Equal(t, int64(2), next.NextVal("accounts_id_seq"))
+}
+
+func TestUnwrap_OtherTransaction(t *testing.T) {
+	var tx ports.Transaction
+	_, err := memstore.Unwrap(tx)
+	assert.Error(t, err)
+}
+
+func TestStore_ReadOnlyTransaction(t *testing.T) {
+	tx, err := memstore.New().BeginTx(context.Background(), ports.TxOptions{ReadOnly: true})
+	require.NoError(t, err)
+	memoryTx, err := memstore.Unwrap(tx)
+	require.NoError(t, err)
+	defer memoryTx.Rollback()
+
+	var storeErr *memstore.Error
+	require.True(t, errors.As(memoryTx.Put("accounts", int64(1), "alice"), &storeErr))
+	assert.Equal(t, memstore.StateReadOnlyTransaction, storeErr.SQLState())
+	require.True(t, errors.As(memoryTx.Lock(context.Background(), "accounts", int64(1)), &storeErr))
+	assert.Equal(t, memstore.StateReadOnlyTransaction, storeErr.SQLState())
+}
+
+func TestStore_Lock_LockTimeout(t *testing.T) {
+	store := memstore.New()
+	owner := begin(t, store)
+	defer owner.Rollback()
+	tx, err := store.BeginTx(context.Background(), ports.TxOptions{LockTimeout: 10 * time.Millisecond})
+	require.NoError(t, err)
+	waiter, err := memstore.Unwrap(tx)
+	require.NoError(t, err)
+	defer waiter.Rollback()
+
+	require.NoError(t, owner.Lock(context.Background(), "accounts", int64(1)))
+
+	err = waiter.Lock(context.Background(), "accounts", int64(1))
+	var storeErr *memstore.Error
+	require.True(t, errors.As(err, &storeErr))
+	assert.Equal(t, memstore.StateLockNotAvailable, storeErr.SQLState())
+}
@@ -2,6 +2,8 @@ package testutils
 
 import (
 	"context"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
 	"transfer-system/pkg/logger"
 
 	"github.com/labstack/echo/v4"
@@ -13,3 +15,9 @@ func InjectLoggerToContext(ctx echo.Context) {
 	newCtx := context.WithValue(ctx.Request().Context(), logger.LoggerContextKey, baseLogger)
 	ctx.SetRequest(ctx.Request().WithContext(newCtx))
 }
+
+// InjectPrincipalToContext authenticates the request as BearerTokenMiddleware would
+func InjectPrincipalToContext(ctx echo.Context, principal *entities.Principal) {
+	newCtx := context.WithValue(ctx.Request().Context(), ports.PrincipalContextKey, principal)
+	ctx.SetRequest(ctx.Request().WithContext(newCtx))
+}
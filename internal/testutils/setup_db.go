@@ -20,7 +20,8 @@ func SetupTestDB(t *testing.T) ports.Database {
 	require.NotEmpty(t, dbURL, "DB_URL must be set in .env.test")
 
 	baseLogger := logrus.New()
-	db, err := datastore.NewDatabase(dbURL, baseLogger)
+	// DB_DRIVER=pgx runs the suite through the pgx pool, COPY included
+	db, err := datastore.Open(dbURL, os.Getenv("DB_DRIVER"), baseLogger)
 	require.NoError(t, err, "failed to connect to test database")
 
 	return db
@@ -18,8 +18,49 @@ func (m *MockAccountRepository) FindById(ctx context.Context, tx ports.Transacti
 	return account, args.Error(1)
 }
 
+func (m *MockAccountRepository) FindByIdForUpdate(ctx context.Context, tx ports.Transaction, id int64) (*entities.Account, error) {
+	args := m.Called(ctx, tx, id)
+	account, _ := args.Get(0).(*entities.Account)
+	return account, args.Error(1)
+}
+
 func (m *MockAccountRepository) Save(ctx context.Context, tx ports.Transaction, acc *entities.Account) (*entities.Account, error) {
 	args := m.Called(ctx, tx, acc)
 	account, _ := args.Get(0).(*entities.Account)
 	return account, args.Error(1)
 }
+
+func (m *MockAccountRepository) SaveMany(ctx context.Context, tx ports.Transaction, accounts []*entities.Account) error {
+	args := m.Called(ctx, tx, accounts)
+	return args.Error(0)
+}
+
+func (m *MockAccountRepository) FindByExternalReference(ctx context.Context, tx ports.Transaction, reference string) (*entities.Account, error) {
+	args := m.Called(ctx, tx, reference)
+	account, _ := args.Get(0).(*entities.Account)
+	return account, args.Error(1)
+}
+
+func (m *MockAccountRepository) List(ctx context.Context, tx ports.Transaction, filter entities.AccountFilter) (*entities.AccountPage, error) {
+	args := m.Called(ctx, tx, filter)
+	page, _ := args.Get(0).(*entities.AccountPage)
+	return page, args.Error(1)
+}
+
+func (m *MockAccountRepository) Update(ctx context.Context, tx ports.Transaction, acc *entities.Account) (*entities.Account, error) {
+	args := m.Called(ctx, tx, acc)
+	account, _ := args.Get(0).(*entities.Account)
+	return account, args.Error(1)
+}
+
+func (m *MockAccountRepository) SumByCurrency(ctx context.Context, tx ports.Transaction) ([]*entities.CurrencyTotal, error) {
+	args := m.Called(ctx, tx)
+	totals, _ := args.Get(0).([]*entities.CurrencyTotal)
+	return totals, args.Error(1)
+}
+
+func (m *MockAccountRepository) SystemAccount(ctx context.Context, tx ports.Transaction, kind entities.AccountKind, currency string) (*entities.Account, error) {
+	args := m.Called(ctx, tx, kind, currency)
+	account, _ := args.Get(0).(*entities.Account)
+	return account, args.Error(1)
+}
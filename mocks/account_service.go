@@ -11,12 +11,31 @@ type MockAccountService struct {
 	mock.Mock
 }
 
-func (m *MockAccountService) Save(ctx context.Context, acc *entities.Account) error {
+func (m *MockAccountService) Save(ctx context.Context, acc *entities.Account) (*entities.Account, error) {
 	args := m.Called(ctx, acc)
-	return args.Error(0)
+	account, _ := args.Get(0).(*entities.Account)
+	return account, args.Error(1)
 }
 
 func (m *MockAccountService) FindById(ctx context.Context, accountId int64) (*entities.Account, error) {
 	args := m.Called(ctx, accountId)
 	return args.Get(0).(*entities.Account), args.Error(1)
 }
+
+func (m *MockAccountService) FindByExternalReference(ctx context.Context, reference string) (*entities.Account, error) {
+	args := m.Called(ctx, reference)
+	account, _ := args.Get(0).(*entities.Account)
+	return account, args.Error(1)
+}
+
+func (m *MockAccountService) List(ctx context.Context, filter entities.AccountFilter) (*entities.AccountPage, error) {
+	args := m.Called(ctx, filter)
+	page, _ := args.Get(0).(*entities.AccountPage)
+	return page, args.Error(1)
+}
+
+func (m *MockAccountService) Update(ctx context.Context, id int64, patch entities.AccountPatch) (*entities.Account, error) {
+	args := m.Called(ctx, id, patch)
+	account, _ := args.Get(0).(*entities.Account)
+	return account, args.Error(1)
+}
@@ -0,0 +1,25 @@
+package mocks
+
+import (
+	"context"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Save(ctx context.Context, tx ports.Transaction, key *entities.APIKey) (*entities.APIKey, error) {
+	args := m.Called(ctx, tx, key)
+	saved, _ := args.Get(0).(*entities.APIKey)
+	return saved, args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) FindByHash(ctx context.Context, tx ports.Transaction, hash string) (*entities.APIKey, error) {
+	args := m.Called(ctx, tx, hash)
+	key, _ := args.Get(0).(*entities.APIKey)
+	return key, args.Error(1)
+}
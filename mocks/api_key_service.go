@@ -0,0 +1,24 @@
+package mocks
+
+import (
+	"context"
+	"transfer-system/domain/entities"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) Issue(ctx context.Context, name string, signing bool) (*entities.APIKey, string, error) {
+	args := m.Called(ctx, name, signing)
+	key, _ := args.Get(0).(*entities.APIKey)
+	return key, args.String(1), args.Error(2)
+}
+
+func (m *MockAPIKeyService) Authenticate(ctx context.Context, secret string) (*entities.APIKey, error) {
+	args := m.Called(ctx, secret)
+	key, _ := args.Get(0).(*entities.APIKey)
+	return key, args.Error(1)
+}
@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"context"
+	"time"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBalanceService struct {
+	mock.Mock
+}
+
+func (m *MockBalanceService) BalanceAsOf(ctx context.Context, accountID int64, asOf time.Time) (*entities.AccountBalance, error) {
+	args := m.Called(ctx, accountID, asOf)
+	balance, _ := args.Get(0).(*entities.AccountBalance)
+	return balance, args.Error(1)
+}
+
+func (m *MockBalanceService) CreateSnapshots(ctx context.Context, asOf time.Time) (int, error) {
+	args := m.Called(ctx, asOf)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockBalanceService) Statement(ctx context.Context, accountID int64, from, to time.Time, writer ports.StatementWriter) error {
+	args := m.Called(ctx, accountID, from, to, writer)
+	return args.Error(0)
+}
@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"context"
+	"time"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBalanceSnapshotRepository struct {
+	mock.Mock
+}
+
+func (m *MockBalanceSnapshotRepository) Save(ctx context.Context, tx ports.Transaction, snapshot *entities.BalanceSnapshot) error {
+	args := m.Called(ctx, tx, snapshot)
+	return args.Error(0)
+}
+
+func (m *MockBalanceSnapshotRepository) FindLatest(ctx context.Context, tx ports.Transaction, accountID int64, asOf time.Time) (*entities.BalanceSnapshot, error) {
+	args := m.Called(ctx, tx, accountID, asOf)
+	snapshot, _ := args.Get(0).(*entities.BalanceSnapshot)
+	return snapshot, args.Error(1)
+}
+
+func (m *MockBalanceSnapshotRepository) ListStaleAccounts(ctx context.Context, tx ports.Transaction, asOf time.Time, afterID int64, limit int) ([]int64, error) {
+	args := m.Called(ctx, tx, asOf, afterID, limit)
+	ids, _ := args.Get(0).([]int64)
+	return ids, args.Error(1)
+}
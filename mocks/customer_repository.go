@@ -0,0 +1,42 @@
+package mocks
+
+import (
+	"context"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCustomerRepository struct {
+	mock.Mock
+}
+
+func (m *MockCustomerRepository) Save(ctx context.Context, tx ports.Transaction, cust *entities.Customer) (*entities.Customer, error) {
+	args := m.Called(ctx, tx, cust)
+	customer, _ := args.Get(0).(*entities.Customer)
+	return customer, args.Error(1)
+}
+
+func (m *MockCustomerRepository) FindById(ctx context.Context, tx ports.Transaction, id int64) (*entities.Customer, error) {
+	args := m.Called(ctx, tx, id)
+	customer, _ := args.Get(0).(*entities.Customer)
+	return customer, args.Error(1)
+}
+
+func (m *MockCustomerRepository) FindByIdForUpdate(ctx context.Context, tx ports.Transaction, id int64) (*entities.Customer, error) {
+	args := m.Called(ctx, tx, id)
+	customer, _ := args.Get(0).(*entities.Customer)
+	return customer, args.Error(1)
+}
+
+func (m *MockCustomerRepository) Update(ctx context.Context, tx ports.Transaction, cust *entities.Customer) (*entities.Customer, error) {
+	args := m.Called(ctx, tx, cust)
+	customer, _ := args.Get(0).(*entities.Customer)
+	return customer, args.Error(1)
+}
+
+func (m *MockCustomerRepository) Delete(ctx context.Context, tx ports.Transaction, id int64) error {
+	args := m.Called(ctx, tx, id)
+	return args.Error(0)
+}
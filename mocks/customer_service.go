@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"context"
+	"transfer-system/domain/entities"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCustomerService struct {
+	mock.Mock
+}
+
+func (m *MockCustomerService) Save(ctx context.Context, cust *entities.Customer) (*entities.Customer, error) {
+	args := m.Called(ctx, cust)
+	customer, _ := args.Get(0).(*entities.Customer)
+	return customer, args.Error(1)
+}
+
+func (m *MockCustomerService) FindById(ctx context.Context, id int64) (*entities.Customer, error) {
+	args := m.Called(ctx, id)
+	customer, _ := args.Get(0).(*entities.Customer)
+	return customer, args.Error(1)
+}
+
+func (m *MockCustomerService) Update(ctx context.Context, cust *entities.Customer) (*entities.Customer, error) {
+	args := m.Called(ctx, cust)
+	customer, _ := args.Get(0).(*entities.Customer)
+	return customer, args.Error(1)
+}
+
+func (m *MockCustomerService) Delete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
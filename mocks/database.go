@@ -19,8 +19,8 @@ func (m *MockRow) Scan(dest ...interface{}) error {
 	return nil
 }
 
-func NewMockRowWithScan(scanFunc func(dest ...interface{}) error) *sql.Row {
-	return &sql.Row{}
+func NewMockRowWithScan(scanFunc func(dest ...interface{}) error) *MockRow {
+	return &MockRow{scanFunc: scanFunc}
 }
 
 type MockTransaction struct {
@@ -36,22 +36,23 @@ func (m *MockTransaction) ExecContext(ctx context.Context, query string, args ..
 	return argsM.Get(0).(sql.Result), argsM.Error(1)
 }
 
-func (m *MockTransaction) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+func (m *MockTransaction) QueryRowContext(ctx context.Context, query string, args ...interface{}) ports.Row {
 	callArgs := make([]interface{}, 0, len(args)+2)
 	callArgs = append(callArgs, ctx, query)
 	callArgs = append(callArgs, args...)
 
 	argsM := m.Called(callArgs...)
-	return argsM.Get(0).(*sql.Row)
+	return argsM.Get(0).(ports.Row)
 }
 
-func (m *MockTransaction) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+func (m *MockTransaction) QueryContext(ctx context.Context, query string, args ...interface{}) (ports.Rows, error) {
 	callArgs := make([]interface{}, 0, len(args)+2)
 	callArgs = append(callArgs, ctx, query)
 	callArgs = append(callArgs, args...)
 
 	argsM := m.Called(callArgs...)
-	return argsM.Get(0).(*sql.Rows), argsM.Error(1)
+	rows, _ := argsM.Get(0).(ports.Rows)
+	return rows, argsM.Error(1)
 }
 
 func (m *MockTransaction) Commit() error {
@@ -66,8 +67,13 @@ type MockDatabase struct {
 	mock.Mock
 }
 
-func (m *MockDatabase) BeginTx(ctx context.Context) (ports.Transaction, error) {
-	args := m.Called(ctx)
+// BeginTx records the options as a second argument when there are any
+func (m *MockDatabase) BeginTx(ctx context.Context, opts ...ports.TxOptions) (ports.Transaction, error) {
+	callArgs := []interface{}{ctx}
+	for _, options := range opts {
+		callArgs = append(callArgs, options)
+	}
+	args := m.Called(callArgs...)
 	return args.Get(0).(ports.Transaction), args.Error(1)
 }
 
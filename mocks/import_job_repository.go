@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"context"
+	"transfer-system/domain/entities"
+	"transfer-system/domain/ports"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockImportJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockImportJobRepository) Save(ctx context.Context, tx ports.Transaction, job *entities.ImportJob) (*entities.ImportJob, error) {
+	args := m.Called(ctx, tx, job)
+	saved, _ := args.Get(0).(*entities.ImportJob)
+	return saved, args.Error(1)
+}
+
+func (m *MockImportJobRepository) FindById(ctx context.Context, tx ports.Transaction, id string) (*entities.ImportJob, error) {
+	args := m.Called(ctx, tx, id)
+	job, _ := args.Get(0).(*entities.ImportJob)
+	return job, args.Error(1)
+}
+
+func (m *MockImportJobRepository) Update(ctx context.Context, tx ports.Transaction, job *entities.ImportJob) error {
+	args := m.Called(ctx, tx, job)
+	return args.Error(0)
+}
@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"context"
+	"transfer-system/domain/entities"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockImportService struct {
+	mock.Mock
+}
+
+func (m *MockImportService) Validate(ctx context.Context, file *entities.ImportFile) ([]entities.ImportRowError, error) {
+	args := m.Called(ctx, file)
+	rowErrors, _ := args.Get(0).([]entities.ImportRowError)
+	return rowErrors, args.Error(1)
+}
+
+func (m *MockImportService) Import(ctx context.Context, file *entities.ImportFile, jobID string) (*entities.ImportJob, error) {
+	args := m.Called(ctx, file, jobID)
+	job, _ := args.Get(0).(*entities.ImportJob)
+	return job, args.Error(1)
+}
+
+func (m *MockImportService) FindJob(ctx context.Context, id string) (*entities.ImportJob, error) {
+	args := m.Called(ctx, id)
+	job, _ := args.Get(0).(*entities.ImportJob)
+	return job, args.Error(1)
+}
@@ -0,0 +1,18 @@
+package mocks
+
+import (
+	"context"
+
+	"transfer-system/domain/entities"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLedgerReporter struct {
+	mock.Mock
+}
+
+func (m *MockLedgerReporter) Report(ctx context.Context, report *entities.LedgerReport) error {
+	args := m.Called(ctx, report)
+	return args.Error(0)
+}
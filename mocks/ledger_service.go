@@ -0,0 +1,18 @@
+package mocks
+
+import (
+	"context"
+	"transfer-system/domain/entities"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLedgerService struct {
+	mock.Mock
+}
+
+func (m *MockLedgerService) Verify(ctx context.Context) (*entities.LedgerReport, error) {
+	args := m.Called(ctx)
+	report, _ := args.Get(0).(*entities.LedgerReport)
+	return report, args.Error(1)
+}
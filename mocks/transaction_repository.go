@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 	"transfer-system/domain/entities"
 	"transfer-system/domain/ports"
 
@@ -19,7 +20,46 @@ func (m *MockTransactionRepository) Save(ctx context.Context, tx ports.Transacti
 	return transaction, args.Error(1)
 }
 
+func (m *MockTransactionRepository) SaveMany(ctx context.Context, tx ports.Transaction, transactions []*entities.Transaction) error {
+	args := m.Called(ctx, tx, transactions)
+	return args.Error(0)
+}
+
 func (m *MockTransactionRepository) UpdateBalance(ctx context.Context, tx ports.Transaction, accId int64, amount decimal.Decimal) error {
 	args := m.Called(ctx, tx, accId, amount)
 	return args.Error(0)
 }
+
+func (m *MockTransactionRepository) SumOutgoingByOwner(ctx context.Context, tx ports.Transaction, ownerID int64, since time.Time) (decimal.Decimal, error) {
+	args := m.Called(ctx, tx, ownerID, since)
+	return args.Get(0).(decimal.Decimal), args.Error(1)
+}
+
+func (m *MockTransactionRepository) FindById(ctx context.Context, tx ports.Transaction, id int64) (*entities.Transaction, error) {
+	args := m.Called(ctx, tx, id)
+	transaction, _ := args.Get(0).(*entities.Transaction)
+	return transaction, args.Error(1)
+}
+
+func (m *MockTransactionRepository) ListByAccount(ctx context.Context, tx ports.Transaction, filter entities.TransactionFilter) (*entities.TransactionPage, error) {
+	args := m.Called(ctx, tx, filter)
+	page, _ := args.Get(0).(*entities.TransactionPage)
+	return page, args.Error(1)
+}
+
+func (m *MockTransactionRepository) NetMovement(ctx context.Context, tx ports.Transaction, accountID int64, after *time.Time, until time.Time) (decimal.Decimal, error) {
+	args := m.Called(ctx, tx, accountID, after, until)
+	return args.Get(0).(decimal.Decimal), args.Error(1)
+}
+
+// StreamByAccount feeds the transactions returned by the expectation to fn
+func (m *MockTransactionRepository) StreamByAccount(ctx context.Context, tx ports.Transaction, accountID int64, after, until time.Time, fn func(*entities.Transaction) error) error {
+	args := m.Called(ctx, tx, accountID, after, until, fn)
+	transactions, _ := args.Get(0).([]*entities.Transaction)
+	for _, transaction := range transactions {
+		if err := fn(transaction); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
@@ -11,7 +11,32 @@ type MockTransactionService struct {
 	mock.Mock
 }
 
-func (m *MockTransactionService) Save(ctx context.Context, req *entities.Transaction) error {
+func (m *MockTransactionService) Save(ctx context.Context, req *entities.Transaction) (*entities.Transaction, error) {
 	args := m.Called(ctx, req)
-	return args.Error(0)
+	transaction, _ := args.Get(0).(*entities.Transaction)
+	return transaction, args.Error(1)
+}
+
+func (m *MockTransactionService) FindById(ctx context.Context, id int64) (*entities.Transaction, error) {
+	args := m.Called(ctx, id)
+	transaction, _ := args.Get(0).(*entities.Transaction)
+	return transaction, args.Error(1)
+}
+
+func (m *MockTransactionService) ListByAccount(ctx context.Context, filter entities.TransactionFilter) (*entities.TransactionPage, error) {
+	args := m.Called(ctx, filter)
+	page, _ := args.Get(0).(*entities.TransactionPage)
+	return page, args.Error(1)
+}
+
+func (m *MockTransactionService) Deposit(ctx context.Context, req *entities.Transaction) (*entities.Transaction, error) {
+	args := m.Called(ctx, req)
+	transaction, _ := args.Get(0).(*entities.Transaction)
+	return transaction, args.Error(1)
+}
+
+func (m *MockTransactionService) Withdraw(ctx context.Context, req *entities.Transaction) (*entities.Transaction, error) {
+	args := m.Called(ctx, req)
+	transaction, _ := args.Get(0).(*entities.Transaction)
+	return transaction, args.Error(1)
 }
@@ -0,0 +1,18 @@
+package mocks
+
+import (
+	"context"
+	"transfer-system/domain/entities"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTransferBatchService struct {
+	mock.Mock
+}
+
+func (m *MockTransferBatchService) Execute(ctx context.Context, batch *entities.TransferBatch) ([]*entities.TransferInstructionResult, error) {
+	args := m.Called(ctx, batch)
+	results, _ := args.Get(0).([]*entities.TransferInstructionResult)
+	return results, args.Error(1)
+}
@@ -12,6 +12,10 @@ func (e *AppError) Error() string {
 	return e.Message
 }
 
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
 func NewBadRequestError(message string, err error) *AppError {
 	return &AppError{
 		Message:    message,
@@ -20,6 +24,46 @@ func NewBadRequestError(message string, err error) *AppError {
 	}
 }
 
+func NewNotFoundError(message string, err error) *AppError {
+	return &AppError{
+		Message:    message,
+		StatusCode: http.StatusNotFound,
+		Err:        err,
+	}
+}
+
+func NewConflictError(message string, err error) *AppError {
+	return &AppError{
+		Message:    message,
+		StatusCode: http.StatusConflict,
+		Err:        err,
+	}
+}
+
+func NewPreconditionFailedError(message string, err error) *AppError {
+	return &AppError{
+		Message:    message,
+		StatusCode: http.StatusPreconditionFailed,
+		Err:        err,
+	}
+}
+
+func NewPreconditionRequiredError(message string, err error) *AppError {
+	return &AppError{
+		Message:    message,
+		StatusCode: http.StatusPreconditionRequired,
+		Err:        err,
+	}
+}
+
+func NewUnprocessableError(message string, err error) *AppError {
+	return &AppError{
+		Message:    message,
+		StatusCode: http.StatusUnprocessableEntity,
+		Err:        err,
+	}
+}
+
 func NewInternalServerError(message string, err error) *AppError {
 	return &AppError{
 		Message:    message,
@@ -27,3 +71,43 @@ func NewInternalServerError(message string, err error) *AppError {
 		Err:        err,
 	}
 }
+
+func NewServiceUnavailableError(message string, err error) *AppError {
+	return &AppError{
+		Message:    message,
+		StatusCode: http.StatusServiceUnavailable,
+		Err:        err,
+	}
+}
+
+func NewTimeoutError(message string, err error) *AppError {
+	return &AppError{
+		Message:    message,
+		StatusCode: http.StatusGatewayTimeout,
+		Err:        err,
+	}
+}
+
+func NewUnauthorizedError(message string, err error) *AppError {
+	return &AppError{
+		Message:    message,
+		StatusCode: http.StatusUnauthorized,
+		Err:        err,
+	}
+}
+
+func NewForbiddenError(message string, err error) *AppError {
+	return &AppError{
+		Message:    message,
+		StatusCode: http.StatusForbidden,
+		Err:        err,
+	}
+}
+
+func NewPayloadTooLargeError(message string, err error) *AppError {
+	return &AppError{
+		Message:    message,
+		StatusCode: http.StatusRequestEntityTooLarge,
+		Err:        err,
+	}
+}
@@ -1,10 +1,85 @@
 package validator
 
-import "regexp"
+import (
+	"regexp"
+	"strings"
+)
 
+// ValidateDecimalFormat checks for a non-negative amount that fits the Money rule,
+// e.g. 100, 100.5 or 12345.12345
 func ValidateDecimalFormat(input string) bool {
-	// assuming that the input will always in number with 5 digits after the decimal point
-	// e.g. 12345.12345
-	regex := regexp.MustCompile(`^\d+\.\d{5}$`)
-	return regex.MatchString(input)
+	return Money.Match(input)
+}
+
+var referenceRegex = regexp.MustCompile(`^[A-Za-z0-9._:/-]{1,64}$`)
+
+// ValidateReference checks client supplied references such as account external references
+func ValidateReference(input string) bool {
+	return referenceRegex.MatchString(input)
+}
+
+var currencyRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// ValidateCurrency checks for an ISO 4217 alphabetic code, e.g. USD
+func ValidateCurrency(input string) bool {
+	return currencyRegex.MatchString(input)
+}
+
+var emailRegex = regexp.MustCompile(`^[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}$`)
+
+// ValidateEmail does a pragmatic syntax check, not full RFC 5322 parsing
+func ValidateEmail(input string) bool {
+	return len(input) <= 254 && emailRegex.MatchString(input)
+}
+
+var countryRegex = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// ValidateCountry checks for an ISO 3166-1 alpha-2 code, e.g. ID
+func ValidateCountry(input string) bool {
+	return countryRegex.MatchString(input)
+}
+
+// SEPA restricts payment text to the Latin character set below, so transfers
+// stay exportable to ISO 20022 messages
+var descriptionRegex = regexp.MustCompile(`^[A-Za-z0-9/?:().,'+ -]{1,140}$`)
+
+// ValidateDescription checks a free-text transfer purpose (SEPA unstructured remittance, max 140)
+func ValidateDescription(input string) bool {
+	return descriptionRegex.MatchString(input)
+}
+
+var paymentReferenceRegex = regexp.MustCompile(`^[A-Za-z0-9/?:().,'+-]{1,35}$`)
+
+// ValidatePaymentReference checks a client transfer reference (SEPA end-to-end id, max 35)
+func ValidatePaymentReference(input string) bool {
+	return paymentReferenceRegex.MatchString(input)
+}
+
+var creditorReferenceRegex = regexp.MustCompile(`^RF[0-9]{2}[A-Za-z0-9]{1,21}$`)
+
+// ValidateCreditorReference checks an ISO 11649 creditor reference, e.g. RF18539007547034,
+// including its mod 97 check digits
+func ValidateCreditorReference(input string) bool {
+	if !creditorReferenceRegex.MatchString(input) {
+		return false
+	}
+
+	// move "RF" and the check digits to the end, then map letters to 10..35
+	rearranged := strings.ToUpper(input[4:] + input[:4])
+	remainder := 0
+	for _, r := range rearranged {
+		if r >= 'A' && r <= 'Z' {
+			remainder = (remainder*100 + int(r-'A') + 10) % 97
+		} else {
+			remainder = (remainder*10 + int(r-'0')) % 97
+		}
+	}
+	return remainder == 1
+}
+
+var invoiceNumberRegex = regexp.MustCompile(`^[A-Za-z0-9/?:().,'+-]{1,35}$`)
+
+// ValidateInvoiceNumber checks the referred document number of structured remittance info
+func ValidateInvoiceNumber(input string) bool {
+	return invoiceNumberRegex.MatchString(input)
 }
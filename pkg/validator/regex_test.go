@@ -1,6 +1,7 @@
 package validator_test
 
 import (
+	"strings"
 	"testing"
 	"transfer-system/pkg/validator"
 )
@@ -32,21 +33,36 @@ func TestValidateDecimalFormat(t *testing.T) {
 			input:    "0.12345",
 			expected: true,
 		},
+		{
+			name:     "Valid format - less than 5 decimal digits",
+			input:    "100.5",
+			expected: true,
+		},
+		{
+			name:     "Valid format - no decimal part",
+			input:    "100",
+			expected: true,
+		},
+		{
+			name:     "Valid format - 15 integer digits",
+			input:    "123456789012345.12345",
+			expected: true,
+		},
 
 		// Invalid cases
 		{
-			name:     "Invalid - less than 5 decimal digits",
-			input:    "123.1234",
+			name:     "Invalid - 16 integer digits",
+			input:    "1234567890123456",
 			expected: false,
 		},
 		{
-			name:     "Invalid - more than 5 decimal digits",
-			input:    "123.123456",
+			name:     "Invalid - trailing decimal point",
+			input:    "123.",
 			expected: false,
 		},
 		{
-			name:     "Invalid - no decimal part",
-			input:    "123",
+			name:     "Invalid - more than 5 decimal digits",
+			input:    "123.123456",
 			expected: false,
 		},
 		{
@@ -91,3 +107,156 @@ func TestValidateDecimalFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateReference(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{name: "Valid - alphanumeric", input: "crm000123", expected: true},
+		{name: "Valid - with separators", input: "crm-000.123_a:b/c", expected: true},
+		{name: "Valid - max length", input: strings.Repeat("a", 64), expected: true},
+		{name: "Invalid - empty string", input: "", expected: false},
+		{name: "Invalid - too long", input: strings.Repeat("a", 65), expected: false},
+		{name: "Invalid - contains space", input: "crm 123", expected: false},
+		{name: "Invalid - contains quote", input: "crm'123", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validator.ValidateReference(tt.input)
+
+			if got != tt.expected {
+				t.Errorf("ValidateReference(%q) = %v; want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateCurrency(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{name: "Valid - USD", input: "USD", expected: true},
+		{name: "Valid - EUR", input: "EUR", expected: true},
+		{name: "Invalid - lowercase", input: "usd", expected: false},
+		{name: "Invalid - too short", input: "US", expected: false},
+		{name: "Invalid - too long", input: "USDT", expected: false},
+		{name: "Invalid - digits", input: "840", expected: false},
+		{name: "Invalid - empty string", input: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validator.ValidateCurrency(tt.input)
+
+			if got != tt.expected {
+				t.Errorf("ValidateCurrency(%q) = %v; want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{name: "Valid - simple", input: "jane@example.com", expected: true},
+		{name: "Valid - plus and subdomain", input: "jane.doe+bank@mail.example.co.id", expected: true},
+		{name: "Invalid - missing at", input: "jane.example.com", expected: false},
+		{name: "Invalid - missing domain", input: "jane@", expected: false},
+		{name: "Invalid - missing tld", input: "jane@example", expected: false},
+		{name: "Invalid - contains space", input: "jane doe@example.com", expected: false},
+		{name: "Invalid - empty string", input: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validator.ValidateEmail(tt.input)
+
+			if got != tt.expected {
+				t.Errorf("ValidateEmail(%q) = %v; want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateDescription(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{name: "Valid - sentence", input: "Invoice 2025/001, rent (March)", expected: true},
+		{name: "Valid - max length", input: strings.Repeat("a", 140), expected: true},
+		{name: "Invalid - empty string", input: "", expected: false},
+		{name: "Invalid - too long", input: strings.Repeat("a", 141), expected: false},
+		{name: "Invalid - newline", input: "rent\nMarch", expected: false},
+		{name: "Invalid - non latin", input: "sewa Maret ✓", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validator.ValidateDescription(tt.input)
+
+			if got != tt.expected {
+				t.Errorf("ValidateDescription(%q) = %v; want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidatePaymentReference(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{name: "Valid - alphanumeric", input: "PAY000123", expected: true},
+		{name: "Valid - with separators", input: "INV-2025/001", expected: true},
+		{name: "Valid - max length", input: strings.Repeat("a", 35), expected: true},
+		{name: "Invalid - empty string", input: "", expected: false},
+		{name: "Invalid - too long", input: strings.Repeat("a", 36), expected: false},
+		{name: "Invalid - contains space", input: "PAY 123", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validator.ValidatePaymentReference(tt.input)
+
+			if got != tt.expected {
+				t.Errorf("ValidatePaymentReference(%q) = %v; want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateCreditorReference(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{name: "Valid - numeric", input: "RF18539007547034", expected: true},
+		{name: "Valid - alphanumeric", input: "RF712348231", expected: true},
+		{name: "Invalid - wrong check digits", input: "RF19539007547034", expected: false},
+		{name: "Invalid - missing prefix", input: "18539007547034", expected: false},
+		{name: "Invalid - too long", input: "RF18" + strings.Repeat("1", 22), expected: false},
+		{name: "Invalid - empty string", input: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validator.ValidateCreditorReference(tt.input)
+
+			if got != tt.expected {
+				t.Errorf("ValidateCreditorReference(%q) = %v; want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
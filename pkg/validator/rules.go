@@ -0,0 +1,177 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// FieldError is a rule broken by one field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors lists every invalid field of a request, so clients can fix them in one round trip
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldError := range e {
+		messages = append(messages, fieldError.Field+": "+fieldError.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Rule checks a value, returning a message when the value breaks it
+type Rule[T any] func(value T) string
+
+// Field applies rules to the value of field in order and reports the first one
+// it breaks, so a missing value is not also reported as malformed
+func Field[T any](field string, value T, rules ...Rule[T]) Errors {
+	for _, rule := range rules {
+		if message := rule(value); message != "" {
+			return Errors{{Field: field, Message: message}}
+		}
+	}
+	return nil
+}
+
+// Validate joins the results of Field, returning nil when every field is valid
+func Validate(fields ...Errors) error {
+	var all Errors
+	for _, fieldErrors := range fields {
+		all = append(all, fieldErrors...)
+	}
+	if len(all) == 0 {
+		return nil
+	}
+	return all
+}
+
+// Required rejects empty strings
+func Required(value string) string {
+	if value == "" {
+		return "is required"
+	}
+	return ""
+}
+
+// MaxLength rejects strings longer than max bytes
+func MaxLength(max int) Rule[string] {
+	return func(value string) string {
+		if len(value) > max {
+			return fmt.Sprintf("must be at most %d characters", max)
+		}
+		return ""
+	}
+}
+
+// Date rejects strings that are not a YYYY-MM-DD date
+func Date(value string) string {
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		return "expected YYYY-MM-DD"
+	}
+	return ""
+}
+
+// Optional applies rules only to non-empty strings
+func Optional(rules ...Rule[string]) Rule[string] {
+	return func(value string) string {
+		if value == "" {
+			return ""
+		}
+		for _, rule := range rules {
+			if message := rule(value); message != "" {
+				return message
+			}
+		}
+		return ""
+	}
+}
+
+// Matches adapts one of the Validate functions of this package
+func Matches(valid func(string) bool, message string) Rule[string] {
+	return func(value string) string {
+		if !valid(value) {
+			return message
+		}
+		return ""
+	}
+}
+
+// OneOf accepts only the listed values
+func OneOf(values ...string) Rule[string] {
+	return func(value string) string {
+		for _, allowed := range values {
+			if value == allowed {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(values, ", ")
+	}
+}
+
+// Min rejects integers below min, such as ids that must be positive
+func Min(min int64) Rule[int64] {
+	return func(value int64) string {
+		if value < min {
+			return fmt.Sprintf("must be at least %d", min)
+		}
+		return ""
+	}
+}
+
+// NotEqual rejects other, naming the field it has to differ from
+func NotEqual[T comparable](other T, otherField string) Rule[T] {
+	return func(value T) string {
+		if value == other {
+			return "must differ from " + otherField
+		}
+		return ""
+	}
+}
+
+var decimalRegex = regexp.MustCompile(`^(\d+)(?:\.(\d+))?$`)
+
+// DecimalRule bounds plain, non-negative decimal strings like a NUMERIC(Precision, Scale)
+// column: at most Scale fraction digits and Precision-Scale integer digits
+type DecimalRule struct {
+	Precision int
+	Scale     int
+}
+
+// Money matches the NUMERIC(20, 5) columns balances and amounts are stored in
+var Money = DecimalRule{Precision: 20, Scale: 5}
+
+// Match reports whether input is a decimal within the rule, e.g. "100", "100.5" or "100.12345" for Money
+func (r DecimalRule) Match(input string) bool {
+	parts := decimalRegex.FindStringSubmatch(input)
+	if parts == nil {
+		return false
+	}
+	integer := strings.TrimLeft(parts[1], "0")
+	return len(parts[2]) <= r.Scale && len(integer) <= r.Precision-r.Scale
+}
+
+// Decimal rejects strings that are not decimals within rule
+func Decimal(rule DecimalRule) Rule[string] {
+	return func(value string) string {
+		if !rule.Match(value) {
+			return fmt.Sprintf("must be a decimal with at most %d integer and %d fraction digits", rule.Precision-rule.Scale, rule.Scale)
+		}
+		return ""
+	}
+}
+
+// Positive rejects decimals that are zero; apply it after Decimal
+func Positive(value string) string {
+	amount, err := decimal.NewFromString(value)
+	if err != nil || !amount.IsPositive() {
+		return "must be greater than zero"
+	}
+	return ""
+}
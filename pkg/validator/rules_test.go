@@ -0,0 +1,53 @@
+package validator_test
+
+import (
+	"testing"
+
+	"transfer-system/pkg/validator"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecimalRule_Match(t *testing.T) {
+	rule := validator.DecimalRule{Precision: 6, Scale: 2}
+
+	for input, expected := range map[string]bool{
+		"1234":     true,
+		"1234.5":   true,
+		"1234.56":  true,
+		"001234.5": true,
+		"12345":    false,
+		"1.567":    false,
+		"-1":       false,
+		"1e3":      false,
+		"":         false,
+	} {
+		assert.Equal(t, expected, rule.Match(input), input)
+	}
+}
+
+func TestValidate_CollectsEveryField(t *testing.T) {
+	err := validator.Validate(
+		validator.Field("amount", "", validator.Required, validator.Decimal(validator.Money), validator.Positive),
+		validator.Field("source", int64(0), validator.Min(1)),
+		validator.Field("currency", "USD", validator.Optional(validator.Matches(validator.ValidateCurrency, "bad currency"))),
+		validator.Field("kind", "equity", validator.OneOf("customer", "settlement")),
+	)
+
+	assert.Equal(t, validator.Errors{
+		{Field: "amount", Message: "is required"},
+		{Field: "source", Message: "must be at least 1"},
+		{Field: "kind", Message: "must be one of customer, settlement"},
+	}, err)
+	assert.EqualError(t, err, "amount: is required; source: must be at least 1; kind: must be one of customer, settlement")
+}
+
+func TestValidate_Valid(t *testing.T) {
+	err := validator.Validate(
+		validator.Field("amount", "0.5", validator.Required, validator.Decimal(validator.Money), validator.Positive),
+		validator.Field("destination", int64(2), validator.NotEqual(int64(1), "source")),
+		validator.Field("date", "", validator.Optional(validator.Date)),
+	)
+
+	assert.NoError(t, err)
+}